@@ -0,0 +1,191 @@
+package handler
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"time"
+
+	"github.com/valyala/fasthttp"
+	"go.uber.org/zap"
+
+	"github.com/fastygo/backend/api/transport"
+	"github.com/fastygo/backend/domain"
+	"github.com/fastygo/backend/internal/infrastructure/buffer"
+	"github.com/fastygo/backend/internal/services"
+	"github.com/fastygo/backend/pkg/httpcontext"
+)
+
+// importBatchSize bounds how many imported items are decoded before they are written.
+const importBatchSize = 500
+
+// BufferDrainer runs a synchronous buffer drain.
+type BufferDrainer interface {
+	Drain(ctx context.Context) (services.DrainResult, error)
+}
+
+// BufferArchive walks and restores the queued buffer contents.
+type BufferArchive interface {
+	ForEach(fn func(buffer.Item) error) error
+	Import(items []buffer.Item) (int, error)
+}
+
+// ImportResult summarises a buffer import.
+type ImportResult struct {
+	Imported int `json:"imported"`
+	Skipped  int `json:"skipped"`
+}
+
+type AdminHandler struct {
+	baseHandler
+	buffer      BufferDrainer
+	archive     BufferArchive
+	syncTimeout time.Duration
+}
+
+func NewAdminHandler(buffer BufferDrainer, archive BufferArchive, adapter *httpcontext.Adapter, logger *zap.Logger, syncTimeout time.Duration, opts ...Option) *AdminHandler {
+	if syncTimeout <= 0 {
+		syncTimeout = 30 * time.Second
+	}
+	return &AdminHandler{
+		baseHandler: newBaseHandler(adapter, logger, opts...),
+		buffer:      buffer,
+		archive:     archive,
+		syncTimeout: syncTimeout,
+	}
+}
+
+// @Summary Force a buffer drain and report the outcome
+// @Tags admin
+// @Router /admin/buffer/sync [post]
+func (h *AdminHandler) SyncBuffer(ctx *fasthttp.RequestCtx) {
+	stdCtx, cancel := h.requestContext(ctx)
+	defer cancel()
+
+	// The drain gets its own deadline rather than inheriting the (shorter) request timeout.
+	drainCtx, drainCancel := context.WithTimeout(context.WithoutCancel(stdCtx), h.syncTimeout)
+	defer drainCancel()
+
+	result, err := h.buffer.Drain(drainCtx)
+	if errors.Is(err, services.ErrDrainInProgress) {
+		h.respondError(ctx, err)
+		return
+	}
+	if err != nil {
+		h.ctxLogger(stdCtx).Error("manual buffer sync failed", zap.Error(err))
+		h.respondError(ctx, err)
+		return
+	}
+	h.respondSuccess(ctx, http.StatusOK, result)
+}
+
+// @Summary Stream all queued buffer items as newline-delimited JSON
+// @Tags admin
+// @Produce application/x-ndjson
+// @Router /admin/buffer/export [get]
+func (h *AdminHandler) ExportBuffer(ctx *fasthttp.RequestCtx) {
+	stdCtx, cancel := h.requestContext(ctx)
+	// The stream is written after the handler returns; only the request ID is needed from stdCtx.
+	logger := h.ctxLogger(stdCtx)
+	cancel()
+
+	ctx.Response.Header.SetContentType("application/x-ndjson")
+	ctx.SetStatusCode(http.StatusOK)
+	ctx.SetBodyStreamWriter(func(w *bufio.Writer) {
+		encoder := json.NewEncoder(w)
+		exported := 0
+		err := h.archive.ForEach(func(item buffer.Item) error {
+			exported++
+			return encoder.Encode(item)
+		})
+		if err == nil {
+			err = w.Flush()
+		}
+		if err != nil {
+			// Headers are already sent; the truncated stream is the client's signal.
+			logger.Error("buffer export aborted", zap.Int("exported", exported), zap.Error(err))
+			return
+		}
+		logger.Info("buffer exported", zap.Int("items", exported))
+	})
+}
+
+// @Summary Restore buffer items from a newline-delimited JSON export
+// @Description Items keep their IDs and retry counts; operations already queued are skipped. Items are
+// @Description written in batches, so a malformed line leaves earlier lines imported and the
+// @Description request can simply be retried.
+// @Tags admin
+// @Accept application/x-ndjson
+// @Router /admin/buffer/import [post]
+func (h *AdminHandler) ImportBuffer(ctx *fasthttp.RequestCtx) {
+	if !h.lenientContentType && !isNDJSONContentType(string(ctx.Request.Header.ContentType())) {
+		h.respondJSON(ctx, http.StatusUnsupportedMediaType, transport.NewError(ErrCodeUnsupportedMediaType, "content type must be application/x-ndjson", nil))
+		return
+	}
+
+	stdCtx, cancel := h.requestContext(ctx)
+	defer cancel()
+	logger := h.ctxLogger(stdCtx)
+
+	var result ImportResult
+	flush := func(batch []buffer.Item) error {
+		imported, err := h.archive.Import(batch)
+		result.Imported += imported
+		result.Skipped += len(batch) - imported
+		return err
+	}
+
+	decoder := json.NewDecoder(requestBody(ctx))
+	batch := make([]buffer.Item, 0, importBatchSize)
+	for line := 1; ; line++ {
+		var item buffer.Item
+		err := decoder.Decode(&item)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			h.respondJSON(ctx, http.StatusBadRequest, transport.NewError(string(domain.ErrCodeInvalid), fmt.Sprintf("invalid item %d", line), map[string]interface{}{
+				"imported": result.Imported,
+				"skipped":  result.Skipped,
+			}))
+			return
+		}
+		if batch = append(batch, item); len(batch) == importBatchSize {
+			if err := flush(batch); err != nil {
+				logger.Error("buffer import failed", zap.Error(err))
+				h.respondError(ctx, err)
+				return
+			}
+			batch = batch[:0]
+		}
+	}
+	if len(batch) > 0 {
+		if err := flush(batch); err != nil {
+			logger.Error("buffer import failed", zap.Error(err))
+			h.respondError(ctx, err)
+			return
+		}
+	}
+
+	logger.Info("buffer imported", zap.Int("imported", result.Imported), zap.Int("skipped", result.Skipped))
+	h.respondSuccess(ctx, http.StatusOK, result)
+}
+
+// requestBody reads the body incrementally when the server streams request bodies.
+func requestBody(ctx *fasthttp.RequestCtx) io.Reader {
+	if stream := ctx.RequestBodyStream(); stream != nil {
+		return stream
+	}
+	return bytes.NewReader(ctx.PostBody())
+}
+
+func isNDJSONContentType(value string) bool {
+	mediaType, _, err := mime.ParseMediaType(value)
+	return err == nil && mediaType == "application/x-ndjson"
+}
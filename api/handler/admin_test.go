@@ -0,0 +1,204 @@
+package handler_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+
+	apiHandler "github.com/fastygo/backend/api/handler"
+	"github.com/fastygo/backend/domain"
+	"github.com/fastygo/backend/internal/infrastructure/buffer"
+	"github.com/fastygo/backend/internal/infrastructure/buffer/buffertest"
+	"github.com/fastygo/backend/internal/services"
+	"github.com/fastygo/backend/pkg/clock"
+	"github.com/fastygo/backend/pkg/httpcontext"
+	"github.com/fastygo/backend/repository/repositorytest"
+)
+
+func bufferedTask(t *testing.T, id, operation string, task domain.Task, retries int) buffer.Item {
+	t.Helper()
+	data, err := json.Marshal(task)
+	if err != nil {
+		t.Fatalf("marshal task: %v", err)
+	}
+	return buffer.Item{ID: id, Entity: buffer.EntityTask, Operation: operation, Data: data, Retries: retries}
+}
+
+func TestSyncBufferReportsSeededOutcome(t *testing.T) {
+	store := buffertest.NewMemoryStore(clock.NewFake(time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)))
+	seed := []buffer.Item{
+		bufferedTask(t, "create-1", buffer.OperationCreate, domain.Task{ID: "t1", UserID: "u1"}, 0),
+		bufferedTask(t, "create-2", buffer.OperationCreate, domain.Task{ID: "t2", UserID: "u1"}, 0),
+		bufferedTask(t, "update-missing", buffer.OperationUpdate, domain.Task{ID: "missing", UserID: "u1"}, 0),
+		bufferedTask(t, "exhausted", buffer.OperationUpdate, domain.Task{ID: "missing", UserID: "u1"}, 3),
+	}
+	for _, item := range seed {
+		if err := store.Enqueue(item); err != nil {
+			t.Fatalf("enqueue %s: %v", item.ID, err)
+		}
+	}
+	processor, err := services.NewBufferProcessor(store, nil, repositorytest.NewUsers(), repositorytest.NewTasks(), nil, services.ProcessorConfig{MaxRetries: 3})
+	if err != nil {
+		t.Fatalf("new processor: %v", err)
+	}
+	h := apiHandler.NewAdminHandler(processor, nil, nil, nil, time.Second)
+
+	ctx := newRequestCtx(testRequest{method: http.MethodPost, uri: "/admin/buffer/sync"})
+	h.SyncBuffer(ctx)
+
+	if ctx.Response.StatusCode() != http.StatusOK {
+		t.Fatalf("status = %d, want 200; body %s", ctx.Response.StatusCode(), ctx.Response.Body())
+	}
+	var body struct {
+		Data services.DrainResult `json:"data"`
+	}
+	if err := json.Unmarshal(ctx.Response.Body(), &body); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	got := body.Data
+	if got.Attempted != 4 || got.Succeeded != 2 || got.Requeued != 1 || got.DeadLettered != 1 || got.RemainingEstimate != 1 {
+		t.Fatalf("summary = %+v, want attempted 4, succeeded 2, requeued 1, dead-lettered 1, remaining 1", got)
+	}
+}
+
+type failingDrainer struct{}
+
+func (failingDrainer) Drain(context.Context) (services.DrainResult, error) {
+	return services.DrainResult{}, errors.New("bolt closed")
+}
+
+func TestSyncBufferLogsCarryRequestID(t *testing.T) {
+	core, logs := observer.New(zapcore.DebugLevel)
+	h := apiHandler.NewAdminHandler(failingDrainer{}, nil, httpcontext.NewAdapter(time.Second), zap.New(core), time.Second)
+
+	ctx := newRequestCtx(testRequest{
+		method:  http.MethodPost,
+		uri:     "/admin/buffer/sync",
+		headers: map[string]string{"X-Request-ID": "req-sync-1"},
+	})
+	h.SyncBuffer(ctx)
+
+	entries := logs.FilterMessage("manual buffer sync failed").All()
+	if len(entries) != 1 {
+		t.Fatalf("logged %d sync failures, want 1", len(entries))
+	}
+	if got := entries[0].ContextMap()["request_id"]; got != "req-sync-1" {
+		t.Fatalf("request_id = %v, want req-sync-1", got)
+	}
+}
+
+type busyDrainer struct{}
+
+func (busyDrainer) Drain(context.Context) (services.DrainResult, error) {
+	return services.DrainResult{}, services.ErrDrainInProgress
+}
+
+func TestSyncBufferConflictsWithRunningDrain(t *testing.T) {
+	h := apiHandler.NewAdminHandler(busyDrainer{}, nil, nil, nil, time.Second)
+
+	ctx := newRequestCtx(testRequest{method: http.MethodPost, uri: "/admin/buffer/sync"})
+	h.SyncBuffer(ctx)
+
+	if ctx.Response.StatusCode() != http.StatusConflict {
+		t.Fatalf("status = %d, want 409", ctx.Response.StatusCode())
+	}
+}
+
+func openBufferStore(t *testing.T, fake *clock.Fake) *buffer.Store {
+	t.Helper()
+	store, err := buffer.Open(filepath.Join(t.TempDir(), "buffer.db"), "buffer", buffer.WithClock(fake))
+	if err != nil {
+		t.Fatalf("open buffer: %v", err)
+	}
+	t.Cleanup(func() { store.Close() })
+	return store
+}
+
+func TestExportImportRoundTripsBuffer(t *testing.T) {
+	fake := clock.NewFake(time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC))
+	source := openBufferStore(t, fake)
+	seed := []buffer.Item{
+		bufferedTask(t, "create-1", buffer.OperationCreate, domain.Task{ID: "t1", UserID: "u1"}, 0),
+		bufferedTask(t, "update-1", buffer.OperationUpdate, domain.Task{ID: "t2", UserID: "u1"}, 2),
+		// Older builds keyed buffered task ops by the task ID, so both ops below share an item ID.
+		bufferedTask(t, "t3", buffer.OperationCreate, domain.Task{ID: "t3", UserID: "u1"}, 0),
+		bufferedTask(t, "t3", buffer.OperationUpdate, domain.Task{ID: "t3", UserID: "u1", Title: "renamed"}, 0),
+	}
+	seed[0].Timestamp = fake.Now()
+	seed[1].NextAttempt = fake.Now().Add(time.Hour)
+	for _, item := range seed {
+		if err := source.Enqueue(item); err != nil {
+			t.Fatalf("enqueue %s: %v", item.ID, err)
+		}
+		fake.Advance(time.Millisecond)
+	}
+
+	exportCtx := newRequestCtx(testRequest{method: http.MethodGet, uri: "/admin/buffer/export"})
+	apiHandler.NewAdminHandler(nil, source, nil, nil, time.Second).ExportBuffer(exportCtx)
+	if exportCtx.Response.StatusCode() != http.StatusOK {
+		t.Fatalf("export status = %d, want 200", exportCtx.Response.StatusCode())
+	}
+	exported := string(exportCtx.Response.Body())
+	if lines := strings.Count(exported, "\n"); lines != len(seed) {
+		t.Fatalf("export has %d lines, want %d:\n%s", lines, len(seed), exported)
+	}
+
+	target := openBufferStore(t, fake)
+	if err := target.Enqueue(seed[0]); err != nil {
+		t.Fatalf("enqueue duplicate: %v", err)
+	}
+	h := apiHandler.NewAdminHandler(nil, target, nil, nil, time.Second)
+	importCtx := newRequestCtx(testRequest{method: http.MethodPost, uri: "/admin/buffer/import", contentType: "application/x-ndjson", body: exported})
+	h.ImportBuffer(importCtx)
+	if importCtx.Response.StatusCode() != http.StatusOK {
+		t.Fatalf("import status = %d, want 200; body %s", importCtx.Response.StatusCode(), importCtx.Response.Body())
+	}
+	var body struct {
+		Data apiHandler.ImportResult `json:"data"`
+	}
+	if err := json.Unmarshal(importCtx.Response.Body(), &body); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if body.Data.Imported != 3 || body.Data.Skipped != 1 {
+		t.Fatalf("result = %+v, want 3 imported and the queued duplicate skipped", body.Data)
+	}
+
+	restored := make(map[string]buffer.Item)
+	if err := target.ForEach(func(item buffer.Item) error {
+		restored[item.ID+"/"+item.Operation] = item
+		return nil
+	}); err != nil {
+		t.Fatalf("walk target: %v", err)
+	}
+	got, ok := restored["update-1/"+buffer.OperationUpdate]
+	if len(restored) != len(seed) || !ok {
+		t.Fatalf("restored = %v, want every seeded op", restored)
+	}
+	if _, ok := restored["t3/"+buffer.OperationUpdate]; !ok {
+		t.Fatalf("restored = %v, want both ops of task t3", restored)
+	}
+	if got.Retries != 2 || !got.NextAttempt.Equal(seed[1].NextAttempt) || string(got.Data) != string(seed[1].Data) {
+		t.Fatalf("restored item = %+v, want retries, schedule and payload preserved", got)
+	}
+}
+
+func TestImportBufferRejectsMalformedLine(t *testing.T) {
+	target := openBufferStore(t, clock.NewFake(time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)))
+	h := apiHandler.NewAdminHandler(nil, target, nil, nil, time.Second)
+
+	ctx := newRequestCtx(testRequest{method: http.MethodPost, uri: "/admin/buffer/import", contentType: "application/x-ndjson", body: `{"id":"a","entity":"task","operation":"create"}` + "\n{not json\n"})
+	h.ImportBuffer(ctx)
+
+	if ctx.Response.StatusCode() != http.StatusBadRequest {
+		t.Fatalf("status = %d, want 400", ctx.Response.StatusCode())
+	}
+}
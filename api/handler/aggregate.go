@@ -0,0 +1,186 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/valyala/fasthttp"
+	"go.uber.org/zap"
+
+	"github.com/fastygo/backend/api/transport"
+	"github.com/fastygo/backend/domain"
+	"github.com/fastygo/backend/pkg/httpcontext"
+	"github.com/fastygo/backend/repository"
+	aggregateUC "github.com/fastygo/backend/usecase/aggregate"
+)
+
+// Batch modes accepted by SaveBatch.
+const (
+	batchModeAtomic     = "atomic"
+	batchModeBestEffort = "best_effort"
+	batchModeCopy       = "copy"
+)
+
+type AggregateHandler struct {
+	baseHandler
+	uc *aggregateUC.UseCase
+}
+
+func NewAggregateHandler(uc *aggregateUC.UseCase, adapter *httpcontext.Adapter, logger *zap.Logger, opts ...Option) *AggregateHandler {
+	return &AggregateHandler{
+		baseHandler: newBaseHandler(adapter, logger, opts...),
+		uc:          uc,
+	}
+}
+
+// aggregateResult is the per-item outcome of a batch save.
+type aggregateResult struct {
+	ID    string `json:"id"`
+	Saved bool   `json:"saved"`
+	Code  string `json:"code,omitempty"`
+	Error string `json:"error,omitempty"`
+}
+
+// @Summary Upsert aggregates in one transaction
+// @Description mode "atomic" (default) rolls back on the first failing item; "best_effort" saves
+// @Description the rest and reports failures per item. "copy" bulk-inserts new aggregates for
+// @Description migrations: it is insert-only, so an ID that already exists fails the whole batch
+// @Description with 409, and it allows AGGREGATE_MAX_IMPORT_SIZE items instead of the batch maximum.
+// @Description Every aggregate is owned by the caller and their tenant; owner_id in the body is ignored.
+// @Tags aggregates
+// @Router /api/v1/aggregates/batch [post]
+func (h *AggregateHandler) SaveBatch(ctx *fasthttp.RequestCtx) {
+	userID := string(ctx.Request.Header.Peek("X-User-ID"))
+	if userID == "" {
+		h.respondJSON(ctx, http.StatusUnauthorized, transport.NewError(string(domain.ErrCodeUnauthorized), "missing user id", nil))
+		return
+	}
+
+	var req transport.AggregateBatchRequest
+	if !h.decodeJSON(ctx, &req) {
+		return
+	}
+	if req.Mode == "" {
+		req.Mode = batchModeAtomic
+	}
+	if req.Mode != batchModeAtomic && req.Mode != batchModeBestEffort && req.Mode != batchModeCopy {
+		h.respondJSON(ctx, http.StatusBadRequest, transport.NewError(string(domain.ErrCodeInvalid), transport.FieldError{
+			Field:   "mode",
+			Message: "must be atomic, best_effort or copy",
+		}, nil))
+		return
+	}
+
+	stdCtx, cancel := h.requestContext(ctx)
+	defer cancel()
+	tenantID := httpcontext.TenantID(stdCtx)
+
+	aggregates := make([]*domain.Aggregate, len(req.Aggregates))
+	for i := range req.Aggregates {
+		aggregate := req.Aggregates[i]
+		// Ownership comes from the token; an owner_id in the body is ignored.
+		aggregate.TenantID = tenantID
+		aggregate.OwnerID = userID
+		aggregates[i] = &aggregate
+	}
+
+	if req.Mode == batchModeCopy {
+		if err := h.uc.Import(stdCtx, aggregates); err != nil {
+			h.respondBatchError(ctx, err)
+			return
+		}
+		out := make([]aggregateResult, len(aggregates))
+		for i, aggregate := range aggregates {
+			out[i] = aggregateResult{ID: aggregate.ID, Saved: true}
+		}
+		h.respondJSON(ctx, http.StatusOK, transport.NewSuccess(out, map[string]interface{}{
+			"saved":  len(out),
+			"failed": 0,
+		}))
+		return
+	}
+
+	results, err := h.uc.SaveBatch(stdCtx, aggregates, req.Mode == batchModeBestEffort)
+	if err != nil {
+		h.respondBatchError(ctx, err)
+		return
+	}
+
+	out := make([]aggregateResult, len(results))
+	failed := 0
+	for i, result := range results {
+		out[i] = aggregateResult{ID: result.ID, Saved: result.Err == nil}
+		if result.Err != nil {
+			failed++
+			_, out[i].Code = mapError(result.Err)
+			out[i].Error = result.Err.Error()
+		}
+	}
+	h.respondJSON(ctx, http.StatusOK, transport.NewSuccess(out, map[string]interface{}{
+		"saved":  len(results) - failed,
+		"failed": failed,
+	}))
+}
+
+// respondBatchError points at the failing item when there is one.
+func (h *AggregateHandler) respondBatchError(ctx *fasthttp.RequestCtx, err error) {
+	var itemErr *repository.BatchItemError
+	if errors.As(err, &itemErr) {
+		status, code := mapError(itemErr.Err)
+		h.respondJSON(ctx, status, transport.NewError(code, itemErr.Err.Error(), map[string]interface{}{
+			"index": itemErr.Index,
+			"id":    itemErr.ID,
+		}))
+		return
+	}
+	h.respondError(ctx, err)
+}
+
+// @Summary Diff an aggregate between two versions
+// @Description Rebuilds the payload at "from" and "to" from the aggregate's events and lists the changes.
+// @Tags aggregates
+// @Router /api/v1/aggregates/{id}/diff [get]
+func (h *AggregateHandler) Diff(ctx *fasthttp.RequestCtx) {
+	if string(ctx.Request.Header.Peek("X-User-ID")) == "" {
+		h.respondJSON(ctx, http.StatusUnauthorized, transport.NewError(string(domain.ErrCodeUnauthorized), "missing user id", nil))
+		return
+	}
+
+	id, _ := ctx.UserValue("id").(string)
+	if id == "" {
+		h.respondJSON(ctx, http.StatusBadRequest, transport.NewError(string(domain.ErrCodeInvalid), "missing aggregate id", nil))
+		return
+	}
+	from, ok := h.versionArg(ctx, "from")
+	if !ok {
+		return
+	}
+	to, ok := h.versionArg(ctx, "to")
+	if !ok {
+		return
+	}
+
+	stdCtx, cancel := h.requestContext(ctx)
+	defer cancel()
+
+	diff, err := h.uc.Diff(stdCtx, id, httpcontext.TenantID(stdCtx), from, to)
+	if err != nil {
+		h.respondError(ctx, err)
+		return
+	}
+	h.respondSuccess(ctx, http.StatusOK, diff)
+}
+
+// versionArg reads a required, non-negative version query argument, answering 400 when it is not one.
+func (h *AggregateHandler) versionArg(ctx *fasthttp.RequestCtx, name string) (int, bool) {
+	version, err := strconv.Atoi(string(ctx.QueryArgs().Peek(name)))
+	if err != nil || version < 0 {
+		h.respondJSON(ctx, http.StatusBadRequest, transport.NewError(string(domain.ErrCodeInvalid), transport.FieldError{
+			Field:   name,
+			Message: "must be a non-negative integer",
+		}, nil))
+		return 0, false
+	}
+	return version, true
+}
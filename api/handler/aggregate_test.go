@@ -0,0 +1,186 @@
+package handler_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/valyala/fasthttp"
+
+	apiHandler "github.com/fastygo/backend/api/handler"
+	"github.com/fastygo/backend/domain"
+	"github.com/fastygo/backend/repository/repositorytest"
+	aggregateUC "github.com/fastygo/backend/usecase/aggregate"
+)
+
+const aggregateBatchBody = `{"mode":%q,"aggregates":[
+	{"id":"a1","kind":"deal","version":1},
+	{"id":"a2","kind":"","version":1},
+	{"id":"a3","kind":"deal","version":1,"owner_id":"someone-else"}
+]}`
+
+func postAggregateBatch(t *testing.T, repo *repositorytest.Aggregates, mode string) map[string]interface{} {
+	t.Helper()
+	h := apiHandler.NewAggregateHandler(aggregateUC.New(repo, nil), nil, nil)
+	ctx := newRequestCtx(testRequest{
+		method:      http.MethodPost,
+		uri:         "/api/v1/aggregates/batch",
+		contentType: "application/json",
+		headers:     map[string]string{"X-User-ID": "user-1"},
+		body:        fmt.Sprintf(aggregateBatchBody, mode),
+	})
+	h.SaveBatch(ctx)
+
+	var body map[string]interface{}
+	if err := json.Unmarshal(ctx.Response.Body(), &body); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	body["http_status"] = ctx.Response.StatusCode()
+	return body
+}
+
+func TestSaveAggregateBatchAtomicReportsFailingItem(t *testing.T) {
+	repo := repositorytest.NewAggregates()
+
+	body := postAggregateBatch(t, repo, "atomic")
+
+	if body["http_status"] != http.StatusBadRequest {
+		t.Fatalf("status = %v, want 400; body %v", body["http_status"], body)
+	}
+	if meta, _ := body["meta"].(map[string]interface{}); meta["index"] != float64(1) || meta["id"] != "a2" {
+		t.Fatalf("meta = %v, want the failing item", body["meta"])
+	}
+	if _, err := repo.Get(context.Background(), "a1"); err == nil {
+		t.Fatal("a1 saved despite the rollback")
+	}
+}
+
+func TestSaveAggregateBatchBestEffortSavesValidItems(t *testing.T) {
+	repo := repositorytest.NewAggregates()
+
+	body := postAggregateBatch(t, repo, "best_effort")
+
+	if body["http_status"] != http.StatusOK {
+		t.Fatalf("status = %v, want 200; body %v", body["http_status"], body)
+	}
+	if meta, _ := body["meta"].(map[string]interface{}); meta["saved"] != float64(2) || meta["failed"] != float64(1) {
+		t.Fatalf("meta = %v, want 2 saved and 1 failed", body["meta"])
+	}
+	results, _ := body["data"].([]interface{})
+	if failed, _ := results[1].(map[string]interface{}); failed["saved"] != false || failed["code"] != string(domain.ErrCodeInvalid) {
+		t.Fatalf("item 1 = %v, want an invalid-payload failure", results[1])
+	}
+	stored, err := repo.Get(context.Background(), "a3")
+	if err != nil || stored.OwnerID != "user-1" {
+		t.Fatalf("a3 = %+v, %v; want it saved and owned by the caller", stored, err)
+	}
+}
+
+func TestAggregateDiff(t *testing.T) {
+	repo := repositorytest.NewAggregates(domain.Aggregate{ID: "deal-1", Kind: "deal", Version: 2})
+	for _, event := range []domain.Event{
+		{AggregateID: "deal-1", Version: 1, Payload: json.RawMessage(`{"stage":"lead","amount":100}`)},
+		{AggregateID: "deal-1", Version: 2, Payload: json.RawMessage(`{"stage":"won"}`)},
+	} {
+		if err := repo.AppendEvent(context.Background(), event); err != nil {
+			t.Fatalf("append: %v", err)
+		}
+	}
+	h := apiHandler.NewAggregateHandler(aggregateUC.New(repo, nil), nil, nil)
+
+	tests := []struct {
+		name   string
+		id     string
+		query  string
+		status int
+		body   string
+	}{
+		{"changes", "deal-1", "from=1&to=2", http.StatusOK, `{"aggregate_id":"deal-1","changes":[{"from":"lead","op":"changed","path":"/stage","to":"won"}],"from":1,"to":2}`},
+		{"from empty state", "deal-1", "from=0&to=1", http.StatusOK, `{"aggregate_id":"deal-1","changes":[{"op":"added","path":"","to":{"amount":100,"stage":"lead"}}],"from":0,"to":1}`},
+		{"missing version", "deal-1", "from=1&to=7", http.StatusNotFound, ""},
+		{"missing aggregate", "deal-9", "from=1&to=2", http.StatusNotFound, ""},
+		{"bad version", "deal-1", "from=x&to=2", http.StatusBadRequest, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := newRequestCtx(testRequest{
+				method:  http.MethodGet,
+				uri:     "/api/v1/aggregates/" + tt.id + "/diff?" + tt.query,
+				headers: map[string]string{"X-User-ID": "user-1"},
+			})
+			ctx.SetUserValue("id", tt.id)
+			h.Diff(ctx)
+
+			if ctx.Response.StatusCode() != tt.status {
+				t.Fatalf("status = %d, want %d; body %s", ctx.Response.StatusCode(), tt.status, ctx.Response.Body())
+			}
+			if tt.body == "" {
+				return
+			}
+			data, _ := json.Marshal(decodeEnvelope(t, ctx).Data)
+			if string(data) != tt.body {
+				t.Fatalf("data = %s, want %s", data, tt.body)
+			}
+		})
+	}
+}
+
+func TestSaveAggregateBatchOverLimitExplainsMaximum(t *testing.T) {
+	const limit = 100
+	h := apiHandler.NewAggregateHandler(aggregateUC.New(repositorytest.NewAggregates(), nil, aggregateUC.WithMaxBatchSize(limit)), nil, nil)
+
+	items := make([]string, limit+1)
+	for i := range items {
+		items[i] = fmt.Sprintf(`{"id":"a%d","kind":"deal","version":1}`, i)
+	}
+	ctx := newRequestCtx(testRequest{
+		method:      http.MethodPost,
+		uri:         "/api/v1/aggregates/batch",
+		contentType: "application/json",
+		headers:     map[string]string{"X-User-ID": "user-1"},
+		body:        `{"aggregates":[` + strings.Join(items, ",") + `]}`,
+	})
+	h.SaveBatch(ctx)
+
+	if ctx.Response.StatusCode() != http.StatusBadRequest {
+		t.Fatalf("status = %d, want 400; body %s", ctx.Response.StatusCode(), ctx.Response.Body())
+	}
+	if body := string(ctx.Response.Body()); !strings.Contains(body, "maximum of 100") {
+		t.Fatalf("body %s does not state the maximum batch size", body)
+	}
+}
+
+func TestSaveAggregateBatchCopyModeImports(t *testing.T) {
+	repo := repositorytest.NewAggregates(domain.Aggregate{ID: "a2", Kind: "deal", Version: 1})
+	copyBatch := func(ids ...string) *fasthttp.RequestCtx {
+		items := make([]string, len(ids))
+		for i, id := range ids {
+			items[i] = fmt.Sprintf(`{"id":%q,"kind":"deal","version":1,"owner_id":"someone-else"}`, id)
+		}
+		ctx := newRequestCtx(testRequest{
+			method:      http.MethodPost,
+			uri:         "/api/v1/aggregates/batch",
+			contentType: "application/json",
+			headers:     map[string]string{"X-User-ID": "user-1"},
+			body:        `{"mode":"copy","aggregates":[` + strings.Join(items, ",") + `]}`,
+		})
+		apiHandler.NewAggregateHandler(aggregateUC.New(repo, nil), nil, nil).SaveBatch(ctx)
+		return ctx
+	}
+
+	ctx := copyBatch("a1", "a3")
+	if ctx.Response.StatusCode() != http.StatusOK {
+		t.Fatalf("status = %d, want 200; body %s", ctx.Response.StatusCode(), ctx.Response.Body())
+	}
+	imported, err := repo.Get(context.Background(), "a3")
+	if err != nil || imported.OwnerID != "user-1" {
+		t.Fatalf("a3 = %+v, %v; want it imported and owned by the caller", imported, err)
+	}
+
+	if ctx := copyBatch("a4", "a2"); ctx.Response.StatusCode() != http.StatusConflict {
+		t.Fatalf("status = %d, want 409 for an ID that already exists", ctx.Response.StatusCode())
+	}
+}
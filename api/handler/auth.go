@@ -1,7 +1,6 @@
 package handler
 
 import (
-	"encoding/json"
 	"net/http"
 	"time"
 
@@ -16,27 +15,32 @@ import (
 
 type AuthHandler struct {
 	baseHandler
-	uc        *authUC.UseCase
+	uc         *authUC.UseCase
 	defaultTTL time.Duration
 }
 
-func NewAuthHandler(uc *authUC.UseCase, adapter *httpcontext.Adapter, logger *zap.Logger, ttl time.Duration) *AuthHandler {
+func NewAuthHandler(uc *authUC.UseCase, adapter *httpcontext.Adapter, logger *zap.Logger, ttl time.Duration, opts ...Option) *AuthHandler {
 	if ttl <= 0 {
 		ttl = time.Hour
 	}
 	return &AuthHandler{
-		baseHandler: newBaseHandler(adapter, logger),
+		baseHandler: newBaseHandler(adapter, logger, opts...),
 		uc:          uc,
 		defaultTTL:  ttl,
 	}
 }
 
 // @Summary Issue a new session
+// @Description The credential is checked by the configured authenticator (AUTH_AUTHENTICATOR);
+// @Description by default every login is rejected with 401.
 // @Tags auth
 // @Router /api/v1/auth/login [post]
 func (h *AuthHandler) Login(ctx *fasthttp.RequestCtx) {
 	var req transport.AuthLoginRequest
-	if err := json.Unmarshal(ctx.PostBody(), &req); err != nil || req.UserID == "" {
+	if !h.decodeJSON(ctx, &req) {
+		return
+	}
+	if req.UserID == "" {
 		h.respondJSON(ctx, http.StatusBadRequest, transport.NewError(string(domain.ErrCodeInvalid), "invalid payload", nil))
 		return
 	}
@@ -46,7 +50,7 @@ func (h *AuthHandler) Login(ctx *fasthttp.RequestCtx) {
 	stdCtx, cancel := h.requestContext(ctx)
 	defer cancel()
 
-	session, err := h.uc.CreateSession(stdCtx, req.UserID, ttl)
+	session, err := h.uc.Login(stdCtx, req.UserID, req.Credential, ttl)
 	if err != nil {
 		h.respondError(ctx, err)
 		return
@@ -59,7 +63,10 @@ func (h *AuthHandler) Login(ctx *fasthttp.RequestCtx) {
 // @Router /api/v1/auth/refresh [post]
 func (h *AuthHandler) Refresh(ctx *fasthttp.RequestCtx) {
 	var req transport.RefreshRequest
-	if err := json.Unmarshal(ctx.PostBody(), &req); err != nil || req.SessionID == "" {
+	if !h.decodeJSON(ctx, &req) {
+		return
+	}
+	if req.SessionID == "" {
 		h.respondJSON(ctx, http.StatusBadRequest, transport.NewError(string(domain.ErrCodeInvalid), "invalid payload", nil))
 		return
 	}
@@ -77,10 +84,89 @@ func (h *AuthHandler) Refresh(ctx *fasthttp.RequestCtx) {
 	h.respondSuccess(ctx, http.StatusOK, session)
 }
 
+// @Summary Revoke the current session
+// @Tags auth
+// @Router /api/v1/auth/logout [post]
+func (h *AuthHandler) Logout(ctx *fasthttp.RequestCtx) {
+	userID := string(ctx.Request.Header.Peek("X-User-ID"))
+	if userID == "" {
+		h.respondJSON(ctx, http.StatusUnauthorized, transport.NewError(string(domain.ErrCodeUnauthorized), "missing user id", nil))
+		return
+	}
+
+	stdCtx, cancel := h.requestContext(ctx)
+	defer cancel()
+
+	sessionID := httpcontext.SessionID(stdCtx)
+	if sessionID == "" && len(ctx.PostBody()) > 0 {
+		var req transport.LogoutRequest
+		if !h.decodeJSON(ctx, &req) {
+			return
+		}
+		sessionID = req.SessionID
+	}
+	if sessionID == "" {
+		h.respondJSON(ctx, http.StatusBadRequest, transport.NewError(string(domain.ErrCodeInvalid), "missing session id", nil))
+		return
+	}
+
+	if err := h.uc.RevokeUserSession(stdCtx, userID, sessionID); err != nil {
+		h.respondError(ctx, err)
+		return
+	}
+	h.respondSuccess(ctx, http.StatusNoContent, nil)
+}
+
+// inspectableMetadata lists the session metadata support staff may see. Other keys, such as the
+// client IP, are withheld, including keys added after this list was written.
+var inspectableMetadata = []string{authUC.MetadataUserAgent}
+
+// @Summary Inspect any session
+// @Description Admin only. Session metadata is limited to non-sensitive keys.
+// @Tags admin
+// @Router /admin/sessions/{id} [get]
+func (h *AuthHandler) InspectSession(ctx *fasthttp.RequestCtx) {
+	id, _ := ctx.UserValue("id").(string)
+
+	stdCtx, cancel := h.requestContext(ctx)
+	defer cancel()
+
+	session, err := h.uc.GetSession(stdCtx, id)
+	if err != nil {
+		h.respondError(ctx, err)
+		return
+	}
+	metadata := make(map[string]string, len(inspectableMetadata))
+	for _, key := range inspectableMetadata {
+		if value, ok := session.Metadata[key]; ok {
+			metadata[key] = value
+		}
+	}
+	session.Metadata = metadata
+	h.respondSuccess(ctx, http.StatusOK, session)
+}
+
+// @Summary Revoke any session
+// @Description Admin only. Answers 404 when the session does not exist or already expired.
+// @Tags admin
+// @Router /admin/sessions/{id} [delete]
+func (h *AuthHandler) EvictSession(ctx *fasthttp.RequestCtx) {
+	id, _ := ctx.UserValue("id").(string)
+
+	stdCtx, cancel := h.requestContext(ctx)
+	defer cancel()
+
+	if err := h.uc.EvictSession(stdCtx, id); err != nil {
+		h.respondError(ctx, err)
+		return
+	}
+	h.ctxLogger(stdCtx).Info("session evicted by admin", zap.String("session_id", id))
+	h.respondSuccess(ctx, http.StatusNoContent, nil)
+}
+
 func (h *AuthHandler) ttlFromRequest(ttlSeconds int) time.Duration {
 	if ttlSeconds <= 0 {
 		return h.defaultTTL
 	}
 	return time.Duration(ttlSeconds) * time.Second
 }
-
@@ -0,0 +1,176 @@
+package handler_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/valyala/fasthttp"
+
+	apiHandler "github.com/fastygo/backend/api/handler"
+	"github.com/fastygo/backend/domain"
+	"github.com/fastygo/backend/repository/repositorytest"
+	authUC "github.com/fastygo/backend/usecase/auth"
+)
+
+func logoutRequest(userID, sessionID string) testRequest {
+	return testRequest{
+		method:      http.MethodPost,
+		uri:         "/api/v1/auth/logout",
+		contentType: "application/json",
+		headers:     map[string]string{"X-User-ID": userID},
+		body:        `{"session_id":"` + sessionID + `"}`,
+	}
+}
+
+func TestLogoutRevokesSession(t *testing.T) {
+	uc := authUC.New(repositorytest.NewUsers(domain.User{ID: "user-1"}), repositorytest.NewSessions(), nil)
+	h := apiHandler.NewAuthHandler(uc, nil, nil, time.Hour)
+
+	session, err := uc.CreateSession(context.Background(), "user-1", time.Hour)
+	if err != nil {
+		t.Fatalf("create session: %v", err)
+	}
+
+	ctx := newRequestCtx(logoutRequest("user-1", session.ID))
+	h.Logout(ctx)
+	if ctx.Response.StatusCode() != http.StatusNoContent {
+		t.Fatalf("status = %d, want 204; body %s", ctx.Response.StatusCode(), ctx.Response.Body())
+	}
+
+	if _, err := uc.GetSession(context.Background(), session.ID); err != domain.ErrSessionNotFound {
+		t.Fatalf("get revoked session error = %v, want ErrSessionNotFound", err)
+	}
+
+	ctx = newRequestCtx(logoutRequest("user-1", session.ID))
+	h.Logout(ctx)
+	if ctx.Response.StatusCode() != http.StatusNoContent {
+		t.Fatalf("repeated logout status = %d, want 204", ctx.Response.StatusCode())
+	}
+}
+
+func TestLogoutRejectsAnotherUsersSession(t *testing.T) {
+	uc := authUC.New(repositorytest.NewUsers(domain.User{ID: "user-1"}), repositorytest.NewSessions(), nil)
+	h := apiHandler.NewAuthHandler(uc, nil, nil, time.Hour)
+
+	session, err := uc.CreateSession(context.Background(), "user-1", time.Hour)
+	if err != nil {
+		t.Fatalf("create session: %v", err)
+	}
+
+	ctx := newRequestCtx(logoutRequest("user-2", session.ID))
+	h.Logout(ctx)
+	if ctx.Response.StatusCode() != http.StatusForbidden {
+		t.Fatalf("status = %d, want 403", ctx.Response.StatusCode())
+	}
+	if _, err := uc.GetSession(context.Background(), session.ID); err != nil {
+		t.Fatalf("session must survive a foreign logout: %v", err)
+	}
+}
+
+func TestRefreshMissingSessionReturnsNotFound(t *testing.T) {
+	uc := authUC.New(repositorytest.NewUsers(), repositorytest.NewSessions(), nil)
+	h := apiHandler.NewAuthHandler(uc, nil, nil, time.Hour)
+
+	ctx := newRequestCtx(testRequest{
+		method:      http.MethodPost,
+		uri:         "/api/v1/auth/refresh",
+		contentType: "application/json",
+		body:        `{"session_id":"expired"}`,
+	})
+	h.Refresh(ctx)
+
+	if ctx.Response.StatusCode() != http.StatusNotFound {
+		t.Fatalf("status = %d, want 404; body %s", ctx.Response.StatusCode(), ctx.Response.Body())
+	}
+}
+
+func TestLoginChecksCredential(t *testing.T) {
+	users := repositorytest.NewUsers(domain.User{ID: "user-1"})
+	login := func(uc *authUC.UseCase, credential string) int {
+		ctx := newRequestCtx(testRequest{
+			method:      http.MethodPost,
+			uri:         "/api/v1/auth/login",
+			contentType: "application/json",
+			body:        `{"user_id":"user-1","credential":"` + credential + `"}`,
+		})
+		apiHandler.NewAuthHandler(uc, nil, nil, time.Hour).Login(ctx)
+		return ctx.Response.StatusCode()
+	}
+
+	if status := login(authUC.New(users, repositorytest.NewSessions(), nil), "anything"); status != http.StatusUnauthorized {
+		t.Fatalf("default authenticator: status = %d, want 401", status)
+	}
+	uc := authUC.New(users, repositorytest.NewSessions(), nil, authUC.WithAuthenticator(authUC.AuthenticatorFunc(
+		func(_ context.Context, _, credential string) error {
+			if credential != "hunter2" {
+				return authUC.ErrInvalidCredentials
+			}
+			return nil
+		})))
+	if status := login(uc, "hunter2"); status != http.StatusCreated {
+		t.Fatalf("right credential: status = %d, want 201", status)
+	}
+	if status := login(uc, "wrong"); status != http.StatusUnauthorized {
+		t.Fatalf("wrong credential: status = %d, want 401", status)
+	}
+}
+
+func TestAdminInspectAndEvictSession(t *testing.T) {
+	sessions := repositorytest.NewSessions()
+	uc := authUC.New(repositorytest.NewUsers(domain.User{ID: "user-1"}), sessions, nil)
+	h := apiHandler.NewAuthHandler(uc, nil, nil, time.Hour)
+	err := sessions.Save(context.Background(), &domain.Session{
+		ID:        "s1",
+		UserID:    "user-1",
+		ExpiresAt: time.Now().Add(time.Hour),
+		Metadata:  map[string]string{authUC.MetadataIP: "203.0.113.7", authUC.MetadataUserAgent: "curl/8.0", "refresh_hint": "secret"},
+	})
+	if err != nil {
+		t.Fatalf("save: %v", err)
+	}
+	sessionRequest := func(method, id string) *fasthttp.RequestCtx {
+		ctx := newRequestCtx(testRequest{method: method, uri: "/admin/sessions/" + id})
+		ctx.SetUserValue("id", id)
+		return ctx
+	}
+
+	ctx := sessionRequest(http.MethodGet, "s1")
+	h.InspectSession(ctx)
+	if ctx.Response.StatusCode() != http.StatusOK {
+		t.Fatalf("inspect status = %d, want 200; body %s", ctx.Response.StatusCode(), ctx.Response.Body())
+	}
+	var inspected struct {
+		Data domain.Session `json:"data"`
+	}
+	if err := json.Unmarshal(ctx.Response.Body(), &inspected); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if inspected.Data.UserID != "user-1" || !reflect.DeepEqual(inspected.Data.Metadata, map[string]string{authUC.MetadataUserAgent: "curl/8.0"}) {
+		t.Fatalf("inspected = %+v, want user-1 with only the user agent", inspected.Data)
+	}
+
+	ctx = sessionRequest(http.MethodDelete, "s1")
+	h.EvictSession(ctx)
+	if ctx.Response.StatusCode() != http.StatusNoContent {
+		t.Fatalf("evict status = %d, want 204; body %s", ctx.Response.StatusCode(), ctx.Response.Body())
+	}
+	if _, err := uc.GetSession(context.Background(), "s1"); err != domain.ErrSessionNotFound {
+		t.Fatalf("get evicted session error = %v, want ErrSessionNotFound", err)
+	}
+
+	for _, method := range []string{http.MethodGet, http.MethodDelete} {
+		ctx = sessionRequest(method, "s1")
+		if method == http.MethodGet {
+			h.InspectSession(ctx)
+		} else {
+			h.EvictSession(ctx)
+		}
+		if ctx.Response.StatusCode() != http.StatusNotFound {
+			t.Fatalf("%s missing session status = %d, want 404", method, ctx.Response.StatusCode())
+		}
+	}
+}
@@ -3,7 +3,15 @@ package handler
 import (
 	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"mime"
 	"net/http"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/valyala/fasthttp"
 	"go.uber.org/zap"
@@ -11,43 +19,335 @@ import (
 	"github.com/fastygo/backend/api/transport"
 	"github.com/fastygo/backend/domain"
 	"github.com/fastygo/backend/pkg/httpcontext"
+	appLogger "github.com/fastygo/backend/pkg/logger"
+	"github.com/fastygo/backend/pkg/timing"
+	"github.com/fastygo/backend/usecase"
 )
 
+// ErrCodeUnsupportedMediaType is returned when a request body is not JSON.
+const ErrCodeUnsupportedMediaType = "UNSUPPORTED_MEDIA_TYPE"
+
+// Codes for requests that ran out of time or whose client went away, kept apart from INTERNAL so
+// they are not mistaken for server faults.
+const (
+	ErrCodeRequestTimeout  = "REQUEST_TIMEOUT"
+	ErrCodeRequestCanceled = "REQUEST_CANCELED"
+)
+
+// StatusClientClosedRequest is the non-standard status reported when the client cancelled the request.
+const StatusClientClosedRequest = 499
+
+// HeaderCallbackURL names the URL told the final outcome of a write that ends up buffered.
+const HeaderCallbackURL = "X-Callback-URL"
+
 type baseHandler struct {
-	adapter *httpcontext.Adapter
-	logger  *zap.Logger
+	adapter            *httpcontext.Adapter
+	logger             *zap.Logger
+	strictQuery        bool
+	lenientContentType bool
+	keyCase            transport.KeyCase
+	bufferCallbacks    bool
+	rawResponses       bool
+	problemErrors      bool
+	maxOffset          int
+}
+
+// Option customizes behaviour shared by all handlers.
+type Option func(*baseHandler)
+
+// WithStrictQuery rejects malformed or out-of-range numeric query parameters with 400
+// instead of silently falling back to defaults.
+func WithStrictQuery(strict bool) Option {
+	return func(h *baseHandler) {
+		h.strictQuery = strict
+	}
+}
+
+// WithLenientContentType disables the application/json Content-Type check on request bodies.
+func WithLenientContentType(lenient bool) Option {
+	return func(h *baseHandler) {
+		h.lenientContentType = lenient
+	}
+}
+
+// WithResponseKeyCase sets the key spelling used when the client does not ask for one in its Accept
+// header (e.g. "application/json; case=camel"). Snake case is the default.
+func WithResponseKeyCase(keyCase transport.KeyCase) Option {
+	return func(h *baseHandler) {
+		h.keyCase = keyCase
+	}
+}
+
+// WithRawResponses answers every request as if it accepted transport.MediaTypeRaw: successes carry
+// the bare data and errors an RFC 7807 problem document. Envelope metadata is dropped.
+func WithRawResponses(raw bool) Option {
+	return func(h *baseHandler) {
+		h.rawResponses = raw
+	}
+}
+
+// WithProblemErrors answers every error with an RFC 7807 problem document while successes keep the
+// envelope. Without it, clients opt in per request by accepting transport.MediaTypeProblem.
+func WithProblemErrors(enabled bool) Option {
+	return func(h *baseHandler) {
+		h.problemErrors = enabled
+	}
 }
 
-func newBaseHandler(adapter *httpcontext.Adapter, logger *zap.Logger) baseHandler {
+// WithMaxOffset rejects list requests whose offset exceeds max with 400, since the database still
+// scans every skipped row. Zero or less leaves offsets unbounded.
+func WithMaxOffset(max int) Option {
+	return func(h *baseHandler) {
+		h.maxOffset = max
+	}
+}
+
+// WithBufferCallbacks honours the X-Callback-URL header on buffered writes. When disabled the
+// header is ignored.
+func WithBufferCallbacks(enabled bool) Option {
+	return func(h *baseHandler) {
+		h.bufferCallbacks = enabled
+	}
+}
+
+// newBaseHandler falls back to a default adapter when adapter is nil, so a misconfigured handler
+// still bounds its requests and tags them with an ID; the fallback is logged once, here.
+func newBaseHandler(adapter *httpcontext.Adapter, logger *zap.Logger, opts ...Option) baseHandler {
 	if logger == nil {
 		logger = zap.NewNop()
 	}
-	return baseHandler{adapter: adapter, logger: logger}
+	if adapter == nil {
+		logger.Warn("handler constructed without a request adapter; using the default timeout",
+			zap.Duration("timeout", httpcontext.DefaultTimeout))
+		adapter = httpcontext.NewAdapter(httpcontext.DefaultTimeout)
+	}
+	h := baseHandler{adapter: adapter, logger: logger, keyCase: transport.SnakeCase}
+	for _, opt := range opts {
+		opt(&h)
+	}
+	return h
 }
 
 func (h baseHandler) requestContext(ctx *fasthttp.RequestCtx) (context.Context, context.CancelFunc) {
-	if h.adapter != nil {
-		return h.adapter.Attach(ctx)
+	return h.adapter.Attach(ctx)
+}
+
+// ctxLogger is the handler's logger tagged with the request ID carried by stdCtx.
+func (h baseHandler) ctxLogger(stdCtx context.Context) *zap.Logger {
+	return appLogger.WithRequestID(stdCtx, h.logger)
+}
+
+// withCallback attaches the request's X-Callback-URL to stdCtx so a buffered write reports its
+// outcome there. An invalid URL is answered with 400 and ok=false.
+func (h baseHandler) withCallback(ctx *fasthttp.RequestCtx, stdCtx context.Context) (context.Context, bool) {
+	raw := strings.TrimSpace(string(ctx.Request.Header.Peek(HeaderCallbackURL)))
+	if !h.bufferCallbacks || raw == "" {
+		return stdCtx, true
+	}
+	if !validCallbackURL(raw) {
+		h.respondJSON(ctx, http.StatusBadRequest, transport.NewError(string(domain.ErrCodeInvalid), transport.FieldError{
+			Field:   HeaderCallbackURL,
+			Message: "must be an absolute http or https URL",
+		}, nil))
+		return stdCtx, false
+	}
+	return usecase.WithCallbackURL(stdCtx, raw), true
+}
+
+func validCallbackURL(raw string) bool {
+	u, err := url.Parse(raw)
+	if err != nil || u.Host == "" || u.User != nil {
+		return false
 	}
-	return context.WithCancel(context.Background())
+	return u.Scheme == "http" || u.Scheme == "https"
 }
 
 func (h baseHandler) respondJSON(ctx *fasthttp.RequestCtx, status int, payload transport.Envelope) {
-	ctx.Response.Header.SetContentType("application/json")
+	defer httpcontext.Timing(ctx).Start(timing.SpanSerialize)()
+	contentType := "application/json"
+	raw := h.rawResponses || accepts(ctx, transport.MediaTypeRaw)
+	var body []byte
+	switch {
+	case payload.Status == "error" && (raw || h.problemErrors || accepts(ctx, transport.MediaTypeProblem)):
+		contentType = transport.MediaTypeProblem
+		body, _ = json.Marshal(transport.NewProblem(status, payload, requestID(ctx)))
+	case !raw:
+		body, _ = json.Marshal(payload)
+	case payload.Data != nil:
+		body, _ = json.Marshal(payload.Data)
+	}
+	ctx.Response.Header.SetContentType(contentType)
 	ctx.SetStatusCode(status)
-	body, _ := json.Marshal(payload)
+	if len(body) > 0 && h.responseKeyCase(ctx) == transport.CamelCase {
+		if camel, err := transport.CamelCaseKeys(body); err == nil {
+			body = camel
+		}
+	}
 	ctx.SetBody(body)
 }
 
+// accepts reports whether any media range of the Accept header names mediaType.
+func accepts(ctx *fasthttp.RequestCtx, mediaType string) bool {
+	for _, accepted := range strings.Split(string(ctx.Request.Header.Peek(fasthttp.HeaderAccept)), ",") {
+		if parsed, _, err := mime.ParseMediaType(strings.TrimSpace(accepted)); err == nil && parsed == mediaType {
+			return true
+		}
+	}
+	return false
+}
+
+// requestID is the ID the adapter echoed on the response, or the client's own before the request
+// context was attached.
+func requestID(ctx *fasthttp.RequestCtx) string {
+	if id := ctx.Response.Header.Peek("X-Request-ID"); len(id) > 0 {
+		return string(id)
+	}
+	return string(ctx.Request.Header.Peek("X-Request-ID"))
+}
+
+// responseKeyCase honours a "case" parameter on any Accept media range and otherwise falls back
+// to the configured default.
+func (h baseHandler) responseKeyCase(ctx *fasthttp.RequestCtx) transport.KeyCase {
+	for _, accepted := range strings.Split(string(ctx.Request.Header.Peek(fasthttp.HeaderAccept)), ",") {
+		_, params, err := mime.ParseMediaType(strings.TrimSpace(accepted))
+		if err != nil {
+			continue
+		}
+		if keyCase, ok := transport.ParseKeyCase(params["case"]); ok {
+			return keyCase
+		}
+	}
+	return h.keyCase
+}
+
 func (h baseHandler) respondSuccess(ctx *fasthttp.RequestCtx, status int, data interface{}) {
-	h.respondJSON(ctx, status, transport.NewSuccess(data, nil))
+	h.respondJSON(ctx, status, transport.NewSuccess(emptyIfNil(data), nil))
+}
+
+// emptyIfNil turns a nil slice into an empty one so lists with no results serialize as [] rather
+// than null.
+func emptyIfNil(data interface{}) interface{} {
+	v := reflect.ValueOf(data)
+	if v.Kind() == reflect.Slice && v.IsNil() {
+		return reflect.MakeSlice(v.Type(), 0, 0).Interface()
+	}
+	return data
 }
 
 func (h baseHandler) respondError(ctx *fasthttp.RequestCtx, err error) {
 	status, code := mapError(err)
-	h.respondJSON(ctx, status, transport.NewError(code, err.Error(), nil))
+	h.respondJSON(ctx, status, transport.NewError(code, err.Error(), errorDetails(err)))
 }
 
+// notModified sets Last-Modified from modified and, when the request's If-Modified-Since is at or
+// after it, answers 304 and returns true. HTTP dates carry whole seconds, so modified is truncated
+// before comparing; otherwise a sub-second UpdatedAt would never match the date the client echoes.
+func (h baseHandler) notModified(ctx *fasthttp.RequestCtx, modified time.Time) bool {
+	if modified.IsZero() {
+		return false
+	}
+	modified = modified.UTC().Truncate(time.Second)
+	ctx.Response.Header.SetLastModified(modified)
+
+	since, err := fasthttp.ParseHTTPDate(ctx.Request.Header.Peek(fasthttp.HeaderIfModifiedSince))
+	if err != nil || modified.After(since) {
+		return false
+	}
+	ctx.SetStatusCode(http.StatusNotModified)
+	ctx.ResetBody()
+	return true
+}
+
+// decodeJSON validates the Content-Type and unmarshals the request body into dst.
+// It writes a 415 or 400 response and returns false when the body cannot be accepted.
+func (h baseHandler) decodeJSON(ctx *fasthttp.RequestCtx, dst interface{}) bool {
+	if !h.lenientContentType && !isJSONContentType(string(ctx.Request.Header.ContentType())) {
+		h.respondJSON(ctx, http.StatusUnsupportedMediaType, transport.NewError(ErrCodeUnsupportedMediaType, "content type must be application/json", nil))
+		return false
+	}
+	stop := httpcontext.Timing(ctx).Start(timing.SpanSerialize)
+	err := json.Unmarshal(ctx.PostBody(), dst)
+	stop()
+	if err != nil {
+		h.respondJSON(ctx, http.StatusBadRequest, transport.NewError(string(domain.ErrCodeInvalid), "invalid payload", nil))
+		return false
+	}
+	return true
+}
+
+func isJSONContentType(value string) bool {
+	if value == "" {
+		return false
+	}
+	mediaType, _, err := mime.ParseMediaType(value)
+	return err == nil && mediaType == "application/json"
+}
+
+// queryInt reads a numeric query argument. Missing values yield fallback. In strict mode a malformed
+// or out-of-range value is answered with 400 and ok=false; otherwise it leniently falls back.
+func (h baseHandler) queryInt(ctx *fasthttp.RequestCtx, name string, fallback, min, max int) (int, bool) {
+	raw := string(ctx.QueryArgs().Peek(name))
+	if raw == "" {
+		return fallback, true
+	}
+	value, err := strconv.Atoi(raw)
+	if !h.strictQuery {
+		if err != nil {
+			return fallback, true
+		}
+		return value, true
+	}
+
+	var message string
+	switch {
+	case err != nil:
+		message = "must be an integer"
+	case value < min || value > max:
+		message = outOfRange(min, max)
+	default:
+		return value, true
+	}
+	h.respondJSON(ctx, http.StatusBadRequest, transport.NewError(string(domain.ErrCodeInvalid), transport.FieldError{
+		Field:   name,
+		Message: message,
+	}, nil))
+	return 0, false
+}
+
+// offsetTooDeep reports whether offset is past the configured maximum, in lenient and strict query
+// mode alike, along with a message pointing the caller at filters.
+func (h baseHandler) offsetTooDeep(offset int) (message string, tooDeep bool) {
+	if h.maxOffset <= 0 || offset <= h.maxOffset {
+		return "", false
+	}
+	return fmt.Sprintf("must be at most %d; narrow the listing with status or priority filters instead of paging this deep", h.maxOffset), true
+}
+
+// queryBool reads a boolean query argument; missing values are false. In strict mode anything
+// strconv.ParseBool rejects is answered with 400 and ok=false; otherwise it counts as false.
+func (h baseHandler) queryBool(ctx *fasthttp.RequestCtx, name string) (bool, bool) {
+	raw := string(ctx.QueryArgs().Peek(name))
+	if raw == "" {
+		return false, true
+	}
+	value, err := strconv.ParseBool(raw)
+	if err == nil || !h.strictQuery {
+		return value, true
+	}
+	h.respondJSON(ctx, http.StatusBadRequest, transport.NewError(string(domain.ErrCodeInvalid), transport.FieldError{
+		Field:   name,
+		Message: "must be true or false",
+	}, nil))
+	return false, false
+}
+
+func outOfRange(min, max int) string {
+	return fmt.Sprintf("must be between %d and %d", min, max)
+}
+
+// mapError answers LIMIT_EXCEEDED with 429 rather than 422: the request is well-formed and would
+// succeed once usage drops, which is what 429 tells clients and proxies, and rate limits answer
+// the same way.
 func mapError(err error) (int, string) {
 	switch {
 	case domain.IsDomainError(err, domain.ErrCodeUnauthorized):
@@ -58,8 +358,25 @@ func mapError(err error) (int, string) {
 		return http.StatusBadRequest, string(domain.ErrCodeInvalid)
 	case domain.IsDomainError(err, domain.ErrCodeNotFound):
 		return http.StatusNotFound, string(domain.ErrCodeNotFound)
+	case domain.IsDomainError(err, domain.ErrCodeConflict):
+		return http.StatusConflict, string(domain.ErrCodeConflict)
+	case domain.IsDomainError(err, domain.ErrCodeLimitExceeded):
+		return http.StatusTooManyRequests, string(domain.ErrCodeLimitExceeded)
+	case errors.Is(err, context.DeadlineExceeded):
+		return http.StatusGatewayTimeout, ErrCodeRequestTimeout
+	case errors.Is(err, context.Canceled):
+		return StatusClientClosedRequest, ErrCodeRequestCanceled
 	default:
 		return http.StatusInternalServerError, string(domain.ErrCodeInternal)
 	}
 }
 
+// errorDetails is the structured detail sent as the error's meta, e.g. the limit and current usage
+// of a LimitError; nil when err carries none.
+func errorDetails(err error) interface{} {
+	var limitErr *domain.LimitError
+	if errors.As(err, &limitErr) {
+		return limitErr
+	}
+	return nil
+}
@@ -0,0 +1,31 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/valyala/fasthttp"
+	"go.uber.org/zap"
+
+	"github.com/fastygo/backend/api/transport"
+	"github.com/fastygo/backend/domain"
+	"github.com/fastygo/backend/pkg/httpcontext"
+)
+
+// FallbackHandler answers requests the router could not match, in the same JSON envelope as
+// every other endpoint.
+type FallbackHandler struct {
+	baseHandler
+}
+
+func NewFallbackHandler(adapter *httpcontext.Adapter, logger *zap.Logger, opts ...Option) *FallbackHandler {
+	return &FallbackHandler{baseHandler: newBaseHandler(adapter, logger, opts...)}
+}
+
+// NotFound is the router's handler for unknown paths.
+func (h *FallbackHandler) NotFound(ctx *fasthttp.RequestCtx) {
+	// Attaching the request context echoes X-Request-ID like any matched route.
+	_, cancel := h.requestContext(ctx)
+	defer cancel()
+
+	h.respondJSON(ctx, http.StatusNotFound, transport.NewError(string(domain.ErrCodeNotFound), "route not found", nil))
+}
@@ -0,0 +1,32 @@
+package handler_test
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	apiHandler "github.com/fastygo/backend/api/handler"
+	"github.com/fastygo/backend/pkg/httpcontext"
+)
+
+func TestNotFoundRespondsWithEnvelope(t *testing.T) {
+	h := apiHandler.NewFallbackHandler(httpcontext.NewAdapter(time.Second), nil)
+	ctx := newRequestCtx(testRequest{
+		method:  http.MethodGet,
+		uri:     "/api/v1/nowhere",
+		headers: map[string]string{"X-Request-ID": "req-404"},
+	})
+
+	h.NotFound(ctx)
+
+	if ctx.Response.StatusCode() != http.StatusNotFound {
+		t.Fatalf("status = %d, want 404", ctx.Response.StatusCode())
+	}
+	if got := string(ctx.Response.Header.Peek("X-Request-ID")); got != "req-404" {
+		t.Fatalf("X-Request-ID = %q, want the client's ID echoed", got)
+	}
+	env := decodeEnvelope(t, ctx)
+	if env.Status != "error" || env.Code != "NOT_FOUND" || env.Error != "route not found" {
+		t.Fatalf("envelope = %+v, want a NOT_FOUND route error", env)
+	}
+}
@@ -0,0 +1,236 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"math"
+	"net/http"
+
+	"github.com/graphql-go/graphql"
+	"github.com/valyala/fasthttp"
+	"go.uber.org/zap"
+
+	"github.com/fastygo/backend/api/transport"
+	"github.com/fastygo/backend/domain"
+	"github.com/fastygo/backend/pkg/httpcontext"
+	"github.com/fastygo/backend/repository"
+	profileUC "github.com/fastygo/backend/usecase/profile"
+	taskUC "github.com/fastygo/backend/usecase/task"
+)
+
+type graphqlCallerKey struct{}
+
+// GraphQLHandler serves read queries over profiles and tasks. REST remains the primary API;
+// the same caller scoping applies: a caller only sees their own profile and tasks.
+type GraphQLHandler struct {
+	baseHandler
+	schema graphql.Schema
+}
+
+func NewGraphQLHandler(profiles *profileUC.UseCase, tasks *taskUC.UseCase, adapter *httpcontext.Adapter, logger *zap.Logger, opts ...Option) (*GraphQLHandler, error) {
+	base := newBaseHandler(adapter, logger, opts...)
+	schema, err := newGraphQLSchema(profiles, tasks, base)
+	if err != nil {
+		return nil, err
+	}
+	return &GraphQLHandler{
+		baseHandler: base,
+		schema:      schema,
+	}, nil
+}
+
+// @Summary Execute a GraphQL query
+// @Tags graphql
+// @Router /graphql [post]
+func (h *GraphQLHandler) Query(ctx *fasthttp.RequestCtx) {
+	userID := string(ctx.Request.Header.Peek("X-User-ID"))
+	if userID == "" {
+		h.respondJSON(ctx, http.StatusUnauthorized, transport.NewError(string(domain.ErrCodeUnauthorized), "missing user id", nil))
+		return
+	}
+
+	var req transport.GraphQLRequest
+	if !h.decodeJSON(ctx, &req) {
+		return
+	}
+	if req.Query == "" {
+		h.respondJSON(ctx, http.StatusBadRequest, transport.NewError(string(domain.ErrCodeInvalid), "missing query", nil))
+		return
+	}
+
+	stdCtx, cancel := h.requestContext(ctx)
+	defer cancel()
+
+	result := graphql.Do(graphql.Params{
+		Schema:         h.schema,
+		RequestString:  req.Query,
+		VariableValues: req.Variables,
+		OperationName:  req.OperationName,
+		Context:        context.WithValue(stdCtx, graphqlCallerKey{}, userID),
+	})
+	if result.HasErrors() {
+		h.ctxLogger(stdCtx).Debug("graphql query returned errors", zap.Any("errors", result.Errors))
+	}
+
+	body, err := json.Marshal(result)
+	if err != nil {
+		h.respondError(ctx, err)
+		return
+	}
+	ctx.Response.Header.SetContentType("application/json")
+	ctx.SetStatusCode(http.StatusOK)
+	ctx.SetBody(body)
+}
+
+func newGraphQLSchema(profiles *profileUC.UseCase, tasks *taskUC.UseCase, base baseHandler) (graphql.Schema, error) {
+	logger := base.logger
+	stringMap := graphql.NewScalar(graphql.ScalarConfig{
+		Name:        "StringMap",
+		Description: "A flat JSON object of string values.",
+		Serialize: func(value interface{}) interface{} {
+			if m, ok := value.(map[string]string); ok {
+				return m
+			}
+			return nil
+		},
+	})
+
+	taskArgs := graphql.FieldConfigArgument{
+		"status":   &graphql.ArgumentConfig{Type: graphql.String},
+		"priority": &graphql.ArgumentConfig{Type: graphql.Int},
+		"limit":    &graphql.ArgumentConfig{Type: graphql.Int},
+		"offset":   &graphql.ArgumentConfig{Type: graphql.Int},
+		"overdue":  &graphql.ArgumentConfig{Type: graphql.Boolean},
+	}
+	resolveTasks := func(p graphql.ResolveParams, userID string) (interface{}, error) {
+		filter := repository.TaskFilter{UserID: userID, TenantID: httpcontext.TenantID(p.Context)}
+		filter.Status, _ = p.Args["status"].(string)
+		filter.OnlyOverdue, _ = p.Args["overdue"].(bool)
+		var err error
+		if filter.Limit, err = intArg(p.Args, "limit", defaultTaskLimit, 1, maxTaskLimit); err != nil {
+			return nil, err
+		}
+		if filter.Offset, err = intArg(p.Args, "offset", 0, 0, math.MaxInt32); err != nil {
+			return nil, err
+		}
+		if message, tooDeep := base.offsetTooDeep(filter.Offset); tooDeep {
+			return nil, domain.NewError(domain.ErrCodeInvalid, "offset "+message)
+		}
+		if filter.Priority, err = intArg(p.Args, "priority", 0, minTaskPriority, maxTaskPriority); err != nil {
+			return nil, err
+		}
+		list, err := tasks.ListTasks(p.Context, filter)
+		if err != nil {
+			return nil, publicError(logger, err)
+		}
+		return list, nil
+	}
+
+	// Field names follow the domain JSON tags, which graphql-go's default resolver reads.
+	taskType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Task",
+		Fields: graphql.Fields{
+			"id":          &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+			"user_id":     &graphql.Field{Type: graphql.String},
+			"tenant_id":   &graphql.Field{Type: graphql.String},
+			"title":       &graphql.Field{Type: graphql.String},
+			"description": &graphql.Field{Type: graphql.String},
+			"status":      &graphql.Field{Type: graphql.String},
+			"priority":    &graphql.Field{Type: graphql.Int},
+			"due_date":    &graphql.Field{Type: graphql.DateTime},
+			"metadata":    &graphql.Field{Type: stringMap},
+			"created_at":  &graphql.Field{Type: graphql.DateTime},
+			"updated_at":  &graphql.Field{Type: graphql.DateTime},
+		},
+	})
+
+	userType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "User",
+		Fields: graphql.Fields{
+			"id":         &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+			"email":      &graphql.Field{Type: graphql.String},
+			"role":       &graphql.Field{Type: graphql.String},
+			"status":     &graphql.Field{Type: graphql.String},
+			"metadata":   &graphql.Field{Type: stringMap},
+			"created_at": &graphql.Field{Type: graphql.DateTime},
+			"updated_at": &graphql.Field{Type: graphql.DateTime},
+			"tasks": &graphql.Field{
+				Type: graphql.NewList(graphql.NewNonNull(taskType)),
+				Args: taskArgs,
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					user, ok := p.Source.(*domain.User)
+					if !ok || user == nil {
+						return nil, nil
+					}
+					return resolveTasks(p, user.ID)
+				},
+			},
+		},
+	})
+
+	query := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Query",
+		Fields: graphql.Fields{
+			"profile": &graphql.Field{
+				Type: userType,
+				Args: graphql.FieldConfigArgument{
+					"id": &graphql.ArgumentConfig{Type: graphql.String},
+				},
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					caller := graphqlCaller(p.Context)
+					id, _ := p.Args["id"].(string)
+					if id == "" {
+						id = caller
+					}
+					if id != caller {
+						return nil, domain.NewError(domain.ErrCodeForbidden, "cannot read another user's profile")
+					}
+					profile, err := profiles.GetProfile(p.Context, id)
+					if err != nil {
+						return nil, publicError(logger, err)
+					}
+					return profile, nil
+				},
+			},
+			"tasks": &graphql.Field{
+				Type: graphql.NewList(graphql.NewNonNull(taskType)),
+				Args: taskArgs,
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					return resolveTasks(p, graphqlCaller(p.Context))
+				},
+			},
+		},
+	})
+
+	return graphql.NewSchema(graphql.SchemaConfig{Query: query})
+}
+
+// intArg reads an optional integer argument, rejecting values outside [min, max] with the same
+// message the REST handlers use for query parameters.
+func intArg(args map[string]interface{}, name string, fallback, min, max int) (int, error) {
+	value, ok := args[name].(int)
+	if !ok {
+		return fallback, nil
+	}
+	if value < min || value > max {
+		return 0, domain.NewError(domain.ErrCodeInvalid, name+" "+outOfRange(min, max))
+	}
+	return value, nil
+}
+
+// publicError keeps resolver failures that reach the GraphQL errors array to their domain message;
+// anything else (e.g. a driver error) is logged and replaced with a generic internal error.
+func publicError(logger *zap.Logger, err error) error {
+	var dErr *domain.Error
+	if errors.As(err, &dErr) && dErr.Code != domain.ErrCodeInternal {
+		return domain.NewError(dErr.Code, dErr.Message)
+	}
+	logger.Error("graphql resolver failed", zap.Error(err))
+	return domain.NewError(domain.ErrCodeInternal, "internal error")
+}
+
+func graphqlCaller(ctx context.Context) string {
+	userID, _ := ctx.Value(graphqlCallerKey{}).(string)
+	return userID
+}
@@ -0,0 +1,112 @@
+package handler_test
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+
+	apiHandler "github.com/fastygo/backend/api/handler"
+	"github.com/fastygo/backend/domain"
+	"github.com/fastygo/backend/repository/repositorytest"
+	profileUC "github.com/fastygo/backend/usecase/profile"
+	taskUC "github.com/fastygo/backend/usecase/task"
+)
+
+type graphqlResponse struct {
+	Data   json.RawMessage `json:"data"`
+	Errors []struct {
+		Message string `json:"message"`
+	} `json:"errors"`
+}
+
+func runGraphQL(t *testing.T, tasks *repositorytest.Tasks, query string) graphqlResponse {
+	t.Helper()
+	users := repositorytest.NewUsers(domain.User{ID: "user-1", Email: "one@example.com"})
+	h, err := apiHandler.NewGraphQLHandler(profileUC.New(users, nil, nil), taskUC.New(tasks, nil, nil), nil, nil)
+	if err != nil {
+		t.Fatalf("build handler: %v", err)
+	}
+	body, _ := json.Marshal(map[string]string{"query": query})
+
+	ctx := newRequestCtx(testRequest{
+		method:      http.MethodPost,
+		uri:         "/graphql",
+		contentType: "application/json",
+		headers:     map[string]string{"X-User-ID": "user-1"},
+		body:        string(body),
+	})
+	h.Query(ctx)
+
+	if ctx.Response.StatusCode() != http.StatusOK {
+		t.Fatalf("status = %d, want 200", ctx.Response.StatusCode())
+	}
+	var resp graphqlResponse
+	if err := json.Unmarshal(ctx.Response.Body(), &resp); err != nil {
+		t.Fatalf("decode %s: %v", ctx.Response.Body(), err)
+	}
+	return resp
+}
+
+func TestGraphQLFetchesProfileAndTasksInOneQuery(t *testing.T) {
+	tasks := repositorytest.NewTasks(
+		domain.Task{ID: "t1", UserID: "user-1", Title: "mine", Status: "pending"},
+		domain.Task{ID: "t2", UserID: "user-2", Title: "theirs", Status: "pending"},
+	)
+
+	resp := runGraphQL(t, tasks, `{ profile { id email tasks { id title } } }`)
+
+	if len(resp.Errors) > 0 {
+		t.Fatalf("errors = %+v", resp.Errors)
+	}
+	var data struct {
+		Profile struct {
+			ID    string `json:"id"`
+			Email string `json:"email"`
+			Tasks []struct {
+				ID string `json:"id"`
+			} `json:"tasks"`
+		} `json:"profile"`
+	}
+	if err := json.Unmarshal(resp.Data, &data); err != nil {
+		t.Fatalf("decode data: %v", err)
+	}
+	if data.Profile.ID != "user-1" || data.Profile.Email != "one@example.com" {
+		t.Fatalf("profile = %+v, want user-1", data.Profile)
+	}
+	if len(data.Profile.Tasks) != 1 || data.Profile.Tasks[0].ID != "t1" {
+		t.Fatalf("tasks = %+v, want only the caller's task", data.Profile.Tasks)
+	}
+}
+
+func TestGraphQLRejectsOutOfRangeArguments(t *testing.T) {
+	tests := []struct {
+		args string
+		want string
+	}{
+		{args: "offset: -1", want: "offset must be between 0 and"},
+		{args: "limit: 0", want: "limit must be between 1 and 100"},
+		{args: "limit: 500", want: "limit must be between 1 and 100"},
+		{args: "priority: 9", want: "priority must be between 1 and 5"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.args, func(t *testing.T) {
+			resp := runGraphQL(t, repositorytest.NewTasks(), `{ tasks(`+tt.args+`) { id } }`)
+			if len(resp.Errors) != 1 || !strings.Contains(resp.Errors[0].Message, tt.want) {
+				t.Fatalf("errors = %+v, want %q", resp.Errors, tt.want)
+			}
+		})
+	}
+}
+
+func TestGraphQLHidesRepositoryErrors(t *testing.T) {
+	tasks := repositorytest.NewTasks()
+	tasks.Err = errors.New(`ERROR: OFFSET must not be negative (SQLSTATE 2201X)`)
+
+	resp := runGraphQL(t, tasks, `{ tasks { id } }`)
+
+	if len(resp.Errors) != 1 || resp.Errors[0].Message != "internal error" {
+		t.Fatalf("errors = %+v, want a generic internal error", resp.Errors)
+	}
+}
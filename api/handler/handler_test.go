@@ -0,0 +1,43 @@
+package handler_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/valyala/fasthttp"
+
+	"github.com/fastygo/backend/api/transport"
+)
+
+type testRequest struct {
+	method      string
+	uri         string
+	body        string
+	contentType string
+	headers     map[string]string
+}
+
+func newRequestCtx(req testRequest) *fasthttp.RequestCtx {
+	ctx := &fasthttp.RequestCtx{}
+	ctx.Request.Header.SetMethod(req.method)
+	ctx.Request.SetRequestURI(req.uri)
+	if req.contentType != "" {
+		ctx.Request.Header.SetContentType(req.contentType)
+	}
+	for key, value := range req.headers {
+		ctx.Request.Header.Set(key, value)
+	}
+	if req.body != "" {
+		ctx.Request.SetBodyString(req.body)
+	}
+	return ctx
+}
+
+func decodeEnvelope(t *testing.T, ctx *fasthttp.RequestCtx) transport.Envelope {
+	t.Helper()
+	var env transport.Envelope
+	if err := json.Unmarshal(ctx.Response.Body(), &env); err != nil {
+		t.Fatalf("decode envelope %q: %v", ctx.Response.Body(), err)
+	}
+	return env
+}
@@ -9,18 +9,40 @@ import (
 
 	"github.com/fastygo/backend/api/transport"
 	"github.com/fastygo/backend/internal/infrastructure/monitor"
+	"github.com/fastygo/backend/pkg/buildinfo"
 	"github.com/fastygo/backend/pkg/httpcontext"
 )
 
+// StatusProvider exposes the latest dependency status; *monitor.Monitor is the production implementation.
+type StatusProvider interface {
+	GetStatus() monitor.Status
+}
+
+var _ StatusProvider = (*monitor.Monitor)(nil)
+
+// ReadinessProbe reports whether startup has completed.
+type ReadinessProbe interface {
+	IsReady() bool
+}
+
+// LivenessProbe lists background components whose heartbeat has stalled.
+type LivenessProbe interface {
+	Stale() []string
+}
+
 type HealthHandler struct {
 	baseHandler
-	monitor *monitor.Monitor
+	monitor   StatusProvider
+	readiness ReadinessProbe
+	liveness  LivenessProbe
 }
 
-func NewHealthHandler(mon *monitor.Monitor, adapter *httpcontext.Adapter, logger *zap.Logger) *HealthHandler {
+func NewHealthHandler(mon StatusProvider, readiness ReadinessProbe, liveness LivenessProbe, adapter *httpcontext.Adapter, logger *zap.Logger, opts ...Option) *HealthHandler {
 	return &HealthHandler{
-		baseHandler: newBaseHandler(adapter, logger),
+		baseHandler: newBaseHandler(adapter, logger, opts...),
 		monitor:     mon,
+		readiness:   readiness,
+		liveness:    liveness,
 	}
 }
 
@@ -31,12 +53,14 @@ func (h *HealthHandler) Check(ctx *fasthttp.RequestCtx) {
 	status := h.monitor.GetStatus()
 	payload := map[string]interface{}{
 		"timestamp": time.Now().UTC(),
+		"version":   buildinfo.Version,
 		"services": map[string]interface{}{
 			"postgresql": status.PostgreSQL,
 			"redis":      status.Redis,
 			"buffer": map[string]interface{}{
-				"online": status.Buffer,
-				"size":   status.BufferSize,
+				"online":        status.Buffer,
+				"size":          status.BufferSize,
+				"dead_lettered": status.DeadLetterSize,
 			},
 		},
 	}
@@ -48,3 +72,29 @@ func (h *HealthHandler) Check(ctx *fasthttp.RequestCtx) {
 	h.respondJSON(ctx, http.StatusServiceUnavailable, transport.NewError("DEGRADED", "dependencies unhealthy", payload))
 }
 
+// @Summary Readiness check
+// @Tags health
+// @Router /health/ready [get]
+func (h *HealthHandler) Ready(ctx *fasthttp.RequestCtx) {
+	if h.readiness == nil || !h.readiness.IsReady() {
+		h.respondJSON(ctx, http.StatusServiceUnavailable, transport.NewError("NOT_READY", "service is starting or shutting down", nil))
+		return
+	}
+	h.respondSuccess(ctx, http.StatusOK, map[string]interface{}{"ready": true})
+}
+
+// @Summary Liveness check
+// @Description Answers 503 when a background component (monitor, buffer processor) has stopped heartbeating, so the orchestrator restarts the process.
+// @Tags health
+// @Router /health/live [get]
+func (h *HealthHandler) Live(ctx *fasthttp.RequestCtx) {
+	var stale []string
+	if h.liveness != nil {
+		stale = h.liveness.Stale()
+	}
+	if len(stale) > 0 {
+		h.respondJSON(ctx, http.StatusServiceUnavailable, transport.NewError("NOT_LIVE", "background components stalled", map[string]interface{}{"stale": stale}))
+		return
+	}
+	h.respondSuccess(ctx, http.StatusOK, map[string]interface{}{"live": true})
+}
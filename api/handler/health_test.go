@@ -0,0 +1,242 @@
+package handler_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+
+	apiHandler "github.com/fastygo/backend/api/handler"
+	"github.com/fastygo/backend/internal/infrastructure/monitor"
+	"github.com/fastygo/backend/internal/infrastructure/postgres"
+	"github.com/fastygo/backend/pkg/httpcontext"
+)
+
+type fakeStatus monitor.Status
+
+func (f fakeStatus) GetStatus() monitor.Status {
+	return monitor.Status(f)
+}
+
+type fakeReadiness bool
+
+type fakeLiveness []string
+
+func (f fakeLiveness) Stale() []string {
+	return f
+}
+
+func (f fakeReadiness) IsReady() bool {
+	return bool(f)
+}
+
+func TestHealthCheckReflectsProviderStatus(t *testing.T) {
+	tests := []struct {
+		name       string
+		status     monitor.Status
+		wantStatus int
+		wantCode   string
+	}{
+		{
+			name:       "healthy",
+			status:     monitor.Status{PostgreSQL: true, Redis: true, Buffer: true, BufferSize: 2, DeadLetterSize: 1},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "degraded",
+			status:     monitor.Status{PostgreSQL: true, Redis: false, Buffer: true, BufferSize: 7},
+			wantStatus: http.StatusServiceUnavailable,
+			wantCode:   "DEGRADED",
+		},
+		{
+			name:       "down",
+			status:     monitor.Status{Buffer: true, BufferSize: 40},
+			wantStatus: http.StatusServiceUnavailable,
+			wantCode:   "DEGRADED",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := apiHandler.NewHealthHandler(fakeStatus(tt.status), nil, nil, nil, nil)
+
+			ctx := newRequestCtx(testRequest{method: http.MethodGet, uri: "/health"})
+			h.Check(ctx)
+
+			if ctx.Response.StatusCode() != tt.wantStatus {
+				t.Fatalf("status = %d, want %d", ctx.Response.StatusCode(), tt.wantStatus)
+			}
+			var body struct {
+				Code string `json:"code"`
+				Data *struct {
+					Services servicesPayload `json:"services"`
+				} `json:"data"`
+				Meta *struct {
+					Services servicesPayload `json:"services"`
+				} `json:"meta"`
+			}
+			if err := json.Unmarshal(ctx.Response.Body(), &body); err != nil {
+				t.Fatalf("decode: %v", err)
+			}
+			if body.Code != tt.wantCode {
+				t.Fatalf("code = %q, want %q", body.Code, tt.wantCode)
+			}
+			services := body.Data
+			if services == nil {
+				services = body.Meta
+			}
+			if services == nil {
+				t.Fatalf("no services payload in %s", ctx.Response.Body())
+			}
+			got := services.Services
+			if got.PostgreSQL != tt.status.PostgreSQL || got.Redis != tt.status.Redis ||
+				got.Buffer.Size != tt.status.BufferSize || got.Buffer.DeadLettered != tt.status.DeadLetterSize {
+				t.Fatalf("services = %+v, want it to mirror %+v", got, tt.status)
+			}
+		})
+	}
+}
+
+type servicesPayload struct {
+	PostgreSQL bool `json:"postgresql"`
+	Redis      bool `json:"redis"`
+	Buffer     struct {
+		Online       bool `json:"online"`
+		Size         int  `json:"size"`
+		DeadLettered int  `json:"dead_lettered"`
+	} `json:"buffer"`
+}
+
+func TestReadyReflectsProbe(t *testing.T) {
+	for _, tt := range []struct {
+		ready bool
+		want  int
+	}{
+		{ready: false, want: http.StatusServiceUnavailable},
+		{ready: true, want: http.StatusOK},
+	} {
+		h := apiHandler.NewHealthHandler(fakeStatus{}, fakeReadiness(tt.ready), nil, nil, nil)
+
+		ctx := newRequestCtx(testRequest{method: http.MethodGet, uri: "/health/ready"})
+		h.Ready(ctx)
+
+		if ctx.Response.StatusCode() != tt.want {
+			t.Fatalf("ready=%v status = %d, want %d", tt.ready, ctx.Response.StatusCode(), tt.want)
+		}
+	}
+}
+
+func TestLiveReportsStalledComponents(t *testing.T) {
+	for _, tt := range []struct {
+		stale fakeLiveness
+		want  int
+	}{
+		{stale: nil, want: http.StatusOK},
+		{stale: fakeLiveness{"buffer"}, want: http.StatusServiceUnavailable},
+	} {
+		h := apiHandler.NewHealthHandler(fakeStatus{}, nil, tt.stale, nil, nil)
+
+		ctx := newRequestCtx(testRequest{method: http.MethodGet, uri: "/health/live"})
+		h.Live(ctx)
+
+		if ctx.Response.StatusCode() != tt.want {
+			t.Fatalf("stale=%v status = %d, want %d", tt.stale, ctx.Response.StatusCode(), tt.want)
+		}
+	}
+}
+
+type fakeSchema struct {
+	version postgres.SchemaVersion
+	err     error
+}
+
+func (f fakeSchema) SchemaVersion(context.Context) (postgres.SchemaVersion, error) {
+	return f.version, f.err
+}
+
+func TestVersionReportsSchema(t *testing.T) {
+	tests := []struct {
+		name       string
+		schema     fakeSchema
+		wantStatus int
+		wantSchema string
+	}{
+		{name: "applied", schema: fakeSchema{version: postgres.SchemaVersion{Version: 7}}, wantStatus: http.StatusOK, wantSchema: `{"version":7,"dirty":false}`},
+		{name: "dirty", schema: fakeSchema{version: postgres.SchemaVersion{Version: 8, Dirty: true}}, wantStatus: http.StatusOK, wantSchema: `{"version":8,"dirty":true}`},
+		{name: "never migrated", schema: fakeSchema{err: postgres.ErrNoSchemaVersion}, wantStatus: http.StatusOK, wantSchema: `null`},
+		{name: "database down", schema: fakeSchema{err: errors.New("connection refused")}, wantStatus: http.StatusServiceUnavailable},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := apiHandler.NewVersionHandler(tt.schema, nil, nil)
+
+			ctx := newRequestCtx(testRequest{method: http.MethodGet, uri: "/version"})
+			h.Get(ctx)
+
+			if ctx.Response.StatusCode() != tt.wantStatus {
+				t.Fatalf("status = %d, want %d: %s", ctx.Response.StatusCode(), tt.wantStatus, ctx.Response.Body())
+			}
+			if tt.wantStatus != http.StatusOK {
+				return
+			}
+			var body struct {
+				Data struct {
+					Version string          `json:"version"`
+					Schema  json.RawMessage `json:"schema"`
+				} `json:"data"`
+			}
+			if err := json.Unmarshal(ctx.Response.Body(), &body); err != nil {
+				t.Fatalf("decode: %v", err)
+			}
+			if body.Data.Version == "" {
+				t.Fatalf("no build version in %s", ctx.Response.Body())
+			}
+			if got := string(body.Data.Schema); got != tt.wantSchema {
+				t.Fatalf("schema = %s, want %s", got, tt.wantSchema)
+			}
+		})
+	}
+}
+
+// deadlineSchema records the deadline of the context it is called with.
+type deadlineSchema struct {
+	deadline time.Duration
+	bounded  bool
+}
+
+func (s *deadlineSchema) SchemaVersion(ctx context.Context) (postgres.SchemaVersion, error) {
+	var deadline time.Time
+	deadline, s.bounded = ctx.Deadline()
+	s.deadline = time.Until(deadline)
+	return postgres.SchemaVersion{}, postgres.ErrNoSchemaVersion
+}
+
+func TestHandlerWithoutAdapterStillBoundsRequests(t *testing.T) {
+	core, logs := observer.New(zap.WarnLevel)
+	schema := &deadlineSchema{}
+	h := apiHandler.NewVersionHandler(schema, nil, zap.New(core))
+	if logs.Len() != 1 {
+		t.Fatalf("expected one warning about the missing adapter, got %d", logs.Len())
+	}
+
+	for range 2 {
+		ctx := newRequestCtx(testRequest{method: http.MethodGet, uri: "/version"})
+		h.Get(ctx)
+		if ctx.Response.StatusCode() != http.StatusOK {
+			t.Fatalf("status = %d: %s", ctx.Response.StatusCode(), ctx.Response.Body())
+		}
+		if len(ctx.Response.Header.Peek("X-Request-ID")) == 0 {
+			t.Fatal("expected a generated request ID")
+		}
+	}
+	if !schema.bounded || schema.deadline <= 0 || schema.deadline > httpcontext.DefaultTimeout {
+		t.Fatalf("deadline = %v (bounded %v), want within %v", schema.deadline, schema.bounded, httpcontext.DefaultTimeout)
+	}
+	if logs.Len() != 1 {
+		t.Fatalf("the missing adapter must be logged once, got %d warnings", logs.Len())
+	}
+}
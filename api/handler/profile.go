@@ -1,7 +1,6 @@
 package handler
 
 import (
-	"encoding/json"
 	"net/http"
 
 	"github.com/valyala/fasthttp"
@@ -18,9 +17,9 @@ type ProfileHandler struct {
 	uc *profileUC.UseCase
 }
 
-func NewProfileHandler(uc *profileUC.UseCase, adapter *httpcontext.Adapter, logger *zap.Logger) *ProfileHandler {
+func NewProfileHandler(uc *profileUC.UseCase, adapter *httpcontext.Adapter, logger *zap.Logger, opts ...Option) *ProfileHandler {
 	return &ProfileHandler{
-		baseHandler: newBaseHandler(adapter, logger),
+		baseHandler: newBaseHandler(adapter, logger, opts...),
 		uc:          uc,
 	}
 }
@@ -39,11 +38,15 @@ func (h *ProfileHandler) GetProfile(ctx *fasthttp.RequestCtx) {
 	stdCtx, cancel := h.requestContext(ctx)
 	defer cancel()
 
-	user, err := h.uc.GetProfile(stdCtx, userID)
+	user, stale, err := h.uc.LookupProfile(stdCtx, userID)
 	if err != nil {
 		h.respondError(ctx, err)
 		return
 	}
+	if stale {
+		h.respondJSON(ctx, http.StatusOK, transport.NewSuccess(user, map[string]interface{}{"stale": true}))
+		return
+	}
 	h.respondSuccess(ctx, http.StatusOK, user)
 }
 
@@ -60,8 +63,7 @@ func (h *ProfileHandler) UpdateProfile(ctx *fasthttp.RequestCtx) {
 	}
 
 	var req transport.ProfileUpdateRequest
-	if err := json.Unmarshal(ctx.PostBody(), &req); err != nil {
-		h.respondJSON(ctx, http.StatusBadRequest, transport.NewError(string(domain.ErrCodeInvalid), "invalid payload", nil))
+	if !h.decodeJSON(ctx, &req) {
 		return
 	}
 
@@ -75,6 +77,10 @@ func (h *ProfileHandler) UpdateProfile(ctx *fasthttp.RequestCtx) {
 
 	stdCtx, cancel := h.requestContext(ctx)
 	defer cancel()
+	stdCtx, ok := h.withCallback(ctx, stdCtx)
+	if !ok {
+		return
+	}
 
 	updated, err := h.uc.UpdateProfile(stdCtx, user)
 	if err != nil {
@@ -83,4 +89,3 @@ func (h *ProfileHandler) UpdateProfile(ctx *fasthttp.RequestCtx) {
 	}
 	h.respondSuccess(ctx, http.StatusOK, updated)
 }
-
@@ -0,0 +1,47 @@
+package handler_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	apiHandler "github.com/fastygo/backend/api/handler"
+	"github.com/fastygo/backend/domain"
+	"github.com/fastygo/backend/pkg/clock"
+	"github.com/fastygo/backend/repository/repositorytest"
+	profileUC "github.com/fastygo/backend/usecase/profile"
+)
+
+func TestGetProfileFlagsStaleResponses(t *testing.T) {
+	fake := clock.NewFake(time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC))
+	users := repositorytest.NewUsers(domain.User{ID: "user-1"})
+	online := true
+	uc := profileUC.New(users, nil, nil,
+		profileUC.WithCache(profileUC.NewMemoryCache(time.Hour, 0, fake), time.Minute, func() bool { return online }),
+		profileUC.WithClock(fake),
+	)
+	if _, err := uc.GetProfile(context.Background(), "user-1"); err != nil {
+		t.Fatalf("warm cache: %v", err)
+	}
+	users.Err = errors.New("connection refused")
+	online = false
+	fake.Advance(5 * time.Minute)
+
+	h := apiHandler.NewProfileHandler(uc, nil, nil)
+	ctx := newRequestCtx(testRequest{
+		method:  http.MethodGet,
+		uri:     "/api/v1/profile",
+		headers: map[string]string{"X-User-ID": "user-1"},
+	})
+	h.GetProfile(ctx)
+
+	if ctx.Response.StatusCode() != http.StatusOK {
+		t.Fatalf("status = %d, want 200", ctx.Response.StatusCode())
+	}
+	meta, ok := decodeEnvelope(t, ctx).Meta.(map[string]interface{})
+	if !ok || meta["stale"] != true {
+		t.Fatalf("meta = %v, want stale flag", decodeEnvelope(t, ctx).Meta)
+	}
+}
@@ -1,9 +1,9 @@
 package handler
 
 import (
-	"encoding/json"
+	"math"
 	"net/http"
-	"strconv"
+	"strings"
 	"time"
 
 	"github.com/valyala/fasthttp"
@@ -16,19 +16,38 @@ import (
 	taskUC "github.com/fastygo/backend/usecase/task"
 )
 
+// Bounds on task listing arguments, shared by the REST and GraphQL transports.
+const (
+	defaultTaskLimit = 50
+	maxTaskLimit     = 100
+	minTaskPriority  = domain.MinTaskPriority
+	maxTaskPriority  = domain.MaxTaskPriority
+)
+
+// HeaderIfExists selects what creating a task with an ID that already exists does: "conflict"
+// (the default) answers 409, "return" answers 200 with the caller's existing task.
+const HeaderIfExists = "X-If-Exists"
+
+const (
+	ifExistsConflict = "conflict"
+	ifExistsReturn   = "return"
+)
+
 type TaskHandler struct {
 	baseHandler
 	uc *taskUC.UseCase
 }
 
-func NewTaskHandler(uc *taskUC.UseCase, adapter *httpcontext.Adapter, logger *zap.Logger) *TaskHandler {
+func NewTaskHandler(uc *taskUC.UseCase, adapter *httpcontext.Adapter, logger *zap.Logger, opts ...Option) *TaskHandler {
 	return &TaskHandler{
-		baseHandler: newBaseHandler(adapter, logger),
+		baseHandler: newBaseHandler(adapter, logger, opts...),
 		uc:          uc,
 	}
 }
 
 // @Summary List tasks
+// @Description overdue=true keeps tasks past their due date that are not completed. offset is
+// @Description capped by SERVER_MAX_OFFSET (10000 by default); deeper offsets answer 400.
 // @Tags tasks
 // @Router /api/v1/tasks [get]
 func (h *TaskHandler) GetTasks(ctx *fasthttp.RequestCtx) {
@@ -37,15 +56,42 @@ func (h *TaskHandler) GetTasks(ctx *fasthttp.RequestCtx) {
 		return
 	}
 
+	limit, ok := h.queryInt(ctx, "limit", defaultTaskLimit, 1, maxTaskLimit)
+	if !ok {
+		return
+	}
+	offset, ok := h.queryInt(ctx, "offset", 0, 0, math.MaxInt32)
+	if !ok {
+		return
+	}
+	if message, tooDeep := h.offsetTooDeep(offset); tooDeep {
+		h.respondJSON(ctx, http.StatusBadRequest, transport.NewError(string(domain.ErrCodeInvalid), transport.FieldError{
+			Field:   "offset",
+			Message: message,
+		}, nil))
+		return
+	}
+	priority, ok := h.queryInt(ctx, "priority", 0, minTaskPriority, maxTaskPriority)
+	if !ok {
+		return
+	}
+	overdue, ok := h.queryBool(ctx, "overdue")
+	if !ok {
+		return
+	}
+
 	filter := repository.TaskFilter{
-		UserID: userID,
-		Status: string(ctx.QueryArgs().Peek("status")),
-		Limit:  parseInt(string(ctx.QueryArgs().Peek("limit")), 50),
-		Offset: parseInt(string(ctx.QueryArgs().Peek("offset")), 0),
+		UserID:      userID,
+		Status:      string(ctx.QueryArgs().Peek("status")),
+		Priority:    priority,
+		OnlyOverdue: overdue,
+		Limit:       limit,
+		Offset:      offset,
 	}
 
 	stdCtx, cancel := h.requestContext(ctx)
 	defer cancel()
+	filter.TenantID = httpcontext.TenantID(stdCtx)
 
 	tasks, err := h.uc.ListTasks(stdCtx, filter)
 	if err != nil {
@@ -55,7 +101,43 @@ func (h *TaskHandler) GetTasks(ctx *fasthttp.RequestCtx) {
 	h.respondSuccess(ctx, http.StatusOK, tasks)
 }
 
+// @Summary Get a task
+// @Description Sets Last-Modified from the task's update time and answers 304 to a matching If-Modified-Since.
+// @Tags tasks
+// @Router /api/v1/tasks/{id} [get]
+func (h *TaskHandler) GetTask(ctx *fasthttp.RequestCtx) {
+	userID := h.userID(ctx)
+	if userID == "" {
+		return
+	}
+
+	id, _ := ctx.UserValue("id").(string)
+	if id == "" {
+		h.respondJSON(ctx, http.StatusBadRequest, transport.NewError(string(domain.ErrCodeInvalid), "missing task id", nil))
+		return
+	}
+
+	stdCtx, cancel := h.requestContext(ctx)
+	defer cancel()
+
+	task, err := h.uc.GetTask(stdCtx, id)
+	if err != nil {
+		h.respondError(ctx, err)
+		return
+	}
+	// Answer other users' and tenants' tasks like missing ones, matching what the list exposes.
+	if tenantID := httpcontext.TenantID(stdCtx); task.UserID != userID || (tenantID != "" && task.TenantID != tenantID) {
+		h.respondError(ctx, domain.ErrTaskNotFound)
+		return
+	}
+	if h.notModified(ctx, task.UpdatedAt) {
+		return
+	}
+	h.respondSuccess(ctx, http.StatusOK, task)
+}
+
 // @Summary Create task
+// @Description Client-generated IDs may be retried safely with X-If-Exists: return.
 // @Tags tasks
 // @Router /api/v1/tasks [post]
 func (h *TaskHandler) CreateTask(ctx *fasthttp.RequestCtx) {
@@ -64,6 +146,18 @@ func (h *TaskHandler) CreateTask(ctx *fasthttp.RequestCtx) {
 		return
 	}
 
+	ifExists := strings.ToLower(strings.TrimSpace(string(ctx.Request.Header.Peek(HeaderIfExists))))
+	if ifExists == "" {
+		ifExists = ifExistsConflict
+	}
+	if ifExists != ifExistsConflict && ifExists != ifExistsReturn {
+		h.respondJSON(ctx, http.StatusBadRequest, transport.NewError(string(domain.ErrCodeInvalid), transport.FieldError{
+			Field:   HeaderIfExists,
+			Message: "must be conflict or return",
+		}, nil))
+		return
+	}
+
 	task, ok := h.parseTask(ctx, userID)
 	if !ok {
 		return
@@ -71,6 +165,25 @@ func (h *TaskHandler) CreateTask(ctx *fasthttp.RequestCtx) {
 
 	stdCtx, cancel := h.requestContext(ctx)
 	defer cancel()
+	stdCtx, ok = h.withCallback(ctx, stdCtx)
+	if !ok {
+		return
+	}
+	task.TenantID = httpcontext.TenantID(stdCtx)
+
+	if ifExists == ifExistsReturn {
+		result, created, err := h.uc.CreateOrGetTask(stdCtx, task)
+		if err != nil {
+			h.respondError(ctx, err)
+			return
+		}
+		status := http.StatusOK
+		if created {
+			status = http.StatusCreated
+		}
+		h.respondSuccess(ctx, status, result)
+		return
+	}
 
 	created, err := h.uc.CreateTask(stdCtx, task)
 	if err != nil {
@@ -102,6 +215,11 @@ func (h *TaskHandler) UpdateTask(ctx *fasthttp.RequestCtx) {
 
 	stdCtx, cancel := h.requestContext(ctx)
 	defer cancel()
+	stdCtx, ok = h.withCallback(ctx, stdCtx)
+	if !ok {
+		return
+	}
+	task.TenantID = httpcontext.TenantID(stdCtx)
 
 	updated, err := h.uc.UpdateTask(stdCtx, task)
 	if err != nil {
@@ -128,8 +246,12 @@ func (h *TaskHandler) DeleteTask(ctx *fasthttp.RequestCtx) {
 
 	stdCtx, cancel := h.requestContext(ctx)
 	defer cancel()
+	stdCtx, ok := h.withCallback(ctx, stdCtx)
+	if !ok {
+		return
+	}
 
-	if err := h.uc.DeleteTask(stdCtx, id); err != nil {
+	if err := h.uc.DeleteTask(stdCtx, id, userID, httpcontext.TenantID(stdCtx)); err != nil {
 		h.respondError(ctx, err)
 		return
 	}
@@ -138,16 +260,30 @@ func (h *TaskHandler) DeleteTask(ctx *fasthttp.RequestCtx) {
 
 func (h *TaskHandler) parseTask(ctx *fasthttp.RequestCtx, userID string) (*domain.Task, bool) {
 	var req transport.TaskRequest
-	if err := json.Unmarshal(ctx.PostBody(), &req); err != nil {
-		h.respondJSON(ctx, http.StatusBadRequest, transport.NewError(string(domain.ErrCodeInvalid), "invalid payload", nil))
+	if !h.decodeJSON(ctx, &req) {
 		return nil, false
 	}
 
+	// An empty due_date means "no due date"; anything else must be RFC 3339.
 	var due *time.Time
 	if req.DueDate != "" {
-		if parsed, err := time.Parse(time.RFC3339, req.DueDate); err == nil {
-			due = &parsed
+		parsed, err := time.Parse(time.RFC3339, req.DueDate)
+		if err != nil {
+			h.respondJSON(ctx, http.StatusBadRequest, transport.NewError(string(domain.ErrCodeInvalid), transport.FieldError{
+				Field:   "due_date",
+				Message: "must be an RFC 3339 timestamp",
+			}, nil))
+			return nil, false
 		}
+		due = &parsed
+	}
+
+	if req.Priority != 0 && (req.Priority < minTaskPriority || req.Priority > maxTaskPriority) {
+		h.respondJSON(ctx, http.StatusBadRequest, transport.NewError(string(domain.ErrCodeInvalid), transport.FieldError{
+			Field:   "priority",
+			Message: outOfRange(minTaskPriority, maxTaskPriority),
+		}, nil))
+		return nil, false
 	}
 
 	task := &domain.Task{
@@ -175,11 +311,3 @@ func (h *TaskHandler) userID(ctx *fasthttp.RequestCtx) string {
 	}
 	return userID
 }
-
-func parseInt(value string, fallback int) int {
-	if v, err := strconv.Atoi(value); err == nil {
-		return v
-	}
-	return fallback
-}
-
@@ -0,0 +1,694 @@
+package handler_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/valyala/fasthttp"
+
+	apiHandler "github.com/fastygo/backend/api/handler"
+	"github.com/fastygo/backend/api/transport"
+	"github.com/fastygo/backend/domain"
+	"github.com/fastygo/backend/internal/infrastructure/buffer"
+	"github.com/fastygo/backend/pkg/httpcontext"
+	"github.com/fastygo/backend/repository/repositorytest"
+	taskUC "github.com/fastygo/backend/usecase/task"
+)
+
+func newTaskHandler(opts ...apiHandler.Option) *apiHandler.TaskHandler {
+	uc := taskUC.New(repositorytest.NewTasks(), nil, nil)
+	return apiHandler.NewTaskHandler(uc, nil, nil, opts...)
+}
+
+func TestGetTasksStrictQueryRejectsInvalidNumbers(t *testing.T) {
+	h := newTaskHandler(apiHandler.WithStrictQuery(true))
+
+	tests := []struct {
+		query string
+		field string
+	}{
+		{query: "limit=abc", field: "limit"},
+		{query: "limit=0", field: "limit"},
+		{query: "limit=101", field: "limit"},
+		{query: "offset=-1", field: "offset"},
+		{query: "priority=9", field: "priority"},
+		{query: "overdue=maybe", field: "overdue"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			ctx := newRequestCtx(testRequest{
+				method:  http.MethodGet,
+				uri:     "/api/v1/tasks?" + tt.query,
+				headers: map[string]string{"X-User-ID": "user-1"},
+			})
+			h.GetTasks(ctx)
+
+			if ctx.Response.StatusCode() != http.StatusBadRequest {
+				t.Fatalf("status = %d, want 400", ctx.Response.StatusCode())
+			}
+			env := decodeEnvelope(t, ctx)
+			field, _ := env.Error.(map[string]interface{})
+			if env.Code != "INVALID" || field["field"] != tt.field {
+				t.Fatalf("envelope = %+v, want INVALID for field %q", env, tt.field)
+			}
+		})
+	}
+}
+
+func TestGetTasksLenientQueryFallsBack(t *testing.T) {
+	h := newTaskHandler()
+
+	for _, query := range []string{"limit=abc", "offset=x", "priority=", "limit=101"} {
+		t.Run(query, func(t *testing.T) {
+			ctx := newRequestCtx(testRequest{
+				method:  http.MethodGet,
+				uri:     "/api/v1/tasks?" + query,
+				headers: map[string]string{"X-User-ID": "user-1"},
+			})
+			h.GetTasks(ctx)
+
+			if ctx.Response.StatusCode() != http.StatusOK {
+				t.Fatalf("status = %d, want 200; body %s", ctx.Response.StatusCode(), ctx.Response.Body())
+			}
+		})
+	}
+}
+
+func TestGetTasksRejectsOffsetBeyondMax(t *testing.T) {
+	h := newTaskHandler(apiHandler.WithMaxOffset(1000))
+
+	for query, want := range map[string]int{"offset=1000": http.StatusOK, "offset=1001": http.StatusBadRequest} {
+		t.Run(query, func(t *testing.T) {
+			ctx := newRequestCtx(testRequest{
+				method:  http.MethodGet,
+				uri:     "/api/v1/tasks?" + query,
+				headers: map[string]string{"X-User-ID": "user-1"},
+			})
+			h.GetTasks(ctx)
+
+			if ctx.Response.StatusCode() != want {
+				t.Fatalf("status = %d, want %d", ctx.Response.StatusCode(), want)
+			}
+			if want != http.StatusBadRequest {
+				return
+			}
+			env := decodeEnvelope(t, ctx)
+			field, _ := env.Error.(map[string]interface{})
+			message, _ := field["message"].(string)
+			if field["field"] != "offset" || !strings.Contains(message, "at most 1000") || !strings.Contains(message, "filters") {
+				t.Fatalf("error = %+v, want the offset limit with guidance", env.Error)
+			}
+		})
+	}
+}
+
+func TestGetTasksOnlyOverdue(t *testing.T) {
+	past, future := time.Now().Add(-time.Hour), time.Now().Add(time.Hour)
+	repo := repositorytest.NewTasks(
+		domain.Task{ID: "late", UserID: "user-1", Status: "pending", DueDate: &past},
+		domain.Task{ID: "done", UserID: "user-1", Status: "completed", DueDate: &past},
+		domain.Task{ID: "upcoming", UserID: "user-1", Status: "pending", DueDate: &future},
+	)
+	h := apiHandler.NewTaskHandler(taskUC.New(repo, nil, nil), nil, nil)
+
+	ctx := newRequestCtx(testRequest{
+		method:  http.MethodGet,
+		uri:     "/api/v1/tasks?overdue=true",
+		headers: map[string]string{"X-User-ID": "user-1"},
+	})
+	h.GetTasks(ctx)
+
+	var body struct {
+		Data []domain.Task `json:"data"`
+	}
+	if err := json.Unmarshal(ctx.Response.Body(), &body); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if ctx.Response.StatusCode() != http.StatusOK || len(body.Data) != 1 || body.Data[0].ID != "late" {
+		t.Fatalf("status %d, tasks %+v; want only the overdue task", ctx.Response.StatusCode(), body.Data)
+	}
+}
+
+func TestGetTasksEmptyListIsArray(t *testing.T) {
+	ctx := newRequestCtx(testRequest{
+		method:  http.MethodGet,
+		uri:     "/api/v1/tasks",
+		headers: map[string]string{"X-User-ID": "user-1"},
+	})
+	newTaskHandler().GetTasks(ctx)
+
+	if ctx.Response.StatusCode() != http.StatusOK {
+		t.Fatalf("status = %d, want 200", ctx.Response.StatusCode())
+	}
+	var body map[string]json.RawMessage
+	if err := json.Unmarshal(ctx.Response.Body(), &body); err != nil {
+		t.Fatalf("decode body: %v", err)
+	}
+	if got := string(body["data"]); got != "[]" {
+		t.Fatalf("data = %s, want []", got)
+	}
+}
+
+func TestCreateTaskRejectsPriorityOutOfRange(t *testing.T) {
+	for _, body := range []string{`{"title":"t","priority":6}`, `{"title":"t","priority":-1}`} {
+		ctx := newRequestCtx(testRequest{
+			method:      http.MethodPost,
+			uri:         "/api/v1/tasks",
+			contentType: "application/json",
+			headers:     map[string]string{"X-User-ID": "user-1"},
+			body:        body,
+		})
+		newTaskHandler().CreateTask(ctx)
+
+		if ctx.Response.StatusCode() != http.StatusBadRequest {
+			t.Fatalf("%s: status = %d, want 400", body, ctx.Response.StatusCode())
+		}
+		field, _ := decodeEnvelope(t, ctx).Error.(map[string]interface{})
+		if field["field"] != "priority" {
+			t.Fatalf("%s: error = %v, want a priority field error", body, field)
+		}
+	}
+}
+
+func TestCreateTaskDuplicateReturnsConflictEnvelope(t *testing.T) {
+	h := newTaskHandler()
+	create := func() *fasthttp.RequestCtx {
+		ctx := newRequestCtx(testRequest{
+			method:      http.MethodPost,
+			uri:         "/api/v1/tasks",
+			body:        `{"id":"task-1","title":"duplicate me"}`,
+			contentType: "application/json",
+			headers:     map[string]string{"X-User-ID": "user-1"},
+		})
+		h.CreateTask(ctx)
+		return ctx
+	}
+
+	if ctx := create(); ctx.Response.StatusCode() != http.StatusCreated {
+		t.Fatalf("first create status = %d, want 201", ctx.Response.StatusCode())
+	}
+	ctx := create()
+	if ctx.Response.StatusCode() != http.StatusConflict {
+		t.Fatalf("duplicate create status = %d, want 409", ctx.Response.StatusCode())
+	}
+	if env := decodeEnvelope(t, ctx); env.Status != "error" || env.Code != "CONFLICT" {
+		t.Fatalf("envelope = %+v, want CONFLICT error", env)
+	}
+}
+
+func TestCreateTaskIfExists(t *testing.T) {
+	h := newTaskHandler()
+	create := func(userID, ifExists, title string) *fasthttp.RequestCtx {
+		headers := map[string]string{"X-User-ID": userID}
+		if ifExists != "" {
+			headers[apiHandler.HeaderIfExists] = ifExists
+		}
+		ctx := newRequestCtx(testRequest{
+			method:      http.MethodPost,
+			uri:         "/api/v1/tasks",
+			body:        `{"id":"client-uuid-1","title":"` + title + `"}`,
+			contentType: "application/json",
+			headers:     headers,
+		})
+		h.CreateTask(ctx)
+		return ctx
+	}
+
+	if ctx := create("user-1", "return", "original"); ctx.Response.StatusCode() != http.StatusCreated {
+		t.Fatalf("first create status = %d, want 201", ctx.Response.StatusCode())
+	}
+
+	tests := []struct {
+		name     string
+		userID   string
+		ifExists string
+		want     int
+	}{
+		{name: "idempotent retry", userID: "user-1", ifExists: "return", want: http.StatusOK},
+		{name: "conflict mode", userID: "user-1", ifExists: "conflict", want: http.StatusConflict},
+		{name: "default is conflict", userID: "user-1", want: http.StatusConflict},
+		{name: "another user's id", userID: "user-2", ifExists: "return", want: http.StatusConflict},
+		{name: "unknown mode", userID: "user-1", ifExists: "overwrite", want: http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := create(tt.userID, tt.ifExists, "retried")
+			if ctx.Response.StatusCode() != tt.want {
+				t.Fatalf("status = %d, want %d; body %s", ctx.Response.StatusCode(), tt.want, ctx.Response.Body())
+			}
+			if tt.want != http.StatusOK {
+				return
+			}
+			data, _ := decodeEnvelope(t, ctx).Data.(map[string]interface{})
+			if data["id"] != "client-uuid-1" || data["title"] != "original" {
+				t.Fatalf("data = %v, want the existing task unchanged", data)
+			}
+		})
+	}
+}
+
+func TestCreateTaskContentType(t *testing.T) {
+	tests := []struct {
+		name        string
+		contentType string
+		opts        []apiHandler.Option
+		want        int
+	}{
+		{name: "missing", contentType: "", want: http.StatusUnsupportedMediaType},
+		{name: "wrong", contentType: "text/plain", want: http.StatusUnsupportedMediaType},
+		{name: "json", contentType: "application/json", want: http.StatusCreated},
+		{name: "json with charset", contentType: "application/json; charset=utf-8", want: http.StatusCreated},
+		{name: "lenient missing", contentType: "", opts: []apiHandler.Option{apiHandler.WithLenientContentType(true)}, want: http.StatusCreated},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := newTaskHandler(tt.opts...)
+			ctx := newRequestCtx(testRequest{
+				method:      http.MethodPost,
+				uri:         "/api/v1/tasks",
+				body:        `{"title":"typed"}`,
+				contentType: tt.contentType,
+				headers:     map[string]string{"X-User-ID": "user-1"},
+			})
+			h.CreateTask(ctx)
+
+			if ctx.Response.StatusCode() != tt.want {
+				t.Fatalf("status = %d, want %d; body %s", ctx.Response.StatusCode(), tt.want, ctx.Response.Body())
+			}
+			if tt.want == http.StatusUnsupportedMediaType {
+				if env := decodeEnvelope(t, ctx); env.Code != apiHandler.ErrCodeUnsupportedMediaType {
+					t.Fatalf("code = %q, want %q", env.Code, apiHandler.ErrCodeUnsupportedMediaType)
+				}
+			}
+		})
+	}
+}
+
+func listTaskKeys(t *testing.T, h *apiHandler.TaskHandler, accept string) map[string]interface{} {
+	t.Helper()
+	ctx := newRequestCtx(testRequest{
+		method:  http.MethodGet,
+		uri:     "/api/v1/tasks",
+		headers: map[string]string{"X-User-ID": "user-1", "Accept": accept},
+	})
+	h.GetTasks(ctx)
+	if ctx.Response.StatusCode() != http.StatusOK {
+		t.Fatalf("status = %d, want 200; body %s", ctx.Response.StatusCode(), ctx.Response.Body())
+	}
+	var body struct {
+		Data []map[string]interface{} `json:"data"`
+	}
+	if err := json.Unmarshal(ctx.Response.Body(), &body); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if len(body.Data) != 1 {
+		t.Fatalf("tasks = %v, want one", body.Data)
+	}
+	return body.Data[0]
+}
+
+func TestTaskResponseKeyCase(t *testing.T) {
+	due := time.Date(2030, 1, 2, 0, 0, 0, 0, time.UTC)
+	tasks := repositorytest.NewTasks(domain.Task{
+		ID: "t1", UserID: "user-1", Title: "write", Status: "todo", Priority: 2,
+		DueDate: &due, Metadata: map[string]string{"source_system": "crm"},
+	})
+	newHandler := func(opts ...apiHandler.Option) *apiHandler.TaskHandler {
+		return apiHandler.NewTaskHandler(taskUC.New(tasks, nil, nil), nil, nil, opts...)
+	}
+
+	snake := listTaskKeys(t, newHandler(), "application/json")
+	camel := listTaskKeys(t, newHandler(), "application/json; case=camel")
+
+	pairs := map[string]string{"user_id": "userId", "due_date": "dueDate", "created_at": "createdAt", "title": "title"}
+	for snakeKey, camelKey := range pairs {
+		if _, ok := snake[snakeKey]; !ok {
+			t.Errorf("default encoding lacks %q: %v", snakeKey, snake)
+		}
+		if _, ok := camel[camelKey]; !ok {
+			t.Errorf("camel encoding lacks %q: %v", camelKey, camel)
+		}
+		if snakeKey != camelKey {
+			if _, ok := camel[snakeKey]; ok {
+				t.Errorf("camel encoding still has %q", snakeKey)
+			}
+		}
+	}
+	if camel["priority"] != snake["priority"] || camel["dueDate"] != snake["due_date"] {
+		t.Errorf("values changed between encodings: %v vs %v", camel, snake)
+	}
+	if metadata, _ := camel["metadata"].(map[string]interface{}); metadata["sourceSystem"] != "crm" {
+		t.Errorf("nested map keys = %v, want camelCase", camel["metadata"])
+	}
+
+	configured := newHandler(apiHandler.WithResponseKeyCase(transport.CamelCase))
+	if _, ok := listTaskKeys(t, configured, "")["userId"]; !ok {
+		t.Error("configured camel case not applied by default")
+	}
+	if _, ok := listTaskKeys(t, configured, "application/json; case=snake")["user_id"]; !ok {
+		t.Error("Accept case=snake did not override the configured default")
+	}
+}
+
+func TestCreateTaskRejectsOversizedMetadata(t *testing.T) {
+	uc := taskUC.New(repositorytest.NewTasks(), nil, nil, taskUC.WithMetadataLimits(domain.MetadataLimits{MaxKeys: 1}))
+	h := apiHandler.NewTaskHandler(uc, nil, nil)
+
+	ctx := newRequestCtx(testRequest{
+		method:      http.MethodPost,
+		uri:         "/api/v1/tasks",
+		contentType: "application/json",
+		headers:     map[string]string{"X-User-ID": "user-1"},
+		body:        `{"title":"t","metadata":{"a":"1","b":"2"}}`,
+	})
+	h.CreateTask(ctx)
+
+	if ctx.Response.StatusCode() != http.StatusBadRequest {
+		t.Fatalf("status = %d, want 400; body %s", ctx.Response.StatusCode(), ctx.Response.Body())
+	}
+}
+
+func TestGetTaskConditionalRequests(t *testing.T) {
+	updated := time.Date(2030, 1, 2, 3, 4, 5, 700_000_000, time.UTC)
+	uc := taskUC.New(repositorytest.NewTasks(domain.Task{ID: "t1", UserID: "user-1", Title: "write", UpdatedAt: updated}), nil, nil)
+	h := apiHandler.NewTaskHandler(uc, nil, nil)
+	httpDate := func(at time.Time) string { return at.UTC().Format(http.TimeFormat) }
+
+	cases := []struct {
+		name            string
+		userID          string
+		ifModifiedSince string
+		want            int
+	}{
+		{"no condition", "user-1", "", http.StatusOK},
+		{"same second as sub-second update", "user-1", httpDate(updated), http.StatusNotModified},
+		{"after update", "user-1", httpDate(updated.Add(time.Hour)), http.StatusNotModified},
+		{"before update", "user-1", httpDate(updated.Add(-time.Second)), http.StatusOK},
+		{"malformed date", "user-1", "yesterday", http.StatusOK},
+		{"other user", "user-2", "", http.StatusNotFound},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			headers := map[string]string{"X-User-ID": tc.userID}
+			if tc.ifModifiedSince != "" {
+				headers["If-Modified-Since"] = tc.ifModifiedSince
+			}
+			ctx := newRequestCtx(testRequest{method: http.MethodGet, uri: "/api/v1/tasks/t1", headers: headers})
+			ctx.SetUserValue("id", "t1")
+			h.GetTask(ctx)
+
+			if ctx.Response.StatusCode() != tc.want {
+				t.Fatalf("status = %d, want %d; body %s", ctx.Response.StatusCode(), tc.want, ctx.Response.Body())
+			}
+			if tc.want == http.StatusNotModified && len(ctx.Response.Body()) != 0 {
+				t.Fatalf("304 carried a body: %s", ctx.Response.Body())
+			}
+			if tc.want != http.StatusNotFound {
+				if got := string(ctx.Response.Header.Peek("Last-Modified")); got != httpDate(updated) {
+					t.Fatalf("Last-Modified = %q, want %q", got, httpDate(updated))
+				}
+			}
+		})
+	}
+}
+
+func TestRepositoryContextErrorsAreNotInternal(t *testing.T) {
+	tests := []struct {
+		name   string
+		err    error
+		status int
+		code   string
+	}{
+		{"deadline", fmt.Errorf("list tasks: %w", context.DeadlineExceeded), http.StatusGatewayTimeout, apiHandler.ErrCodeRequestTimeout},
+		{"canceled", context.Canceled, apiHandler.StatusClientClosedRequest, apiHandler.ErrCodeRequestCanceled},
+		{"other", errors.New("connection reset"), http.StatusInternalServerError, "INTERNAL"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tasks := repositorytest.NewTasks()
+			tasks.Err = tt.err
+			h := apiHandler.NewTaskHandler(taskUC.New(tasks, nil, nil), nil, nil)
+
+			ctx := newRequestCtx(testRequest{
+				method:  http.MethodGet,
+				uri:     "/api/v1/tasks",
+				headers: map[string]string{"X-User-ID": "user-1"},
+			})
+			h.GetTasks(ctx)
+
+			if ctx.Response.StatusCode() != tt.status {
+				t.Fatalf("status = %d, want %d", ctx.Response.StatusCode(), tt.status)
+			}
+			if env := decodeEnvelope(t, ctx); env.Code != tt.code {
+				t.Fatalf("code = %q, want %q", env.Code, tt.code)
+			}
+		})
+	}
+}
+
+func TestRawResponses(t *testing.T) {
+	tasks := repositorytest.NewTasks(domain.Task{ID: "t1", UserID: "user-1", Title: "write"})
+	getTask := func(h *apiHandler.TaskHandler, id, accept string) *fasthttp.RequestCtx {
+		ctx := newRequestCtx(testRequest{
+			method:  http.MethodGet,
+			uri:     "/api/v1/tasks/" + id,
+			headers: map[string]string{"X-User-ID": "user-1", "Accept": accept},
+		})
+		ctx.SetUserValue("id", id)
+		h.GetTask(ctx)
+		return ctx
+	}
+
+	modes := []struct {
+		name   string
+		opts   []apiHandler.Option
+		accept string
+	}{
+		{name: "accept header", accept: transport.MediaTypeRaw},
+		{name: "configured", opts: []apiHandler.Option{apiHandler.WithRawResponses(true)}, accept: "application/json"},
+	}
+	for _, mode := range modes {
+		t.Run(mode.name, func(t *testing.T) {
+			h := apiHandler.NewTaskHandler(taskUC.New(tasks, nil, nil), nil, nil, mode.opts...)
+
+			ctx := getTask(h, "t1", mode.accept)
+			var task map[string]interface{}
+			if err := json.Unmarshal(ctx.Response.Body(), &task); err != nil {
+				t.Fatalf("decode: %v", err)
+			}
+			if ctx.Response.StatusCode() != http.StatusOK || task["id"] != "t1" || task["data"] != nil {
+				t.Fatalf("status %d body %s, want the bare task", ctx.Response.StatusCode(), ctx.Response.Body())
+			}
+
+			ctx = getTask(h, "missing", mode.accept)
+			if got := string(ctx.Response.Header.ContentType()); got != transport.MediaTypeProblem {
+				t.Fatalf("content type = %q, want %q", got, transport.MediaTypeProblem)
+			}
+			var problem transport.Problem
+			if err := json.Unmarshal(ctx.Response.Body(), &problem); err != nil {
+				t.Fatalf("decode problem: %v", err)
+			}
+			want := transport.Problem{Type: "urn:fastygo:problem:not-found", Title: "Not Found", Status: http.StatusNotFound, Detail: "task not found",
+				Instance: string(ctx.Response.Header.Peek("X-Request-ID")), Code: "NOT_FOUND"}
+			if ctx.Response.StatusCode() != http.StatusNotFound || problem.Instance == "" || problem != want {
+				t.Fatalf("status %d problem %+v, want %+v", ctx.Response.StatusCode(), problem, want)
+			}
+		})
+	}
+
+	h := apiHandler.NewTaskHandler(taskUC.New(tasks, nil, nil), nil, nil)
+	if env := decodeEnvelope(t, getTask(h, "t1", "application/json")); env.Status != "success" {
+		t.Fatalf("envelope = %+v, want the default envelope", env)
+	}
+}
+
+func TestProblemErrorsMapEachCode(t *testing.T) {
+	tests := []struct {
+		err    error
+		status int
+		code   string
+	}{
+		{domain.ErrUnauthorized, http.StatusUnauthorized, "UNAUTHORIZED"},
+		{domain.NewError(domain.ErrCodeForbidden, "not yours"), http.StatusForbidden, "FORBIDDEN"},
+		{domain.ErrInvalidPayload, http.StatusBadRequest, "INVALID"},
+		{domain.ErrTaskNotFound, http.StatusNotFound, "NOT_FOUND"},
+		{domain.ErrConflict, http.StatusConflict, "CONFLICT"},
+		{buffer.ErrUserQuotaExceeded, http.StatusTooManyRequests, "LIMIT_EXCEEDED"},
+		{context.DeadlineExceeded, http.StatusGatewayTimeout, apiHandler.ErrCodeRequestTimeout},
+		{errors.New("boom"), http.StatusInternalServerError, "INTERNAL"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.code, func(t *testing.T) {
+			tasks := repositorytest.NewTasks()
+			tasks.Err = tt.err
+			for _, h := range []*apiHandler.TaskHandler{
+				apiHandler.NewTaskHandler(taskUC.New(tasks, nil, nil), httpcontext.NewAdapter(time.Second), nil, apiHandler.WithProblemErrors(true)),
+				apiHandler.NewTaskHandler(taskUC.New(tasks, nil, nil), httpcontext.NewAdapter(time.Second), nil),
+			} {
+				ctx := newRequestCtx(testRequest{
+					method:  http.MethodGet,
+					uri:     "/api/v1/tasks",
+					headers: map[string]string{"X-User-ID": "user-1", "X-Request-ID": "req-42", "Accept": "application/problem+json"},
+				})
+				h.GetTasks(ctx)
+
+				if got := string(ctx.Response.Header.ContentType()); got != transport.MediaTypeProblem {
+					t.Fatalf("content type = %q, want %q", got, transport.MediaTypeProblem)
+				}
+				var problem transport.Problem
+				if err := json.Unmarshal(ctx.Response.Body(), &problem); err != nil {
+					t.Fatalf("decode: %v", err)
+				}
+				want := transport.Problem{
+					Type:     transport.ProblemType(tt.code),
+					Title:    http.StatusText(tt.status),
+					Status:   tt.status,
+					Detail:   tt.err.Error(),
+					Instance: "req-42",
+					Code:     tt.code,
+				}
+				if ctx.Response.StatusCode() != tt.status || problem != want {
+					t.Fatalf("status %d problem %+v, want %+v", ctx.Response.StatusCode(), problem, want)
+				}
+			}
+		})
+	}
+
+	h := newTaskHandler(apiHandler.WithProblemErrors(true))
+	ctx := newRequestCtx(testRequest{method: http.MethodGet, uri: "/api/v1/tasks", headers: map[string]string{"X-User-ID": "user-1"}})
+	h.GetTasks(ctx)
+	if env := decodeEnvelope(t, ctx); env.Status != "success" {
+		t.Fatalf("successes must keep the envelope, got %+v", env)
+	}
+}
+
+func TestCreateTaskDueDate(t *testing.T) {
+	tests := []struct {
+		name    string
+		dueDate string
+		status  int
+		due     interface{}
+	}{
+		{name: "rfc3339", dueDate: "2030-01-02T15:04:05Z", status: http.StatusCreated, due: "2030-01-02T15:04:05Z"},
+		{name: "empty means none", dueDate: "", status: http.StatusCreated},
+		{name: "malformed", dueDate: "tomorrow", status: http.StatusBadRequest},
+		{name: "date only", dueDate: "2030-01-02", status: http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := newRequestCtx(testRequest{
+				method:      http.MethodPost,
+				uri:         "/api/v1/tasks",
+				body:        `{"title":"dated","due_date":"` + tt.dueDate + `"}`,
+				contentType: "application/json",
+				headers:     map[string]string{"X-User-ID": "user-1"},
+			})
+			newTaskHandler().CreateTask(ctx)
+
+			if ctx.Response.StatusCode() != tt.status {
+				t.Fatalf("status = %d, want %d; body %s", ctx.Response.StatusCode(), tt.status, ctx.Response.Body())
+			}
+			env := decodeEnvelope(t, ctx)
+			if tt.status == http.StatusBadRequest {
+				if field, _ := env.Error.(map[string]interface{}); field["field"] != "due_date" {
+					t.Fatalf("error = %v, want a due_date field error", env.Error)
+				}
+				return
+			}
+			if data, _ := env.Data.(map[string]interface{}); data["due_date"] != tt.due {
+				t.Fatalf("due_date = %v, want %v", data["due_date"], tt.due)
+			}
+		})
+	}
+}
+
+func TestLimitExceededAnswers429WithDetails(t *testing.T) {
+	tasks := repositorytest.NewTasks()
+	tasks.Err = fmt.Errorf("create task: %w", domain.NewLimitError("tasks", 100, 100))
+	h := apiHandler.NewTaskHandler(taskUC.New(tasks, nil, nil), nil, nil)
+	getTask := func(accept string) *fasthttp.RequestCtx {
+		ctx := newRequestCtx(testRequest{
+			method:  http.MethodGet,
+			uri:     "/api/v1/tasks/t1",
+			headers: map[string]string{"X-User-ID": "user-1", "Accept": accept},
+		})
+		ctx.SetUserValue("id", "t1")
+		h.GetTask(ctx)
+		return ctx
+	}
+	wantMeta := map[string]interface{}{"resource": "tasks", "limit": float64(100), "current": float64(100)}
+
+	ctx := getTask("application/json")
+	if ctx.Response.StatusCode() != http.StatusTooManyRequests {
+		t.Fatalf("status = %d, want 429", ctx.Response.StatusCode())
+	}
+	env := decodeEnvelope(t, ctx)
+	if env.Code != string(domain.ErrCodeLimitExceeded) || !reflect.DeepEqual(env.Meta, wantMeta) {
+		t.Fatalf("envelope = %+v, want LIMIT_EXCEEDED with meta %v", env, wantMeta)
+	}
+
+	ctx = getTask(transport.MediaTypeProblem)
+	var problem struct {
+		Type string                 `json:"type"`
+		Meta map[string]interface{} `json:"meta"`
+	}
+	if err := json.Unmarshal(ctx.Response.Body(), &problem); err != nil {
+		t.Fatalf("decode problem: %v", err)
+	}
+	if problem.Type != "urn:fastygo:problem:limit-exceeded" || !reflect.DeepEqual(problem.Meta, wantMeta) {
+		t.Fatalf("problem = %+v, want limit-exceeded with meta %v", problem, wantMeta)
+	}
+}
+
+func TestTaskWritesAreScopedToOwnerAndTenant(t *testing.T) {
+	tasks := repositorytest.NewTasks(domain.Task{ID: "t1", UserID: "user-1", TenantID: "acme", Title: "write", Status: "pending"})
+	h := apiHandler.NewTaskHandler(taskUC.New(tasks, nil, nil), httpcontext.NewAdapter(time.Second), nil)
+	write := func(method, userID, tenantID string) int {
+		ctx := newRequestCtx(testRequest{
+			method:      method,
+			uri:         "/api/v1/tasks/t1",
+			body:        `{"title":"hijacked","status":"pending"}`,
+			contentType: "application/json",
+			headers:     map[string]string{"X-User-ID": userID},
+		})
+		ctx.SetUserValue("id", "t1")
+		if tenantID != "" {
+			ctx.SetUserValue(httpcontext.KeyTenantID, tenantID)
+		}
+		if method == http.MethodPut {
+			h.UpdateTask(ctx)
+		} else {
+			h.DeleteTask(ctx)
+		}
+		return ctx.Response.StatusCode()
+	}
+
+	for _, caller := range []struct{ name, userID, tenantID string }{
+		{name: "other user in the tenant", userID: "user-2", tenantID: "acme"},
+		{name: "owner from another tenant", userID: "user-1", tenantID: "globex"},
+		{name: "owner without a tenant", userID: "user-1"},
+	} {
+		for _, method := range []string{http.MethodPut, http.MethodDelete} {
+			if status := write(method, caller.userID, caller.tenantID); status != http.StatusNotFound {
+				t.Errorf("%s: %s answered %d, want 404", caller.name, method, status)
+			}
+		}
+	}
+	if task, err := tasks.GetByID(context.Background(), "t1"); err != nil || task.Title != "write" {
+		t.Fatalf("task = %+v, %v; want it untouched", task, err)
+	}
+
+	if status := write(http.MethodPut, "user-1", "acme"); status != http.StatusOK {
+		t.Fatalf("owner update answered %d, want 200", status)
+	}
+	if status := write(http.MethodDelete, "user-1", "acme"); status != http.StatusNoContent {
+		t.Fatalf("owner delete answered %d, want 204", status)
+	}
+}
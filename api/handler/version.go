@@ -0,0 +1,61 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/valyala/fasthttp"
+	"go.uber.org/zap"
+
+	"github.com/fastygo/backend/api/transport"
+	"github.com/fastygo/backend/internal/infrastructure/postgres"
+	"github.com/fastygo/backend/pkg/buildinfo"
+	"github.com/fastygo/backend/pkg/httpcontext"
+)
+
+// SchemaVersionProvider reports the applied migration version; *postgres.SchemaVersionReader is the
+// production implementation.
+type SchemaVersionProvider interface {
+	SchemaVersion(ctx context.Context) (postgres.SchemaVersion, error)
+}
+
+var _ SchemaVersionProvider = (*postgres.SchemaVersionReader)(nil)
+
+type VersionHandler struct {
+	baseHandler
+	schema SchemaVersionProvider
+}
+
+func NewVersionHandler(schema SchemaVersionProvider, adapter *httpcontext.Adapter, logger *zap.Logger, opts ...Option) *VersionHandler {
+	return &VersionHandler{
+		baseHandler: newBaseHandler(adapter, logger, opts...),
+		schema:      schema,
+	}
+}
+
+// @Summary Build and schema version
+// @Description Reports the binary's version and commit and the database migration version, so a deploy can check that code and schema match. "schema" is null when no migration has been recorded.
+// @Tags health
+// @Router /version [get]
+func (h *VersionHandler) Get(ctx *fasthttp.RequestCtx) {
+	payload := map[string]interface{}{
+		"version": buildinfo.Version,
+		"commit":  buildinfo.Commit,
+		"schema":  nil,
+	}
+	stdCtx, cancel := h.requestContext(ctx)
+	defer cancel()
+
+	schema, err := h.schema.SchemaVersion(stdCtx)
+	switch {
+	case errors.Is(err, postgres.ErrNoSchemaVersion):
+	case err != nil:
+		h.ctxLogger(stdCtx).Warn("failed to read schema version", zap.Error(err))
+		h.respondJSON(ctx, http.StatusServiceUnavailable, transport.NewError("SCHEMA_UNAVAILABLE", "schema version could not be read", payload))
+		return
+	default:
+		payload["schema"] = schema
+	}
+	h.respondSuccess(ctx, http.StatusOK, payload)
+}
@@ -0,0 +1,85 @@
+package transport
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+)
+
+// KeyCase selects how object keys are spelled in JSON responses.
+type KeyCase string
+
+const (
+	// SnakeCase keeps the domain JSON tags as declared; it is the default.
+	SnakeCase KeyCase = "snake"
+	// CamelCase rewrites snake_case keys to camelCase.
+	CamelCase KeyCase = "camel"
+)
+
+// ParseKeyCase maps a configured or requested case name to a KeyCase, reporting false for unknown names.
+func ParseKeyCase(name string) (KeyCase, bool) {
+	switch KeyCase(strings.ToLower(strings.TrimSpace(name))) {
+	case SnakeCase:
+		return SnakeCase, true
+	case CamelCase:
+		return CamelCase, true
+	default:
+		return "", false
+	}
+}
+
+// CamelCaseKeys rewrites every object key of a JSON document to camelCase, at any depth. Map keys are
+// rewritten like struct fields because the encoded document no longer tells them apart; values,
+// including numbers, are left untouched.
+func CamelCaseKeys(body []byte) ([]byte, error) {
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+	var doc interface{}
+	if err := decoder.Decode(&doc); err != nil {
+		return nil, err
+	}
+	return json.Marshal(camelizeValue(doc))
+}
+
+func camelizeValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		out := make(map[string]interface{}, len(v))
+		for key, nested := range v {
+			out[camelize(key)] = camelizeValue(nested)
+		}
+		return out
+	case []interface{}:
+		for i, nested := range v {
+			v[i] = camelizeValue(nested)
+		}
+		return v
+	default:
+		return value
+	}
+}
+
+// camelize turns "next_attempt" into "nextAttempt". Leading underscores and keys without
+// underscores are kept as they are.
+func camelize(key string) string {
+	trimmed := strings.TrimLeft(key, "_")
+	if !strings.Contains(trimmed, "_") {
+		return key
+	}
+	var b strings.Builder
+	b.Grow(len(key))
+	b.WriteString(key[:len(key)-len(trimmed)])
+	upper := false
+	for _, r := range trimmed {
+		switch {
+		case r == '_':
+			upper = true
+		case upper:
+			b.WriteString(strings.ToUpper(string(r)))
+			upper = false
+		default:
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
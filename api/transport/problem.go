@@ -0,0 +1,62 @@
+package transport
+
+import (
+	"net/http"
+	"strings"
+)
+
+// MediaTypeRaw asks for the bare resource (or a problem document) instead of the Envelope.
+const MediaTypeRaw = "application/vnd.api.raw+json"
+
+// MediaTypeProblem is the content type of Problem responses.
+const MediaTypeProblem = "application/problem+json"
+
+// ProblemTypePrefix namespaces the type URIs derived from error codes.
+const ProblemTypePrefix = "urn:fastygo:problem:"
+
+// ProblemType maps an error code to its problem type URI, e.g. NOT_FOUND to
+// "urn:fastygo:problem:not-found". Without a code the type is "about:blank".
+func ProblemType(code string) string {
+	if code == "" {
+		return "about:blank"
+	}
+	return ProblemTypePrefix + strings.ToLower(strings.ReplaceAll(code, "_", "-"))
+}
+
+// Problem is an RFC 7807 problem document, the alternative to an error Envelope. Instance carries the
+// request ID; Code, Field, Errors and Meta are extension members carrying what the envelope would have.
+type Problem struct {
+	Type     string      `json:"type"`
+	Title    string      `json:"title"`
+	Status   int         `json:"status"`
+	Detail   string      `json:"detail,omitempty"`
+	Instance string      `json:"instance,omitempty"`
+	Code     string      `json:"code,omitempty"`
+	Field    string      `json:"field,omitempty"`
+	Errors   interface{} `json:"errors,omitempty"`
+	Meta     interface{} `json:"meta,omitempty"`
+}
+
+// NewProblem converts an error envelope answered with status into a problem document about the
+// request identified by instance.
+func NewProblem(status int, env Envelope, instance string) Problem {
+	problem := Problem{
+		Type:     ProblemType(env.Code),
+		Title:    http.StatusText(status),
+		Status:   status,
+		Instance: instance,
+		Code:     env.Code,
+		Meta:     env.Meta,
+	}
+	switch detail := env.Error.(type) {
+	case string:
+		problem.Detail = detail
+	case FieldError:
+		problem.Detail = detail.Message
+		problem.Field = detail.Field
+	case nil:
+	default:
+		problem.Errors = detail
+	}
+	return problem
+}
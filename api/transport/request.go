@@ -1,10 +1,12 @@
 package transport
 
+import "github.com/fastygo/backend/domain"
+
 type ProfileUpdateRequest struct {
-	Email   string            `json:"email"`
-	Role    string            `json:"role"`
-	Status  string            `json:"status"`
-	Meta    map[string]string `json:"metadata"`
+	Email  string            `json:"email"`
+	Role   string            `json:"role"`
+	Status string            `json:"status"`
+	Meta   map[string]string `json:"metadata"`
 }
 
 type TaskRequest struct {
@@ -17,9 +19,17 @@ type TaskRequest struct {
 	Metadata    map[string]string `json:"metadata"`
 }
 
+type AggregateBatchRequest struct {
+	// Mode is "atomic" (the default), "best_effort" or "copy".
+	Mode       string             `json:"mode"`
+	Aggregates []domain.Aggregate `json:"aggregates"`
+}
+
 type AuthLoginRequest struct {
 	UserID string `json:"user_id"`
-	TTL    int    `json:"ttl_seconds"`
+	// Credential is verified by the configured authenticator, e.g. a password or one-time code.
+	Credential string `json:"credential"`
+	TTL        int    `json:"ttl_seconds"`
 }
 
 type RefreshRequest struct {
@@ -27,3 +37,12 @@ type RefreshRequest struct {
 	TTL       int    `json:"ttl_seconds"`
 }
 
+type LogoutRequest struct {
+	SessionID string `json:"session_id"`
+}
+
+type GraphQLRequest struct {
+	Query         string                 `json:"query"`
+	Variables     map[string]interface{} `json:"variables"`
+	OperationName string                 `json:"operationName"`
+}
@@ -11,6 +11,12 @@ type Envelope struct {
 	Meta   interface{} `json:"meta,omitempty"`
 }
 
+// FieldError describes a validation failure tied to a specific request field.
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
 // NewSuccess returns a success envelope.
 func NewSuccess(data interface{}, meta interface{}) Envelope {
 	return Envelope{
@@ -2,26 +2,38 @@ package main
 
 import (
 	"context"
+	"expvar"
 	"log"
+	"net"
+	"os"
+	"os/signal"
+	"syscall"
 	"time"
 
 	"github.com/valyala/fasthttp"
 	"go.uber.org/zap"
 
 	apiHandler "github.com/fastygo/backend/api/handler"
+	"github.com/fastygo/backend/api/transport"
+	"github.com/fastygo/backend/domain"
 	"github.com/fastygo/backend/internal/config"
 	"github.com/fastygo/backend/internal/infrastructure/buffer"
 	"github.com/fastygo/backend/internal/infrastructure/monitor"
 	pgInfra "github.com/fastygo/backend/internal/infrastructure/postgres"
 	redisInfra "github.com/fastygo/backend/internal/infrastructure/redis"
 	"github.com/fastygo/backend/internal/middleware"
+	"github.com/fastygo/backend/internal/notify"
 	"github.com/fastygo/backend/internal/router"
 	"github.com/fastygo/backend/internal/services"
 	"github.com/fastygo/backend/internal/services/lifecycle"
+	grpcTransport "github.com/fastygo/backend/internal/transport/grpc"
+	"github.com/fastygo/backend/pkg/buildinfo"
 	"github.com/fastygo/backend/pkg/httpcontext"
 	"github.com/fastygo/backend/pkg/logger"
 	"github.com/fastygo/backend/repository/postgres"
 	redisRepo "github.com/fastygo/backend/repository/redis"
+	"github.com/fastygo/backend/usecase"
+	aggregateUC "github.com/fastygo/backend/usecase/aggregate"
 	authUC "github.com/fastygo/backend/usecase/auth"
 	profileUC "github.com/fastygo/backend/usecase/profile"
 	taskUC "github.com/fastygo/backend/usecase/task"
@@ -32,21 +44,34 @@ func main() {
 	if err != nil {
 		log.Fatalf("config error: %v", err)
 	}
+	if err := cfg.Validate(); err != nil {
+		log.Fatalf("config error: %v", err)
+	}
 
 	zapLogger, err := logger.New(logger.Config{
-		Level:    cfg.Logger.Level,
-		Encoding: cfg.Logger.Encoding,
+		Level:       cfg.Logger.Level,
+		Encoding:    cfg.Logger.Encoding,
+		Environment: cfg.Environment,
+		Version:     buildinfo.Version,
+		Commit:      buildinfo.Commit,
 	})
 	if err != nil {
 		log.Fatalf("logger error: %v", err)
 	}
 	defer zapLogger.Sync()
+	for _, warning := range cfg.Warnings {
+		zapLogger.Warn("configuration conflict", zap.String("detail", warning))
+	}
 
 	appCtx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
 	manager := lifecycle.New(cfg.Context.ShutdownTimeout, zapLogger)
 	manager.Listen(cancel)
+	go watchReload(appCtx, config.NewReloader(cfg, config.Load), zapLogger)
+	readiness := lifecycle.NewReadiness()
+	// Streaming handlers register here so shutdown can ask their clients to reconnect.
+	streams := lifecycle.NewStreams(cfg.HTTP.StreamGracePeriod)
 
 	if err := pgInfra.RunMigrations(cfg, zapLogger); err != nil {
 		zapLogger.Fatal("migrations failed", zap.Error(err))
@@ -68,16 +93,59 @@ func main() {
 	manager.Register("redis", func(ctx context.Context) error {
 		return redisClient.Close()
 	})
+	sessionOpts := []redisRepo.Option{
+		redisRepo.WithNamespace(cfg.Redis.Namespace),
+		redisRepo.WithRetry(redisRepo.RetryPolicy{MaxRetries: cfg.Redis.MaxRetries, Backoff: cfg.Redis.RetryBackoff}),
+	}
+	if cfg.Redis.ReplicaURL != "" {
+		replicaCfg := cfg.Redis
+		replicaCfg.URL = cfg.Redis.ReplicaURL
+		redisReplica, err := redisInfra.NewClient(replicaCfg)
+		if err != nil {
+			zapLogger.Fatal("redis replica connection failed", zap.Error(err))
+		}
+		manager.Register("redis_replica", func(ctx context.Context) error {
+			return redisReplica.Close()
+		})
+		sessionOpts = append(sessionOpts, redisRepo.WithReadReplica(redisReplica, cfg.Redis.ReadYourWritesWindow))
+	}
+
+	var notifier notify.Notifier = notify.Nop{}
+	switch cfg.Notify.Channel {
+	case "log":
+		notifier = notify.NewLog(zapLogger)
+	case "webhook":
+		notifier = notify.NewWebhook(cfg.Notify.WebhookURL, notify.WithAttempts(cfg.Notify.WebhookAttempts))
+	}
 
-	bufferStore, err := buffer.Open(cfg.Buffer.Path, "buffer")
+	bufferStore, err := buffer.Open(cfg.Buffer.Path, "buffer",
+		buffer.WithUserQuota(cfg.Buffer.UserQuota),
+		buffer.WithMaxSize(cfg.Buffer.MaxSize),
+	)
 	if err != nil {
 		zapLogger.Fatal("failed to open buffer store", zap.Error(err))
 	}
 	manager.Register("buffer", func(ctx context.Context) error {
-		return bufferStore.Close()
+		return bufferStore.CloseGraceful(ctx)
 	})
 
-	mon := monitor.New(pool, redisClient, bufferStore, 10*time.Second, zapLogger)
+	// The watchdog turns a background loop that died or hung into a failing /health/live. A cron
+	// schedule has no fixed period, so the buffer processor is only watched on the interval schedule.
+	var watchdog *lifecycle.Watchdog
+	monitorOpts := []monitor.Option{
+		monitor.WithJitter(cfg.Monitor.Jitter),
+		monitor.WithBufferAlerts(notifier, cfg.Monitor.BufferHighWater, cfg.Monitor.BufferLowWater),
+	}
+	var bufferHeartbeat func()
+	if misses := time.Duration(cfg.Monitor.MissedBeats); misses > 0 {
+		watchdog = lifecycle.NewWatchdog(nil)
+		monitorOpts = append(monitorOpts, monitor.WithHeartbeat(
+			watchdog.Register("monitor", misses*(cfg.Monitor.Interval+cfg.Monitor.Jitter))))
+		if cfg.Buffer.SyncSchedule == "" {
+			bufferHeartbeat = watchdog.Register("buffer_processor", misses*cfg.Buffer.SyncInterval)
+		}
+	}
+	mon := monitor.New(pool, redisClient, bufferStore, cfg.Monitor.Interval, zapLogger, monitorOpts...)
 	mon.Start()
 	manager.Register("monitor", func(ctx context.Context) error {
 		mon.Stop()
@@ -85,56 +153,249 @@ func main() {
 	})
 
 	userRepo := postgres.NewUserRepository(pool)
-	taskRepo := postgres.NewTaskRepository(pool)
-	sessionRepo := redisRepo.NewSessionRepository(redisClient, 24*time.Hour)
+	taskRepo := postgres.NewTaskRepository(pool, postgres.WithReminders(cfg.Reminders.Enabled))
+	aggregateRepo := postgres.NewAggregateRepository(pool)
+	redisTTLs := redisRepo.TTLPolicy{
+		Session:      cfg.Redis.SessionTTL,
+		UserSessions: cfg.Redis.UserSessionsTTL,
+		Nonce:        cfg.Nonce.TTL,
+	}
+	sessionRepo := redisRepo.NewSessionRepository(redisClient, redisTTLs, sessionOpts...)
 
-	bufferProcessor := services.NewBufferProcessor(
+	callbackAttempts := 0
+	if cfg.Buffer.CallbacksEnabled {
+		callbackAttempts = cfg.Buffer.CallbackAttempts
+	}
+	bufferProcessor, err := services.NewBufferProcessor(
 		bufferStore,
 		mon,
 		userRepo,
 		taskRepo,
 		zapLogger,
 		services.ProcessorConfig{
-			Interval:   cfg.Buffer.SyncInterval,
-			BatchSize:  50,
-			MaxRetries: cfg.Buffer.MaxRetry,
+			Interval:     cfg.Buffer.SyncInterval,
+			BatchSize:    50,
+			MaxRetries:   cfg.Buffer.MaxRetry,
+			MaxAge:       cfg.Buffer.MaxAge,
+			RetryBackoff: cfg.Buffer.RetryBackoff,
+			Retention:    time.Duration(cfg.Buffer.RetentionHours) * time.Hour,
+			Schedule:     cfg.Buffer.SyncSchedule,
+			EntityBatchSizes: map[string]int{
+				buffer.EntityProfile: cfg.Buffer.ProfileBatchSize,
+				buffer.EntityTask:    cfg.Buffer.TaskBatchSize,
+			},
+			CallbackAttempts:  callbackAttempts,
+			CallbackTimeout:   cfg.Buffer.CallbackTimeout,
+			RecoveryThreshold: cfg.Buffer.RecoveryThreshold,
+			RecoveryLowWater:  cfg.Buffer.RecoveryLowWater,
+			RecoveryBudget:    cfg.Buffer.RecoveryBudget,
+			RecoveryPause:     cfg.Buffer.RecoveryPause,
+			Notifier:          notifier,
+			Heartbeat:         bufferHeartbeat,
 		},
 	)
+	if err != nil {
+		zapLogger.Fatal("buffer processor misconfigured", zap.Error(err))
+	}
 	bufferProcessor.Start()
 	manager.Register("buffer_processor", func(ctx context.Context) error {
 		bufferProcessor.Stop(ctx)
 		return nil
 	})
 
-	bufferBridge := services.NewBufferBridge(bufferProcessor)
+	if cfg.Reminders.Enabled {
+		reminders, err := services.NewReminderScheduler(postgres.NewTaskReminders(pool), notifier, zapLogger, services.ReminderConfig{
+			Schedule: cfg.Reminders.Schedule,
+			Window:   cfg.Reminders.Window,
+		})
+		if err != nil {
+			zapLogger.Fatal("task reminders misconfigured", zap.Error(err))
+		}
+		reminders.Start()
+		manager.Register("reminders", func(ctx context.Context) error {
+			reminders.Stop(ctx)
+			return nil
+		})
+	}
 
-	authUseCase := authUC.New(userRepo, sessionRepo, zapLogger)
-	profileUseCase := profileUC.New(userRepo, bufferBridge, zapLogger)
-	taskUseCase := taskUC.New(taskRepo, bufferBridge, zapLogger)
+	if cfg.Outbox.Enabled {
+		relay, err := services.NewOutboxRelay(postgres.NewOutboxRepository(pool), services.NotifierPublisher{Notifier: notifier}, zapLogger, services.OutboxConfig{
+			Interval:     cfg.Outbox.Interval,
+			BatchSize:    cfg.Outbox.BatchSize,
+			MaxAttempts:  cfg.Outbox.MaxAttempts,
+			MaxAge:       cfg.Outbox.MaxAge,
+			RetryBackoff: cfg.Outbox.RetryBackoff,
+			Notifier:     notifier,
+		})
+		if err != nil {
+			zapLogger.Fatal("outbox relay misconfigured", zap.Error(err))
+		}
+		relay.Start()
+		manager.Register("outbox_relay", func(ctx context.Context) error {
+			relay.Stop(ctx)
+			return nil
+		})
+	}
+
+	strategy := services.WriteStrategy(cfg.Buffer.WriteStrategy)
+	if strategy != services.StrategyOptimistic && strategy != services.StrategyDeferred {
+		zapLogger.Fatal("BUFFER_WRITE_STRATEGY must be optimistic or deferred", zap.String("value", cfg.Buffer.WriteStrategy))
+	}
+	bufferBridge := services.NewBufferBridge(bufferProcessor, map[string]services.BufferPolicy{
+		buffer.EntityProfile: {Enabled: cfg.Buffer.ProfileEnabled, Priority: cfg.Buffer.ProfilePriority, Strategy: strategy},
+		buffer.EntityTask:    {Enabled: cfg.Buffer.TaskEnabled, Priority: cfg.Buffer.TaskPriority, Strategy: strategy},
+	})
+
+	// Session events go to the log as an audit trail; security tooling can subscribe alongside.
+	events := usecase.NewEventBus()
+	events.Subscribe(func(ctx context.Context, event interface{}) error {
+		if e, ok := event.(domain.SessionEvent); ok {
+			zapLogger.Info("session event",
+				zap.String("event", string(e.Type)),
+				zap.String("user_id", e.UserID),
+				zap.String("session_id", e.SessionID),
+				zap.String("ip", e.IP),
+				zap.String("user_agent", e.UserAgent))
+		}
+		return nil
+	})
+	var authenticator authUC.Authenticator = authUC.DenyAll{}
+	if cfg.Auth.Authenticator == "allow_all" {
+		zapLogger.Warn("AUTH_AUTHENTICATOR=allow_all: any credential logs in as any user")
+		authenticator = authUC.AllowAll{}
+	}
+	authUseCase := authUC.New(userRepo, sessionRepo, zapLogger,
+		authUC.WithEvents(events),
+		authUC.WithAuthenticator(authenticator))
+	var profileOpts []profileUC.Option
+	if cfg.Cache.ProfileEnabled {
+		profileCache := profileUC.NewMemoryCache(cfg.Cache.ProfileStaleTTL, cfg.Cache.ProfileMaxEntries, nil)
+		profileOpts = append(profileOpts, profileUC.WithCache(profileCache, cfg.Cache.ProfileTTL, func() bool {
+			return mon.GetStatus().PostgreSQL
+		}))
+	}
+	metadataLimits := domain.MetadataLimits{MaxKeys: cfg.Metadata.MaxKeys, MaxBytes: cfg.Metadata.MaxBytes}
+	profileOpts = append(profileOpts, profileUC.WithMetadataLimits(metadataLimits))
+	profileUseCase := profileUC.New(userRepo, bufferBridge, zapLogger, profileOpts...)
+	taskOpts := []taskUC.Option{taskUC.WithMetadataLimits(metadataLimits)}
+	if cfg.Cache.TaskListCoalesce {
+		taskOpts = append(taskOpts, taskUC.WithListCoalescing(cfg.Cache.TaskListTTL, cfg.Cache.TaskListMaxEntries, nil))
+	}
+	taskUseCase := taskUC.New(taskRepo, bufferBridge, zapLogger, taskOpts...)
+	aggregateUseCase := aggregateUC.New(aggregateRepo, zapLogger,
+		aggregateUC.WithMetadataLimits(metadataLimits),
+		aggregateUC.WithOptimisticLocking(cfg.Aggregate.OptimisticLocking),
+		aggregateUC.WithMaxBatchSize(cfg.Aggregate.MaxBatchSize),
+		aggregateUC.WithMaxImportSize(cfg.Aggregate.MaxImportSize),
+	)
 
-	ctxAdapter := httpcontext.NewAdapter(cfg.Context.RequestTimeout)
+	var adapterOpts []httpcontext.AdapterOption
+	if cfg.HTTP.RequestIDFormat == "ulid" {
+		adapterOpts = append(adapterOpts, httpcontext.WithRequestIDGenerator(httpcontext.NewULID))
+	}
+	ctxAdapter := httpcontext.NewAdapter(cfg.Context.RequestTimeout, adapterOpts...)
+
+	keyCase, ok := transport.ParseKeyCase(cfg.HTTP.ResponseKeyCase)
+	if !ok {
+		zapLogger.Fatal("SERVER_RESPONSE_KEY_CASE must be snake or camel", zap.String("value", cfg.HTTP.ResponseKeyCase))
+	}
+	handlerOpts := []apiHandler.Option{
+		apiHandler.WithResponseKeyCase(keyCase),
+		apiHandler.WithStrictQuery(cfg.HTTP.StrictQuery),
+		apiHandler.WithMaxOffset(cfg.HTTP.MaxOffset),
+		apiHandler.WithLenientContentType(cfg.HTTP.LenientContentType),
+		apiHandler.WithRawResponses(cfg.HTTP.RawResponses),
+		apiHandler.WithProblemErrors(cfg.HTTP.ProblemErrors),
+		apiHandler.WithBufferCallbacks(cfg.Buffer.CallbacksEnabled),
+	}
 
 	handlers := router.Handlers{
-		Auth:    apiHandler.NewAuthHandler(authUseCase, ctxAdapter, zapLogger, time.Hour),
-		Profile: apiHandler.NewProfileHandler(profileUseCase, ctxAdapter, zapLogger),
-		Task:    apiHandler.NewTaskHandler(taskUseCase, ctxAdapter, zapLogger),
-		Health:  apiHandler.NewHealthHandler(mon, ctxAdapter, zapLogger),
+		Auth:      apiHandler.NewAuthHandler(authUseCase, ctxAdapter, zapLogger, time.Hour, handlerOpts...),
+		Profile:   apiHandler.NewProfileHandler(profileUseCase, ctxAdapter, zapLogger, handlerOpts...),
+		Task:      apiHandler.NewTaskHandler(taskUseCase, ctxAdapter, zapLogger, handlerOpts...),
+		Health:    apiHandler.NewHealthHandler(mon, readiness, watchdog, ctxAdapter, zapLogger, handlerOpts...),
+		Version:   apiHandler.NewVersionHandler(pgInfra.NewSchemaVersionReader(pool, cfg.Migrations.VersionCacheTTL), ctxAdapter, zapLogger, handlerOpts...),
+		Admin:     apiHandler.NewAdminHandler(bufferProcessor, bufferStore, ctxAdapter, zapLogger, cfg.Buffer.ManualSyncTimeout, handlerOpts...),
+		Aggregate: apiHandler.NewAggregateHandler(aggregateUseCase, ctxAdapter, zapLogger, handlerOpts...),
+		Fallback:  apiHandler.NewFallbackHandler(ctxAdapter, zapLogger, handlerOpts...),
+	}
+
+	if cfg.Features.GraphQL {
+		handlers.GraphQL, err = apiHandler.NewGraphQLHandler(profileUseCase, taskUseCase, ctxAdapter, zapLogger, handlerOpts...)
+		if err != nil {
+			zapLogger.Fatal("failed to build graphql schema", zap.Error(err))
+		}
 	}
 
-	authMiddleware := middleware.JWTAuth(cfg.JWT.Secret, zapLogger)
-	r := router.New(handlers, authMiddleware)
+	authMiddleware := middleware.JWTAuth(cfg.JWT.Secret, zapLogger,
+		middleware.WithLeeway(cfg.JWT.Leeway),
+		middleware.WithIssuer(cfg.JWT.Issuer),
+		middleware.WithAudience(cfg.JWT.Audience),
+	)
+	if cfg.APIKeys.Keys != "" {
+		apiKeys, err := middleware.ParseAPIKeys(cfg.APIKeys.Keys)
+		if err != nil {
+			zapLogger.Fatal("invalid API_KEYS", zap.Error(err))
+		}
+		authMiddleware = middleware.APIKeyOrJWT(apiKeys, authMiddleware, zapLogger)
+	}
+	routerOpts := []router.Option{
+		router.WithRequireTenant(cfg.JWT.RequireTenant),
+		router.WithMetrics(cfg.Features.Metrics),
+		router.WithPprof(cfg.Features.Pprof),
+	}
+	if cfg.Nonce.Enabled {
+		if cfg.Nonce.Secret == "" {
+			zapLogger.Fatal("NONCE_SECRET is required when NONCE_ENABLED is set")
+		}
+		nonceStore := redisRepo.NewNonceStore(redisClient, cfg.Redis.Namespace, redisTTLs,
+			redisRepo.RetryPolicy{MaxRetries: cfg.Redis.MaxRetries, Backoff: cfg.Redis.RetryBackoff})
+		routerOpts = append(routerOpts, router.WithAdminGuard(middleware.RequireNonce(nonceStore, middleware.NonceConfig{
+			Secret: cfg.Nonce.Secret,
+			TTL:    redisTTLs.Nonce,
+		}, zapLogger)))
+	}
+	r := router.New(handlers, authMiddleware, routerOpts...)
 
-	server := &fasthttp.Server{
-		Handler:      r.Handler,
-		ReadTimeout:  cfg.HTTP.ReadTimeout,
-		WriteTimeout: cfg.HTTP.WriteTimeout,
-		IdleTimeout:  cfg.HTTP.IdleTimeout,
-		Name:         cfg.AppName,
+	handler := r.Handler
+	if cfg.Features.MetricsMiddleware {
+		handler = middleware.RequestMetrics(expvar.NewMap("http_requests"))(handler)
+	}
+	if cfg.Logger.BodySampleRate > 0 {
+		// Sampled bodies are logged at debug level even when LOG_LEVEL is higher.
+		sampleLogger, err := logger.New(logger.Config{
+			Level:       "debug",
+			Encoding:    cfg.Logger.Encoding,
+			Environment: cfg.Environment,
+			Version:     buildinfo.Version,
+			Commit:      buildinfo.Commit,
+		})
+		if err != nil {
+			zapLogger.Fatal("body sample logger error", zap.Error(err))
+		}
+		handler = middleware.SampleBodies(sampleLogger.Named("sample"), middleware.BodySampleConfig{
+			Rate:       cfg.Logger.BodySampleRate,
+			MaxBytes:   cfg.Logger.BodySampleMaxBytes,
+			RedactKeys: cfg.Logger.RedactKeys,
+		})(handler)
 	}
+	if cfg.Logger.AccessLog || cfg.HTTP.ServerTiming {
+		var accessLogger *zap.Logger
+		if cfg.Logger.AccessLog {
+			accessLogger = zapLogger.Named("access")
+		}
+		handler = middleware.AccessLog(accessLogger, cfg.HTTP.ServerTiming)(handler)
+	}
+	server := newHTTPServer(cfg, handler)
+
+	listener, err := httpListenConfig(cfg).Listen(appCtx, "tcp4", cfg.Address())
+	if err != nil {
+		zapLogger.Fatal("failed to bind http listener", zap.Error(err))
+	}
+	readyChecks := []lifecycle.Check{lifecycle.ListenerCheck(listener.Addr().String())}
 
 	go func() {
-		zapLogger.Info("server started", zap.String("address", cfg.Address()))
-		if err := server.ListenAndServe(cfg.Address()); err != nil {
+		if err := server.Serve(listener); err != nil {
 			zapLogger.Fatal("server crashed", zap.Error(err))
 		}
 	}()
@@ -142,6 +403,45 @@ func main() {
 	manager.Register("http_server", func(ctx context.Context) error {
 		return server.Shutdown()
 	})
+	// Runs before the HTTP server stops: streaming clients are told to reconnect and get a grace
+	// period, since fasthttp's Shutdown would otherwise cut them mid-stream.
+	manager.Register("streams", streams.Shutdown)
+
+	if cfg.Features.GRPC {
+		grpcListener, err := net.Listen("tcp4", cfg.GRPCAddress())
+		if err != nil {
+			zapLogger.Fatal("failed to bind grpc listener", zap.Error(err))
+		}
+		grpcServer := grpcTransport.NewServer(taskUseCase, cfg.JWT.Secret, middleware.TokenRules{
+			Leeway:   cfg.JWT.Leeway,
+			Issuer:   cfg.JWT.Issuer,
+			Audience: cfg.JWT.Audience,
+		}, zapLogger)
+		go func() {
+			if err := grpcServer.Serve(grpcListener); err != nil {
+				zapLogger.Fatal("grpc server crashed", zap.Error(err))
+			}
+		}()
+		manager.Register("grpc_server", grpcServer.Shutdown)
+		readyChecks = append(readyChecks, lifecycle.ListenerCheck(grpcListener.Addr().String()))
+		zapLogger.Info("grpc server listening", zap.String("address", grpcListener.Addr().String()))
+	}
+	// Registered last so it runs first: stop advertising readiness before tearing anything down.
+	manager.Register("readiness", func(ctx context.Context) error {
+		readiness.MarkNotReady()
+		return nil
+	})
+
+	readyCtx, cancelReady := context.WithTimeout(appCtx, cfg.Context.RequestTimeout)
+	err = readiness.MarkReadyAfter(readyCtx, readyChecks...)
+	cancelReady()
+	if err != nil {
+		zapLogger.Fatal("listeners did not become reachable", zap.Error(err))
+	}
+	zapLogger.Info("server ready",
+		zap.String("address", listener.Addr().String()),
+		zap.Any("config", cfg.Redacted()),
+	)
 
 	<-appCtx.Done()
 
@@ -149,3 +449,54 @@ func main() {
 		zapLogger.Error("graceful shutdown error", zap.Error(err))
 	}
 }
+
+// newHTTPServer builds the fasthttp server. A positive SERVER_MAX_CONN caps concurrent connections;
+// once the cap is reached fasthttp answers new connections with 503 Service Unavailable.
+func newHTTPServer(cfg *config.Config, handler fasthttp.RequestHandler) *fasthttp.Server {
+	server := &fasthttp.Server{
+		Handler:            handler,
+		ReadTimeout:        cfg.HTTP.ReadTimeout,
+		WriteTimeout:       cfg.HTTP.WriteTimeout,
+		IdleTimeout:        cfg.HTTP.IdleTimeout,
+		Name:               cfg.AppName,
+		MaxRequestsPerConn: cfg.HTTP.MaxRequestsPerConn,
+		DisableKeepalive:   cfg.HTTP.DisableKeepalive,
+		TCPKeepalive:       cfg.HTTP.TCPKeepalive,
+	}
+	if cfg.HTTP.MaxConn > 0 {
+		server.Concurrency = cfg.HTTP.MaxConn
+	}
+	return server
+}
+
+// httpListenConfig applies TCPKeepalive to the listener main opens itself, since fasthttp only
+// honours the server field on listeners it creates. Enabled keeps Go's default probe period.
+func httpListenConfig(cfg *config.Config) *net.ListenConfig {
+	lc := &net.ListenConfig{}
+	if !cfg.HTTP.TCPKeepalive {
+		lc.KeepAlive = -1
+	}
+	return lc
+}
+
+// watchReload re-reads the configuration on SIGHUP, validates it and logs what changed. An invalid
+// config is rejected and the previous one kept. Every setting is bound at startup, so a valid change
+// only takes effect after a restart; the reload lets operators check an edit before restarting.
+func watchReload(ctx context.Context, reloader *config.Reloader, zapLogger *zap.Logger) {
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	defer signal.Stop(hup)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-hup:
+		}
+		changes, err := reloader.Reload()
+		if err != nil {
+			zapLogger.Error("configuration reload rejected, keeping the current config", zap.Error(err))
+			continue
+		}
+		zapLogger.Info("configuration validated, changes apply on restart", zap.Int("changed", len(changes)), zap.Any("changes", changes))
+	}
+}
@@ -0,0 +1,41 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/valyala/fasthttp"
+
+	"github.com/fastygo/backend/internal/config"
+)
+
+func TestNewHTTPServerAppliesMaxConn(t *testing.T) {
+	handler := func(*fasthttp.RequestCtx) {}
+
+	capped := newHTTPServer(&config.Config{HTTP: config.HTTPConfig{MaxConn: 64}}, handler)
+	if capped.Concurrency != 64 {
+		t.Fatalf("Concurrency = %d, want 64", capped.Concurrency)
+	}
+
+	unbounded := newHTTPServer(&config.Config{}, handler)
+	if unbounded.Concurrency != 0 {
+		t.Fatalf("Concurrency = %d, want fasthttp's default when MaxConn is unset", unbounded.Concurrency)
+	}
+}
+
+func TestNewHTTPServerAppliesConnectionTuning(t *testing.T) {
+	cfg := &config.Config{HTTP: config.HTTPConfig{
+		MaxRequestsPerConn: 100,
+		DisableKeepalive:   true,
+		TCPKeepalive:       true,
+	}}
+
+	server := newHTTPServer(cfg, func(*fasthttp.RequestCtx) {})
+
+	if server.MaxRequestsPerConn != 100 || !server.DisableKeepalive || !server.TCPKeepalive {
+		t.Fatalf("server = {MaxRequestsPerConn: %d, DisableKeepalive: %t, TCPKeepalive: %t}, want the configured values",
+			server.MaxRequestsPerConn, server.DisableKeepalive, server.TCPKeepalive)
+	}
+	if lc := httpListenConfig(&config.Config{}); lc.KeepAlive >= 0 {
+		t.Fatalf("KeepAlive = %v, want probes disabled when TCPKeepalive is off", lc.KeepAlive)
+	}
+}
@@ -18,6 +18,18 @@ type Aggregate struct {
 	UpdatedAt time.Time         `json:"updated_at"`
 }
 
+// Validate checks the fields every save requires (an ID, a kind and, when present, a JSON payload)
+// and bounds the labels by limits.
+func (a *Aggregate) Validate(limits MetadataLimits) error {
+	if a == nil || a.ID == "" || a.Kind == "" {
+		return ErrInvalidPayload
+	}
+	if len(a.Payload) > 0 && !json.Valid(a.Payload) {
+		return ErrInvalidPayload
+	}
+	return limits.Check(a.Labels)
+}
+
 func (a *Aggregate) Touch() {
 	if a == nil {
 		return
@@ -0,0 +1,136 @@
+package domain
+
+import (
+	"encoding/json"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// Kinds of PayloadChange.
+const (
+	ChangeAdded   = "added"
+	ChangeRemoved = "removed"
+	ChangeChanged = "changed"
+)
+
+// PayloadChange is one difference between two payloads. Path is a JSON Pointer (RFC 6901); arrays
+// and scalars are compared as whole values.
+type PayloadChange struct {
+	Path string      `json:"path"`
+	Op   string      `json:"op"`
+	From interface{} `json:"from,omitempty"`
+	To   interface{} `json:"to,omitempty"`
+}
+
+// AggregateDiff describes how an aggregate's payload changed between two versions.
+type AggregateDiff struct {
+	AggregateID string          `json:"aggregate_id"`
+	From        int             `json:"from"`
+	To          int             `json:"to"`
+	Changes     []PayloadChange `json:"changes"`
+}
+
+// Rehydrate rebuilds an aggregate payload as of version by applying, in version order, every event
+// at or below it. Each event payload is a JSON merge patch (RFC 7386) against the previous state, so
+// version 0 is the empty state (null).
+func Rehydrate(events []Event, version int) (json.RawMessage, error) {
+	ordered := append([]Event(nil), events...)
+	sort.SliceStable(ordered, func(i, j int) bool { return ordered[i].Version < ordered[j].Version })
+
+	var state interface{}
+	for _, event := range ordered {
+		if event.Version > version {
+			break
+		}
+		if len(event.Payload) == 0 {
+			continue
+		}
+		var patch interface{}
+		if err := json.Unmarshal(event.Payload, &patch); err != nil {
+			return nil, ErrInvalidPayload
+		}
+		state = mergePatch(state, patch)
+	}
+	return json.Marshal(state)
+}
+
+func mergePatch(target, patch interface{}) interface{} {
+	fields, ok := patch.(map[string]interface{})
+	if !ok {
+		return patch
+	}
+	merged, ok := target.(map[string]interface{})
+	if !ok {
+		merged = make(map[string]interface{}, len(fields))
+	}
+	for key, value := range fields {
+		if value == nil {
+			delete(merged, key)
+			continue
+		}
+		merged[key] = mergePatch(merged[key], value)
+	}
+	return merged
+}
+
+// DiffPayloads lists the changes that turn from into to, ordered by path.
+func DiffPayloads(from, to json.RawMessage) ([]PayloadChange, error) {
+	var before, after interface{}
+	if len(from) > 0 {
+		if err := json.Unmarshal(from, &before); err != nil {
+			return nil, ErrInvalidPayload
+		}
+	}
+	if len(to) > 0 {
+		if err := json.Unmarshal(to, &after); err != nil {
+			return nil, ErrInvalidPayload
+		}
+	}
+	changes := []PayloadChange{}
+	diffValues("", before, after, &changes)
+	return changes, nil
+}
+
+func diffValues(path string, before, after interface{}, changes *[]PayloadChange) {
+	beforeFields, beforeIsObject := before.(map[string]interface{})
+	afterFields, afterIsObject := after.(map[string]interface{})
+	if !beforeIsObject || !afterIsObject {
+		switch {
+		case reflect.DeepEqual(before, after):
+		case before == nil:
+			*changes = append(*changes, PayloadChange{Path: path, Op: ChangeAdded, To: after})
+		case after == nil:
+			*changes = append(*changes, PayloadChange{Path: path, Op: ChangeRemoved, From: before})
+		default:
+			*changes = append(*changes, PayloadChange{Path: path, Op: ChangeChanged, From: before, To: after})
+		}
+		return
+	}
+
+	keys := make([]string, 0, len(beforeFields)+len(afterFields))
+	for key := range beforeFields {
+		keys = append(keys, key)
+	}
+	for key := range afterFields {
+		if _, ok := beforeFields[key]; !ok {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		child := path + "/" + pointerEscaper.Replace(key)
+		oldValue, hadOld := beforeFields[key]
+		newValue, hasNew := afterFields[key]
+		switch {
+		case !hadOld:
+			*changes = append(*changes, PayloadChange{Path: child, Op: ChangeAdded, To: newValue})
+		case !hasNew:
+			*changes = append(*changes, PayloadChange{Path: child, Op: ChangeRemoved, From: oldValue})
+		default:
+			diffValues(child, oldValue, newValue, changes)
+		}
+	}
+}
+
+var pointerEscaper = strings.NewReplacer("~", "~0", "/", "~1")
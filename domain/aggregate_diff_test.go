@@ -0,0 +1,64 @@
+package domain_test
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/fastygo/backend/domain"
+)
+
+func dealEvents() []domain.Event {
+	return []domain.Event{
+		{Version: 1, Payload: json.RawMessage(`{"title":"Deal","stage":"lead","amount":100,"tags":["a"]}`)},
+		{Version: 2, Payload: json.RawMessage(`{"stage":"qualified","owner":{"name":"ann"}}`)},
+		{Version: 4, Payload: json.RawMessage(`{"amount":250,"owner":{"name":"bob","team":"east"},"tags":["a","b"]}`)},
+		{Version: 5, Payload: json.RawMessage(`{"title":null,"a/b":true}`)},
+	}
+}
+
+func TestRehydrateAppliesMergePatchesUpToVersion(t *testing.T) {
+	tests := []struct {
+		version int
+		want    string
+	}{
+		{0, `null`},
+		{1, `{"amount":100,"stage":"lead","tags":["a"],"title":"Deal"}`},
+		{3, `{"amount":100,"owner":{"name":"ann"},"stage":"qualified","tags":["a"],"title":"Deal"}`},
+		{5, `{"a/b":true,"amount":250,"owner":{"name":"bob","team":"east"},"stage":"qualified","tags":["a","b"]}`},
+	}
+	for _, tt := range tests {
+		got, err := domain.Rehydrate(dealEvents(), tt.version)
+		if err != nil {
+			t.Fatalf("rehydrate v%d: %v", tt.version, err)
+		}
+		if string(got) != tt.want {
+			t.Errorf("v%d = %s, want %s", tt.version, got, tt.want)
+		}
+	}
+}
+
+func TestDiffPayloadsBetweenVersions(t *testing.T) {
+	from, _ := domain.Rehydrate(dealEvents(), 2)
+	to, _ := domain.Rehydrate(dealEvents(), 5)
+
+	changes, err := domain.DiffPayloads(from, to)
+	if err != nil {
+		t.Fatalf("diff: %v", err)
+	}
+	want := []domain.PayloadChange{
+		{Path: "/a~1b", Op: domain.ChangeAdded, To: true},
+		{Path: "/amount", Op: domain.ChangeChanged, From: float64(100), To: float64(250)},
+		{Path: "/owner/name", Op: domain.ChangeChanged, From: "ann", To: "bob"},
+		{Path: "/owner/team", Op: domain.ChangeAdded, To: "east"},
+		{Path: "/tags", Op: domain.ChangeChanged, From: []interface{}{"a"}, To: []interface{}{"a", "b"}},
+		{Path: "/title", Op: domain.ChangeRemoved, From: "Deal"},
+	}
+	if !reflect.DeepEqual(changes, want) {
+		t.Fatalf("changes = %+v\nwant      %+v", changes, want)
+	}
+
+	if changes, _ := domain.DiffPayloads(to, to); len(changes) != 0 {
+		t.Fatalf("identical payloads produced %+v", changes)
+	}
+}
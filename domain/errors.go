@@ -15,6 +15,9 @@ const (
 	ErrCodeForbidden    ErrorCode = "FORBIDDEN"
 	ErrCodeUnauthorized ErrorCode = "UNAUTHORIZED"
 	ErrCodeInternal     ErrorCode = "INTERNAL"
+	// ErrCodeLimitExceeded means the caller hit a quota, rate limit or cap: the request was valid
+	// and conflicts with nothing, but is refused until usage drops.
+	ErrCodeLimitExceeded ErrorCode = "LIMIT_EXCEEDED"
 )
 
 // Error represents a domain-level error.
@@ -57,14 +60,43 @@ func WrapError(code ErrorCode, message string, err error) *Error {
 
 // Common domain errors.
 var (
-	ErrUserNotFound    = NewError(ErrCodeNotFound, "user not found")
-	ErrTaskNotFound    = NewError(ErrCodeNotFound, "task not found")
-	ErrSessionNotFound = NewError(ErrCodeNotFound, "session not found")
+	ErrUserNotFound      = NewError(ErrCodeNotFound, "user not found")
+	ErrTaskNotFound      = NewError(ErrCodeNotFound, "task not found")
+	ErrSessionNotFound   = NewError(ErrCodeNotFound, "session not found")
 	ErrAggregateNotFound = NewError(ErrCodeNotFound, "aggregate not found")
-	ErrUnauthorized    = NewError(ErrCodeUnauthorized, "unauthorized")
-	ErrInvalidPayload  = NewError(ErrCodeInvalid, "invalid payload")
+	ErrKeyNotFound       = NewError(ErrCodeNotFound, "key not found")
+	ErrOutboxNotFound    = NewError(ErrCodeNotFound, "outbox message not found")
+	ErrUnauthorized      = NewError(ErrCodeUnauthorized, "unauthorized")
+	ErrInvalidPayload    = NewError(ErrCodeInvalid, "invalid payload")
+	ErrConflict          = NewError(ErrCodeConflict, "resource already exists")
+	ErrLimitExceeded     = NewError(ErrCodeLimitExceeded, "limit exceeded")
+
+	ErrAggregateVersionConflict = NewError(ErrCodeConflict, "aggregate version conflict")
+	ErrAggregateForeignTenant   = NewError(ErrCodeForbidden, "aggregate belongs to another tenant")
+	ErrAggregateVersionNotFound = NewError(ErrCodeNotFound, "aggregate version not found")
 )
 
+// LimitError reports which limit was hit, its value and the usage that hit it. It unwraps to
+// ErrLimitExceeded, so errors.Is and IsDomainError match it like the sentinel.
+type LimitError struct {
+	Resource string `json:"resource"`
+	Limit    int64  `json:"limit"`
+	Current  int64  `json:"current"`
+}
+
+// NewLimitError reports that current usage of resource has reached limit.
+func NewLimitError(resource string, limit, current int64) *LimitError {
+	return &LimitError{Resource: resource, Limit: limit, Current: current}
+}
+
+func (e *LimitError) Error() string {
+	return fmt.Sprintf("%s limit of %d exceeded (current %d)", e.Resource, e.Limit, e.Current)
+}
+
+func (e *LimitError) Unwrap() error {
+	return ErrLimitExceeded
+}
+
 // IsDomainError helps checking error codes.
 func IsDomainError(err error, code ErrorCode) bool {
 	var dErr *Error
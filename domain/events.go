@@ -0,0 +1,26 @@
+package domain
+
+import "time"
+
+// SessionEventType identifies a session lifecycle transition.
+type SessionEventType string
+
+const (
+	SessionCreated   SessionEventType = "session.created"
+	SessionRefreshed SessionEventType = "session.refreshed"
+	SessionRevoked   SessionEventType = "session.revoked"
+	SessionExpired   SessionEventType = "session.expired"
+)
+
+// SessionEvent is published by the auth use case for audit and security tooling. IP and UserAgent
+// describe the client that caused the transition, or the session's own client when that is unknown.
+type SessionEvent struct {
+	Type      SessionEventType `json:"type"`
+	UserID    string           `json:"user_id"`
+	SessionID string           `json:"session_id"`
+	IP        string           `json:"ip,omitempty"`
+	UserAgent string           `json:"user_agent,omitempty"`
+	// Suspicious is set on SessionCreated when the login came from a new IP; see Session.Suspicious.
+	Suspicious bool      `json:"suspicious,omitempty"`
+	At         time.Time `json:"at"`
+}
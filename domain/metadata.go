@@ -0,0 +1,31 @@
+package domain
+
+import "encoding/json"
+
+// MetadataLimits bounds free-form string maps such as task and user metadata or aggregate labels.
+// A zero field disables that check.
+type MetadataLimits struct {
+	MaxKeys int
+	// MaxBytes caps the JSON-encoded size, which is what ends up in the JSONB column.
+	MaxBytes int
+}
+
+// DefaultMetadataLimits are applied when no limits are configured.
+var DefaultMetadataLimits = MetadataLimits{MaxKeys: 64, MaxBytes: 16 << 10}
+
+// Check returns ErrInvalidPayload when values exceeds either limit.
+func (l MetadataLimits) Check(values map[string]string) error {
+	if len(values) == 0 {
+		return nil
+	}
+	if l.MaxKeys > 0 && len(values) > l.MaxKeys {
+		return ErrInvalidPayload
+	}
+	if l.MaxBytes > 0 {
+		encoded, err := json.Marshal(values)
+		if err != nil || len(encoded) > l.MaxBytes {
+			return ErrInvalidPayload
+		}
+	}
+	return nil
+}
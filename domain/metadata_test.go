@@ -0,0 +1,66 @@
+package domain_test
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/fastygo/backend/domain"
+)
+
+func metadataOfSize(t *testing.T, size int) map[string]string {
+	t.Helper()
+	// {"k":""} is 8 bytes; the value pads the encoding to exactly size.
+	values := map[string]string{"k": strings.Repeat("x", size-8)}
+	if encoded, _ := json.Marshal(values); len(encoded) != size {
+		t.Fatalf("fixture encodes to %d bytes, want %d", len(encoded), size)
+	}
+	return values
+}
+
+func metadataWithKeys(n int) map[string]string {
+	values := make(map[string]string, n)
+	for i := 0; i < n; i++ {
+		values[fmt.Sprintf("k%d", i)] = "v"
+	}
+	return values
+}
+
+func TestMetadataLimitsBoundaries(t *testing.T) {
+	limits := domain.MetadataLimits{MaxKeys: 3, MaxBytes: 64}
+
+	cases := []struct {
+		name    string
+		values  map[string]string
+		wantErr bool
+	}{
+		{"keys at limit", metadataWithKeys(3), false},
+		{"keys over limit", metadataWithKeys(4), true},
+		{"bytes at limit", metadataOfSize(t, 64), false},
+		{"bytes over limit", metadataOfSize(t, 65), true},
+		{"empty", nil, false},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			checks := map[string]error{
+				"task":      (&domain.Task{Metadata: tc.values}).Validate(limits),
+				"user":      (&domain.User{Metadata: tc.values}).Validate(limits),
+				"aggregate": (&domain.Aggregate{ID: "a", Kind: "deal", Labels: tc.values}).Validate(limits),
+			}
+			for entity, err := range checks {
+				if tc.wantErr != errors.Is(err, domain.ErrInvalidPayload) || (!tc.wantErr && err != nil) {
+					t.Errorf("%s: err = %v, want rejected %v", entity, err, tc.wantErr)
+				}
+			}
+		})
+	}
+}
+
+func TestZeroMetadataLimitsAreUnbounded(t *testing.T) {
+	task := domain.Task{Metadata: metadataWithKeys(1000)}
+	if err := task.Validate(domain.MetadataLimits{}); err != nil {
+		t.Fatalf("validate: %v", err)
+	}
+}
@@ -0,0 +1,21 @@
+package domain
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// OutboxMessage is an event stored in the same transaction as the change that produced it, so the
+// event is published if and only if the change committed. The outbox relay publishes it
+// afterwards, at least once.
+type OutboxMessage struct {
+	ID      string          `json:"id"`
+	Topic   string          `json:"topic"`
+	Payload json.RawMessage `json:"payload"`
+	// Attempts counts failed publish attempts.
+	Attempts  int       `json:"attempts"`
+	CreatedAt time.Time `json:"created_at"`
+	// NextAttemptAt defers the message after a failed attempt; zero means it is due now.
+	NextAttemptAt time.Time `json:"next_attempt_at,omitempty"`
+	LastError     string    `json:"last_error,omitempty"`
+}
@@ -9,6 +9,9 @@ type Session struct {
 	ExpiresAt time.Time         `json:"expires_at"`
 	CreatedAt time.Time         `json:"created_at"`
 	Metadata  map[string]string `json:"metadata,omitempty"`
+	// Suspicious marks a login from an IP none of the user's recent sessions used. It is a
+	// heuristic for security tooling and never blocks the login.
+	Suspicious bool `json:"suspicious,omitempty"`
 }
 
 func (s *Session) IsExpired(reference time.Time) bool {
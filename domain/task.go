@@ -1,11 +1,24 @@
 package domain
 
-import "time"
+import (
+	"strings"
+	"time"
+)
 
-// Task represents a user-owned activity item.
+// Task priorities run from MinTaskPriority (lowest) to MaxTaskPriority. A task created or updated
+// without one gets DefaultTaskPriority, whether it is written live or through the buffer.
+const (
+	MinTaskPriority     = 1
+	MaxTaskPriority     = 5
+	DefaultTaskPriority = 3
+)
+
+// Task represents a user-owned activity item. DueDate is always stored and returned in UTC,
+// whatever offset the client sent.
 type Task struct {
 	ID          string            `json:"id"`
 	UserID      string            `json:"user_id"`
+	TenantID    string            `json:"tenant_id,omitempty"`
 	Title       string            `json:"title"`
 	Description string            `json:"description,omitempty"`
 	Status      string            `json:"status"`
@@ -16,6 +29,35 @@ type Task struct {
 	UpdatedAt   time.Time         `json:"updated_at"`
 }
 
+// NormalizeTaskStatus folds a status to the lowercase form tasks are stored and filtered by, so
+// "Completed", "COMPLETED" and "completed" are the same status.
+func NormalizeTaskStatus(status string) string {
+	return strings.ToLower(strings.TrimSpace(status))
+}
+
+// Validate checks the task against limits and its priority range, filling in DefaultTaskPriority
+// when none was given and normalizing its status.
+func (t *Task) Validate(limits MetadataLimits) error {
+	if t == nil {
+		return ErrInvalidPayload
+	}
+	t.Status = NormalizeTaskStatus(t.Status)
+	if t.Priority == 0 {
+		t.Priority = DefaultTaskPriority
+	}
+	if t.Priority < MinTaskPriority || t.Priority > MaxTaskPriority {
+		return ErrInvalidPayload
+	}
+	return limits.Check(t.Metadata)
+}
+
+// IsCompleted ignores case, so rows written before statuses were normalized still count.
 func (t *Task) IsCompleted() bool {
-	return t != nil && t.Status == "completed"
+	return t != nil && strings.EqualFold(t.Status, "completed")
+}
+
+// IsOverdue reports whether the task is not completed and its due date lies strictly before now.
+// A task due exactly now is not overdue yet; one without a due date never is.
+func (t *Task) IsOverdue(now time.Time) bool {
+	return t != nil && t.DueDate != nil && t.DueDate.Before(now) && !t.IsCompleted()
 }
@@ -0,0 +1,34 @@
+package domain_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/fastygo/backend/domain"
+)
+
+func TestTaskIsOverdue(t *testing.T) {
+	now := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	at := func(d time.Duration) *time.Time {
+		due := now.Add(d)
+		return &due
+	}
+	tests := []struct {
+		name string
+		task domain.Task
+		want bool
+	}{
+		{"due a moment ago", domain.Task{Status: "pending", DueDate: at(-time.Nanosecond)}, true},
+		{"due exactly now", domain.Task{Status: "pending", DueDate: at(0)}, false},
+		{"due later", domain.Task{Status: "pending", DueDate: at(time.Hour)}, false},
+		{"completed past due", domain.Task{Status: "completed", DueDate: at(-time.Hour)}, false},
+		{"no due date", domain.Task{Status: "pending"}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.task.IsOverdue(now); got != tt.want {
+				t.Fatalf("IsOverdue = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
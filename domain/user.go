@@ -13,6 +13,14 @@ type User struct {
 	UpdatedAt time.Time         `json:"updated_at"`
 }
 
+// Validate checks the user against limits; only metadata is bounded today.
+func (u *User) Validate(limits MetadataLimits) error {
+	if u == nil {
+		return ErrInvalidPayload
+	}
+	return limits.Check(u.Metadata)
+}
+
 func (u *User) IsActive() bool {
 	return u != nil && u.Status == "active"
 }
@@ -1,9 +1,14 @@
 package config
 
 import (
+	"errors"
 	"fmt"
+	"net"
+	"net/url"
 	"os"
 	"strconv"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/joho/godotenv"
@@ -14,24 +19,83 @@ type Config struct {
 	AppName     string
 	Environment string
 	HTTP        HTTPConfig
+	GRPC        GRPCConfig
+	Features    FeaturesConfig
 	Database    DatabaseConfig
 	Redis       RedisConfig
 	JWT         JWTConfig
+	APIKeys     APIKeyConfig
+	Auth        AuthConfig
+	Nonce       NonceConfig
 	Buffer      BufferConfig
+	Cache       CacheConfig
+	Aggregate   AggregateConfig
+	Metadata    MetadataConfig
 	Context     ContextConfig
 	Logger      LoggerConfig
 	Migrations  MigrationsConfig
+	Monitor     MonitorConfig
+	Reminders   RemindersConfig
+	Notify      NotifyConfig
+	Outbox      OutboxConfig
+
+	// Warnings lists non-fatal problems found by Load, such as a connection URL that disagrees with
+	// discrete settings. Load runs before logging is set up, so callers log them.
+	Warnings []string
 }
 
 type HTTPConfig struct {
-	Host          string
-	Port          string
-	ReadTimeout   time.Duration
-	WriteTimeout  time.Duration
-	IdleTimeout   time.Duration
-	MaxConn       int
-	EnablePprof   bool
-	EnableMetrics bool
+	Host         string
+	Port         string
+	ReadTimeout  time.Duration
+	WriteTimeout time.Duration
+	IdleTimeout  time.Duration
+	// MaxConn caps concurrent connections; excess ones get 503. Zero keeps fasthttp's default.
+	MaxConn int
+	// MaxRequestsPerConn closes a connection after that many requests; zero means unlimited.
+	MaxRequestsPerConn int
+	// DisableKeepalive closes every connection after its first response.
+	DisableKeepalive bool
+	// TCPKeepalive enables TCP keep-alive probes on accepted connections.
+	TCPKeepalive bool
+	StrictQuery  bool
+	// MaxOffset caps the offset of list requests; deeper pages answer 400. Zero disables the cap.
+	MaxOffset int
+	// LenientContentType accepts request bodies without an application/json Content-Type.
+	LenientContentType bool
+	// ResponseKeyCase is the default JSON key spelling, "snake" or "camel"; clients may override it per request.
+	ResponseKeyCase string
+	// RawResponses drops the response envelope for every client; otherwise clients opt in per request
+	// by accepting application/vnd.api.raw+json.
+	RawResponses bool
+	// ProblemErrors answers errors with application/problem+json for every client; otherwise clients
+	// opt in per request through their Accept header.
+	ProblemErrors bool
+	// RequestIDFormat is how IDs are generated for requests without one: "uuid" or "ulid".
+	RequestIDFormat string
+	// StreamGracePeriod is how long SSE and WebSocket clients get to reconnect elsewhere after the
+	// closing event before shutdown cuts their streams.
+	StreamGracePeriod time.Duration
+	// ServerTiming sends each request's timing breakdown in a Server-Timing response header. Meant
+	// for debugging: it tells clients how long the database and Redis took.
+	ServerTiming bool
+}
+
+// GRPCConfig configures the optional gRPC listener, which binds to the HTTP host. It only runs when
+// Features.GRPC is set.
+type GRPCConfig struct {
+	Port string
+}
+
+// FeaturesConfig switches optional modules on and off; Validate rejects combinations that cannot work.
+type FeaturesConfig struct {
+	GraphQL bool
+	GRPC    bool
+	// Metrics serves expvar counters at /debug/vars; MetricsMiddleware counts requests into them.
+	Metrics           bool
+	MetricsMiddleware bool
+	// Pprof serves the runtime profiler at /debug/pprof/. It is refused in production.
+	Pprof bool
 }
 
 type DatabaseConfig struct {
@@ -45,26 +109,140 @@ type DatabaseConfig struct {
 	MaxIdleConns    int
 	MaxConnLifetime time.Duration
 	SSLMode         string
+	// WarmUp pre-establishes MaxIdleConns connections at startup instead of dialing lazily.
+	WarmUp bool
+	// SlowQueryLog logs statements that take at least SlowQueryThreshold.
+	SlowQueryLog       bool
+	SlowQueryThreshold time.Duration
+	// StatementTimeout is the server-side statement_timeout of every pooled connection; zero
+	// disables it. Long operations can override it per call with postgres.WithStatementTimeout.
+	StatementTimeout time.Duration
 }
 
 type RedisConfig struct {
 	URL      string
 	Password string
 	DB       int
+	// Namespace prefixes every key so environments can safely share one Redis instance.
+	Namespace string
+	// MaxRetries and RetryBackoff bound retries of transient failures in Redis-backed repositories.
+	MaxRetries   int
+	RetryBackoff time.Duration
+	// ReplicaURL, when set, serves session reads from a replica. Reads of a session written within
+	// ReadYourWritesWindow still go to the primary; see redis.WithReadReplica for the trade-off.
+	ReplicaURL           string
+	ReadYourWritesWindow time.Duration
+	// SessionTTL and UserSessionsTTL expire session keys and each user's session index; see
+	// redis.TTLPolicy. Both must be positive.
+	SessionTTL      time.Duration
+	UserSessionsTTL time.Duration
 }
 
 type JWTConfig struct {
 	Secret string
+	// Issuer must match the iss claim of every accepted token; empty disables the check.
 	Issuer string
+	// Audience, when set, must appear in the aud claim. Tokens without aud are rejected then.
+	Audience string
+	// RequireTenant rejects tokens without a tenant_id claim on tenant-scoped routes.
+	RequireTenant bool
+	// Leeway is the clock skew tolerated when checking exp, nbf and iat.
+	Leeway time.Duration
+}
+
+// AuthConfig controls how /auth/login verifies credentials.
+type AuthConfig struct {
+	// Authenticator is "deny" (the default: no login succeeds) or "allow_all", which accepts any
+	// credential and is refused in production.
+	Authenticator string
+}
+
+// APIKeyConfig lets service-to-service callers authenticate with a static X-API-Key header.
+type APIKeyConfig struct {
+	// Keys lists comma-separated "key=user_id[:role[:tenant_id]]" entries; see middleware.ParseAPIKeys.
+	Keys string
+}
+
+// NonceConfig controls replay protection on admin endpoints.
+type NonceConfig struct {
+	Enabled bool
+	// Secret signs client-generated nonces; see middleware.SignNonce.
+	Secret string
+	// TTL is both the accepted nonce age and how long a nonce is remembered; see redis.TTLPolicy.
+	// It must be positive.
+	TTL time.Duration
 }
 
 type BufferConfig struct {
-	Path            string
-	MaxSize         int
-	RetentionHours  int
-	SyncInterval    time.Duration
+	Path           string
+	MaxSize        int
+	RetentionHours int
+	SyncInterval   time.Duration
+	// SyncSchedule is an optional six-field cron expression that replaces SyncInterval.
+	SyncSchedule    string
 	MaxRetry        int
+	MaxAge          time.Duration
+	RetryBackoff    time.Duration
 	PriorityBuckets int
+	// ManualSyncTimeout bounds drains triggered through the admin API.
+	ManualSyncTimeout time.Duration
+	// Per-entity buffering policy; a disabled entity fails fast instead of being buffered.
+	ProfileEnabled  bool
+	ProfilePriority int
+	TaskEnabled     bool
+	TaskPriority    int
+	// WriteStrategy is "optimistic" (try the database, buffer on failure) or "deferred" (buffer
+	// directly while the monitor reports the database offline).
+	WriteStrategy string
+	// Per-entity drain batch sizes; zero falls back to the shared batch size.
+	ProfileBatchSize int
+	TaskBatchSize    int
+	// CallbacksEnabled honours X-Callback-URL on writes; buffered ones POST their final outcome there.
+	CallbacksEnabled bool
+	CallbackAttempts int
+	CallbackTimeout  time.Duration
+	// RecoveryThreshold is the backlog that, right after an outage, drains in back-to-back passes
+	// until RecoveryLowWater, within RecoveryBudget and RecoveryPause apart. Zero disables recovery.
+	RecoveryThreshold int
+	RecoveryLowWater  int
+	RecoveryBudget    time.Duration
+	RecoveryPause     time.Duration
+	// UserQuota caps the queued items of one user so a single client cannot fill the buffer. Zero
+	// means unlimited.
+	UserQuota int
+}
+
+// CacheConfig controls the optional read-through profile cache and task list coalescing.
+type CacheConfig struct {
+	ProfileEnabled bool
+	// ProfileTTL is how long a cached profile is served without a database read.
+	ProfileTTL time.Duration
+	// ProfileStaleTTL is how long an entry is kept to be served stale while the database is offline.
+	ProfileStaleTTL   time.Duration
+	ProfileMaxEntries int
+
+	// TaskListCoalesce lets concurrent identical task listings share one database query.
+	TaskListCoalesce bool
+	// TaskListTTL additionally caches list results for this long; zero only coalesces.
+	TaskListTTL        time.Duration
+	TaskListMaxEntries int
+}
+
+// AggregateConfig controls aggregate writes.
+type AggregateConfig struct {
+	// OptimisticLocking requires updates to carry the stored version plus one.
+	OptimisticLocking bool
+	// MaxBatchSize caps aggregates per batch request; larger batches answer 400. It may not exceed
+	// aggregate.MaxBatchSizeLimit (10000); zero keeps the use case default.
+	MaxBatchSize int
+	// MaxImportSize caps aggregates per batch request in copy mode, the bulk path for migrations.
+	MaxImportSize int
+}
+
+// MetadataConfig bounds task and user metadata and aggregate labels; zero disables a limit.
+type MetadataConfig struct {
+	MaxKeys  int
+	MaxBytes int
 }
 
 type ContextConfig struct {
@@ -75,81 +253,293 @@ type ContextConfig struct {
 type LoggerConfig struct {
 	Level    string
 	Encoding string
+	// AccessLog logs one line per request, with the time spent in the database, Redis and
+	// serialization.
+	AccessLog bool
+	// BodySampleRate is the fraction of requests, from 0 to 1, whose request and response bodies
+	// are logged at debug level regardless of Level; BodySampleMaxBytes caps each body.
+	BodySampleRate     float64
+	BodySampleMaxBytes int
+	// RedactKeys lists header names and JSON keys whose values are replaced with "***" in logged
+	// requests, in addition to built-in ones such as Authorization, password and token.
+	RedactKeys []string
+}
+
+// RemindersConfig controls reminders for tasks whose due date is approaching.
+type RemindersConfig struct {
+	Enabled bool
+	// Schedule is a cron expression with a leading seconds field or a descriptor such as "@every 1m".
+	Schedule string
+	// Window is how long before its due date a task is reminded.
+	Window time.Duration
+}
+
+// OutboxConfig controls the relay that publishes outbox messages through the notifier.
+type OutboxConfig struct {
+	Enabled   bool
+	Interval  time.Duration
+	BatchSize int
+	// MaxAttempts and MaxAge dead-letter a message that keeps failing; RetryBackoff is the base
+	// delay between attempts, doubled on each retry.
+	MaxAttempts  int
+	MaxAge       time.Duration
+	RetryBackoff time.Duration
+}
+
+// NotifyConfig selects where reminders and operational alerts are delivered.
+type NotifyConfig struct {
+	// Channel is "log", "webhook" or "none".
+	Channel string
+	// WebhookURL receives each notification as a JSON POST when Channel is "webhook".
+	WebhookURL string
+	// WebhookAttempts bounds retries of a failing webhook delivery.
+	WebhookAttempts int
 }
 
 type MigrationsConfig struct {
 	Enabled bool
 	Path    string
+	// VersionCacheTTL is how long /version reuses the schema_migrations row it last read.
+	VersionCacheTTL time.Duration
+}
+
+type MonitorConfig struct {
+	Interval time.Duration
+	// Jitter randomizes check timing so replicas do not ping datastores in lockstep.
+	Jitter time.Duration
+	// MissedBeats is how many intervals the monitor or the buffer processor may go without a
+	// heartbeat before /health/live reports the process stalled. Zero disables the watchdog.
+	MissedBeats int
+	// BufferHighWater is the buffer size that triggers a "buffer degraded" notification; the
+	// matching "buffer recovered" waits until the size drops below BufferLowWater. Zero disables.
+	BufferHighWater int
+	BufferLowWater  int
 }
 
 // Load reads configuration from environment variables (optionally .env)
 // and applies sane defaults so the service can boot in any environment.
 func Load() (*Config, error) {
-	_ = godotenv.Load(".env")
+	loadDotenv(".env")
 
 	cfg := &Config{
 		AppName:     getString("APP_NAME", "go-backend"),
 		Environment: getString("APP_ENV", "development"),
 		HTTP: HTTPConfig{
-			Host:          getString("SERVER_HOST", "0.0.0.0"),
-			Port:          getString("SERVER_PORT", "8080"),
-			ReadTimeout:   getDuration("SERVER_READ_TIMEOUT", 10*time.Second),
-			WriteTimeout:  getDuration("SERVER_WRITE_TIMEOUT", 10*time.Second),
-			IdleTimeout:   getDuration("SERVER_IDLE_TIMEOUT", 120*time.Second),
-			MaxConn:       getInt("SERVER_MAX_CONN", 0),
-			EnablePprof:   getBool("SERVER_ENABLE_PPROF", false),
-			EnableMetrics: getBool("SERVER_ENABLE_METRICS", false),
+			Host:               getString("SERVER_HOST", "0.0.0.0"),
+			Port:               getString("SERVER_PORT", "8080"),
+			ReadTimeout:        getDuration("SERVER_READ_TIMEOUT", 10*time.Second),
+			WriteTimeout:       getDuration("SERVER_WRITE_TIMEOUT", 10*time.Second),
+			IdleTimeout:        getDuration("SERVER_IDLE_TIMEOUT", 120*time.Second),
+			MaxConn:            getInt("SERVER_MAX_CONN", 0),
+			MaxRequestsPerConn: getInt("SERVER_MAX_REQUESTS_PER_CONN", 0),
+			DisableKeepalive:   getBool("SERVER_DISABLE_KEEPALIVE", false),
+			TCPKeepalive:       getBool("SERVER_TCP_KEEPALIVE", true),
+			StrictQuery:        getBool("SERVER_STRICT_QUERY", false),
+			MaxOffset:          getInt("SERVER_MAX_OFFSET", 10_000),
+			LenientContentType: getBool("SERVER_LENIENT_CONTENT_TYPE", false),
+			ResponseKeyCase:    getString("SERVER_RESPONSE_KEY_CASE", "snake"),
+			RawResponses:       getBool("SERVER_RAW_RESPONSES", false),
+			ProblemErrors:      getBool("SERVER_PROBLEM_ERRORS", false),
+			RequestIDFormat:    getString("SERVER_REQUEST_ID_FORMAT", "uuid"),
+			StreamGracePeriod:  getDuration("SERVER_STREAM_GRACE_PERIOD", 5*time.Second),
+			ServerTiming:       getBool("SERVER_TIMING_HEADER", false),
+		},
+		GRPC: GRPCConfig{
+			Port: getString("GRPC_PORT", "9090"),
+		},
+		// The FEATURE_* names win; the older per-section switches are still honoured.
+		Features: FeaturesConfig{
+			GraphQL:           getBool("FEATURE_GRAPHQL", getBool("SERVER_ENABLE_GRAPHQL", false)),
+			GRPC:              getBool("FEATURE_GRPC", getBool("GRPC_ENABLED", false)),
+			Metrics:           getBool("FEATURE_METRICS", getBool("SERVER_ENABLE_METRICS", false)),
+			MetricsMiddleware: getBool("FEATURE_METRICS_MIDDLEWARE", false),
+			Pprof:             getBool("FEATURE_PPROF", getBool("SERVER_ENABLE_PPROF", false)),
 		},
 		Database: DatabaseConfig{
-			URL:             os.Getenv("DATABASE_URL"),
-			Host:            getString("DB_HOST", "localhost"),
-			Port:            getString("DB_PORT", "5432"),
-			Name:            getString("DB_NAME", "backend_db"),
-			User:            getString("DB_USER", "backend_user"),
-			Password:        os.Getenv("DB_PASSWORD"),
-			MaxOpenConns:    getInt("DB_MAX_OPEN_CONNS", 25),
-			MaxIdleConns:    getInt("DB_MAX_IDLE_CONNS", 10),
-			MaxConnLifetime: getDuration("DB_CONN_LIFETIME", time.Hour),
-			SSLMode:         getString("DB_SSLMODE", "disable"),
+			URL:                os.Getenv("DATABASE_URL"),
+			Host:               getString("DB_HOST", "localhost"),
+			Port:               getString("DB_PORT", "5432"),
+			Name:               getString("DB_NAME", "backend_db"),
+			User:               getString("DB_USER", "backend_user"),
+			Password:           os.Getenv("DB_PASSWORD"),
+			MaxOpenConns:       getInt("DB_MAX_OPEN_CONNS", 25),
+			MaxIdleConns:       getInt("DB_MAX_IDLE_CONNS", 10),
+			MaxConnLifetime:    getDuration("DB_CONN_LIFETIME", time.Hour),
+			SSLMode:            getString("DB_SSLMODE", "disable"),
+			WarmUp:             getBool("DB_WARM_UP", false),
+			SlowQueryLog:       getBool("DB_SLOW_QUERY_LOG", false),
+			SlowQueryThreshold: getDuration("DB_SLOW_QUERY_THRESHOLD", 500*time.Millisecond),
+			StatementTimeout:   getDuration("DB_STATEMENT_TIMEOUT", 30*time.Second),
 		},
 		Redis: RedisConfig{
-			URL:      getString("REDIS_URL", "redis://localhost:6379"),
-			Password: os.Getenv("REDIS_PASSWORD"),
-			DB:       getInt("REDIS_DB", 0),
+			URL:          getString("REDIS_URL", "redis://localhost:6379"),
+			Password:     os.Getenv("REDIS_PASSWORD"),
+			DB:           getInt("REDIS_DB", 0),
+			Namespace:    os.Getenv("REDIS_NAMESPACE"),
+			MaxRetries:   getInt("REDIS_MAX_RETRIES", 2),
+			RetryBackoff: getDuration("REDIS_RETRY_BACKOFF", 50*time.Millisecond),
+
+			ReplicaURL:           os.Getenv("REDIS_REPLICA_URL"),
+			ReadYourWritesWindow: getDuration("REDIS_READ_YOUR_WRITES_WINDOW", 2*time.Second),
+
+			SessionTTL:      getDuration("REDIS_SESSION_TTL", 24*time.Hour),
+			UserSessionsTTL: getDuration("REDIS_USER_SESSIONS_TTL", 24*time.Hour),
 		},
 		JWT: JWTConfig{
-			Secret: os.Getenv("JWT_SECRET"),
-			Issuer: getString("JWT_ISSUER", "go-backend"),
+			Secret:        os.Getenv("JWT_SECRET"),
+			Issuer:        getString("JWT_ISSUER", "go-backend"),
+			Audience:      os.Getenv("JWT_AUDIENCE"),
+			RequireTenant: getBool("JWT_REQUIRE_TENANT", false),
+			Leeway:        getDuration("JWT_LEEWAY", 30*time.Second),
+		},
+		Auth: AuthConfig{
+			Authenticator: getString("AUTH_AUTHENTICATOR", "deny"),
+		},
+		APIKeys: APIKeyConfig{
+			Keys: os.Getenv("API_KEYS"),
+		},
+		Nonce: NonceConfig{
+			Enabled: getBool("NONCE_ENABLED", false),
+			Secret:  os.Getenv("NONCE_SECRET"),
+			TTL:     getDuration("NONCE_TTL", 5*time.Minute),
 		},
 		Buffer: BufferConfig{
-			Path:            getString("BOLTDB_PATH", "./data/buffer.db"),
-			MaxSize:         getInt("BUFFER_MAX_SIZE", 1_000_000),
-			RetentionHours:  getInt("BUFFER_RETENTION_HOURS", 24),
-			SyncInterval:    getDuration("SYNC_INTERVAL_SECONDS", 30*time.Second),
-			MaxRetry:        getInt("MAX_RETRY_ATTEMPTS", 3),
-			PriorityBuckets: getInt("BUFFER_PRIORITY_BUCKETS", 5),
+			Path:              getString("BOLTDB_PATH", "./data/buffer.db"),
+			MaxSize:           getInt("BUFFER_MAX_SIZE", 1_000_000),
+			RetentionHours:    getInt("BUFFER_RETENTION_HOURS", 24),
+			SyncInterval:      getDuration("SYNC_INTERVAL_SECONDS", 30*time.Second),
+			SyncSchedule:      os.Getenv("BUFFER_SYNC_SCHEDULE"),
+			MaxRetry:          getInt("MAX_RETRY_ATTEMPTS", 3),
+			MaxAge:            getDuration("BUFFER_MAX_AGE", 0),
+			RetryBackoff:      getDuration("BUFFER_RETRY_BACKOFF", 0),
+			PriorityBuckets:   getInt("BUFFER_PRIORITY_BUCKETS", 5),
+			ManualSyncTimeout: getDuration("BUFFER_MANUAL_SYNC_TIMEOUT", 30*time.Second),
+			ProfileEnabled:    getBool("BUFFER_PROFILE_ENABLED", true),
+			ProfilePriority:   getInt("BUFFER_PROFILE_PRIORITY", 3),
+			TaskEnabled:       getBool("BUFFER_TASK_ENABLED", true),
+			TaskPriority:      getInt("BUFFER_TASK_PRIORITY", 4),
+			WriteStrategy:     getString("BUFFER_WRITE_STRATEGY", "optimistic"),
+			ProfileBatchSize:  getInt("BUFFER_PROFILE_BATCH_SIZE", 0),
+			TaskBatchSize:     getInt("BUFFER_TASK_BATCH_SIZE", 0),
+			CallbacksEnabled:  getBool("BUFFER_CALLBACKS_ENABLED", false),
+			CallbackAttempts:  getInt("BUFFER_CALLBACK_ATTEMPTS", 3),
+			CallbackTimeout:   getDuration("BUFFER_CALLBACK_TIMEOUT", 5*time.Second),
+			RecoveryThreshold: getInt("BUFFER_RECOVERY_THRESHOLD", 500),
+			RecoveryLowWater:  getInt("BUFFER_RECOVERY_LOW_WATER", 0),
+			RecoveryBudget:    getDuration("BUFFER_RECOVERY_BUDGET", 5*time.Minute),
+			RecoveryPause:     getDuration("BUFFER_RECOVERY_PAUSE", 100*time.Millisecond),
+			UserQuota:         getInt("BUFFER_USER_QUOTA", 0),
+		},
+		Cache: CacheConfig{
+			ProfileEnabled:    getBool("PROFILE_CACHE_ENABLED", false),
+			ProfileTTL:        getDuration("PROFILE_CACHE_TTL", 30*time.Second),
+			ProfileStaleTTL:   getDuration("PROFILE_CACHE_STALE_TTL", time.Hour),
+			ProfileMaxEntries: getInt("PROFILE_CACHE_MAX_ENTRIES", 10000),
+
+			TaskListCoalesce:   getBool("TASK_LIST_COALESCE", false),
+			TaskListTTL:        getDuration("TASK_LIST_CACHE_TTL", 0),
+			TaskListMaxEntries: getInt("TASK_LIST_CACHE_MAX_ENTRIES", 1000),
+		},
+		Aggregate: AggregateConfig{
+			OptimisticLocking: getBool("AGGREGATE_OPTIMISTIC_LOCKING", false),
+			MaxBatchSize:      getInt("AGGREGATE_MAX_BATCH_SIZE", 500),
+			MaxImportSize:     getInt("AGGREGATE_MAX_IMPORT_SIZE", 100_000),
+		},
+		Metadata: MetadataConfig{
+			MaxKeys:  getInt("METADATA_MAX_KEYS", 64),
+			MaxBytes: getInt("METADATA_MAX_BYTES", 16<<10),
 		},
 		Context: ContextConfig{
 			RequestTimeout:  getDuration("REQUEST_TIMEOUT_SECONDS", 5*time.Second),
 			ShutdownTimeout: getDuration("SHUTDOWN_TIMEOUT_SECONDS", 15*time.Second),
 		},
 		Logger: LoggerConfig{
-			Level:    getString("LOG_LEVEL", "info"),
-			Encoding: getString("LOG_ENCODING", "json"),
+			Level:     getString("LOG_LEVEL", "info"),
+			Encoding:  getString("LOG_ENCODING", "json"),
+			AccessLog: getBool("LOG_ACCESS", false),
+
+			BodySampleRate:     getFloat("LOG_BODY_SAMPLE_RATE", 0),
+			BodySampleMaxBytes: getInt("LOG_BODY_SAMPLE_MAX_BYTES", 4096),
+			RedactKeys:         getList("LOG_REDACT_KEYS"),
 		},
 		Migrations: MigrationsConfig{
 			Enabled: getBool("RUN_MIGRATIONS", true),
 			Path:    getString("MIGRATIONS_PATH", "./assets/migrations"),
+
+			VersionCacheTTL: getDuration("MIGRATIONS_VERSION_CACHE_TTL", 30*time.Second),
+		},
+		Reminders: RemindersConfig{
+			Enabled:  getBool("REMINDERS_ENABLED", false),
+			Schedule: getString("REMINDERS_SCHEDULE", "@every 1m"),
+			Window:   getDuration("REMINDERS_WINDOW", time.Hour),
+		},
+		Outbox: OutboxConfig{
+			Enabled:      getBool("OUTBOX_ENABLED", false),
+			Interval:     getDuration("OUTBOX_INTERVAL", 5*time.Second),
+			BatchSize:    getInt("OUTBOX_BATCH_SIZE", 100),
+			MaxAttempts:  getInt("OUTBOX_MAX_ATTEMPTS", 10),
+			MaxAge:       getDuration("OUTBOX_MAX_AGE", 24*time.Hour),
+			RetryBackoff: getDuration("OUTBOX_RETRY_BACKOFF", 5*time.Second),
+		},
+		Notify: NotifyConfig{
+			Channel:         getString("NOTIFY_CHANNEL", "log"),
+			WebhookURL:      os.Getenv("NOTIFY_WEBHOOK_URL"),
+			WebhookAttempts: getInt("NOTIFY_WEBHOOK_ATTEMPTS", 3),
+		},
+		Monitor: MonitorConfig{
+			Interval:    getDuration("MONITOR_INTERVAL", 10*time.Second),
+			Jitter:      getDuration("MONITOR_JITTER", 2*time.Second),
+			MissedBeats: getInt("WATCHDOG_MISSED_BEATS", 3),
+
+			BufferHighWater: getInt("MONITOR_BUFFER_HIGH_WATER", 10_000),
+			BufferLowWater:  getInt("MONITOR_BUFFER_LOW_WATER", 1_000),
 		},
 	}
 
 	if cfg.Database.URL == "" {
 		cfg.Database.URL = buildPostgresURL(cfg)
+	} else {
+		cfg.Warnings = append(cfg.Warnings, databaseURLConflicts(cfg.Database.URL)...)
+	}
+	if os.Getenv("REDIS_URL") != "" {
+		cfg.Warnings = append(cfg.Warnings, redisURLConflicts(cfg.Redis.URL)...)
 	}
 
 	return cfg, nil
 }
 
+var (
+	dotenvMu sync.Mutex
+	// dotenvKeys are the variables set from .env rather than by the process environment.
+	dotenvKeys = make(map[string]struct{})
+)
+
+// loadDotenv exports the variables of path. Like godotenv.Load it never overrides the process
+// environment, but unlike it a later call refreshes the variables an earlier call took from the
+// file, and unsets those since removed from it, so a reload sees the file's current contents.
+func loadDotenv(path string) {
+	values, err := godotenv.Read(path)
+	if err != nil {
+		return
+	}
+	dotenvMu.Lock()
+	defer dotenvMu.Unlock()
+	for key := range dotenvKeys {
+		if _, ok := values[key]; !ok {
+			_ = os.Unsetenv(key)
+			delete(dotenvKeys, key)
+		}
+	}
+	for key, value := range values {
+		_, fromFile := dotenvKeys[key]
+		if _, set := os.LookupEnv(key); set && !fromFile {
+			continue
+		}
+		_ = os.Setenv(key, value)
+		dotenvKeys[key] = struct{}{}
+	}
+}
+
 // MustLoad panics if configuration cannot be loaded.
 func MustLoad() *Config {
 	cfg, err := Load()
@@ -159,15 +549,64 @@ func MustLoad() *Config {
 	return cfg
 }
 
+// buildPostgresURL assembles the DSN with escaped credentials, so passwords containing
+// URL delimiters such as "/" or "@" neither break parsing nor survive redaction.
 func buildPostgresURL(cfg *Config) string {
-	return fmt.Sprintf("postgres://%s:%s@%s:%s/%s?sslmode=%s",
-		cfg.Database.User,
-		cfg.Database.Password,
-		cfg.Database.Host,
-		cfg.Database.Port,
-		cfg.Database.Name,
-		cfg.Database.SSLMode,
-	)
+	dsn := url.URL{
+		Scheme:   "postgres",
+		User:     url.UserPassword(cfg.Database.User, cfg.Database.Password),
+		Host:     net.JoinHostPort(cfg.Database.Host, cfg.Database.Port),
+		Path:     "/" + cfg.Database.Name,
+		RawQuery: url.Values{"sslmode": {cfg.Database.SSLMode}}.Encode(),
+	}
+	return dsn.String()
+}
+
+// databaseURLConflicts reports discrete DB_* variables that were set explicitly but disagree with
+// DATABASE_URL, which always wins.
+func databaseURLConflicts(raw string) []string {
+	dsn, err := url.Parse(raw)
+	if err != nil {
+		return []string{"DATABASE_URL does not parse as a URL; discrete DB_* settings are ignored regardless"}
+	}
+	fromURL := map[string]string{
+		"DB_HOST": dsn.Hostname(),
+		"DB_PORT": dsn.Port(),
+		"DB_NAME": strings.TrimPrefix(dsn.Path, "/"),
+		"DB_USER": dsn.User.Username(),
+	}
+	var warnings []string
+	for _, key := range []string{"DB_HOST", "DB_PORT", "DB_NAME", "DB_USER"} {
+		if explicit := os.Getenv(key); explicit != "" && explicit != fromURL[key] {
+			warnings = append(warnings, fmt.Sprintf("%s=%q conflicts with DATABASE_URL (%q); DATABASE_URL wins", key, explicit, fromURL[key]))
+		}
+	}
+	if password := os.Getenv("DB_PASSWORD"); password != "" {
+		if fromURL, _ := dsn.User.Password(); fromURL != password {
+			warnings = append(warnings, "DB_PASSWORD conflicts with the password in DATABASE_URL; DATABASE_URL wins")
+		}
+	}
+	return warnings
+}
+
+// redisURLConflicts reports REDIS_PASSWORD and REDIS_DB values that override a different setting
+// embedded in an explicit REDIS_URL.
+func redisURLConflicts(raw string) []string {
+	parsed, err := url.Parse(raw)
+	if err != nil {
+		return nil // The Redis client reports the parse error.
+	}
+	urlDB, _ := strconv.Atoi(strings.TrimPrefix(parsed.Path, "/"))
+
+	var warnings []string
+	urlPassword, _ := parsed.User.Password()
+	if password := os.Getenv("REDIS_PASSWORD"); password != "" && urlPassword != "" && urlPassword != password {
+		warnings = append(warnings, "REDIS_PASSWORD conflicts with the password in REDIS_URL; REDIS_PASSWORD wins")
+	}
+	if db := getInt("REDIS_DB", 0); db != 0 && db != urlDB {
+		warnings = append(warnings, fmt.Sprintf("REDIS_DB=%d conflicts with REDIS_URL (db %d); REDIS_DB wins", db, urlDB))
+	}
+	return warnings
 }
 
 func getString(key, fallback string) string {
@@ -186,6 +625,26 @@ func getInt(key string, fallback int) int {
 	return fallback
 }
 
+// getList splits a comma-separated variable, dropping empty entries.
+func getList(key string) []string {
+	var list []string
+	for _, item := range strings.Split(os.Getenv(key), ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			list = append(list, item)
+		}
+	}
+	return list
+}
+
+func getFloat(key string, fallback float64) float64 {
+	if val := os.Getenv(key); val != "" {
+		if parsed, err := strconv.ParseFloat(val, 64); err == nil {
+			return parsed
+		}
+	}
+	return fallback
+}
+
 func getBool(key string, fallback bool) bool {
 	if val := os.Getenv(key); val != "" {
 		if parsed, err := strconv.ParseBool(val); err == nil {
@@ -207,7 +666,121 @@ func getDuration(key string, fallback time.Duration) time.Duration {
 	return fallback
 }
 
+// Validate reports every feature combination that cannot work, joined into one error.
+func (c *Config) Validate() error {
+	var errs []error
+	if c.Features.MetricsMiddleware && !c.Features.Metrics {
+		errs = append(errs, errors.New("FEATURE_METRICS_MIDDLEWARE requires FEATURE_METRICS"))
+	}
+	if c.Features.GRPC && c.GRPC.Port == c.HTTP.Port {
+		errs = append(errs, fmt.Errorf("FEATURE_GRPC requires GRPC_PORT to differ from SERVER_PORT (%s)", c.HTTP.Port))
+	}
+	if c.Features.Pprof && c.Environment == "production" {
+		errs = append(errs, errors.New("FEATURE_PPROF cannot be enabled when APP_ENV=production"))
+	}
+	if c.Aggregate.MaxBatchSize < 0 || c.Aggregate.MaxBatchSize > maxAggregateBatchSize {
+		errs = append(errs, fmt.Errorf("AGGREGATE_MAX_BATCH_SIZE must be at most %d, got %d", maxAggregateBatchSize, c.Aggregate.MaxBatchSize))
+	}
+	switch c.Auth.Authenticator {
+	case "", "deny":
+	case "allow_all":
+		if c.Environment == "production" {
+			errs = append(errs, errors.New("AUTH_AUTHENTICATOR=allow_all cannot be used when APP_ENV=production"))
+		}
+	default:
+		errs = append(errs, fmt.Errorf("AUTH_AUTHENTICATOR must be deny or allow_all, got %q", c.Auth.Authenticator))
+	}
+	switch c.Notify.Channel {
+	case "", "log", "none":
+	case "webhook":
+		if u, err := url.Parse(c.Notify.WebhookURL); err != nil || u.Host == "" || (u.Scheme != "http" && u.Scheme != "https") {
+			errs = append(errs, errors.New("NOTIFY_WEBHOOK_URL must be an absolute http or https URL when NOTIFY_CHANNEL=webhook"))
+		}
+	default:
+		errs = append(errs, fmt.Errorf("NOTIFY_CHANNEL must be log, webhook or none, got %q", c.Notify.Channel))
+	}
+	if c.Monitor.BufferHighWater > 0 && (c.Monitor.BufferLowWater <= 0 || c.Monitor.BufferLowWater > c.Monitor.BufferHighWater) {
+		errs = append(errs, fmt.Errorf("MONITOR_BUFFER_LOW_WATER must be between 1 and MONITOR_BUFFER_HIGH_WATER (%d), got %d",
+			c.Monitor.BufferHighWater, c.Monitor.BufferLowWater))
+	}
+	if c.Logger.BodySampleRate < 0 || c.Logger.BodySampleRate > 1 {
+		errs = append(errs, fmt.Errorf("LOG_BODY_SAMPLE_RATE must be between 0 and 1, got %v", c.Logger.BodySampleRate))
+	}
+	if c.Redis.SessionTTL <= 0 {
+		errs = append(errs, fmt.Errorf("REDIS_SESSION_TTL must be positive, got %v", c.Redis.SessionTTL))
+	}
+	if c.Redis.UserSessionsTTL <= 0 {
+		errs = append(errs, fmt.Errorf("REDIS_USER_SESSIONS_TTL must be positive, got %v", c.Redis.UserSessionsTTL))
+	}
+	if c.Nonce.TTL <= 0 {
+		errs = append(errs, fmt.Errorf("NONCE_TTL must be positive, got %v", c.Nonce.TTL))
+	}
+	switch c.HTTP.RequestIDFormat {
+	case "", "uuid", "ulid":
+	default:
+		errs = append(errs, fmt.Errorf("SERVER_REQUEST_ID_FORMAT must be uuid or ulid, got %q", c.HTTP.RequestIDFormat))
+	}
+	return errors.Join(errs...)
+}
+
 // Address returns the HTTP listen address for the fasthttp server.
 func (c *Config) Address() string {
 	return fmt.Sprintf("%s:%s", c.HTTP.Host, c.HTTP.Port)
 }
+
+// GRPCAddress returns the listen address for the gRPC server.
+func (c *Config) GRPCAddress() string {
+	return fmt.Sprintf("%s:%s", c.HTTP.Host, c.GRPC.Port)
+}
+
+const redactedValue = "***"
+
+// maxAggregateBatchSize mirrors aggregate.MaxBatchSizeLimit; config does not import use cases.
+const maxAggregateBatchSize = 10_000
+
+// Redacted returns a copy of the configuration that is safe to log: secrets are masked,
+// including passwords embedded in connection URLs.
+func (c *Config) Redacted() Config {
+	if c == nil {
+		return Config{}
+	}
+	out := *c
+	out.Database.Password = redactSecret(out.Database.Password)
+	out.Database.URL = scrub(redactURL(out.Database.URL), c.Database.Password)
+	out.Redis.Password = redactSecret(out.Redis.Password)
+	out.Redis.URL = scrub(redactURL(out.Redis.URL), c.Redis.Password)
+	out.Redis.ReplicaURL = scrub(redactURL(out.Redis.ReplicaURL), c.Redis.Password)
+	out.JWT.Secret = redactSecret(out.JWT.Secret)
+	out.Nonce.Secret = redactSecret(out.Nonce.Secret)
+	out.APIKeys.Keys = redactSecret(out.APIKeys.Keys)
+	// Webhook URLs often carry their token in the path, so the whole URL is hidden.
+	out.Notify.WebhookURL = redactSecret(out.Notify.WebhookURL)
+	return out
+}
+
+func redactSecret(value string) string {
+	if value == "" {
+		return ""
+	}
+	return redactedValue
+}
+
+// scrub masks any literal occurrence of secret that URL parsing failed to recognise.
+func scrub(value, secret string) string {
+	if secret == "" {
+		return value
+	}
+	return strings.ReplaceAll(value, secret, redactedValue)
+}
+
+func redactURL(raw string) string {
+	if raw == "" {
+		return ""
+	}
+	parsed, err := url.Parse(raw)
+	if err != nil {
+		// An unparseable URL may still embed credentials; hide it entirely.
+		return redactedValue
+	}
+	return parsed.Redacted()
+}
@@ -0,0 +1,261 @@
+package config_test
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/fastygo/backend/internal/config"
+)
+
+func TestRedactedHidesSecrets(t *testing.T) {
+	secrets := map[string]string{
+		"DB_PASSWORD":    "12/secret@db",
+		"REDIS_PASSWORD": "redis-s3cret",
+		"JWT_SECRET":     "jwt-signing-key",
+		"API_KEYS":       "svc-key-123=billing:admin",
+	}
+	for key, value := range secrets {
+		t.Setenv(key, value)
+	}
+	t.Setenv("DATABASE_URL", "")
+	t.Setenv("REDIS_URL", "redis://:redis-s3cret@cache:6379/0")
+
+	cfg, err := config.Load()
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+
+	dsn, err := url.Parse(cfg.Database.URL)
+	if err != nil {
+		t.Fatalf("assembled database url does not parse: %v", err)
+	}
+	if password, _ := dsn.User.Password(); password != secrets["DB_PASSWORD"] {
+		t.Fatalf("database url password = %q, want %q", password, secrets["DB_PASSWORD"])
+	}
+
+	redacted := fmt.Sprintf("%+v", cfg.Redacted())
+	for key, value := range secrets {
+		for _, form := range []string{value, url.QueryEscape(value), url.PathEscape(value)} {
+			if strings.Contains(redacted, form) {
+				t.Errorf("%s leaked as %q in %s", key, form, redacted)
+			}
+		}
+	}
+}
+
+func TestLoadWarnsWhenURLsConflictWithDiscreteFields(t *testing.T) {
+	t.Setenv("DATABASE_URL", "postgres://app:pw@db.internal:5432/app?sslmode=disable")
+	t.Setenv("DB_HOST", "localhost")
+	t.Setenv("DB_NAME", "app")
+	t.Setenv("DB_PASSWORD", "other-pw")
+	t.Setenv("REDIS_URL", "redis://:url-pw@cache:6379/2")
+	t.Setenv("REDIS_PASSWORD", "env-pw")
+	t.Setenv("REDIS_DB", "5")
+
+	cfg, err := config.Load()
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+
+	joined := strings.Join(cfg.Warnings, "\n")
+	for _, want := range []string{"DB_HOST", "DB_PASSWORD", "REDIS_PASSWORD", "REDIS_DB=5"} {
+		if !strings.Contains(joined, want) {
+			t.Errorf("warnings lack %s:\n%s", want, joined)
+		}
+	}
+	if strings.Contains(joined, "DB_NAME") {
+		t.Errorf("matching DB_NAME reported as a conflict:\n%s", joined)
+	}
+	for _, secret := range []string{"pw@", "other-pw", "url-pw", "env-pw"} {
+		if strings.Contains(joined, secret) {
+			t.Errorf("warnings leak %q:\n%s", secret, joined)
+		}
+	}
+}
+
+func TestLoadHasNoWarningsWithoutExplicitURLs(t *testing.T) {
+	t.Setenv("DATABASE_URL", "")
+	t.Setenv("REDIS_URL", "")
+	t.Setenv("DB_HOST", "db.internal")
+	t.Setenv("REDIS_DB", "3")
+
+	cfg, err := config.Load()
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	if len(cfg.Warnings) != 0 {
+		t.Fatalf("warnings = %v, want none", cfg.Warnings)
+	}
+}
+
+func TestLoadSplitsRedactKeys(t *testing.T) {
+	t.Setenv("LOG_REDACT_KEYS", " ssn, ,X-Tenant-Secret ")
+
+	cfg, err := config.Load()
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	if want := []string{"ssn", "X-Tenant-Secret"}; !reflect.DeepEqual(cfg.Logger.RedactKeys, want) {
+		t.Fatalf("redact keys = %q, want %q", cfg.Logger.RedactKeys, want)
+	}
+}
+
+func TestValidateFeatureCombinations(t *testing.T) {
+	valid := func() *config.Config {
+		return &config.Config{
+			Environment: "production",
+			HTTP:        config.HTTPConfig{Port: "8080"},
+			GRPC:        config.GRPCConfig{Port: "9090"},
+			Features:    config.FeaturesConfig{GraphQL: true, GRPC: true, Metrics: true, MetricsMiddleware: true},
+			Redis:       config.RedisConfig{SessionTTL: time.Hour, UserSessionsTTL: time.Hour},
+			Nonce:       config.NonceConfig{TTL: time.Minute},
+		}
+	}
+	tests := []struct {
+		name   string
+		mutate func(*config.Config)
+		want   []string
+	}{
+		{name: "valid", mutate: func(*config.Config) {}},
+		{name: "metrics middleware without endpoint", mutate: func(c *config.Config) { c.Features.Metrics = false }, want: []string{"FEATURE_METRICS_MIDDLEWARE requires FEATURE_METRICS"}},
+		{name: "grpc on the http port", mutate: func(c *config.Config) { c.GRPC.Port = "8080" }, want: []string{"GRPC_PORT"}},
+		{name: "grpc disabled on the http port", mutate: func(c *config.Config) { c.GRPC.Port = "8080"; c.Features.GRPC = false }},
+		{name: "pprof in production", mutate: func(c *config.Config) { c.Features.Pprof = true }, want: []string{"FEATURE_PPROF"}},
+		{name: "pprof in development", mutate: func(c *config.Config) { c.Features.Pprof = true; c.Environment = "development" }},
+		{name: "low water above high water", mutate: func(c *config.Config) { c.Monitor.BufferHighWater = 10; c.Monitor.BufferLowWater = 20 }, want: []string{"MONITOR_BUFFER_LOW_WATER"}},
+		{name: "buffer alerts disabled", mutate: func(c *config.Config) { c.Monitor.BufferLowWater = 20 }},
+		{name: "allow-all logins in production", mutate: func(c *config.Config) { c.Auth.Authenticator = "allow_all" }, want: []string{"AUTH_AUTHENTICATOR"}},
+		{name: "allow-all logins in development", mutate: func(c *config.Config) { c.Auth.Authenticator = "allow_all"; c.Environment = "development" }},
+		{name: "aggregate batches above the ceiling", mutate: func(c *config.Config) { c.Aggregate.MaxBatchSize = 20_000 }, want: []string{"AGGREGATE_MAX_BATCH_SIZE"}},
+		{name: "body sample rate above one", mutate: func(c *config.Config) { c.Logger.BodySampleRate = 1.5 }, want: []string{"LOG_BODY_SAMPLE_RATE"}},
+		{name: "zero session ttl", mutate: func(c *config.Config) { c.Redis.SessionTTL = 0 }, want: []string{"REDIS_SESSION_TTL"}},
+		{name: "negative user sessions ttl", mutate: func(c *config.Config) { c.Redis.UserSessionsTTL = -time.Minute }, want: []string{"REDIS_USER_SESSIONS_TTL"}},
+		{name: "zero nonce ttl", mutate: func(c *config.Config) { c.Nonce.TTL = 0 }, want: []string{"NONCE_TTL"}},
+		{name: "every problem reported", mutate: func(c *config.Config) {
+			c.Features.Metrics = false
+			c.Features.Pprof = true
+		}, want: []string{"FEATURE_METRICS_MIDDLEWARE", "FEATURE_PPROF"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := valid()
+			tt.mutate(cfg)
+			err := cfg.Validate()
+			if len(tt.want) == 0 {
+				if err != nil {
+					t.Fatalf("validate: %v", err)
+				}
+				return
+			}
+			if err == nil {
+				t.Fatalf("validate accepted the combination, want %v", tt.want)
+			}
+			for _, fragment := range tt.want {
+				if !strings.Contains(err.Error(), fragment) {
+					t.Errorf("error %q does not mention %q", err, fragment)
+				}
+			}
+		})
+	}
+}
+
+func TestFeatureFlagsHonourLegacyNames(t *testing.T) {
+	t.Setenv("SERVER_ENABLE_GRAPHQL", "true")
+	t.Setenv("GRPC_ENABLED", "true")
+	t.Setenv("FEATURE_GRPC", "false")
+
+	cfg, err := config.Load()
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	if !cfg.Features.GraphQL || cfg.Features.GRPC {
+		t.Fatalf("features = %+v, want GraphQL from the legacy name and FEATURE_GRPC overriding GRPC_ENABLED", cfg.Features)
+	}
+}
+
+func TestDiffListsChangedKeysWithSecretsRedacted(t *testing.T) {
+	old := &config.Config{HTTP: config.HTTPConfig{Port: "8080"}, JWT: config.JWTConfig{Secret: "old-secret"}, Warnings: []string{"a"}}
+	next := &config.Config{HTTP: config.HTTPConfig{Port: "9000"}, JWT: config.JWTConfig{Secret: "new-secret"}}
+
+	changes := next.Diff(old)
+
+	want := []config.Change{
+		{Key: "HTTP.Port", Old: "8080", New: "9000"},
+		{Key: "JWT.Secret", Old: "***", New: "***"},
+	}
+	if !reflect.DeepEqual(changes, want) {
+		t.Fatalf("changes = %+v, want %+v", changes, want)
+	}
+	if len(next.Diff(next)) != 0 {
+		t.Fatal("a config must not differ from itself")
+	}
+}
+
+func TestLoadPicksUpDotenvEditsWithoutOverridingTheEnvironment(t *testing.T) {
+	t.Chdir(t.TempDir())
+	for _, key := range []string{"SERVER_PORT", "GRPC_PORT"} {
+		t.Setenv(key, "")
+		os.Unsetenv(key)
+	}
+	t.Setenv("APP_NAME", "from-env")
+	writeDotenv := func(contents string) {
+		t.Helper()
+		if err := os.WriteFile(".env", []byte(contents), 0o600); err != nil {
+			t.Fatalf("write .env: %v", err)
+		}
+	}
+
+	writeDotenv("SERVER_PORT=8081\nGRPC_PORT=9091\nAPP_NAME=from-file\n")
+	cfg, err := config.Load()
+	if err != nil || cfg.HTTP.Port != "8081" || cfg.GRPC.Port != "9091" {
+		t.Fatalf("first load = %+v, %v; want the ports from .env", cfg, err)
+	}
+
+	writeDotenv("SERVER_PORT=8082\nAPP_NAME=from-file\n")
+	cfg, err = config.Load()
+	if err != nil {
+		t.Fatalf("reload: %v", err)
+	}
+	if cfg.HTTP.Port != "8082" || cfg.GRPC.Port != "9090" || cfg.AppName != "from-env" {
+		t.Fatalf("reload = port %s, grpc %s, app %s; want the edited port, the removed key defaulted and the environment kept",
+			cfg.HTTP.Port, cfg.GRPC.Port, cfg.AppName)
+	}
+}
+
+func TestReloaderAppliesValidConfigAndRejectsInvalid(t *testing.T) {
+	redis := config.RedisConfig{SessionTTL: time.Hour, UserSessionsTTL: time.Hour}
+	nonce := config.NonceConfig{TTL: time.Minute}
+	initial := &config.Config{HTTP: config.HTTPConfig{Port: "8080"}, GRPC: config.GRPCConfig{Port: "9090"}, Redis: redis, Nonce: nonce}
+	var next *config.Config
+	reloader := config.NewReloader(initial, func() (*config.Config, error) { return next, nil })
+
+	next = &config.Config{HTTP: config.HTTPConfig{Port: "8081"}, GRPC: config.GRPCConfig{Port: "9090"}, Redis: redis, Nonce: nonce}
+	changes, err := reloader.Reload()
+	if err != nil {
+		t.Fatalf("reload: %v", err)
+	}
+	if len(changes) != 1 || changes[0].Key != "HTTP.Port" || reloader.Current() != next {
+		t.Fatalf("changes = %+v, current = %+v; want the port change applied", changes, reloader.Current())
+	}
+
+	applied := reloader.Current()
+	next = &config.Config{HTTP: config.HTTPConfig{Port: "9090"}, GRPC: config.GRPCConfig{Port: "9090"}, Features: config.FeaturesConfig{GRPC: true}, Redis: redis, Nonce: nonce}
+	if _, err := reloader.Reload(); err == nil {
+		t.Fatal("expected a config that fails Validate to be rejected")
+	}
+	if reloader.Current() != applied {
+		t.Fatal("a rejected reload must keep the previous config")
+	}
+
+	loadErr := errors.New("unreadable")
+	failing := config.NewReloader(applied, func() (*config.Config, error) { return nil, loadErr })
+	if _, err := failing.Reload(); !errors.Is(err, loadErr) || failing.Current() != applied {
+		t.Fatalf("load failure: err = %v, want it reported and the config kept", err)
+	}
+}
@@ -0,0 +1,95 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"sync/atomic"
+)
+
+// Change is one setting that differs between two configs. Key is the field path (e.g.
+// "HTTP.Port"); secrets are shown redacted.
+type Change struct {
+	Key string `json:"key"`
+	Old string `json:"old"`
+	New string `json:"new"`
+}
+
+// Diff lists the settings of c that differ from old, sorted by key.
+func (c *Config) Diff(old *Config) []Change {
+	before, after := flatten(old), flatten(c)
+	oldRedacted, newRedacted := old.Redacted(), c.Redacted()
+	shownBefore, shownAfter := flatten(&oldRedacted), flatten(&newRedacted)
+
+	var changes []Change
+	for key, value := range after {
+		if before[key] != value {
+			changes = append(changes, Change{Key: key, Old: shownBefore[key], New: shownAfter[key]})
+		}
+	}
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Key < changes[j].Key })
+	return changes
+}
+
+// flatten renders every setting of cfg keyed by its field path. Warnings describe how the config
+// was loaded rather than a setting, so they are left out.
+func flatten(cfg *Config) map[string]string {
+	fields := make(map[string]string)
+	if cfg == nil {
+		cfg = &Config{}
+	}
+	flattenValue(reflect.ValueOf(*cfg), "", fields)
+	delete(fields, "Warnings")
+	return fields
+}
+
+func flattenValue(v reflect.Value, prefix string, fields map[string]string) {
+	if v.Kind() != reflect.Struct {
+		fields[prefix] = fmt.Sprint(v.Interface())
+		return
+	}
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		if !t.Field(i).IsExported() {
+			continue
+		}
+		key := t.Field(i).Name
+		if prefix != "" {
+			key = prefix + "." + key
+		}
+		flattenValue(v.Field(i), key, fields)
+	}
+}
+
+// Reloader holds the active config and replaces it only with one that passes Validate, so a bad
+// reload leaves the running settings untouched.
+type Reloader struct {
+	current atomic.Pointer[Config]
+	load    func() (*Config, error)
+}
+
+// NewReloader starts from initial and reloads with load (usually Load).
+func NewReloader(initial *Config, load func() (*Config, error)) *Reloader {
+	r := &Reloader{load: load}
+	r.current.Store(initial)
+	return r
+}
+
+// Current returns the active config.
+func (r *Reloader) Current() *Config {
+	return r.current.Load()
+}
+
+// Reload loads and validates a fresh config. When both succeed it becomes current and the changes
+// against the previous one are returned; otherwise the previous config stays current.
+func (r *Reloader) Reload() ([]Change, error) {
+	next, err := r.load()
+	if err != nil {
+		return nil, err
+	}
+	if err := next.Validate(); err != nil {
+		return nil, err
+	}
+	previous := r.current.Swap(next)
+	return next.Diff(previous), nil
+}
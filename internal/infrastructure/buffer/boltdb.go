@@ -1,23 +1,88 @@
 package buffer
 
 import (
+	"context"
+	"encoding/binary"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
+	"sync"
 	"time"
 
 	bolt "go.etcd.io/bbolt"
+
+	"github.com/fastygo/backend/domain"
+	"github.com/fastygo/backend/pkg/clock"
 )
 
+// ErrItemNotQueued is returned when an item was removed from the active buffer (e.g. by a concurrent
+// drain) before it could be rescheduled or dead-lettered; the write is skipped so it is not resurrected.
+var ErrItemNotQueued = errors.New("buffer item no longer queued")
+
+// ErrUserQuotaExceeded is returned by Enqueue when the item's user already has the maximum number
+// of queued items. The returned error also wraps a *domain.LimitError with the quota and usage.
+var ErrUserQuotaExceeded = domain.NewError(domain.ErrCodeLimitExceeded, "buffer quota exceeded for user")
+
+// ErrBufferFull is returned by Enqueue when the store already holds its maximum number of queued items.
+var ErrBufferFull = errors.New("buffer is full")
+
+// queuedTotalKey holds the number of queued items in the user counts bucket. User IDs never start
+// with a NUL byte, so it cannot collide with one.
+var queuedTotalKey = []byte("\x00queued")
+
 // Store wraps BoltDB to persist buffered operations while external services are unavailable.
 type Store struct {
-	db     *bolt.DB
-	bucket []byte
+	db         *bolt.DB
+	bucket     []byte
+	deadBucket []byte
+	metaBucket []byte
+	// userBucket maps each user ID to the number of its queued items, plus the total under
+	// queuedTotalKey.
+	userBucket []byte
+	userQuota  int
+	maxSize    int
+	clock      clock.Clock
+
+	// txMu guards the count of running write transactions, so CloseGraceful can wait for them
+	// and refuse new ones once closing has begun.
+	txMu     sync.Mutex
+	activeTx int
+	closing  bool
+	idle     chan struct{}
+}
+
+// Option customizes the buffer store.
+type Option func(*Store)
+
+// WithClock overrides the time source used to timestamp buffered items.
+func WithClock(c clock.Clock) Option {
+	return func(s *Store) {
+		if c != nil {
+			s.clock = c
+		}
+	}
+}
+
+// WithUserQuota caps the queued items of a single user; Enqueue rejects more with
+// ErrUserQuotaExceeded. Zero or less means unlimited. Items without a user ID are never capped.
+func WithUserQuota(quota int) Option {
+	return func(s *Store) {
+		s.userQuota = quota
+	}
+}
+
+// WithMaxSize caps the number of queued items; Enqueue rejects more with ErrBufferFull. Zero or
+// less means unlimited. Dead letters do not count.
+func WithMaxSize(size int) Option {
+	return func(s *Store) {
+		s.maxSize = size
+	}
 }
 
 // Open initializes the BoltDB file and ensures the bucket exists.
-func Open(path string, bucket string) (*Store, error) {
+func Open(path string, bucket string, opts ...Option) (*Store, error) {
 	if bucket == "" {
 		bucket = "buffer"
 	}
@@ -29,18 +94,55 @@ func Open(path string, bucket string) (*Store, error) {
 		return nil, err
 	}
 
+	deadBucket := bucket + "_dead"
+	metaBucket := bucket + "_meta"
+	userBucket := bucket + "_users"
 	if err := db.Update(func(tx *bolt.Tx) error {
-		_, err := tx.CreateBucketIfNotExists([]byte(bucket))
-		return err
+		for _, name := range []string{bucket, deadBucket, metaBucket} {
+			if _, err := tx.CreateBucketIfNotExists([]byte(name)); err != nil {
+				return err
+			}
+		}
+		if users := tx.Bucket([]byte(userBucket)); users != nil {
+			if users.Get(queuedTotalKey) != nil {
+				return nil
+			}
+			// Files written before the total existed get it counted once.
+			return putCount(users, queuedTotalKey, tx.Bucket([]byte(bucket)).Stats().KeyN)
+		}
+		// Files written before user counts existed get them rebuilt once.
+		users, err := tx.CreateBucket([]byte(userBucket))
+		if err != nil {
+			return err
+		}
+		return tx.Bucket([]byte(bucket)).ForEach(func(_, v []byte) error {
+			return adjustUserCount(users, queuedUserID(v), 1)
+		})
 	}); err != nil {
 		db.Close()
 		return nil, err
 	}
 
-	return &Store{
-		db:     db,
-		bucket: []byte(bucket),
-	}, nil
+	store := &Store{
+		db:         db,
+		bucket:     []byte(bucket),
+		deadBucket: []byte(deadBucket),
+		metaBucket: []byte(metaBucket),
+		userBucket: []byte(userBucket),
+		clock:      clock.Real(),
+	}
+	for _, opt := range opts {
+		opt(store)
+	}
+	return store, nil
+}
+
+// Now returns the current time according to the store's clock.
+func (s *Store) Now() time.Time {
+	if s == nil || s.clock == nil {
+		return time.Now()
+	}
+	return s.clock.Now()
 }
 
 // Enqueue stores a buffer item using a priority-aware key.
@@ -48,22 +150,45 @@ func (s *Store) Enqueue(item Item) error {
 	if s == nil || s.db == nil {
 		return bolt.ErrDatabaseNotOpen
 	}
-	item.normalize()
-	key := buildKey(item)
-	item.bucketKey = []byte(key)
+	item.Normalize(s.Now())
 
-	payload, err := json.Marshal(item)
-	if err != nil {
-		return err
-	}
+	return s.update(func(tx *bolt.Tx) error {
+		users := tx.Bucket(s.userBucket)
+		if s.maxSize > 0 && readCount(users, queuedTotalKey) >= s.maxSize {
+			return ErrBufferFull
+		}
+		if err := s.checkUserQuota(users, item.UserID); err != nil {
+			return err
+		}
+		return s.put(tx, item)
+	})
+}
 
-	return s.db.Update(func(tx *bolt.Tx) error {
-		return tx.Bucket(s.bucket).Put(item.bucketKey, payload)
+// UserCount returns how many items of userID are queued.
+func (s *Store) UserCount(userID string) (int, error) {
+	if s == nil || s.db == nil {
+		return 0, bolt.ErrDatabaseNotOpen
+	}
+	var count int
+	err := s.db.View(func(tx *bolt.Tx) error {
+		count = readCount(tx.Bucket(s.userBucket), []byte(userID))
+		return nil
 	})
+	return count, err
 }
 
-// GetBatch returns up to limit items without removing them.
+// GetBatch returns up to limit items that are due for processing, without removing them.
 func (s *Store) GetBatch(limit int) ([]Item, error) {
+	return s.batch(limit, "")
+}
+
+// GetEntityBatch is GetBatch restricted to items of one entity.
+func (s *Store) GetEntityBatch(entity string, limit int) ([]Item, error) {
+	return s.batch(limit, entity)
+}
+
+// batch collects due items in key order; an empty entity matches every item.
+func (s *Store) batch(limit int, entity string) ([]Item, error) {
 	if s == nil || s.db == nil {
 		return nil, bolt.ErrDatabaseNotOpen
 	}
@@ -71,6 +196,7 @@ func (s *Store) GetBatch(limit int) ([]Item, error) {
 		limit = 50
 	}
 
+	now := s.Now()
 	var items []Item
 	err := s.db.View(func(tx *bolt.Tx) error {
 		c := tx.Bucket(s.bucket).Cursor()
@@ -79,6 +205,9 @@ func (s *Store) GetBatch(limit int) ([]Item, error) {
 			if err := json.Unmarshal(v, &item); err != nil {
 				continue
 			}
+			if !item.IsReady(now) || (entity != "" && item.Entity != entity) {
+				continue
+			}
 			item.bucketKey = append([]byte(nil), k...)
 			items = append(items, item)
 		}
@@ -87,6 +216,73 @@ func (s *Store) GetBatch(limit int) ([]Item, error) {
 	return items, err
 }
 
+// ForEach calls fn for every queued item in drain order, deferred ones included, from a single
+// read transaction so the whole buffer is never held in memory. An error from fn stops the walk.
+func (s *Store) ForEach(fn func(Item) error) error {
+	if s == nil || s.db == nil {
+		return bolt.ErrDatabaseNotOpen
+	}
+	return s.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(s.bucket).ForEach(func(k, v []byte) error {
+			var item Item
+			if err := json.Unmarshal(v, &item); err != nil {
+				return nil
+			}
+			return fn(item)
+		})
+	})
+}
+
+// Import queues previously exported items in one transaction, keeping their IDs, retry counts and
+// timestamps. Items already queued with the same ID, operation and enqueue time are skipped; the
+// number actually added is returned. The size limit and per-user quota apply as in Enqueue, and
+// exceeding either rolls back the whole batch.
+func (s *Store) Import(items []Item) (int, error) {
+	if s == nil || s.db == nil {
+		return 0, bolt.ErrDatabaseNotOpen
+	}
+	now := s.Now()
+	imported := 0
+	err := s.update(func(tx *bolt.Tx) error {
+		queued := make(map[string]struct{})
+		if err := tx.Bucket(s.bucket).ForEach(func(_, v []byte) error {
+			var item Item
+			if json.Unmarshal(v, &item) == nil {
+				item.Normalize(now)
+				queued[importKey(item)] = struct{}{}
+			}
+			return nil
+		}); err != nil {
+			return err
+		}
+
+		users := tx.Bucket(s.userBucket)
+		for _, item := range items {
+			item.Normalize(now)
+			key := importKey(item)
+			if _, ok := queued[key]; ok {
+				continue
+			}
+			if s.maxSize > 0 && readCount(users, queuedTotalKey) >= s.maxSize {
+				return ErrBufferFull
+			}
+			if err := s.checkUserQuota(users, item.UserID); err != nil {
+				return err
+			}
+			if err := s.put(tx, item); err != nil {
+				return err
+			}
+			queued[key] = struct{}{}
+			imported++
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return imported, nil
+}
+
 // Remove deletes the provided item from the buffer.
 func (s *Store) Remove(item Item) error {
 	if s == nil || s.db == nil {
@@ -95,18 +291,75 @@ func (s *Store) Remove(item Item) error {
 	if len(item.bucketKey) == 0 {
 		return s.deleteByID(item.ID)
 	}
-	return s.db.Update(func(tx *bolt.Tx) error {
-		return tx.Bucket(s.bucket).Delete(item.bucketKey)
+	return s.update(func(tx *bolt.Tx) error {
+		if err := s.takeQueued(tx, item.bucketKey, item.ID); err != nil && !errors.Is(err, ErrItemNotQueued) {
+			return err
+		}
+		return nil
 	})
 }
 
 // Requeue re-inserts an item after bumping its timestamp.
 func (s *Store) Requeue(item Item) error {
 	item.bucketKey = nil
-	item.Timestamp = time.Now()
+	item.Timestamp = s.Now()
 	return s.Enqueue(item)
 }
 
+// Reschedule atomically replaces the item's current entry with a freshly timestamped one that becomes
+// due at nextAttempt, so an interruption can never leave the operation removed but not re-enqueued.
+func (s *Store) Reschedule(item Item, nextAttempt time.Time) error {
+	if s == nil || s.db == nil {
+		return bolt.ErrDatabaseNotOpen
+	}
+	oldKey := item.bucketKey
+	item.NextAttempt = nextAttempt
+	item.Timestamp = s.Now()
+	item.Normalize(item.Timestamp)
+
+	return s.update(func(tx *bolt.Tx) error {
+		if err := s.takeQueued(tx, oldKey, item.ID); err != nil {
+			return err
+		}
+		return s.put(tx, item)
+	})
+}
+
+// DeadLetter moves the item out of the active buffer into the dead-letter bucket in a single transaction.
+func (s *Store) DeadLetter(item Item) error {
+	if s == nil || s.db == nil {
+		return bolt.ErrDatabaseNotOpen
+	}
+	key := item.bucketKey
+	payload, err := json.Marshal(item)
+	if err != nil {
+		return err
+	}
+
+	return s.update(func(tx *bolt.Tx) error {
+		if err := s.takeQueued(tx, key, item.ID); err != nil {
+			return err
+		}
+		if len(key) == 0 {
+			key = []byte(buildKey(item))
+		}
+		return tx.Bucket(s.deadBucket).Put(key, payload)
+	})
+}
+
+// DeadLetterSize returns the number of dead-lettered items awaiting operator action.
+func (s *Store) DeadLetterSize() (int, error) {
+	if s == nil || s.db == nil {
+		return 0, bolt.ErrDatabaseNotOpen
+	}
+	var count int
+	err := s.db.View(func(tx *bolt.Tx) error {
+		count = tx.Bucket(s.deadBucket).Stats().KeyN
+		return nil
+	})
+	return count, err
+}
+
 // Size returns the number of buffered items.
 func (s *Store) Size() (int, error) {
 	if s == nil || s.db == nil {
@@ -120,26 +373,38 @@ func (s *Store) Size() (int, error) {
 	return count, err
 }
 
-// Cleanup removes items older than the provided timestamp.
-func (s *Store) Cleanup(olderThan time.Time) error {
+// Cleanup removes active items first enqueued before the provided time and returns how many were
+// purged; rescheduling does not extend an item's retention. Dead-lettered items are left untouched;
+// see CleanupDeadLetters.
+func (s *Store) Cleanup(olderThan time.Time) (int, error) {
 	if s == nil || s.db == nil {
-		return bolt.ErrDatabaseNotOpen
+		return 0, bolt.ErrDatabaseNotOpen
 	}
-	return s.db.Update(func(tx *bolt.Tx) error {
-		c := tx.Bucket(s.bucket).Cursor()
-		for k, v := c.First(); k != nil; k, v = c.Next() {
-			var item Item
-			if err := json.Unmarshal(v, &item); err != nil {
-				continue
-			}
-			if item.Timestamp.Before(olderThan) {
-				if err := c.Delete(); err != nil {
-					return err
-				}
-			}
-		}
-		return nil
+	var purged int
+	err := s.update(func(tx *bolt.Tx) error {
+		var err error
+		users := tx.Bucket(s.userBucket)
+		purged, err = purgeBefore(tx.Bucket(s.bucket), olderThan, Item.FirstEnqueued, func(v []byte) error {
+			return adjustUserCount(users, queuedUserID(v), -1)
+		})
+		return err
+	})
+	return purged, err
+}
+
+// CleanupDeadLetters removes dead-lettered items whose last attempt is older than the provided time
+// and returns how many were purged.
+func (s *Store) CleanupDeadLetters(olderThan time.Time) (int, error) {
+	if s == nil || s.db == nil {
+		return 0, bolt.ErrDatabaseNotOpen
+	}
+	var purged int
+	err := s.update(func(tx *bolt.Tx) error {
+		var err error
+		purged, err = purgeBefore(tx.Bucket(s.deadBucket), olderThan, func(item Item) time.Time { return item.Timestamp }, nil)
+		return err
 	})
+	return purged, err
 }
 
 // Close closes the Bolt database.
@@ -150,6 +415,52 @@ func (s *Store) Close() error {
 	return s.db.Close()
 }
 
+// CloseGraceful stops accepting writes, waits for running write transactions to finish and then
+// closes the file. If ctx ends first the store is left open and ctx's error is returned; BoltDB
+// commits atomically, so exiting then loses at most the unfinished transaction.
+func (s *Store) CloseGraceful(ctx context.Context) error {
+	if s == nil || s.db == nil {
+		return nil
+	}
+	s.txMu.Lock()
+	s.closing = true
+	if s.activeTx > 0 && s.idle == nil {
+		s.idle = make(chan struct{})
+	}
+	idle, active := s.idle, s.activeTx
+	s.txMu.Unlock()
+
+	if active > 0 {
+		select {
+		case <-idle:
+		case <-ctx.Done():
+			return fmt.Errorf("buffer store closing with write transactions in flight: %w", ctx.Err())
+		}
+	}
+	return s.db.Close()
+}
+
+// update runs fn in a write transaction counted for CloseGraceful.
+func (s *Store) update(fn func(tx *bolt.Tx) error) error {
+	s.txMu.Lock()
+	if s.closing {
+		s.txMu.Unlock()
+		return bolt.ErrDatabaseNotOpen
+	}
+	s.activeTx++
+	s.txMu.Unlock()
+
+	defer func() {
+		s.txMu.Lock()
+		defer s.txMu.Unlock()
+		if s.activeTx--; s.activeTx == 0 && s.idle != nil {
+			close(s.idle)
+			s.idle = nil
+		}
+	}()
+	return s.db.Update(fn)
+}
+
 // Stats exposes Bolt statistics for monitoring endpoints.
 func (s *Store) Stats() bolt.Stats {
 	if s == nil || s.db == nil {
@@ -158,25 +469,164 @@ func (s *Store) Stats() bolt.Stats {
 	return s.db.Stats()
 }
 
+// put stores item in the active bucket under a key carrying the next value of the persisted
+// sequence, so items with identical priority and timestamp still drain in insertion order.
+// checkUserQuota returns ErrUserQuotaExceeded, with the quota and usage attached, when userID has
+// no quota left.
+func (s *Store) checkUserQuota(users *bolt.Bucket, userID string) error {
+	if s.userQuota <= 0 || userID == "" {
+		return nil
+	}
+	if queued := readCount(users, []byte(userID)); queued >= s.userQuota {
+		return fmt.Errorf("%w: %w", ErrUserQuotaExceeded, domain.NewLimitError("buffer_user_items", int64(s.userQuota), int64(queued)))
+	}
+	return nil
+}
+
+// importKey identifies an operation across export and import. Sequence and bucket keys are
+// reassigned on every insert, so the ID, operation and original enqueue time are used instead.
+func importKey(item Item) string {
+	return fmt.Sprintf("%s_%s_%020d", item.ID, item.Operation, item.EnqueuedAt.UnixNano())
+}
+
+func (s *Store) put(tx *bolt.Tx, item Item) error {
+	seq, err := tx.Bucket(s.metaBucket).NextSequence()
+	if err != nil {
+		return err
+	}
+	item.Sequence = seq
+	payload, err := json.Marshal(item)
+	if err != nil {
+		return err
+	}
+	if err := adjustUserCount(tx.Bucket(s.userBucket), item.UserID, 1); err != nil {
+		return err
+	}
+	return tx.Bucket(s.bucket).Put([]byte(buildKey(item)), payload)
+}
+
 func (s *Store) deleteByID(id string) error {
 	if id == "" {
 		return nil
 	}
-	return s.db.Update(func(tx *bolt.Tx) error {
-		c := tx.Bucket(s.bucket).Cursor()
-		for k, v := c.First(); k != nil; k, v = c.Next() {
-			var item Item
-			if err := json.Unmarshal(v, &item); err != nil {
-				continue
-			}
-			if item.ID == id {
-				return c.Delete()
-			}
+	return s.update(func(tx *bolt.Tx) error {
+		if err := s.takeQueued(tx, nil, id); err != nil && !errors.Is(err, ErrItemNotQueued) {
+			return err
 		}
 		return nil
 	})
 }
 
+// takeQueued deletes the item's active entry by key, or by ID when the key is unknown, releasing
+// its user's quota. It returns ErrItemNotQueued when there is no such entry.
+func (s *Store) takeQueued(tx *bolt.Tx, key []byte, id string) error {
+	bucket := tx.Bucket(s.bucket)
+	var userID string
+	if len(key) > 0 {
+		payload := bucket.Get(key)
+		if payload == nil {
+			return ErrItemNotQueued
+		}
+		userID = queuedUserID(payload)
+		if err := bucket.Delete(key); err != nil {
+			return err
+		}
+	} else {
+		payload, err := deleteByID(bucket, id)
+		if err != nil {
+			return err
+		}
+		if payload == nil {
+			return ErrItemNotQueued
+		}
+		userID = queuedUserID(payload)
+	}
+	return adjustUserCount(tx.Bucket(s.userBucket), userID, -1)
+}
+
+// queuedUserID reads the user ID of a stored item, empty when it cannot be decoded.
+func queuedUserID(payload []byte) string {
+	var item struct {
+		UserID string `json:"user_id"`
+	}
+	_ = json.Unmarshal(payload, &item)
+	return item.UserID
+}
+
+func readCount(users *bolt.Bucket, key []byte) int {
+	v := users.Get(key)
+	if len(v) != 8 {
+		return 0
+	}
+	return int(binary.BigEndian.Uint64(v))
+}
+
+func putCount(users *bolt.Bucket, key []byte, n int) error {
+	var v [8]byte
+	binary.BigEndian.PutUint64(v[:], uint64(n))
+	return users.Put(key, v[:])
+}
+
+// adjustUserCount adds delta to the queued total and to userID's count, deleting the user's entry
+// when it reaches zero. Every queued item passes through here on the way in and out.
+func adjustUserCount(users *bolt.Bucket, userID string, delta int) error {
+	if err := putCount(users, queuedTotalKey, max(readCount(users, queuedTotalKey)+delta, 0)); err != nil {
+		return err
+	}
+	if userID == "" {
+		return nil
+	}
+	n := readCount(users, []byte(userID)) + delta
+	if n <= 0 {
+		return users.Delete([]byte(userID))
+	}
+	return putCount(users, []byte(userID), n)
+}
+
+// purgeBefore deletes entries whose Timestamp is before olderThan, calling onPurge with each
+// deleted payload when set.
+func purgeBefore(bucket *bolt.Bucket, olderThan time.Time, since func(Item) time.Time, onPurge func([]byte) error) (int, error) {
+	var purged int
+	c := bucket.Cursor()
+	for k, v := c.First(); k != nil; k, v = c.Next() {
+		var item Item
+		if err := json.Unmarshal(v, &item); err != nil {
+			continue
+		}
+		if since(item).Before(olderThan) {
+			if onPurge != nil {
+				if err := onPurge(v); err != nil {
+					return purged, err
+				}
+			}
+			if err := c.Delete(); err != nil {
+				return purged, err
+			}
+			purged++
+		}
+	}
+	return purged, nil
+}
+
+// deleteByID deletes the entry holding id and returns a copy of its payload, nil when absent.
+func deleteByID(bucket *bolt.Bucket, id string) ([]byte, error) {
+	if id == "" {
+		return nil, nil
+	}
+	c := bucket.Cursor()
+	for k, v := c.First(); k != nil; k, v = c.Next() {
+		var item Item
+		if err := json.Unmarshal(v, &item); err != nil {
+			continue
+		}
+		if item.ID == id {
+			payload := append([]byte(nil), v...)
+			return payload, c.Delete()
+		}
+	}
+	return nil, nil
+}
+
 func buildKey(item Item) string {
-	return fmt.Sprintf("%d_%020d_%s", item.Priority, item.Timestamp.UnixNano(), item.ID)
+	return fmt.Sprintf("%d_%020d_%020d_%s", item.Priority, item.Timestamp.UnixNano(), item.Sequence, item.ID)
 }
@@ -0,0 +1,490 @@
+package buffer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+
+	"github.com/fastygo/backend/domain"
+	"github.com/fastygo/backend/pkg/clock"
+)
+
+func openTestStore(t *testing.T, c clock.Clock) *Store {
+	t.Helper()
+	store, err := Open(filepath.Join(t.TempDir(), "buffer.db"), "buffer", WithClock(c))
+	if err != nil {
+		t.Fatalf("open store: %v", err)
+	}
+	t.Cleanup(func() { _ = store.Close() })
+	return store
+}
+
+func TestStoreTimestampsItemsWithClock(t *testing.T) {
+	fake := clock.NewFake(time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC))
+	store := openTestStore(t, fake)
+
+	if err := store.Enqueue(Item{ID: "a", Entity: EntityTask, Operation: OperationCreate}); err != nil {
+		t.Fatalf("enqueue: %v", err)
+	}
+	items, err := store.GetBatch(10)
+	if err != nil || len(items) != 1 {
+		t.Fatalf("get batch: %v (%d items)", err, len(items))
+	}
+	enqueued := fake.Now()
+	if !items[0].Timestamp.Equal(enqueued) || !items[0].EnqueuedAt.Equal(enqueued) {
+		t.Fatalf("timestamps = %v / %v, want %v", items[0].Timestamp, items[0].EnqueuedAt, enqueued)
+	}
+
+	fake.Advance(time.Minute)
+	if err := store.Remove(items[0]); err != nil {
+		t.Fatalf("remove: %v", err)
+	}
+	if err := store.Requeue(items[0]); err != nil {
+		t.Fatalf("requeue: %v", err)
+	}
+	items, err = store.GetBatch(10)
+	if err != nil || len(items) != 1 {
+		t.Fatalf("get batch after requeue: %v (%d items)", err, len(items))
+	}
+	if !items[0].Timestamp.Equal(fake.Now()) {
+		t.Fatalf("requeue timestamp = %v, want %v", items[0].Timestamp, fake.Now())
+	}
+	if !items[0].EnqueuedAt.Equal(enqueued) {
+		t.Fatalf("enqueued_at = %v, want it preserved at %v", items[0].EnqueuedAt, enqueued)
+	}
+}
+
+func TestStoreCleanupKeepsDeadLettersUntilTheirOwnRetention(t *testing.T) {
+	fake := clock.NewFake(time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC))
+	store := openTestStore(t, fake)
+
+	for _, id := range []string{"old", "dead"} {
+		if err := store.Enqueue(Item{ID: id, Entity: EntityTask, Operation: OperationCreate}); err != nil {
+			t.Fatalf("enqueue %s: %v", id, err)
+		}
+	}
+	items, err := store.GetBatch(10)
+	if err != nil {
+		t.Fatalf("get batch: %v", err)
+	}
+	for _, item := range items {
+		if item.ID == "dead" {
+			if err := store.DeadLetter(item); err != nil {
+				t.Fatalf("dead-letter: %v", err)
+			}
+		}
+	}
+
+	fake.Advance(time.Hour)
+	if err := store.Enqueue(Item{ID: "new", Entity: EntityTask, Operation: OperationCreate}); err != nil {
+		t.Fatalf("enqueue new: %v", err)
+	}
+	// A retry resets Timestamp, but retention counts from the first enqueue.
+	for _, item := range items {
+		if item.ID == "old" {
+			if err := store.Reschedule(item, fake.Now()); err != nil {
+				t.Fatalf("reschedule: %v", err)
+			}
+		}
+	}
+
+	if purged, err := store.Cleanup(fake.Now().Add(-30 * time.Minute)); err != nil || purged != 1 {
+		t.Fatalf("cleanup = %d, %v; want the old active item purged", purged, err)
+	}
+	if size, _ := store.Size(); size != 1 {
+		t.Fatalf("active size = %d, want only the new item left", size)
+	}
+	if size, _ := store.DeadLetterSize(); size != 1 {
+		t.Fatalf("dead-letter size = %d, want Cleanup to leave it alone", size)
+	}
+
+	purged, err := store.CleanupDeadLetters(fake.Now().Add(-30 * time.Minute))
+	if err != nil || purged != 1 {
+		t.Fatalf("cleanup dead letters = %d, %v; want 1 purged", purged, err)
+	}
+	if size, _ := store.DeadLetterSize(); size != 0 {
+		t.Fatalf("dead-letter size = %d after purge, want 0", size)
+	}
+}
+
+// countBoth reports active and dead-lettered entries from a single read transaction.
+func countBoth(t *testing.T, store *Store) (active, dead int) {
+	t.Helper()
+	err := store.db.View(func(tx *bolt.Tx) error {
+		active = tx.Bucket(store.bucket).Stats().KeyN
+		dead = tx.Bucket(store.deadBucket).Stats().KeyN
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("count: %v", err)
+	}
+	return active, dead
+}
+
+func TestRescheduleAndDeadLetterSkipRemovedItems(t *testing.T) {
+	store := openTestStore(t, clock.NewFake(time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)))
+	if err := store.Enqueue(Item{ID: "a", Entity: EntityTask, Operation: OperationCreate}); err != nil {
+		t.Fatalf("enqueue: %v", err)
+	}
+	items, err := store.GetBatch(1)
+	if err != nil || len(items) != 1 {
+		t.Fatalf("get batch: %v (%d items)", err, len(items))
+	}
+	if err := store.Remove(items[0]); err != nil {
+		t.Fatalf("remove: %v", err)
+	}
+
+	if err := store.Reschedule(items[0], store.Now().Add(time.Minute)); !errors.Is(err, ErrItemNotQueued) {
+		t.Fatalf("reschedule removed item error = %v, want ErrItemNotQueued", err)
+	}
+	if err := store.DeadLetter(items[0]); !errors.Is(err, ErrItemNotQueued) {
+		t.Fatalf("dead-letter removed item error = %v, want ErrItemNotQueued", err)
+	}
+	withoutKey := items[0]
+	withoutKey.bucketKey = nil
+	if err := store.Reschedule(withoutKey, store.Now()); !errors.Is(err, ErrItemNotQueued) {
+		t.Fatalf("reschedule by id error = %v, want ErrItemNotQueued", err)
+	}
+	if active, dead := countBoth(t, store); active != 0 || dead != 0 {
+		t.Fatalf("removed item resurrected: %d active, %d dead", active, dead)
+	}
+}
+
+// A crash rolls back an uncommitted bolt transaction, so the item is lost only if a committed
+// state without it is ever observable. Concurrent readers must always see exactly one copy.
+func TestRescheduleAndDeadLetterNeverExposeMissingItem(t *testing.T) {
+	store := openTestStore(t, clock.Real())
+	if err := store.Enqueue(Item{ID: "a", Entity: EntityTask, Operation: OperationCreate}); err != nil {
+		t.Fatalf("enqueue: %v", err)
+	}
+
+	stop := make(chan struct{})
+	violations := make(chan string, 1)
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for {
+			select {
+			case <-stop:
+				return
+			default:
+			}
+			_ = store.db.View(func(tx *bolt.Tx) error {
+				active := tx.Bucket(store.bucket).Stats().KeyN
+				dead := tx.Bucket(store.deadBucket).Stats().KeyN
+				if active+dead != 1 {
+					select {
+					case violations <- fmt.Sprintf("%d active, %d dead", active, dead):
+					default:
+					}
+				}
+				return nil
+			})
+		}
+	}()
+
+	for i := 0; i < 200; i++ {
+		items, err := store.GetBatch(1)
+		if err != nil || len(items) != 1 {
+			t.Fatalf("get batch %d: %v (%d items)", i, err, len(items))
+		}
+		if err := store.Reschedule(items[0], time.Time{}); err != nil {
+			t.Fatalf("reschedule %d: %v", i, err)
+		}
+	}
+	items, err := store.GetBatch(1)
+	if err != nil || len(items) != 1 {
+		t.Fatalf("get batch: %v (%d items)", err, len(items))
+	}
+	if err := store.DeadLetter(items[0]); err != nil {
+		t.Fatalf("dead-letter: %v", err)
+	}
+	close(stop)
+	wg.Wait()
+
+	select {
+	case v := <-violations:
+		t.Fatalf("reader observed a torn state: %s", v)
+	default:
+	}
+	if active, dead := countBoth(t, store); active != 0 || dead != 1 {
+		t.Fatalf("final state %d active, %d dead, want 0 and 1", active, dead)
+	}
+}
+
+func TestStoreDrainOrderIsStableForIdenticalTimestamps(t *testing.T) {
+	fake := clock.NewFake(time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC))
+	path := filepath.Join(t.TempDir(), "buffer.db")
+	store, err := Open(path, "buffer", WithClock(fake))
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+
+	// IDs sort in the reverse of insertion order, so only the sequence can keep them in line.
+	const n = 300
+	for i := 0; i < n; i++ {
+		if err := store.Enqueue(Item{ID: fmt.Sprintf("z%04d", n-i), Entity: EntityTask, Operation: OperationCreate}); err != nil {
+			t.Fatalf("enqueue %d: %v", i, err)
+		}
+	}
+	items, err := store.GetBatch(n)
+	if err != nil || len(items) != n {
+		t.Fatalf("get batch: %v (%d items)", err, len(items))
+	}
+	for i, item := range items {
+		if want := fmt.Sprintf("z%04d", n-i); item.ID != want {
+			t.Fatalf("position %d = %s, want %s", i, item.ID, want)
+		}
+	}
+	last := items[n-1].Sequence
+
+	if err := store.Close(); err != nil {
+		t.Fatalf("close: %v", err)
+	}
+	reopened, err := Open(path, "buffer", WithClock(fake))
+	if err != nil {
+		t.Fatalf("reopen: %v", err)
+	}
+	defer reopened.Close()
+	if err := reopened.Enqueue(Item{ID: "a-after-restart", Entity: EntityTask, Operation: OperationCreate}); err != nil {
+		t.Fatalf("enqueue after restart: %v", err)
+	}
+	items, err = reopened.GetBatch(n + 1)
+	if err != nil || len(items) != n+1 {
+		t.Fatalf("get batch after restart: %v (%d items)", err, len(items))
+	}
+	if tail := items[n]; tail.ID != "a-after-restart" || tail.Sequence <= last {
+		t.Fatalf("tail = %s (seq %d), want the new item after seq %d", tail.ID, tail.Sequence, last)
+	}
+}
+
+func TestEnqueueEnforcesUserQuota(t *testing.T) {
+	fake := clock.NewFake(time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC))
+	store, err := Open(filepath.Join(t.TempDir(), "buffer.db"), "buffer", WithClock(fake), WithUserQuota(2))
+	if err != nil {
+		t.Fatalf("open store: %v", err)
+	}
+	t.Cleanup(func() { _ = store.Close() })
+
+	for _, id := range []string{"a1", "a2"} {
+		if err := store.Enqueue(Item{ID: id, UserID: "alice", Entity: EntityTask}); err != nil {
+			t.Fatalf("enqueue %s within quota: %v", id, err)
+		}
+	}
+	err = store.Enqueue(Item{ID: "a3", UserID: "alice", Entity: EntityTask})
+	if !errors.Is(err, ErrUserQuotaExceeded) || !domain.IsDomainError(err, domain.ErrCodeLimitExceeded) {
+		t.Fatalf("enqueue over quota: err = %v, want ErrUserQuotaExceeded as a limit error", err)
+	}
+	var limitErr *domain.LimitError
+	if !errors.As(err, &limitErr) || limitErr.Limit != 2 || limitErr.Current != 2 {
+		t.Fatalf("enqueue over quota: details = %+v, want limit 2 and current 2", limitErr)
+	}
+	if err := store.Enqueue(Item{ID: "b1", UserID: "bob", Entity: EntityTask}); err != nil {
+		t.Fatalf("another user must keep their own quota: %v", err)
+	}
+	if err := store.Enqueue(Item{ID: "anon", Entity: EntityTask}); err != nil {
+		t.Fatalf("items without a user are not capped: %v", err)
+	}
+
+	items, err := store.GetBatch(10)
+	if err != nil {
+		t.Fatalf("get batch: %v", err)
+	}
+	var first Item
+	for _, item := range items {
+		if item.ID == "a1" {
+			first = item
+		}
+	}
+	// Rescheduling keeps the item queued and must not consume more quota.
+	if err := store.Reschedule(first, fake.Now().Add(time.Minute)); err != nil {
+		t.Fatalf("reschedule: %v", err)
+	}
+	if count, _ := store.UserCount("alice"); count != 2 {
+		t.Fatalf("alice count after reschedule = %d, want 2", count)
+	}
+
+	if err := store.Remove(Item{ID: "a1"}); err != nil {
+		t.Fatalf("remove: %v", err)
+	}
+	if err := store.Enqueue(Item{ID: "a3", UserID: "alice", Entity: EntityTask}); err != nil {
+		t.Fatalf("removing an item must free quota: %v", err)
+	}
+
+	items, _ = store.GetBatch(10)
+	for _, item := range items {
+		if item.ID == "a2" {
+			if err := store.DeadLetter(item); err != nil {
+				t.Fatalf("dead-letter: %v", err)
+			}
+		}
+	}
+	fake.Advance(time.Hour)
+	if _, err := store.Cleanup(fake.Now()); err != nil {
+		t.Fatalf("cleanup: %v", err)
+	}
+	if count, _ := store.UserCount("alice"); count != 0 {
+		t.Fatalf("alice count after dead-letter and cleanup = %d, want 0", count)
+	}
+}
+
+func TestOpenRebuildsUserCounts(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "buffer.db")
+	store, err := Open(path, "buffer")
+	if err != nil {
+		t.Fatalf("open store: %v", err)
+	}
+	for _, id := range []string{"a1", "a2"} {
+		if err := store.Enqueue(Item{ID: id, UserID: "alice"}); err != nil {
+			t.Fatalf("enqueue: %v", err)
+		}
+	}
+	// Simulate a file written before user counts were kept.
+	if err := store.db.Update(func(tx *bolt.Tx) error {
+		return tx.DeleteBucket(store.userBucket)
+	}); err != nil {
+		t.Fatalf("drop user bucket: %v", err)
+	}
+	_ = store.Close()
+
+	reopened, err := Open(path, "buffer", WithUserQuota(2))
+	if err != nil {
+		t.Fatalf("reopen: %v", err)
+	}
+	t.Cleanup(func() { _ = reopened.Close() })
+	if err := reopened.Enqueue(Item{ID: "a3", UserID: "alice"}); !errors.Is(err, ErrUserQuotaExceeded) {
+		t.Fatalf("enqueue after rebuild: err = %v, want ErrUserQuotaExceeded", err)
+	}
+}
+
+func TestCloseGracefulWaitsForActiveWrite(t *testing.T) {
+	store := openTestStore(t, clock.Real())
+	entered, release := make(chan struct{}), make(chan struct{})
+	enqueued := make(chan error, 1)
+	go func() {
+		enqueued <- store.update(func(tx *bolt.Tx) error {
+			close(entered)
+			<-release
+			return store.put(tx, Item{ID: "slow", Entity: EntityTask, Operation: OperationCreate})
+		})
+	}()
+	<-entered
+
+	closed := make(chan error, 1)
+	go func() { closed <- store.CloseGraceful(context.Background()) }()
+	select {
+	case err := <-closed:
+		t.Fatalf("CloseGraceful returned %v during an active write", err)
+	case <-time.After(50 * time.Millisecond):
+	}
+	if err := store.Enqueue(Item{ID: "late", Entity: EntityTask, Operation: OperationCreate}); !errors.Is(err, bolt.ErrDatabaseNotOpen) {
+		t.Fatalf("enqueue while closing = %v, want ErrDatabaseNotOpen", err)
+	}
+
+	close(release)
+	if err := <-enqueued; err != nil {
+		t.Fatalf("in-flight enqueue failed: %v", err)
+	}
+	if err := <-closed; err != nil {
+		t.Fatalf("close: %v", err)
+	}
+}
+
+func TestCloseGracefulGivesUpAtDeadline(t *testing.T) {
+	store := openTestStore(t, clock.Real())
+	entered, release := make(chan struct{}), make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		_ = store.update(func(*bolt.Tx) error {
+			close(entered)
+			<-release
+			return nil
+		})
+	}()
+	<-entered
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := store.CloseGraceful(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("close = %v, want the deadline error", err)
+	}
+	close(release)
+	<-done
+}
+
+func TestEnqueueEnforcesMaxSize(t *testing.T) {
+	fake := clock.NewFake(time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC))
+	path := filepath.Join(t.TempDir(), "buffer.db")
+	store, err := Open(path, "buffer", WithClock(fake), WithMaxSize(2))
+	if err != nil {
+		t.Fatalf("open store: %v", err)
+	}
+
+	for _, item := range []Item{{ID: "a1", UserID: "alice"}, {ID: "anon"}} {
+		item.Entity = EntityTask
+		if err := store.Enqueue(item); err != nil {
+			t.Fatalf("enqueue %s within the cap: %v", item.ID, err)
+		}
+	}
+	if err := store.Enqueue(Item{ID: "b1", UserID: "bob", Entity: EntityTask}); !errors.Is(err, ErrBufferFull) {
+		t.Fatalf("enqueue over the cap: err = %v, want ErrBufferFull", err)
+	}
+
+	items, _ := store.GetBatch(10)
+	if err := store.DeadLetter(items[0]); err != nil {
+		t.Fatalf("dead-letter: %v", err)
+	}
+	if err := store.Enqueue(Item{ID: "b1", UserID: "bob", Entity: EntityTask}); err != nil {
+		t.Fatalf("dead letters must not count against the cap: %v", err)
+	}
+	if err := store.Close(); err != nil {
+		t.Fatalf("close: %v", err)
+	}
+
+	reopened, err := Open(path, "buffer", WithMaxSize(2))
+	if err != nil {
+		t.Fatalf("reopen: %v", err)
+	}
+	t.Cleanup(func() { _ = reopened.Close() })
+	if err := reopened.Enqueue(Item{ID: "c1", Entity: EntityTask}); !errors.Is(err, ErrBufferFull) {
+		t.Fatalf("the total must survive a restart: err = %v", err)
+	}
+}
+
+func TestImportSkipsQueuedOpsAndEnforcesLimits(t *testing.T) {
+	fake := clock.NewFake(time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC))
+	store, err := Open(filepath.Join(t.TempDir(), "buffer.db"), "buffer", WithClock(fake), WithUserQuota(2), WithMaxSize(3))
+	if err != nil {
+		t.Fatalf("open store: %v", err)
+	}
+	t.Cleanup(func() { _ = store.Close() })
+
+	create := Item{ID: "t1", UserID: "alice", Entity: EntityTask, Operation: OperationCreate, Timestamp: fake.Now()}
+	update := Item{ID: "t1", UserID: "alice", Entity: EntityTask, Operation: OperationUpdate, Timestamp: fake.Now().Add(time.Millisecond)}
+	if err := store.Enqueue(create); err != nil {
+		t.Fatalf("enqueue: %v", err)
+	}
+	imported, err := store.Import([]Item{create, update})
+	if err != nil || imported != 1 {
+		t.Fatalf("import = %d, %v; want the update of the same ID imported and the queued create skipped", imported, err)
+	}
+
+	if _, err := store.Import([]Item{{ID: "a3", UserID: "alice", Entity: EntityTask}}); !errors.Is(err, ErrUserQuotaExceeded) {
+		t.Fatalf("import over quota: err = %v, want ErrUserQuotaExceeded", err)
+	}
+	imported, err = store.Import([]Item{{ID: "b1", UserID: "bob", Entity: EntityTask}, {ID: "b2", UserID: "bob", Entity: EntityTask}})
+	if !errors.Is(err, ErrBufferFull) || imported != 0 {
+		t.Fatalf("import over the cap = %d, %v; want ErrBufferFull and nothing imported", imported, err)
+	}
+	if count, _ := store.UserCount("bob"); count != 0 {
+		t.Fatalf("bob has %d queued items, want the failed batch rolled back", count)
+	}
+}
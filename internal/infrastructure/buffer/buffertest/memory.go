@@ -0,0 +1,169 @@
+// Package buffertest provides an in-memory buffer store for exercising the processor without BoltDB.
+package buffertest
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/fastygo/backend/internal/infrastructure/buffer"
+	"github.com/fastygo/backend/pkg/clock"
+)
+
+// MemoryStore mirrors buffer.Store semantics (priority, timestamp, then sequence ordering, deferred
+// NextAttempt, dead-letter bucket) entirely in memory.
+type MemoryStore struct {
+	mu    sync.Mutex
+	clock clock.Clock
+	items []buffer.Item
+	dead  []buffer.Item
+	seq   uint64
+}
+
+// NewMemoryStore creates an empty store using c as its time source (the real clock when nil).
+func NewMemoryStore(c clock.Clock) *MemoryStore {
+	if c == nil {
+		c = clock.Real()
+	}
+	return &MemoryStore{clock: c}
+}
+
+func (m *MemoryStore) Now() time.Time {
+	return m.clock.Now()
+}
+
+func (m *MemoryStore) Enqueue(item buffer.Item) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.items = append(m.items, m.normalize(item))
+	m.sort()
+	return nil
+}
+
+func (m *MemoryStore) GetBatch(limit int) ([]buffer.Item, error) {
+	return m.batch(limit, "")
+}
+
+func (m *MemoryStore) GetEntityBatch(entity string, limit int) ([]buffer.Item, error) {
+	return m.batch(limit, entity)
+}
+
+func (m *MemoryStore) batch(limit int, entity string) ([]buffer.Item, error) {
+	if limit <= 0 {
+		limit = 50
+	}
+	now := m.clock.Now()
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var batch []buffer.Item
+	for _, item := range m.items {
+		if len(batch) >= limit {
+			break
+		}
+		if !item.IsReady(now) || (entity != "" && item.Entity != entity) {
+			continue
+		}
+		batch = append(batch, item)
+	}
+	return batch, nil
+}
+
+func (m *MemoryStore) Remove(item buffer.Item) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.remove(item.ID)
+	return nil
+}
+
+func (m *MemoryStore) Requeue(item buffer.Item) error {
+	item.Timestamp = m.clock.Now()
+	return m.Enqueue(item)
+}
+
+func (m *MemoryStore) Reschedule(item buffer.Item, nextAttempt time.Time) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if !m.remove(item.ID) {
+		return buffer.ErrItemNotQueued
+	}
+	item.NextAttempt = nextAttempt
+	item.Timestamp = m.clock.Now()
+	m.items = append(m.items, m.normalize(item))
+	m.sort()
+	return nil
+}
+
+func (m *MemoryStore) DeadLetter(item buffer.Item) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if !m.remove(item.ID) {
+		return buffer.ErrItemNotQueued
+	}
+	m.dead = append(m.dead, item)
+	return nil
+}
+
+func (m *MemoryStore) Size() (int, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return len(m.items), nil
+}
+
+// Cleanup drops active items first enqueued before olderThan; dead letters are kept.
+func (m *MemoryStore) Cleanup(olderThan time.Time) (int, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	kept := m.items[:0]
+	for _, item := range m.items {
+		if !item.FirstEnqueued().Before(olderThan) {
+			kept = append(kept, item)
+		}
+	}
+	purged := len(m.items) - len(kept)
+	m.items = kept
+	return purged, nil
+}
+
+// Items returns a snapshot of the active items in drain order.
+func (m *MemoryStore) Items() []buffer.Item {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]buffer.Item(nil), m.items...)
+}
+
+// DeadLettered returns a snapshot of the dead-lettered items.
+func (m *MemoryStore) DeadLettered() []buffer.Item {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]buffer.Item(nil), m.dead...)
+}
+
+func (m *MemoryStore) normalize(item buffer.Item) buffer.Item {
+	item.Normalize(m.clock.Now())
+	m.seq++
+	item.Sequence = m.seq
+	return item
+}
+
+func (m *MemoryStore) remove(id string) bool {
+	for i, existing := range m.items {
+		if existing.ID == id {
+			m.items = append(m.items[:i], m.items[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+func (m *MemoryStore) sort() {
+	sort.SliceStable(m.items, func(i, j int) bool {
+		a, b := m.items[i], m.items[j]
+		if a.Priority != b.Priority {
+			return a.Priority < b.Priority
+		}
+		if !a.Timestamp.Equal(b.Timestamp) {
+			return a.Timestamp.Before(b.Timestamp)
+		}
+		return a.Sequence < b.Sequence
+	})
+}
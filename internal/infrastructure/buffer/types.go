@@ -5,6 +5,8 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+
+	"github.com/fastygo/backend/internal/retry"
 )
 
 const (
@@ -26,11 +28,21 @@ type Item struct {
 	Priority  int             `json:"priority"`
 	Retries   int             `json:"retries"`
 	Timestamp time.Time       `json:"timestamp"`
+	// EnqueuedAt records when the operation was first buffered; unlike Timestamp it survives requeues.
+	EnqueuedAt time.Time `json:"enqueued_at,omitempty"`
+	// NextAttempt defers the item until the given time; zero means it is ready immediately.
+	NextAttempt time.Time `json:"next_attempt,omitempty"`
+	// Sequence is assigned by the store on every insert and breaks ties between equal timestamps.
+	Sequence uint64 `json:"sequence,omitempty"`
+	// CallbackURL, when set, is POSTed the final outcome once the item is applied or dead-lettered.
+	CallbackURL string `json:"callback_url,omitempty"`
 
 	bucketKey []byte
 }
 
-func (i *Item) normalize() {
+// Normalize fills the defaults every store applies on insert: a generated ID, the default priority,
+// and Timestamp/EnqueuedAt set to now when unset.
+func (i *Item) Normalize(now time.Time) {
 	if i.ID == "" {
 		i.ID = uuid.NewString()
 	}
@@ -38,6 +50,41 @@ func (i *Item) normalize() {
 		i.Priority = 3
 	}
 	if i.Timestamp.IsZero() {
-		i.Timestamp = time.Now()
+		i.Timestamp = now
+	}
+	if i.EnqueuedAt.IsZero() {
+		i.EnqueuedAt = i.Timestamp
+	}
+}
+
+// IsReady reports whether the item is due for another attempt.
+func (i Item) IsReady(now time.Time) bool {
+	return !i.NextAttempt.After(now)
+}
+
+// ShouldDeadLetter reports whether the item exhausted its retries or has been buffered longer than maxAge.
+// Zero limits are ignored.
+func (i Item) ShouldDeadLetter(now time.Time, maxRetries int, maxAge time.Duration) bool {
+	return retry.Policy{MaxAttempts: maxRetries, MaxAge: maxAge}.Exhausted(i.Retries, i.Age(now))
+}
+
+// MarkAttemptFailed records a failed attempt and defers the next one by backoffBase doubled per
+// retry, capped at retry.DefaultMaxBackoff.
+func (i *Item) MarkAttemptFailed(now time.Time, backoffBase time.Duration) {
+	i.Retries++
+	i.NextAttempt = now.Add(retry.Policy{Backoff: backoffBase}.Delay(i.Retries))
+}
+
+// Age reports how long the item has been buffered relative to now.
+func (i Item) Age(now time.Time) time.Duration {
+	return now.Sub(i.FirstEnqueued())
+}
+
+// FirstEnqueued is when the operation was first buffered, falling back to Timestamp for items
+// written before EnqueuedAt existed.
+func (i Item) FirstEnqueued() time.Time {
+	if i.EnqueuedAt.IsZero() {
+		return i.Timestamp
 	}
+	return i.EnqueuedAt
 }
@@ -0,0 +1,77 @@
+package buffer_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/fastygo/backend/internal/infrastructure/buffer"
+)
+
+var start = time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)
+
+func TestMarkAttemptFailedDoublesBackoffUpToCap(t *testing.T) {
+	item := buffer.Item{ID: "a"}
+	want := []time.Duration{time.Minute, 2 * time.Minute, 4 * time.Minute, 8 * time.Minute}
+	for i, delay := range want {
+		item.MarkAttemptFailed(start, time.Minute)
+		if item.Retries != i+1 {
+			t.Fatalf("retries = %d, want %d", item.Retries, i+1)
+		}
+		if got := item.NextAttempt.Sub(start); got != delay {
+			t.Fatalf("attempt %d delay = %v, want %v", i+1, got, delay)
+		}
+	}
+
+	for i := 0; i < 20; i++ {
+		item.MarkAttemptFailed(start, time.Minute)
+	}
+	if got := item.NextAttempt.Sub(start); got != time.Hour {
+		t.Fatalf("capped delay = %v, want 1h", got)
+	}
+
+	noBackoff := buffer.Item{ID: "b"}
+	noBackoff.MarkAttemptFailed(start, 0)
+	if !noBackoff.NextAttempt.Equal(start) || noBackoff.Retries != 1 {
+		t.Fatalf("item = %+v, want an immediate retry without backoff", noBackoff)
+	}
+}
+
+func TestIsReady(t *testing.T) {
+	tests := []struct {
+		name        string
+		nextAttempt time.Time
+		want        bool
+	}{
+		{name: "never deferred", want: true},
+		{name: "due now", nextAttempt: start, want: true},
+		{name: "overdue", nextAttempt: start.Add(-time.Second), want: true},
+		{name: "deferred", nextAttempt: start.Add(time.Second), want: false},
+	}
+	for _, tt := range tests {
+		item := buffer.Item{NextAttempt: tt.nextAttempt}
+		if got := item.IsReady(start); got != tt.want {
+			t.Errorf("%s: IsReady = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestShouldDeadLetter(t *testing.T) {
+	tests := []struct {
+		name       string
+		item       buffer.Item
+		maxRetries int
+		maxAge     time.Duration
+		want       bool
+	}{
+		{name: "fresh", item: buffer.Item{EnqueuedAt: start}, maxRetries: 3, maxAge: time.Hour},
+		{name: "retries exhausted", item: buffer.Item{EnqueuedAt: start, Retries: 3}, maxRetries: 3, maxAge: time.Hour, want: true},
+		{name: "too old", item: buffer.Item{EnqueuedAt: start.Add(-2 * time.Hour)}, maxRetries: 3, maxAge: time.Hour, want: true},
+		{name: "age falls back to timestamp", item: buffer.Item{Timestamp: start.Add(-2 * time.Hour)}, maxAge: time.Hour, want: true},
+		{name: "zero limits disabled", item: buffer.Item{EnqueuedAt: start.Add(-48 * time.Hour), Retries: 100}},
+	}
+	for _, tt := range tests {
+		if got := tt.item.ShouldDeadLetter(start, tt.maxRetries, tt.maxAge); got != tt.want {
+			t.Errorf("%s: ShouldDeadLetter = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
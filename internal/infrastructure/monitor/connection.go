@@ -2,6 +2,8 @@ package monitor
 
 import (
 	"context"
+	"math/rand/v2"
+	"strconv"
 	"sync"
 	"time"
 
@@ -10,43 +12,120 @@ import (
 	"go.uber.org/zap"
 
 	"github.com/fastygo/backend/internal/infrastructure/buffer"
+	"github.com/fastygo/backend/internal/notify"
 )
 
 type Monitor struct {
-	pg     *pgxpool.Pool
-	redis  *redislib.Client
-	buffer *buffer.Store
+	// pingPostgres and pingRedis are nil when the dependency is not configured.
+	pingPostgres func(ctx context.Context) error
+	pingRedis    func(ctx context.Context) error
+	buffer       *buffer.Store
 
 	status   Status
 	mu       sync.RWMutex
 	interval time.Duration
+	jitter   time.Duration
+	beat     func()
 	stopCh   chan struct{}
 	logger   *zap.Logger
+
+	// alerts is nil unless WithBufferAlerts is set. degraded is only touched by refresh, which
+	// never runs concurrently with itself.
+	alerts    notify.Notifier
+	highWater int
+	lowWater  int
+	degraded  bool
+
+	// ctx parents every health check so Stop can abort a Ping that is still in flight.
+	ctx      context.Context
+	cancel   context.CancelFunc
+	stopOnce sync.Once
+	wg       sync.WaitGroup
+}
+
+// Option customizes the monitor.
+type Option func(*Monitor)
+
+// WithJitter spreads checks out across replicas: every tick after the first adds a random delay in
+// [0, jitter) on top of the interval. The first check always runs synchronously in Start.
+func WithJitter(jitter time.Duration) Option {
+	return func(m *Monitor) {
+		if jitter > 0 {
+			m.jitter = jitter
+		}
+	}
 }
 
-func New(pg *pgxpool.Pool, redis *redislib.Client, buf *buffer.Store, interval time.Duration, logger *zap.Logger) *Monitor {
+// WithHeartbeat calls beat after every completed check, so a watchdog notices a stuck monitor.
+func WithHeartbeat(beat func()) Option {
+	return func(m *Monitor) {
+		m.beat = beat
+	}
+}
+
+// WithBufferAlerts notifies once when the buffer reaches highWater items and once more when it
+// drains below lowWater. The gap between the marks keeps a backlog hovering around a single
+// threshold from flapping. A non-positive highWater disables the alerts.
+func WithBufferAlerts(notifier notify.Notifier, highWater, lowWater int) Option {
+	return func(m *Monitor) {
+		if notifier == nil || highWater <= 0 {
+			return
+		}
+		if lowWater <= 0 || lowWater > highWater {
+			lowWater = highWater
+		}
+		m.alerts, m.highWater, m.lowWater = notifier, highWater, lowWater
+	}
+}
+
+func New(pg *pgxpool.Pool, redis *redislib.Client, buf *buffer.Store, interval time.Duration, logger *zap.Logger, opts ...Option) *Monitor {
 	if interval <= 0 {
 		interval = 10 * time.Second
 	}
 	if logger == nil {
 		logger = zap.NewNop()
 	}
-	return &Monitor{
-		pg:       pg,
-		redis:    redis,
+	ctx, cancel := context.WithCancel(context.Background())
+	m := &Monitor{
 		buffer:   buf,
 		interval: interval,
 		stopCh:   make(chan struct{}),
 		logger:   logger,
+		ctx:      ctx,
+		cancel:   cancel,
+	}
+	if pg != nil {
+		m.pingPostgres = pg.Ping
+	}
+	if redis != nil {
+		m.pingRedis = func(ctx context.Context) error {
+			return redis.Ping(ctx).Err()
+		}
 	}
+	for _, opt := range opts {
+		opt(m)
+	}
+	return m
 }
 
+// Start records an initial status before returning, so dependents never observe the service as
+// offline merely because the first check has not run yet, then keeps refreshing in the background.
 func (m *Monitor) Start() {
-	go m.loop()
+	m.safeRefresh()
+	m.wg.Add(1)
+	go func() {
+		defer m.wg.Done()
+		m.loop()
+	}()
 }
 
+// Stop cancels any in-flight checks and waits for the monitor loop to exit. It is safe to call more than once.
 func (m *Monitor) Stop() {
-	close(m.stopCh)
+	m.stopOnce.Do(func() {
+		m.cancel()
+		close(m.stopCh)
+	})
+	m.wg.Wait()
 }
 
 func (m *Monitor) IsOnline() bool {
@@ -62,51 +141,91 @@ func (m *Monitor) GetStatus() Status {
 }
 
 func (m *Monitor) loop() {
-	ticker := time.NewTicker(m.interval)
-	defer ticker.Stop()
+	timer := time.NewTimer(m.nextDelay())
+	defer timer.Stop()
 
-	m.refresh()
 	for {
 		select {
-		case <-ticker.C:
-			m.refresh()
+		case <-timer.C:
+			m.safeRefresh()
+			timer.Reset(m.nextDelay())
 		case <-m.stopCh:
 			return
 		}
 	}
 }
 
+func (m *Monitor) nextDelay() time.Duration {
+	return m.interval + m.randomJitter()
+}
+
+func (m *Monitor) randomJitter() time.Duration {
+	if m.jitter <= 0 {
+		return 0
+	}
+	return time.Duration(rand.Int64N(int64(m.jitter)))
+}
+
+// safeRefresh runs refresh with panics (e.g. from a driver) recovered and logged, so the loop
+// checks again on the next tick instead of dying and freezing the last status.
+func (m *Monitor) safeRefresh() {
+	defer func() {
+		if r := recover(); r != nil {
+			m.logger.Error("health check panicked", zap.Any("panic", r), zap.Stack("stack"))
+		}
+	}()
+	m.refresh()
+}
+
 func (m *Monitor) refresh() {
 	bufferOK, bufferSize := m.checkBuffer()
 	status := Status{
-		PostgreSQL: m.checkPostgres(),
-		Redis:      m.checkRedis(),
-		Buffer:     bufferOK,
-		BufferSize: bufferSize,
-		LastCheck:  time.Now(),
+		PostgreSQL:     m.checkPostgres(),
+		Redis:          m.checkRedis(),
+		Buffer:         bufferOK,
+		BufferSize:     bufferSize,
+		DeadLetterSize: m.checkDeadLetters(),
+		LastCheck:      time.Now(),
+	}
+	if m.ctx.Err() != nil {
+		// Checks were aborted by Stop; their failures say nothing about dependency health.
+		return
 	}
 
 	m.mu.Lock()
+	previous := m.status.DeadLetterSize
 	m.status = status
 	m.mu.Unlock()
+	if m.beat != nil {
+		m.beat()
+	}
+
+	if bufferOK {
+		m.trackBufferLevel(status.BufferSize)
+	}
+	if status.DeadLetterSize > previous {
+		m.logger.Warn("buffer dead-letter queue grew",
+			zap.Int("dead_letter_size", status.DeadLetterSize),
+			zap.Int("new_items", status.DeadLetterSize-previous))
+	}
 }
 
 func (m *Monitor) checkPostgres() bool {
-	if m.pg == nil {
+	if m.pingPostgres == nil {
 		return false
 	}
-	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	ctx, cancel := context.WithTimeout(m.ctx, 3*time.Second)
 	defer cancel()
-	return m.pg.Ping(ctx) == nil
+	return m.pingPostgres(ctx) == nil
 }
 
 func (m *Monitor) checkRedis() bool {
-	if m.redis == nil {
+	if m.pingRedis == nil {
 		return false
 	}
-	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	ctx, cancel := context.WithTimeout(m.ctx, 2*time.Second)
 	defer cancel()
-	return m.redis.Ping(ctx).Err() == nil
+	return m.pingRedis(ctx) == nil
 }
 
 func (m *Monitor) checkBuffer() (bool, int) {
@@ -120,3 +239,57 @@ func (m *Monitor) checkBuffer() (bool, int) {
 	}
 	return true, size
 }
+
+func (m *Monitor) checkDeadLetters() int {
+	if m.buffer == nil {
+		return 0
+	}
+	size, err := m.buffer.DeadLetterSize()
+	if err != nil {
+		m.logger.Warn("dead-letter size check failed", zap.Error(err))
+	}
+	return size
+}
+
+// trackBufferLevel alerts on transitions between the healthy and degraded states only, so a
+// backlog that stays large produces one notification rather than one per tick.
+func (m *Monitor) trackBufferLevel(size int) {
+	if m.alerts == nil {
+		return
+	}
+	switch {
+	case !m.degraded && size >= m.highWater:
+		m.degraded = true
+		m.alert(notify.EventBufferDegraded, "buffer backlog crossed the high-water mark", size, m.highWater)
+	case m.degraded && size < m.lowWater:
+		m.degraded = false
+		m.alert(notify.EventBufferRecovered, "buffer backlog drained below the low-water mark", size, m.lowWater)
+	}
+}
+
+// alertTimeout bounds a single alert delivery.
+const alertTimeout = 10 * time.Second
+
+// alert delivers in the background so a slow channel never delays the next health check. Stop
+// cancels deliveries still in flight and waits for them to return.
+func (m *Monitor) alert(event, message string, size, threshold int) {
+	n := notify.Notification{
+		Event:   event,
+		Message: message,
+		Fields: map[string]string{
+			"buffer_size": strconv.Itoa(size),
+			"threshold":   strconv.Itoa(threshold),
+		},
+		Time: time.Now(),
+	}
+	m.logger.Warn(message, zap.Int("buffer_size", size), zap.Int("threshold", threshold))
+	m.wg.Add(1)
+	go func() {
+		defer m.wg.Done()
+		ctx, cancel := context.WithTimeout(m.ctx, alertTimeout)
+		defer cancel()
+		if err := m.alerts.Notify(ctx, n); err != nil {
+			m.logger.Warn("buffer alert failed", zap.String("event", event), zap.Error(err))
+		}
+	}()
+}
@@ -0,0 +1,152 @@
+package monitor
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/fastygo/backend/internal/notify"
+)
+
+func TestStartRecordsStatusBeforeReturning(t *testing.T) {
+	m := New(nil, nil, nil, time.Hour, nil, WithJitter(time.Hour))
+	m.pingPostgres = func(context.Context) error { return nil }
+	m.pingRedis = func(context.Context) error { return nil }
+
+	m.Start()
+	defer m.Stop()
+
+	if !m.IsOnline() || m.GetStatus().LastCheck.IsZero() {
+		t.Fatalf("status = %+v, want an initial check regardless of jitter", m.GetStatus())
+	}
+}
+
+func TestNextDelayStaysWithinJitterWindow(t *testing.T) {
+	interval, jitter := 10*time.Second, 2*time.Second
+	m := New(nil, nil, nil, interval, nil, WithJitter(jitter))
+
+	for i := 0; i < 1000; i++ {
+		if delay := m.nextDelay(); delay < interval || delay >= interval+jitter {
+			t.Fatalf("delay = %v, want within [%v, %v)", delay, interval, interval+jitter)
+		}
+	}
+
+	unjittered := New(nil, nil, nil, interval, nil)
+	if delay := unjittered.nextDelay(); delay != interval {
+		t.Fatalf("delay without jitter = %v, want %v", delay, interval)
+	}
+}
+
+func TestStopAbortsBlockedPing(t *testing.T) {
+	m := New(nil, nil, nil, time.Millisecond, nil)
+	var calls atomic.Int32
+	entered := make(chan struct{})
+	m.pingPostgres = func(ctx context.Context) error {
+		if calls.Add(1) == 1 {
+			return nil
+		}
+		close(entered)
+		<-ctx.Done()
+		return ctx.Err()
+	}
+	m.pingRedis = func(context.Context) error { return nil }
+
+	m.Start()
+	initial := m.GetStatus()
+	<-entered
+
+	stopped := make(chan struct{})
+	go func() {
+		m.Stop()
+		close(stopped)
+	}()
+	select {
+	case <-stopped:
+	case <-time.After(500 * time.Millisecond):
+		t.Fatal("Stop did not return while a Ping was blocked")
+	}
+
+	if status := m.GetStatus(); status != initial {
+		t.Fatalf("status = %+v, an aborted check must not replace %+v", status, initial)
+	}
+}
+
+func TestLoopSurvivesPanickingCheck(t *testing.T) {
+	m := New(nil, nil, nil, time.Millisecond, nil)
+	var calls atomic.Int32
+	m.pingPostgres = func(context.Context) error {
+		if calls.Add(1) <= 3 {
+			panic("driver bug")
+		}
+		return nil
+	}
+	m.pingRedis = func(context.Context) error { return nil }
+
+	m.Start()
+	defer m.Stop()
+
+	deadline := time.Now().Add(time.Second)
+	for !m.IsOnline() {
+		if time.Now().After(deadline) {
+			t.Fatalf("monitor stopped checking after a panic (%d calls)", calls.Load())
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+type recordingNotifier struct {
+	mu     sync.Mutex
+	events []string
+}
+
+func (n *recordingNotifier) Notify(_ context.Context, notification notify.Notification) error {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.events = append(n.events, notification.Event)
+	return nil
+}
+
+func TestBufferAlertsUseHysteresis(t *testing.T) {
+	notifier := &recordingNotifier{}
+	m := New(nil, nil, nil, time.Hour, nil, WithBufferAlerts(notifier, 100, 20))
+
+	// Climb past the high-water mark, hover between the marks, then drain below the low one.
+	for _, size := range []int{0, 50, 100, 150, 99, 100, 60, 20, 19, 5, 50, 99} {
+		m.trackBufferLevel(size)
+	}
+	m.Stop()
+
+	// Deliveries run in the background, so only the count of each event is deterministic.
+	counts := map[string]int{}
+	for _, event := range notifier.events {
+		counts[event]++
+	}
+	if len(notifier.events) != 2 || counts[notify.EventBufferDegraded] != 1 || counts[notify.EventBufferRecovered] != 1 {
+		t.Fatalf("events = %v, want one degraded and one recovered", notifier.events)
+	}
+}
+
+type blockingNotifier struct{}
+
+func (blockingNotifier) Notify(ctx context.Context, _ notify.Notification) error {
+	<-ctx.Done()
+	return ctx.Err()
+}
+
+func TestStopCancelsSlowAlertDelivery(t *testing.T) {
+	m := New(nil, nil, nil, time.Hour, nil, WithBufferAlerts(blockingNotifier{}, 1, 0))
+	m.trackBufferLevel(1)
+
+	stopped := make(chan struct{})
+	go func() {
+		m.Stop()
+		close(stopped)
+	}()
+	select {
+	case <-stopped:
+	case <-time.After(time.Second):
+		t.Fatal("Stop waited for a blocked alert delivery")
+	}
+}
@@ -44,6 +44,14 @@ func NewPool(ctx context.Context, cfg config.DatabaseConfig, logger *zap.Logger)
 	if cfg.MaxConnLifetime > 0 {
 		pgxCfg.MaxConnLifetime = cfg.MaxConnLifetime
 	}
+	// statement_timeout bounds every query on the server even if a caller's context never expires.
+	pgxCfg.ConnConfig.RuntimeParams["statement_timeout"] = statementTimeoutParam(cfg.StatementTimeout)
+	pgxCfg.PrepareConn = prepareStatementTimeout(cfg.StatementTimeout)
+	tracer := spanTracer{}
+	if cfg.SlowQueryLog {
+		tracer.next = newSlowQueryTracer(cfg.SlowQueryThreshold, logger, nil)
+	}
+	pgxCfg.ConnConfig.Tracer = tracer
 
 	pool, err := pgxpool.NewWithConfig(ctx, pgxCfg)
 	if err != nil {
@@ -59,9 +67,48 @@ func NewPool(ctx context.Context, cfg config.DatabaseConfig, logger *zap.Logger)
 	}
 
 	logger.Info("connected to postgres", zap.String("host", cfg.Host), zap.String("db", cfg.Name))
+
+	if cfg.WarmUp {
+		warmCtx, warmCancel := context.WithTimeout(ctx, 10*time.Second)
+		defer warmCancel()
+		warmed := warmUp(warmCtx, acquireFrom(pool), int(pgxCfg.MinConns))
+		logger.Info("postgres pool warmed up", zap.Int("connections", warmed), zap.Int32("target", pgxCfg.MinConns))
+	}
 	return pool, nil
 }
 
+// acquireFunc checks out one pooled connection and returns the function that gives it back.
+type acquireFunc func(ctx context.Context) (release func(), err error)
+
+func acquireFrom(pool *pgxpool.Pool) acquireFunc {
+	return func(ctx context.Context) (func(), error) {
+		conn, err := pool.Acquire(ctx)
+		if err != nil {
+			return nil, err
+		}
+		return conn.Release, nil
+	}
+}
+
+// warmUp establishes up to target connections by holding them simultaneously, then returns them to the pool.
+func warmUp(ctx context.Context, acquire acquireFunc, target int) int {
+	if target <= 0 {
+		return 0
+	}
+	releases := make([]func(), 0, target)
+	for len(releases) < target {
+		release, err := acquire(ctx)
+		if err != nil {
+			break
+		}
+		releases = append(releases, release)
+	}
+	for _, release := range releases {
+		release()
+	}
+	return len(releases)
+}
+
 // Close releases the pool and logs the result.
 func Close(pool *pgxpool.Pool, logger *zap.Logger) {
 	if pool == nil {
@@ -0,0 +1,59 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+// fakePool dials a new connection whenever no idle one is available, like pgxpool.
+type fakePool struct {
+	idle, open, inUse int
+	failAfter         int
+}
+
+func (p *fakePool) acquire(ctx context.Context) (func(), error) {
+	if p.failAfter > 0 && p.inUse >= p.failAfter {
+		return nil, errors.New("too many connections")
+	}
+	if p.idle > 0 {
+		p.idle--
+	} else {
+		p.open++
+	}
+	p.inUse++
+	return func() {
+		p.inUse--
+		p.idle++
+	}, nil
+}
+
+func TestWarmUpCreatesConnectionsUpFront(t *testing.T) {
+	pool := &fakePool{}
+
+	if warmed := warmUp(context.Background(), pool.acquire, 4); warmed != 4 {
+		t.Fatalf("warmed = %d, want 4", warmed)
+	}
+	if pool.open != 4 || pool.idle != 4 || pool.inUse != 0 {
+		t.Fatalf("pool = %+v, want 4 open idle connections and none checked out", pool)
+	}
+}
+
+func TestWarmUpStopsAtFirstFailure(t *testing.T) {
+	pool := &fakePool{failAfter: 2}
+
+	if warmed := warmUp(context.Background(), pool.acquire, 5); warmed != 2 {
+		t.Fatalf("warmed = %d, want 2", warmed)
+	}
+	if pool.inUse != 0 {
+		t.Fatalf("pool = %+v, every acquired connection must be released", pool)
+	}
+}
+
+func TestWarmUpDisabledForZeroTarget(t *testing.T) {
+	pool := &fakePool{}
+
+	if warmed := warmUp(context.Background(), pool.acquire, 0); warmed != 0 || pool.open != 0 {
+		t.Fatalf("warmed = %d, pool = %+v, want nothing dialed", warmed, pool)
+	}
+}
@@ -3,12 +3,14 @@ package postgres
 import (
 	"database/sql"
 	"fmt"
+	"os"
 	"path/filepath"
 
 	_ "github.com/lib/pq"
 
 	"github.com/golang-migrate/migrate/v4"
 	"github.com/golang-migrate/migrate/v4/database/postgres"
+	"github.com/golang-migrate/migrate/v4/source"
 	"go.uber.org/zap"
 
 	"github.com/fastygo/backend/internal/config"
@@ -23,6 +25,15 @@ func RunMigrations(cfg *config.Config, logger *zap.Logger) error {
 		logger = zap.NewNop()
 	}
 
+	count, err := countMigrations(cfg.Migrations.Path)
+	if err != nil {
+		return err
+	}
+	if count == 0 {
+		logger.Warn("no migration files found, skipping migrations", zap.String("path", cfg.Migrations.Path))
+		return nil
+	}
+
 	dsn := cfg.Database.URL
 	if dsn == "" {
 		dsn = fmt.Sprintf(
@@ -65,3 +76,22 @@ func RunMigrations(cfg *config.Config, logger *zap.Logger) error {
 	logger.Info("database migrations applied")
 	return nil
 }
+
+// countMigrations checks that path is a readable directory and counts the files in it that
+// golang-migrate recognizes, so a bad MIGRATIONS_PATH fails before any database work.
+func countMigrations(path string) (int, error) {
+	entries, err := os.ReadDir(path)
+	if err != nil {
+		return 0, fmt.Errorf("migrations path %q (MIGRATIONS_PATH): %w", path, err)
+	}
+	count := 0
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		if _, err := source.DefaultParse(entry.Name()); err == nil {
+			count++
+		}
+	}
+	return count, nil
+}
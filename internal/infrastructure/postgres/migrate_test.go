@@ -0,0 +1,62 @@
+package postgres
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+
+	"github.com/fastygo/backend/internal/config"
+)
+
+// migrationsConfig points at a database that is never reached: both cases must stop before connecting.
+func migrationsConfig(path string) *config.Config {
+	return &config.Config{
+		Database:   config.DatabaseConfig{URL: "postgres://nobody@127.0.0.1:1/none?sslmode=disable"},
+		Migrations: config.MigrationsConfig{Enabled: true, Path: path},
+	}
+}
+
+func TestRunMigrationsRejectsMissingPath(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "missing")
+	err := RunMigrations(migrationsConfig(path), zap.NewNop())
+	if !errors.Is(err, fs.ErrNotExist) {
+		t.Fatalf("err = %v, want fs.ErrNotExist", err)
+	}
+	if !strings.Contains(err.Error(), "MIGRATIONS_PATH") || !strings.Contains(err.Error(), path) {
+		t.Fatalf("err = %q, want it to name MIGRATIONS_PATH and the path", err)
+	}
+}
+
+func TestRunMigrationsSkipsEmptyDirectory(t *testing.T) {
+	dir := t.TempDir()
+	// Files golang-migrate would not load do not count as migrations.
+	if err := os.WriteFile(filepath.Join(dir, "README.md"), []byte("notes"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	core, logs := observer.New(zap.WarnLevel)
+
+	if err := RunMigrations(migrationsConfig(dir), zap.New(core)); err != nil {
+		t.Fatalf("err = %v, want a clean skip", err)
+	}
+	if logs.FilterMessage("no migration files found, skipping migrations").Len() != 1 {
+		t.Fatalf("want one skip warning, got %v", logs.All())
+	}
+}
+
+func TestCountMigrationsIgnoresUnrelatedFiles(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"1_init.up.sql", "1_init.down.sql", "2_tasks.up.sql", "notes.txt"} {
+		if err := os.WriteFile(filepath.Join(dir, name), nil, 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if got, err := countMigrations(dir); err != nil || got != 3 {
+		t.Fatalf("countMigrations = %d, %v; want 3", got, err)
+	}
+}
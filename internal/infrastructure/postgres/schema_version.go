@@ -0,0 +1,85 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+
+	"github.com/fastygo/backend/pkg/clock"
+)
+
+// ErrNoSchemaVersion means no migration has been recorded: schema_migrations is missing or empty.
+var ErrNoSchemaVersion = errors.New("no schema version recorded")
+
+// SchemaVersion is the migration state golang-migrate records in schema_migrations.
+type SchemaVersion struct {
+	Version uint `json:"version"`
+	// Dirty means a migration failed partway and the schema needs manual repair before the next run.
+	Dirty bool `json:"dirty"`
+}
+
+type rowQuerier interface {
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+}
+
+// SchemaVersionReader reads the applied migration version, caching it for ttl so frequent version
+// probes do not each cost a query. Failed reads are not cached.
+type SchemaVersionReader struct {
+	db    rowQuerier
+	ttl   time.Duration
+	clock clock.Clock
+
+	mu      sync.Mutex
+	version SchemaVersion
+	err     error
+	readAt  time.Time
+	cached  bool
+}
+
+// NewSchemaVersionReader returns a reader over db, typically the application pool. A ttl of zero
+// reads the table on every call.
+func NewSchemaVersionReader(db rowQuerier, ttl time.Duration) *SchemaVersionReader {
+	return &SchemaVersionReader{db: db, ttl: ttl, clock: clock.Real()}
+}
+
+// SchemaVersion returns the recorded migration version, or ErrNoSchemaVersion when none is recorded.
+func (r *SchemaVersionReader) SchemaVersion(ctx context.Context) (SchemaVersion, error) {
+	now := r.clock.Now()
+	r.mu.Lock()
+	if r.cached && now.Sub(r.readAt) < r.ttl {
+		version, err := r.version, r.err
+		r.mu.Unlock()
+		return version, err
+	}
+	r.mu.Unlock()
+
+	version, err := r.read(ctx)
+	if err != nil && !errors.Is(err, ErrNoSchemaVersion) {
+		return SchemaVersion{}, err
+	}
+	r.mu.Lock()
+	r.version, r.err, r.readAt, r.cached = version, err, now, true
+	r.mu.Unlock()
+	return version, err
+}
+
+func (r *SchemaVersionReader) read(ctx context.Context) (SchemaVersion, error) {
+	var (
+		version int64
+		dirty   bool
+	)
+	err := r.db.QueryRow(ctx, "SELECT version, dirty FROM schema_migrations LIMIT 1").Scan(&version, &dirty)
+	var pgErr *pgconn.PgError
+	switch {
+	case errors.Is(err, pgx.ErrNoRows), errors.As(err, &pgErr) && pgErr.Code == "42P01":
+		// 42P01 is undefined_table: migrations have never run against this database.
+		return SchemaVersion{}, ErrNoSchemaVersion
+	case err != nil:
+		return SchemaVersion{}, err
+	}
+	return SchemaVersion{Version: uint(version), Dirty: dirty}, nil
+}
@@ -0,0 +1,119 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"go.uber.org/zap"
+
+	"github.com/fastygo/backend/internal/config"
+	"github.com/fastygo/backend/pkg/clock"
+)
+
+type fakeRow struct {
+	version int64
+	dirty   bool
+	err     error
+}
+
+func (r fakeRow) Scan(dest ...any) error {
+	if r.err != nil {
+		return r.err
+	}
+	*dest[0].(*int64) = r.version
+	*dest[1].(*bool) = r.dirty
+	return nil
+}
+
+type fakeQuerier struct {
+	row     fakeRow
+	queries int
+}
+
+func (q *fakeQuerier) QueryRow(context.Context, string, ...any) pgx.Row {
+	q.queries++
+	return q.row
+}
+
+func TestSchemaVersionReaderCachesForTTL(t *testing.T) {
+	db := &fakeQuerier{row: fakeRow{version: 3}}
+	fake := clock.NewFake(time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC))
+	reader := NewSchemaVersionReader(db, time.Minute)
+	reader.clock = fake
+
+	for range 3 {
+		got, err := reader.SchemaVersion(context.Background())
+		if err != nil || got != (SchemaVersion{Version: 3}) {
+			t.Fatalf("SchemaVersion = %+v, %v; want version 3", got, err)
+		}
+	}
+	if db.queries != 1 {
+		t.Fatalf("queries within ttl = %d, want 1", db.queries)
+	}
+
+	db.row = fakeRow{version: 4, dirty: true}
+	fake.Advance(time.Minute)
+	if got, _ := reader.SchemaVersion(context.Background()); got != (SchemaVersion{Version: 4, Dirty: true}) {
+		t.Fatalf("after ttl = %+v, want version 4 dirty", got)
+	}
+}
+
+func TestSchemaVersionReaderMissingTableAndErrors(t *testing.T) {
+	missing := &fakeQuerier{row: fakeRow{err: &pgconn.PgError{Code: "42P01"}}}
+	if _, err := NewSchemaVersionReader(missing, time.Minute).SchemaVersion(context.Background()); !errors.Is(err, ErrNoSchemaVersion) {
+		t.Fatalf("missing table: err = %v, want ErrNoSchemaVersion", err)
+	}
+	empty := &fakeQuerier{row: fakeRow{err: pgx.ErrNoRows}}
+	if _, err := NewSchemaVersionReader(empty, time.Minute).SchemaVersion(context.Background()); !errors.Is(err, ErrNoSchemaVersion) {
+		t.Fatalf("empty table: err = %v, want ErrNoSchemaVersion", err)
+	}
+
+	down := &fakeQuerier{row: fakeRow{err: errors.New("connection refused")}}
+	reader := NewSchemaVersionReader(down, time.Minute)
+	for range 2 {
+		if _, err := reader.SchemaVersion(context.Background()); err == nil {
+			t.Fatal("read error was swallowed")
+		}
+	}
+	if down.queries != 2 {
+		t.Fatalf("queries after failures = %d, want 2: failed reads must not be cached", down.queries)
+	}
+}
+
+// TestSchemaVersionReaderReadsMigrationTable needs a database: TEST_DATABASE_URL=postgres://... go test ./internal/infrastructure/postgres
+func TestSchemaVersionReaderReadsMigrationTable(t *testing.T) {
+	url := os.Getenv("TEST_DATABASE_URL")
+	if url == "" {
+		t.Skip("TEST_DATABASE_URL is not set")
+	}
+	pool, err := NewPool(context.Background(), config.DatabaseConfig{URL: url, MaxOpenConns: 1}, zap.NewNop())
+	if err != nil {
+		t.Fatalf("connect: %v", err)
+	}
+	defer pool.Close()
+
+	// A temporary table shadows any real schema_migrations on the single pooled connection and
+	// disappears with it, mirroring the layout golang-migrate's postgres driver creates.
+	ctx := context.Background()
+	if _, err := pool.Exec(ctx, "CREATE TEMP TABLE schema_migrations (version bigint NOT NULL PRIMARY KEY, dirty boolean NOT NULL)"); err != nil {
+		t.Fatalf("create table: %v", err)
+	}
+	if _, err := NewSchemaVersionReader(pool, 0).SchemaVersion(ctx); !errors.Is(err, ErrNoSchemaVersion) {
+		t.Fatalf("empty table: err = %v, want ErrNoSchemaVersion", err)
+	}
+	if _, err := pool.Exec(ctx, "INSERT INTO schema_migrations (version, dirty) VALUES (20240105, true)"); err != nil {
+		t.Fatalf("insert: %v", err)
+	}
+	got, err := NewSchemaVersionReader(pool, 0).SchemaVersion(ctx)
+	if err != nil {
+		t.Fatalf("SchemaVersion: %v", err)
+	}
+	if want := (SchemaVersion{Version: 20240105, Dirty: true}); got != want {
+		t.Fatalf("SchemaVersion = %+v, want %+v", got, want)
+	}
+}
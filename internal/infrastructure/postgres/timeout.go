@@ -0,0 +1,63 @@
+package postgres
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// statementTimeoutData keys the timeout currently set on a connection in its pgconn CustomData.
+const statementTimeoutData = "statement_timeout"
+
+type statementTimeoutKey struct{}
+
+// WithStatementTimeout overrides the pool's server-side statement_timeout for queries run with ctx,
+// for operations known to run long such as bulk imports. Zero lifts the limit entirely.
+func WithStatementTimeout(ctx context.Context, timeout time.Duration) context.Context {
+	if timeout < 0 {
+		timeout = 0
+	}
+	return context.WithValue(ctx, statementTimeoutKey{}, timeout)
+}
+
+func statementTimeoutFrom(ctx context.Context, fallback time.Duration) time.Duration {
+	if timeout, ok := ctx.Value(statementTimeoutKey{}).(time.Duration); ok {
+		return timeout
+	}
+	return fallback
+}
+
+// statementTimeoutParam renders a timeout the way statement_timeout takes it: whole milliseconds,
+// rounded up so a sub-millisecond timeout does not become 0 and disable the limit.
+func statementTimeoutParam(timeout time.Duration) string {
+	ms := timeout.Milliseconds()
+	if timeout%time.Millisecond != 0 {
+		ms++
+	}
+	return strconv.FormatInt(ms, 10)
+}
+
+// prepareStatementTimeout returns a PrepareConn hook that gives each checked-out connection the
+// timeout its context asks for. Connections start with fallback, set as a runtime parameter, and
+// only pay a SET round trip when the wanted value differs from the one last applied.
+func prepareStatementTimeout(fallback time.Duration) func(context.Context, *pgx.Conn) (bool, error) {
+	return func(ctx context.Context, conn *pgx.Conn) (bool, error) {
+		want := statementTimeoutFrom(ctx, fallback)
+		data := conn.PgConn().CustomData()
+		current, ok := data[statementTimeoutData].(time.Duration)
+		if !ok {
+			current = fallback
+		}
+		if want == current {
+			return true, nil
+		}
+		if _, err := conn.Exec(ctx, "SET statement_timeout = "+statementTimeoutParam(want)); err != nil {
+			// The session state is unknown now; drop the connection rather than reuse it.
+			return false, err
+		}
+		data[statementTimeoutData] = want
+		return true, nil
+	}
+}
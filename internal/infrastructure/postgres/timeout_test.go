@@ -0,0 +1,69 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"go.uber.org/zap"
+
+	"github.com/fastygo/backend/internal/config"
+)
+
+func TestStatementTimeoutParamRoundsUp(t *testing.T) {
+	tests := map[time.Duration]string{
+		0:                       "0",
+		30 * time.Second:        "30000",
+		1500 * time.Microsecond: "2",
+		time.Microsecond:        "1",
+	}
+	for timeout, want := range tests {
+		if got := statementTimeoutParam(timeout); got != want {
+			t.Errorf("statementTimeoutParam(%v) = %q, want %q", timeout, got, want)
+		}
+	}
+}
+
+func TestStatementTimeoutFromContext(t *testing.T) {
+	if got := statementTimeoutFrom(context.Background(), time.Second); got != time.Second {
+		t.Fatalf("default = %v, want 1s", got)
+	}
+	ctx := WithStatementTimeout(context.Background(), time.Minute)
+	if got := statementTimeoutFrom(ctx, time.Second); got != time.Minute {
+		t.Fatalf("override = %v, want 1m", got)
+	}
+	if got := statementTimeoutFrom(WithStatementTimeout(ctx, -time.Second), time.Second); got != 0 {
+		t.Fatalf("negative override = %v, want 0 (no limit)", got)
+	}
+}
+
+// TestStatementTimeoutCancelsSlowQuery needs a database: TEST_DATABASE_URL=postgres://... go test ./internal/infrastructure/postgres
+func TestStatementTimeoutCancelsSlowQuery(t *testing.T) {
+	url := os.Getenv("TEST_DATABASE_URL")
+	if url == "" {
+		t.Skip("TEST_DATABASE_URL is not set")
+	}
+	pool, err := NewPool(context.Background(), config.DatabaseConfig{URL: url, MaxOpenConns: 1, StatementTimeout: 100 * time.Millisecond}, zap.NewNop())
+	if err != nil {
+		t.Fatalf("connect: %v", err)
+	}
+	defer pool.Close()
+
+	// The context never expires, so only the server-side timeout can stop the query.
+	_, err = pool.Exec(context.Background(), "SELECT pg_sleep(5)")
+	var pgErr *pgconn.PgError
+	if !errors.As(err, &pgErr) || pgErr.Code != "57014" {
+		t.Fatalf("slow query err = %v, want query_canceled (57014)", err)
+	}
+
+	// A per-call override lets a known-long statement finish on the same pooled connection.
+	if _, err := pool.Exec(WithStatementTimeout(context.Background(), time.Second), "SELECT pg_sleep(0.3)"); err != nil {
+		t.Fatalf("overridden query: %v", err)
+	}
+	if _, err := pool.Exec(context.Background(), "SELECT pg_sleep(0.3)"); err == nil {
+		t.Fatal("the override leaked to a later query on the connection")
+	}
+}
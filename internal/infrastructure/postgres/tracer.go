@@ -0,0 +1,103 @@
+package postgres
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"go.uber.org/zap"
+
+	"github.com/fastygo/backend/pkg/clock"
+	appLogger "github.com/fastygo/backend/pkg/logger"
+	"github.com/fastygo/backend/pkg/timing"
+)
+
+// maxLoggedSQL bounds how much of a slow statement is logged.
+const maxLoggedSQL = 256
+
+// slowQueryTracer is a pgx.QueryTracer that logs statements running at least threshold. Only the
+// number of arguments is logged, never their values.
+type slowQueryTracer struct {
+	threshold time.Duration
+	logger    *zap.Logger
+	clock     clock.Clock
+}
+
+var _ pgx.QueryTracer = (*slowQueryTracer)(nil)
+
+type queryStartKey struct{}
+
+type queryStart struct {
+	at   time.Time
+	sql  string
+	args int
+}
+
+func newSlowQueryTracer(threshold time.Duration, logger *zap.Logger, c clock.Clock) *slowQueryTracer {
+	if c == nil {
+		c = clock.Real()
+	}
+	return &slowQueryTracer{threshold: threshold, logger: logger, clock: c}
+}
+
+func (t *slowQueryTracer) TraceQueryStart(ctx context.Context, _ *pgx.Conn, data pgx.TraceQueryStartData) context.Context {
+	return context.WithValue(ctx, queryStartKey{}, queryStart{at: t.clock.Now(), sql: data.SQL, args: len(data.Args)})
+}
+
+func (t *slowQueryTracer) TraceQueryEnd(ctx context.Context, _ *pgx.Conn, data pgx.TraceQueryEndData) {
+	start, ok := ctx.Value(queryStartKey{}).(queryStart)
+	if !ok {
+		return
+	}
+	elapsed := t.clock.Now().Sub(start.at)
+	if elapsed < t.threshold {
+		return
+	}
+	fields := []zap.Field{
+		zap.String("sql", truncateSQL(start.sql)),
+		zap.Int("args", start.args),
+		zap.Duration("duration", elapsed),
+		zap.Duration("threshold", t.threshold),
+	}
+	if data.Err != nil {
+		fields = append(fields, zap.Error(data.Err))
+	}
+	appLogger.WithRequestID(ctx, t.logger).Warn("slow query", fields...)
+}
+
+func truncateSQL(sql string) string {
+	runes := []rune(sql)
+	if len(runes) <= maxLoggedSQL {
+		return sql
+	}
+	return string(runes[:maxLoggedSQL]) + "…"
+}
+
+// spanTracer records each statement as a timing.SpanDB span on the request's recorder, if any, and
+// passes the trace on to next.
+type spanTracer struct {
+	next pgx.QueryTracer
+}
+
+var _ pgx.QueryTracer = spanTracer{}
+
+type querySpanKey struct{}
+
+func (t spanTracer) TraceQueryStart(ctx context.Context, conn *pgx.Conn, data pgx.TraceQueryStartData) context.Context {
+	if rec := timing.FromContext(ctx); rec != nil {
+		ctx = context.WithValue(ctx, querySpanKey{}, rec.Start(timing.SpanDB))
+	}
+	if t.next != nil {
+		ctx = t.next.TraceQueryStart(ctx, conn, data)
+	}
+	return ctx
+}
+
+func (t spanTracer) TraceQueryEnd(ctx context.Context, conn *pgx.Conn, data pgx.TraceQueryEndData) {
+	if stop, ok := ctx.Value(querySpanKey{}).(func()); ok {
+		stop()
+	}
+	if t.next != nil {
+		t.next.TraceQueryEnd(ctx, conn, data)
+	}
+}
@@ -0,0 +1,47 @@
+package postgres
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+
+	"github.com/fastygo/backend/pkg/clock"
+	appLogger "github.com/fastygo/backend/pkg/logger"
+)
+
+func TestSlowQueryTracerLogsOnlySlowQueries(t *testing.T) {
+	core, logs := observer.New(zapcore.DebugLevel)
+	fake := clock.NewFake(time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC))
+	tracer := newSlowQueryTracer(100*time.Millisecond, zap.New(core), fake)
+	ctx := appLogger.ContextWithRequestID(context.Background(), "req-7")
+
+	run := func(sql string, took time.Duration) {
+		queryCtx := tracer.TraceQueryStart(ctx, nil, pgx.TraceQueryStartData{SQL: sql, Args: []any{"secret@example.com", 42}})
+		fake.Advance(took)
+		tracer.TraceQueryEnd(queryCtx, nil, pgx.TraceQueryEndData{})
+	}
+	run("SELECT 1", 20*time.Millisecond)
+	run("SELECT * FROM users WHERE email = $1 AND age > $2 "+strings.Repeat("x", 400), 150*time.Millisecond)
+
+	entries := logs.All()
+	if len(entries) != 1 {
+		t.Fatalf("logged %d entries, want only the slow query: %+v", len(entries), entries)
+	}
+	fields := entries[0].ContextMap()
+	sql, _ := fields["sql"].(string)
+	if !strings.HasPrefix(sql, "SELECT * FROM users") || len([]rune(sql)) != maxLoggedSQL+1 {
+		t.Errorf("sql = %q, want the statement truncated to %d characters", sql, maxLoggedSQL)
+	}
+	if fields["args"] != int64(2) || fields["duration"] != 150*time.Millisecond || fields["request_id"] != "req-7" {
+		t.Errorf("fields = %v, want args count, duration and request id", fields)
+	}
+	if strings.Contains(entries[0].Message+sql, "secret@example.com") {
+		t.Error("argument values must not be logged")
+	}
+}
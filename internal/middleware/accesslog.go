@@ -0,0 +1,41 @@
+package middleware
+
+import (
+	"time"
+
+	"github.com/valyala/fasthttp"
+	"go.uber.org/zap"
+
+	"github.com/fastygo/backend/pkg/httpcontext"
+	"github.com/fastygo/backend/pkg/timing"
+)
+
+// AccessLog attaches a timing.Recorder to every request and, once the handler returns, logs the
+// request with the time spent in each recorded span. A nil logger skips the log line. serverTiming
+// also sends the breakdown to the client in a Server-Timing header.
+func AccessLog(logger *zap.Logger, serverTiming bool) func(fasthttp.RequestHandler) fasthttp.RequestHandler {
+	return func(next fasthttp.RequestHandler) fasthttp.RequestHandler {
+		return func(ctx *fasthttp.RequestCtx) {
+			rec := timing.NewRecorder()
+			ctx.SetUserValue(httpcontext.KeyTiming, rec)
+			start := time.Now()
+			next(ctx)
+			elapsed := time.Since(start)
+
+			if serverTiming {
+				ctx.Response.Header.Set("Server-Timing", rec.ServerTiming(elapsed))
+			}
+			if logger == nil {
+				return
+			}
+			logger.Info("request",
+				zap.ByteString("method", ctx.Method()),
+				zap.ByteString("path", ctx.Path()),
+				zap.Int("status", ctx.Response.StatusCode()),
+				zap.Duration("duration", elapsed),
+				zap.ByteString("request_id", ctx.Response.Header.Peek("X-Request-ID")),
+				zap.Object("timing", rec),
+			)
+		}
+	}
+}
@@ -0,0 +1,66 @@
+package middleware_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/valyala/fasthttp"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+
+	"github.com/fastygo/backend/internal/middleware"
+	"github.com/fastygo/backend/pkg/httpcontext"
+	"github.com/fastygo/backend/pkg/timing"
+)
+
+func TestAccessLogRecordsTimingBreakdown(t *testing.T) {
+	core, logs := observer.New(zap.InfoLevel)
+	adapter := httpcontext.NewAdapter(time.Second)
+	handler := middleware.AccessLog(zap.New(core), true)(func(ctx *fasthttp.RequestCtx) {
+		stdCtx, cancel := adapter.Attach(ctx)
+		defer cancel()
+		rec := timing.FromContext(stdCtx)
+		rec.Add(timing.SpanDB, 12*time.Millisecond)
+		rec.Add(timing.SpanDB, 3*time.Millisecond)
+		rec.Add(timing.SpanRedis, 2*time.Millisecond)
+		ctx.SetStatusCode(fasthttp.StatusCreated)
+	})
+
+	ctx := &fasthttp.RequestCtx{}
+	ctx.Request.Header.SetMethod(fasthttp.MethodPost)
+	ctx.Request.SetRequestURI("/api/v1/tasks")
+	handler(ctx)
+
+	entries := logs.FilterMessage("request").All()
+	if len(entries) != 1 {
+		t.Fatalf("expected one access log entry, got %d", len(entries))
+	}
+	fields := entries[0].ContextMap()
+	if fields["method"] != "POST" || fields["path"] != "/api/v1/tasks" || fields["status"] != int64(fasthttp.StatusCreated) {
+		t.Fatalf("unexpected request fields: %v", fields)
+	}
+	spans, ok := fields["timing"].(map[string]interface{})
+	if !ok {
+		t.Fatalf("expected a timing object, got %v", fields["timing"])
+	}
+	if spans["db"] != 15*time.Millisecond || spans["db_count"] != 2 || spans["redis"] != 2*time.Millisecond {
+		t.Fatalf("unexpected timing breakdown: %v", spans)
+	}
+
+	header := string(ctx.Response.Header.Peek("Server-Timing"))
+	if !strings.HasPrefix(header, "db;dur=15.000, redis;dur=2.000, total;dur=") {
+		t.Fatalf("unexpected Server-Timing header %q", header)
+	}
+}
+
+func TestAccessLogWithoutServerTimingSendsNoHeader(t *testing.T) {
+	handler := middleware.AccessLog(nil, false)(func(ctx *fasthttp.RequestCtx) {
+		ctx.SetStatusCode(fasthttp.StatusOK)
+	})
+	ctx := &fasthttp.RequestCtx{}
+	handler(ctx)
+	if header := ctx.Response.Header.Peek("Server-Timing"); len(header) != 0 {
+		t.Fatalf("expected no Server-Timing header, got %q", header)
+	}
+}
@@ -0,0 +1,79 @@
+package middleware_test
+
+import (
+	"testing"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/valyala/fasthttp"
+
+	"github.com/fastygo/backend/internal/middleware"
+	"github.com/fastygo/backend/pkg/httpcontext"
+)
+
+func TestParseAPIKeys(t *testing.T) {
+	keys, err := middleware.ParseAPIKeys("k1=billing:admin, k2=reports::acme,")
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	if got := keys["k1"]; got != (middleware.APIKeyIdentity{UserID: "billing", Role: "admin"}) {
+		t.Fatalf("k1 = %+v", got)
+	}
+	if got := keys["k2"]; got != (middleware.APIKeyIdentity{UserID: "reports", TenantID: "acme"}) {
+		t.Fatalf("k2 = %+v", got)
+	}
+
+	for _, spec := range []string{"k1", "=svc", "k1=", "k1=a:b:c:d", "k1=a,k1=b"} {
+		if _, err := middleware.ParseAPIKeys(spec); err == nil {
+			t.Errorf("ParseAPIKeys(%q) accepted a malformed spec", spec)
+		}
+	}
+}
+
+func TestAPIKeyOrJWT(t *testing.T) {
+	keys := middleware.APIKeys{"svc-key": {UserID: "billing", Role: middleware.RoleAdmin, TenantID: "acme"}}
+	var user, role, tenant string
+	handler := middleware.APIKeyOrJWT(keys, middleware.JWTAuth(authSecret, nil), nil)(func(ctx *fasthttp.RequestCtx) {
+		user = string(ctx.Request.Header.Peek("X-User-ID"))
+		role = string(ctx.Request.Header.Peek("X-User-Role"))
+		tenant, _ = ctx.UserValue(httpcontext.KeyTenantID).(string)
+		ctx.SetStatusCode(fasthttp.StatusOK)
+	})
+
+	t.Run("api key only", func(t *testing.T) {
+		ctx := &fasthttp.RequestCtx{}
+		ctx.Request.Header.Set(middleware.HeaderAPIKey, "svc-key")
+		ctx.Request.Header.Set("X-User-ID", "spoofed")
+		handler(ctx)
+		if ctx.Response.StatusCode() != fasthttp.StatusOK || user != "billing" || role != middleware.RoleAdmin || tenant != "acme" {
+			t.Fatalf("status %d, identity %q/%q/%q; want the key's identity", ctx.Response.StatusCode(), user, role, tenant)
+		}
+	})
+
+	t.Run("jwt only", func(t *testing.T) {
+		if got := serveWithToken(t, handler, jwt.MapClaims{"user_id": "u1", "role": "member"}); got != fasthttp.StatusOK || user != "u1" || role != "member" {
+			t.Fatalf("status %d, identity %q/%q; want the token's identity", got, user, role)
+		}
+	})
+
+	t.Run("neither", func(t *testing.T) {
+		ctx := &fasthttp.RequestCtx{}
+		handler(ctx)
+		if got := ctx.Response.StatusCode(); got != fasthttp.StatusUnauthorized {
+			t.Fatalf("status = %d, want 401", got)
+		}
+	})
+
+	t.Run("wrong key does not fall back to jwt", func(t *testing.T) {
+		token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{"user_id": "u1"}).SignedString([]byte(authSecret))
+		if err != nil {
+			t.Fatalf("sign token: %v", err)
+		}
+		ctx := &fasthttp.RequestCtx{}
+		ctx.Request.Header.Set(middleware.HeaderAPIKey, "svc-kez")
+		ctx.Request.Header.Set("Authorization", "Bearer "+token)
+		handler(ctx)
+		if got := ctx.Response.StatusCode(); got != fasthttp.StatusUnauthorized {
+			t.Fatalf("status = %d, want 401", got)
+		}
+	})
+}
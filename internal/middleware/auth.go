@@ -1,17 +1,74 @@
 package middleware
 
 import (
+	"errors"
 	"strings"
+	"time"
 
 	"github.com/golang-jwt/jwt/v4"
 	"github.com/valyala/fasthttp"
 	"go.uber.org/zap"
+
+	"github.com/fastygo/backend/pkg/httpcontext"
 )
 
-func JWTAuth(secret string, logger *zap.Logger) func(fasthttp.RequestHandler) fasthttp.RequestHandler {
+// RoleAdmin is the role claim value granting access to operator endpoints.
+const RoleAdmin = "admin"
+
+// DefaultLeeway is the clock skew tolerated on exp, nbf and iat unless WithLeeway overrides it.
+const DefaultLeeway = 30 * time.Second
+
+var (
+	errInvalidToken = errors.New("invalid token")
+	errTokenExpired = errors.New("token is expired")
+	errTokenNotYet  = errors.New("token is not valid yet")
+	errTokenIssued  = errors.New("token used before issued")
+	errWrongIssuer  = errors.New("token issuer mismatch")
+	errWrongAud     = errors.New("token audience mismatch")
+)
+
+// TokenRules are the claim checks applied on top of the signature.
+type TokenRules struct {
+	// Leeway is how far the issuer's clock may drift from ours when checking exp, nbf and iat.
+	Leeway time.Duration
+	// Issuer, when set, must equal the iss claim.
+	Issuer string
+	// Audience, when set, must appear in the aud claim.
+	Audience string
+}
+
+// AuthOption customizes JWTAuth.
+type AuthOption func(*TokenRules)
+
+// WithLeeway overrides DefaultLeeway.
+func WithLeeway(leeway time.Duration) AuthOption {
+	return func(r *TokenRules) {
+		r.Leeway = leeway
+	}
+}
+
+// WithIssuer rejects tokens whose iss claim differs from issuer.
+func WithIssuer(issuer string) AuthOption {
+	return func(r *TokenRules) {
+		r.Issuer = issuer
+	}
+}
+
+// WithAudience rejects tokens whose aud claim does not contain audience.
+func WithAudience(audience string) AuthOption {
+	return func(r *TokenRules) {
+		r.Audience = audience
+	}
+}
+
+func JWTAuth(secret string, logger *zap.Logger, opts ...AuthOption) func(fasthttp.RequestHandler) fasthttp.RequestHandler {
 	if logger == nil {
 		logger = zap.NewNop()
 	}
+	rules := TokenRules{Leeway: DefaultLeeway}
+	for _, opt := range opts {
+		opt(&rules)
+	}
 	return func(next fasthttp.RequestHandler) fasthttp.RequestHandler {
 		return func(ctx *fasthttp.RequestCtx) {
 			tokenString := extractToken(ctx)
@@ -20,19 +77,29 @@ func JWTAuth(secret string, logger *zap.Logger) func(fasthttp.RequestHandler) fa
 				return
 			}
 
-			token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
-				return []byte(secret), nil
-			})
-			if err != nil || !token.Valid {
+			claims, err := ParseToken(secret, tokenString, rules)
+			if err != nil {
 				logger.Warn("invalid jwt token", zap.Error(err))
 				ctx.SetStatusCode(fasthttp.StatusUnauthorized)
 				return
 			}
 
-			if claims, ok := token.Claims.(jwt.MapClaims); ok {
-				if userID, ok := claims["user_id"].(string); ok {
-					ctx.Request.Header.Set("X-User-ID", userID)
-				}
+			ctx.Request.Header.Del("X-User-ID")
+			ctx.Request.Header.Del("X-User-Role")
+			if userID, ok := claims["user_id"].(string); ok {
+				ctx.Request.Header.Set("X-User-ID", userID)
+			}
+			if role, ok := claims["role"].(string); ok {
+				ctx.Request.Header.Set("X-User-Role", role)
+			}
+			if tenantID, ok := claims["tenant_id"].(string); ok && tenantID != "" {
+				ctx.SetUserValue(httpcontext.KeyTenantID, tenantID)
+			}
+			if sessionID, ok := claims["session_id"].(string); ok && sessionID != "" {
+				ctx.SetUserValue(httpcontext.KeySessionID, sessionID)
+			}
+			if scopes := scopesFromClaims(claims); len(scopes) > 0 {
+				ctx.SetUserValue(httpcontext.KeyScopes, scopes)
 			}
 
 			next(ctx)
@@ -40,6 +107,87 @@ func JWTAuth(secret string, logger *zap.Logger) func(fasthttp.RequestHandler) fa
 	}
 }
 
+// ParseToken validates a raw JWT signed with secret and returns its claims. Transports other
+// than HTTP use it so every entry point accepts exactly the same tokens.
+func ParseToken(secret, raw string, rules TokenRules) (jwt.MapClaims, error) {
+	parser := jwt.Parser{SkipClaimsValidation: true}
+	token, err := parser.Parse(raw, func(token *jwt.Token) (interface{}, error) {
+		return []byte(secret), nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !token.Valid || !ok {
+		return nil, errInvalidToken
+	}
+	if err := rules.verify(claims, jwt.TimeFunc()); err != nil {
+		return nil, err
+	}
+	return claims, nil
+}
+
+// verify checks the time-based claims against now widened by Leeway in the token's favour, then
+// the issuer and audience when configured.
+func (r TokenRules) verify(claims jwt.MapClaims, now time.Time) error {
+	leeway := r.Leeway
+	switch {
+	case !claims.VerifyExpiresAt(now.Add(-leeway).Unix(), false):
+		return errTokenExpired
+	case !claims.VerifyNotBefore(now.Add(leeway).Unix(), false):
+		return errTokenNotYet
+	case !claims.VerifyIssuedAt(now.Add(leeway).Unix(), false):
+		return errTokenIssued
+	case r.Issuer != "" && !claims.VerifyIssuer(r.Issuer, true):
+		return errWrongIssuer
+	case r.Audience != "" && !claims.VerifyAudience(r.Audience, true):
+		return errWrongAud
+	}
+	return nil
+}
+
+// RequireRole rejects requests whose authenticated role differs from role. It must run after JWTAuth.
+func RequireRole(role string) func(fasthttp.RequestHandler) fasthttp.RequestHandler {
+	return func(next fasthttp.RequestHandler) fasthttp.RequestHandler {
+		return func(ctx *fasthttp.RequestCtx) {
+			if string(ctx.Request.Header.Peek("X-User-Role")) != role {
+				ctx.SetStatusCode(fasthttp.StatusForbidden)
+				return
+			}
+			next(ctx)
+		}
+	}
+}
+
+// RequireTenant rejects requests whose token carries no tenant_id claim. It must run after JWTAuth.
+func RequireTenant(next fasthttp.RequestHandler) fasthttp.RequestHandler {
+	return func(ctx *fasthttp.RequestCtx) {
+		if tenantID, _ := ctx.UserValue(httpcontext.KeyTenantID).(string); tenantID == "" {
+			ctx.SetStatusCode(fasthttp.StatusForbidden)
+			return
+		}
+		next(ctx)
+	}
+}
+
+// scopesFromClaims accepts either an OAuth2-style space-delimited "scope" or a "scopes" array.
+func scopesFromClaims(claims jwt.MapClaims) []string {
+	if scope, ok := claims["scope"].(string); ok {
+		return strings.Fields(scope)
+	}
+	raw, ok := claims["scopes"].([]interface{})
+	if !ok {
+		return nil
+	}
+	scopes := make([]string, 0, len(raw))
+	for _, value := range raw {
+		if scope, ok := value.(string); ok && scope != "" {
+			scopes = append(scopes, scope)
+		}
+	}
+	return scopes
+}
+
 func extractToken(ctx *fasthttp.RequestCtx) string {
 	header := string(ctx.Request.Header.Peek("Authorization"))
 	if header == "" {
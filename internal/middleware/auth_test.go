@@ -0,0 +1,104 @@
+package middleware_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/valyala/fasthttp"
+
+	"github.com/fastygo/backend/internal/middleware"
+)
+
+const authSecret = "auth-secret"
+
+func serveWithToken(t *testing.T, handler fasthttp.RequestHandler, claims jwt.MapClaims) int {
+	t.Helper()
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(authSecret))
+	if err != nil {
+		t.Fatalf("sign token: %v", err)
+	}
+	ctx := &fasthttp.RequestCtx{}
+	ctx.Request.Header.Set("Authorization", "Bearer "+token)
+	handler(ctx)
+	return ctx.Response.StatusCode()
+}
+
+func TestJWTAuthLeeway(t *testing.T) {
+	handler := middleware.JWTAuth(authSecret, nil, middleware.WithLeeway(30*time.Second))(func(ctx *fasthttp.RequestCtx) {
+		ctx.SetStatusCode(fasthttp.StatusOK)
+	})
+	now := time.Now()
+
+	cases := []struct {
+		name   string
+		claims jwt.MapClaims
+		want   int
+	}{
+		{"expired within leeway", jwt.MapClaims{"user_id": "u1", "exp": now.Add(-10 * time.Second).Unix()}, fasthttp.StatusOK},
+		{"expired beyond leeway", jwt.MapClaims{"user_id": "u1", "exp": now.Add(-time.Minute).Unix()}, fasthttp.StatusUnauthorized},
+		{"not before within leeway", jwt.MapClaims{"user_id": "u1", "nbf": now.Add(10 * time.Second).Unix()}, fasthttp.StatusOK},
+		{"not before beyond leeway", jwt.MapClaims{"user_id": "u1", "nbf": now.Add(time.Minute).Unix()}, fasthttp.StatusUnauthorized},
+		{"issued in the future beyond leeway", jwt.MapClaims{"user_id": "u1", "iat": now.Add(time.Minute).Unix()}, fasthttp.StatusUnauthorized},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := serveWithToken(t, handler, tc.claims); got != tc.want {
+				t.Fatalf("status = %d, want %d", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestParseTokenWithoutLeewayRejectsExpired(t *testing.T) {
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"exp": time.Now().Add(-5 * time.Second).Unix(),
+	}).SignedString([]byte(authSecret))
+	if err != nil {
+		t.Fatalf("sign token: %v", err)
+	}
+	if _, err := middleware.ParseToken(authSecret, token, middleware.TokenRules{}); err == nil {
+		t.Fatal("expected an expired token to be rejected without leeway")
+	}
+	if _, err := middleware.ParseToken(authSecret, token, middleware.TokenRules{Leeway: middleware.DefaultLeeway}); err != nil {
+		t.Fatalf("expected the default leeway to accept it: %v", err)
+	}
+}
+
+func TestJWTAuthIssuerAndAudience(t *testing.T) {
+	handler := middleware.JWTAuth(authSecret, nil,
+		middleware.WithIssuer("fastygo"),
+		middleware.WithAudience("tasks-api"),
+	)(func(ctx *fasthttp.RequestCtx) {
+		ctx.SetStatusCode(fasthttp.StatusOK)
+	})
+
+	cases := []struct {
+		name   string
+		claims jwt.MapClaims
+		want   int
+	}{
+		{"matching issuer and audience", jwt.MapClaims{"iss": "fastygo", "aud": "tasks-api"}, fasthttp.StatusOK},
+		{"audience among several", jwt.MapClaims{"iss": "fastygo", "aud": []string{"billing", "tasks-api"}}, fasthttp.StatusOK},
+		{"wrong issuer", jwt.MapClaims{"iss": "other-service", "aud": "tasks-api"}, fasthttp.StatusUnauthorized},
+		{"missing issuer", jwt.MapClaims{"aud": "tasks-api"}, fasthttp.StatusUnauthorized},
+		{"wrong audience", jwt.MapClaims{"iss": "fastygo", "aud": "billing"}, fasthttp.StatusUnauthorized},
+		{"missing audience", jwt.MapClaims{"iss": "fastygo"}, fasthttp.StatusUnauthorized},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := serveWithToken(t, handler, tc.claims); got != tc.want {
+				t.Fatalf("status = %d, want %d", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestJWTAuthAudienceOptional(t *testing.T) {
+	handler := middleware.JWTAuth(authSecret, nil, middleware.WithIssuer("fastygo"))(func(ctx *fasthttp.RequestCtx) {
+		ctx.SetStatusCode(fasthttp.StatusOK)
+	})
+	if got := serveWithToken(t, handler, jwt.MapClaims{"iss": "fastygo"}); got != fasthttp.StatusOK {
+		t.Fatalf("status = %d, want tokens without aud accepted when no audience is configured", got)
+	}
+}
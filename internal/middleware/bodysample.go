@@ -0,0 +1,90 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math/rand/v2"
+	"strings"
+
+	"github.com/valyala/fasthttp"
+	"go.uber.org/zap"
+)
+
+// DefaultBodySampleMaxBytes caps each logged body when BodySampleConfig.MaxBytes is not set.
+const DefaultBodySampleMaxBytes = 4096
+
+// BodySampleConfig controls which requests have their bodies logged.
+type BodySampleConfig struct {
+	// Rate is the fraction of requests sampled, from 0 (none) to 1 (all).
+	Rate float64
+	// MaxBytes truncates each logged body; it defaults to DefaultBodySampleMaxBytes.
+	MaxBytes int
+	// RedactKeys names headers and JSON keys redacted in addition to DefaultRedactKeys.
+	RedactKeys []string
+}
+
+// SampleBodies logs the headers and bodies of a Rate fraction of requests and their responses at
+// debug level, tagged with the request ID, so intermittent bugs can be diagnosed without debug
+// logging for all traffic. Values of sensitive headers and JSON keys are replaced with "***" at any
+// depth, and bodies that are not JSON are logged by size only. Bodies are read from fasthttp's
+// buffers, which leaves them intact for the handler; streamed bodies are not logged.
+func SampleBodies(logger *zap.Logger, cfg BodySampleConfig) func(fasthttp.RequestHandler) fasthttp.RequestHandler {
+	if cfg.MaxBytes <= 0 {
+		cfg.MaxBytes = DefaultBodySampleMaxBytes
+	}
+	redact := newRedactor(cfg.RedactKeys)
+	return func(next fasthttp.RequestHandler) fasthttp.RequestHandler {
+		if cfg.Rate <= 0 {
+			return next
+		}
+		return func(ctx *fasthttp.RequestCtx) {
+			if cfg.Rate < 1 && rand.Float64() >= cfg.Rate {
+				next(ctx)
+				return
+			}
+			requestHeaders := redact.requestHeaders(&ctx.Request.Header)
+			var request string
+			if !ctx.Request.IsBodyStream() {
+				request = loggableBody(redact, ctx.Request.Body(), cfg.MaxBytes)
+			}
+			next(ctx)
+			var response string
+			if !ctx.Response.IsBodyStream() {
+				response = loggableBody(redact, ctx.Response.Body(), cfg.MaxBytes)
+			}
+			logger.Debug("sampled request",
+				zap.ByteString("request_id", ctx.Response.Header.Peek("X-Request-ID")),
+				zap.ByteString("method", ctx.Method()),
+				zap.ByteString("path", ctx.Path()),
+				zap.Int("status", ctx.Response.StatusCode()),
+				zap.Any("request_headers", requestHeaders),
+				zap.String("request_body", request),
+				zap.Any("response_headers", redact.responseHeaders(&ctx.Response.Header)),
+				zap.String("response_body", response),
+			)
+		}
+	}
+}
+
+// loggableBody redacts a JSON body and truncates it to maxBytes. Other bodies may carry secrets
+// in a form redaction cannot see, so only their size is logged.
+func loggableBody(redact redactor, body []byte, maxBytes int) string {
+	if len(body) == 0 {
+		return ""
+	}
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+	var decoded interface{}
+	if err := decoder.Decode(&decoded); err != nil {
+		return fmt.Sprintf("[%d bytes, not JSON]", len(body))
+	}
+	redacted, err := json.Marshal(redact.json(decoded))
+	if err != nil {
+		return fmt.Sprintf("[%d bytes]", len(body))
+	}
+	if len(redacted) > maxBytes {
+		return strings.ToValidUTF8(string(redacted[:maxBytes]), "") + "…"
+	}
+	return string(redacted)
+}
@@ -0,0 +1,106 @@
+package middleware_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/valyala/fasthttp"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+
+	"github.com/fastygo/backend/internal/middleware"
+)
+
+func serveSampled(t *testing.T, cfg middleware.BodySampleConfig, body string) *observer.ObservedLogs {
+	t.Helper()
+	core, logs := observer.New(zapcore.DebugLevel)
+	handler := middleware.SampleBodies(zap.New(core), cfg)(func(ctx *fasthttp.RequestCtx) {
+		if got := string(ctx.PostBody()); got != body {
+			t.Fatalf("handler read body %q, want %q", got, body)
+		}
+		ctx.Response.Header.Set("X-Request-ID", "req-1")
+		ctx.SetStatusCode(fasthttp.StatusCreated)
+		ctx.SetBodyString(`{"id":"t1","access_token":"abc"}`)
+	})
+	ctx := &fasthttp.RequestCtx{}
+	ctx.Request.Header.SetMethod(fasthttp.MethodPost)
+	ctx.Request.SetRequestURI("/api/v1/tasks")
+	ctx.Request.SetBodyString(body)
+	handler(ctx)
+	return logs
+}
+
+func TestSampleBodiesAtFullRateLogsRedactedBodies(t *testing.T) {
+	logs := serveSampled(t, middleware.BodySampleConfig{Rate: 1}, `{"title":"write","owner":{"password":"hunter2"}}`)
+
+	entries := logs.FilterMessage("sampled request").All()
+	if len(entries) != 1 {
+		t.Fatalf("expected one sampled entry, got %d", len(entries))
+	}
+	fields := entries[0].ContextMap()
+	if fields["request_id"] != "req-1" || fields["status"] != int64(fasthttp.StatusCreated) {
+		t.Fatalf("unexpected request fields: %v", fields)
+	}
+	if got := fields["request_body"]; got != `{"owner":{"password":"***"},"title":"write"}` {
+		t.Fatalf("request_body = %v", got)
+	}
+	if got := fields["response_body"]; got != `{"access_token":"***","id":"t1"}` {
+		t.Fatalf("response_body = %v", got)
+	}
+}
+
+func TestSampleBodiesAtZeroRateLogsNothing(t *testing.T) {
+	logs := serveSampled(t, middleware.BodySampleConfig{Rate: 0}, `{"title":"write"}`)
+	if logs.Len() != 0 {
+		t.Fatalf("expected no entries, got %d", logs.Len())
+	}
+}
+
+func TestSampleBodiesTruncatesAndHidesNonJSON(t *testing.T) {
+	logs := serveSampled(t, middleware.BodySampleConfig{Rate: 1, MaxBytes: 10}, `{"title":"a much longer title"}`)
+	fields := logs.All()[0].ContextMap()
+	if got := fields["request_body"].(string); !strings.HasPrefix(got, `{"title":"`) || len(got) > 10+len("…") {
+		t.Fatalf("request_body = %q, want it truncated to 10 bytes", got)
+	}
+
+	logs = serveSampled(t, middleware.BodySampleConfig{Rate: 1}, "password=hunter2")
+	if got := logs.All()[0].ContextMap()["request_body"]; got != "[16 bytes, not JSON]" {
+		t.Fatalf("request_body = %v, want only the size of a non-JSON body", got)
+	}
+}
+
+func TestSampleBodiesRedactsConfiguredKeysInHeadersAndNestedJSON(t *testing.T) {
+	core, logs := observer.New(zapcore.DebugLevel)
+	cfg := middleware.BodySampleConfig{Rate: 1, RedactKeys: []string{"ssn", "X-Tenant-Secret"}}
+	handler := middleware.SampleBodies(zap.New(core), cfg)(func(ctx *fasthttp.RequestCtx) {
+		ctx.Response.Header.Set("Set-Cookie", "session=abc")
+		ctx.Response.Header.Set("X-Trace", "resp-trace")
+		ctx.SetStatusCode(fasthttp.StatusOK)
+	})
+	ctx := &fasthttp.RequestCtx{}
+	ctx.Request.Header.SetMethod(fasthttp.MethodPost)
+	ctx.Request.SetRequestURI("/api/v1/profile")
+	ctx.Request.Header.Set("Authorization", "Bearer eyJ.secret")
+	ctx.Request.Header.Set("X-Tenant-Secret", "s3cret")
+	ctx.Request.Header.Set("X-Trace", "req-trace")
+	ctx.Request.SetBodyString(`{"profile":{"SSN":"123-45","name":"ann","devices":[{"token":"t1","model":"x"}]}}`)
+	handler(ctx)
+
+	fields := logs.All()[0].ContextMap()
+	if got := fields["request_body"]; got != `{"profile":{"SSN":"***","devices":[{"model":"x","token":"***"}],"name":"ann"}}` {
+		t.Fatalf("request_body = %v", got)
+	}
+	requestHeaders, _ := fields["request_headers"].(map[string]string)
+	for name, want := range map[string]string{"Authorization": "***", "X-Tenant-Secret": "***", "X-Trace": "req-trace"} {
+		if requestHeaders[name] != want {
+			t.Errorf("request header %s = %v, want %q", name, requestHeaders[name], want)
+		}
+	}
+	responseHeaders, _ := fields["response_headers"].(map[string]string)
+	for name, want := range map[string]string{"Set-Cookie": "***", "X-Trace": "resp-trace"} {
+		if responseHeaders[name] != want {
+			t.Errorf("response header %s = %v, want %q", name, responseHeaders[name], want)
+		}
+	}
+}
@@ -0,0 +1,12 @@
+package middleware
+
+import "github.com/valyala/fasthttp"
+
+// HeadOnly serves HEAD from a GET handler: next runs unchanged, so status, Last-Modified and
+// Content-Length match the GET response, and the body is dropped when the response is written.
+func HeadOnly(next fasthttp.RequestHandler) fasthttp.RequestHandler {
+	return func(ctx *fasthttp.RequestCtx) {
+		next(ctx)
+		ctx.Response.SkipBody = true
+	}
+}
@@ -0,0 +1,20 @@
+package middleware
+
+import (
+	"expvar"
+	"strconv"
+
+	"github.com/valyala/fasthttp"
+)
+
+// RequestMetrics counts every request into counters, keyed by response status class ("2xx", "4xx", ...)
+// plus a "total" key.
+func RequestMetrics(counters *expvar.Map) func(fasthttp.RequestHandler) fasthttp.RequestHandler {
+	return func(next fasthttp.RequestHandler) fasthttp.RequestHandler {
+		return func(ctx *fasthttp.RequestCtx) {
+			next(ctx)
+			counters.Add("total", 1)
+			counters.Add(strconv.Itoa(ctx.Response.StatusCode()/100)+"xx", 1)
+		}
+	}
+}
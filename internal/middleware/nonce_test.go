@@ -0,0 +1,86 @@
+package middleware_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/valyala/fasthttp"
+
+	"github.com/fastygo/backend/internal/middleware"
+	"github.com/fastygo/backend/pkg/clock"
+)
+
+const nonceSecret = "nonce-secret"
+
+// memoryNonces forgets a nonce once its TTL has elapsed on the shared fake clock.
+type memoryNonces struct {
+	mu    sync.Mutex
+	clock clock.Clock
+	seen  map[string]time.Time
+}
+
+func (m *memoryNonces) Claim(_ context.Context, nonce string, ttl time.Duration) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if expires, ok := m.seen[nonce]; ok && m.clock.Now().Before(expires) {
+		return false, nil
+	}
+	m.seen[nonce] = m.clock.Now().Add(ttl)
+	return true, nil
+}
+
+func serveWithNonce(handler fasthttp.RequestHandler, nonce string) int {
+	ctx := &fasthttp.RequestCtx{}
+	ctx.Request.Header.SetMethod(fasthttp.MethodPost)
+	ctx.Request.SetRequestURI("/admin/buffer/sync")
+	if nonce != "" {
+		ctx.Request.Header.Set(middleware.NonceHeader, nonce)
+	}
+	handler(ctx)
+	return ctx.Response.StatusCode()
+}
+
+func newNonceHandler(fake *clock.Fake) fasthttp.RequestHandler {
+	store := &memoryNonces{clock: fake, seen: make(map[string]time.Time)}
+	guard := middleware.RequireNonce(store, middleware.NonceConfig{Secret: nonceSecret, TTL: time.Minute, Clock: fake}, nil)
+	return guard(func(ctx *fasthttp.RequestCtx) {
+		ctx.SetStatusCode(fasthttp.StatusOK)
+	})
+}
+
+func TestRequireNonceAcceptsFirstUseAndRejectsReplay(t *testing.T) {
+	fake := clock.NewFake(time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC))
+	handler := newNonceHandler(fake)
+	nonce := middleware.SignNonce(nonceSecret, fake.Now(), "a1b2c3")
+
+	if status := serveWithNonce(handler, nonce); status != fasthttp.StatusOK {
+		t.Fatalf("first use status = %d, want 200", status)
+	}
+	fake.Advance(30 * time.Second)
+	if status := serveWithNonce(handler, nonce); status != fasthttp.StatusConflict {
+		t.Fatalf("replay within ttl status = %d, want 409", status)
+	}
+	if status := serveWithNonce(handler, middleware.SignNonce(nonceSecret, fake.Now(), "d4e5f6")); status != fasthttp.StatusOK {
+		t.Fatalf("fresh nonce status = %d, want 200", status)
+	}
+}
+
+func TestRequireNonceRejectsInvalidNonces(t *testing.T) {
+	fake := clock.NewFake(time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC))
+	handler := newNonceHandler(fake)
+
+	tests := map[string]string{
+		"missing":      "",
+		"malformed":    "not-a-nonce",
+		"wrong secret": middleware.SignNonce("other-secret", fake.Now(), "a1"),
+		"expired":      middleware.SignNonce(nonceSecret, fake.Now().Add(-2*time.Minute), "a2"),
+		"from future":  middleware.SignNonce(nonceSecret, fake.Now().Add(2*time.Minute), "a3"),
+	}
+	for name, nonce := range tests {
+		if status := serveWithNonce(handler, nonce); status != fasthttp.StatusBadRequest {
+			t.Errorf("%s: status = %d, want 400", name, status)
+		}
+	}
+}
@@ -0,0 +1,85 @@
+package middleware
+
+import (
+	"strings"
+
+	"github.com/valyala/fasthttp"
+)
+
+// DefaultRedactKeys are the header names and JSON object keys always kept out of request logs.
+var DefaultRedactKeys = []string{
+	"authorization", "cookie", "set-cookie", HeaderAPIKey,
+	"password", "token", "access_token", "refresh_token", "secret",
+}
+
+const redactedValue = "***"
+
+// redactor replaces the values of sensitive headers and JSON keys with "***". Keys match without
+// regard to case.
+type redactor struct {
+	keys map[string]struct{}
+}
+
+// newRedactor redacts DefaultRedactKeys plus extra.
+func newRedactor(extra []string) redactor {
+	r := redactor{keys: make(map[string]struct{}, len(DefaultRedactKeys)+len(extra))}
+	for _, key := range append(append([]string(nil), DefaultRedactKeys...), extra...) {
+		if key = strings.TrimSpace(key); key != "" {
+			r.keys[strings.ToLower(key)] = struct{}{}
+		}
+	}
+	return r
+}
+
+func (r redactor) sensitive(key string) bool {
+	_, ok := r.keys[strings.ToLower(key)]
+	return ok
+}
+
+// json replaces sensitive values at any depth of a decoded JSON document, including inside arrays.
+func (r redactor) json(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, child := range v {
+			if r.sensitive(key) {
+				v[key] = redactedValue
+				continue
+			}
+			v[key] = r.json(child)
+		}
+	case []interface{}:
+		for i, child := range v {
+			v[i] = r.json(child)
+		}
+	}
+	return value
+}
+
+// requestHeaders returns the request headers with sensitive values redacted.
+func (r redactor) requestHeaders(header *fasthttp.RequestHeader) map[string]string {
+	out := make(map[string]string)
+	header.VisitAll(func(key, value []byte) {
+		r.add(out, string(key), string(value))
+	})
+	return out
+}
+
+// responseHeaders returns the response headers with sensitive values redacted.
+func (r redactor) responseHeaders(header *fasthttp.ResponseHeader) map[string]string {
+	out := make(map[string]string)
+	header.VisitAll(func(key, value []byte) {
+		r.add(out, string(key), string(value))
+	})
+	return out
+}
+
+// add records a header, joining repeated ones with ", ".
+func (r redactor) add(out map[string]string, key, value string) {
+	if r.sensitive(key) {
+		value = redactedValue
+	}
+	if prev, ok := out[key]; ok {
+		value = prev + ", " + value
+	}
+	out[key] = value
+}
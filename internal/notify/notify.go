@@ -0,0 +1,61 @@
+// Package notify delivers operational and user-facing events (reminders, dead letters, outages)
+// to wherever operators want them.
+package notify
+
+import (
+	"context"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// Events sent by the application.
+const (
+	EventTaskDueSoon        = "task.due_soon"
+	EventBufferDeadLettered = "buffer.dead_lettered"
+	EventBufferFull         = "buffer.full"
+	EventBufferDegraded     = "buffer.degraded"
+	EventBufferRecovered    = "buffer.recovered"
+	EventOutboxDeadLettered = "outbox.dead_lettered"
+)
+
+// Notification is one event. Fields carry event-specific details such as the task or item ID.
+type Notification struct {
+	Event   string            `json:"event"`
+	Message string            `json:"message"`
+	Fields  map[string]string `json:"fields,omitempty"`
+	Time    time.Time         `json:"time"`
+}
+
+// Notifier delivers notifications. Implementations must be safe for concurrent use.
+type Notifier interface {
+	Notify(ctx context.Context, n Notification) error
+}
+
+// Nop drops every notification; it is the default when nothing is configured.
+type Nop struct{}
+
+func (Nop) Notify(context.Context, Notification) error { return nil }
+
+// Log writes each notification to a zap logger at warn level, so it stands out from routine logs.
+type Log struct {
+	logger *zap.Logger
+}
+
+// NewLog returns a Notifier writing to logger.
+func NewLog(logger *zap.Logger) *Log {
+	if logger == nil {
+		logger = zap.NewNop()
+	}
+	return &Log{logger: logger}
+}
+
+func (l *Log) Notify(_ context.Context, n Notification) error {
+	fields := make([]zap.Field, 0, len(n.Fields)+2)
+	fields = append(fields, zap.String("event", n.Event), zap.Time("event_time", n.Time))
+	for key, value := range n.Fields {
+		fields = append(fields, zap.String(key, value))
+	}
+	l.logger.Warn(n.Message, fields...)
+	return nil
+}
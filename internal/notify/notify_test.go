@@ -0,0 +1,88 @@
+package notify_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+
+	"github.com/fastygo/backend/internal/notify"
+)
+
+func webhookServer(t *testing.T, statuses ...int) (*httptest.Server, *atomic.Int32, <-chan notify.Notification) {
+	t.Helper()
+	var calls atomic.Int32
+	received := make(chan notify.Notification, len(statuses)+1)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		call := int(calls.Add(1))
+		var n notify.Notification
+		if err := json.NewDecoder(r.Body).Decode(&n); err != nil {
+			t.Errorf("decode: %v", err)
+		}
+		received <- n
+		if call <= len(statuses) {
+			w.WriteHeader(statuses[call-1])
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return srv, &calls, received
+}
+
+func TestWebhookRetriesTransientFailures(t *testing.T) {
+	srv, calls, received := webhookServer(t, http.StatusServiceUnavailable, http.StatusTooManyRequests, http.StatusOK)
+	hook := notify.NewWebhook(srv.URL, notify.WithAttempts(3), notify.WithBackoff(time.Millisecond))
+
+	err := hook.Notify(context.Background(), notify.Notification{Event: notify.EventBufferFull, Message: "buffer full"})
+	if err != nil {
+		t.Fatalf("notify: %v", err)
+	}
+	if calls.Load() != 3 {
+		t.Fatalf("calls = %d, want two retries", calls.Load())
+	}
+	if n := <-received; n.Event != notify.EventBufferFull || n.Message != "buffer full" {
+		t.Fatalf("payload = %+v", n)
+	}
+}
+
+func TestWebhookGivesUp(t *testing.T) {
+	srv, calls, _ := webhookServer(t, http.StatusBadGateway, http.StatusBadGateway, http.StatusBadGateway)
+	hook := notify.NewWebhook(srv.URL, notify.WithAttempts(2), notify.WithBackoff(time.Millisecond))
+	if err := hook.Notify(context.Background(), notify.Notification{Event: "e"}); err == nil || calls.Load() != 2 {
+		t.Fatalf("err = %v after %d calls, want failure after 2 attempts", err, calls.Load())
+	}
+
+	srv, calls, _ = webhookServer(t, http.StatusBadRequest)
+	hook = notify.NewWebhook(srv.URL, notify.WithAttempts(3), notify.WithBackoff(time.Millisecond))
+	if err := hook.Notify(context.Background(), notify.Notification{Event: "e"}); err == nil || calls.Load() != 1 {
+		t.Fatalf("err = %v after %d calls, want a 400 not retried", err, calls.Load())
+	}
+}
+
+func TestLogWritesNotification(t *testing.T) {
+	core, logs := observer.New(zap.InfoLevel)
+	at := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	err := notify.NewLog(zap.New(core)).Notify(context.Background(), notify.Notification{
+		Event:   notify.EventBufferDeadLettered,
+		Message: "buffer item dead-lettered",
+		Fields:  map[string]string{"item_id": "i1"},
+		Time:    at,
+	})
+	if err != nil {
+		t.Fatalf("notify: %v", err)
+	}
+	entries := logs.All()
+	if len(entries) != 1 {
+		t.Fatalf("got %d log entries, want 1", len(entries))
+	}
+	fields := entries[0].ContextMap()
+	if entries[0].Message != "buffer item dead-lettered" || fields["event"] != notify.EventBufferDeadLettered || fields["item_id"] != "i1" {
+		t.Fatalf("entry = %q %v", entries[0].Message, fields)
+	}
+}
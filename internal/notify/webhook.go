@@ -0,0 +1,107 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// Webhook POSTs each notification as JSON to a URL, retrying network errors, 429 and 5xx answers
+// with exponential backoff. Other 4xx answers are not retried.
+type Webhook struct {
+	url      string
+	client   *http.Client
+	attempts int
+	backoff  time.Duration
+}
+
+// WebhookOption customizes a Webhook.
+type WebhookOption func(*Webhook)
+
+// WithAttempts bounds how many times a notification is tried; it defaults to 3.
+func WithAttempts(attempts int) WebhookOption {
+	return func(w *Webhook) {
+		if attempts > 0 {
+			w.attempts = attempts
+		}
+	}
+}
+
+// WithBackoff sets the pause before the second attempt, doubled for each later one; it defaults
+// to one second.
+func WithBackoff(backoff time.Duration) WebhookOption {
+	return func(w *Webhook) {
+		w.backoff = backoff
+	}
+}
+
+// WithHTTPClient replaces the default client, which times requests out after five seconds.
+func WithHTTPClient(client *http.Client) WebhookOption {
+	return func(w *Webhook) {
+		if client != nil {
+			w.client = client
+		}
+	}
+}
+
+// NewWebhook returns a Notifier posting to url.
+func NewWebhook(url string, opts ...WebhookOption) *Webhook {
+	w := &Webhook{
+		url:      url,
+		client:   &http.Client{Timeout: 5 * time.Second},
+		attempts: 3,
+		backoff:  time.Second,
+	}
+	for _, opt := range opts {
+		opt(w)
+	}
+	return w
+}
+
+func (w *Webhook) Notify(ctx context.Context, n Notification) error {
+	body, err := json.Marshal(n)
+	if err != nil {
+		return err
+	}
+	delay := w.backoff
+	for attempt := 1; ; attempt++ {
+		retry, err := w.post(ctx, body)
+		if err == nil {
+			return nil
+		}
+		if !retry || attempt >= w.attempts {
+			return fmt.Errorf("webhook %s after %d attempt(s): %w", n.Event, attempt, err)
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(delay):
+		}
+		delay *= 2
+	}
+}
+
+// post sends one attempt and reports whether a failure is worth retrying.
+func (w *Webhook) post(ctx context.Context, body []byte) (bool, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.url, bytes.NewReader(body))
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return ctx.Err() == nil, err
+	}
+	resp.Body.Close()
+	switch {
+	case resp.StatusCode >= 200 && resp.StatusCode <= 299:
+		return false, nil
+	case resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500:
+		return true, fmt.Errorf("webhook answered %d", resp.StatusCode)
+	default:
+		return false, fmt.Errorf("webhook answered %d", resp.StatusCode)
+	}
+}
@@ -0,0 +1,121 @@
+// Package retry holds the retry, backoff and dead-letter rules shared by the background consumers
+// that replay work from a queue: the buffer processor and the outbox relay. Each consumer keeps its
+// own storage and bookkeeping and plugs it in through Queue.
+package retry
+
+import (
+	"context"
+	"time"
+)
+
+// DefaultMaxBackoff caps the exponential delay when a Policy sets no MaxBackoff.
+const DefaultMaxBackoff = time.Hour
+
+// Policy decides how long a failing message waits before its next attempt and when it is given up
+// on as poison.
+type Policy struct {
+	// MaxAttempts dead-letters a message once it has failed this many times. Zero disables the limit.
+	MaxAttempts int
+	// MaxAge dead-letters a message older than this, regardless of attempts. Zero disables the check.
+	MaxAge time.Duration
+	// Backoff is the delay after the first failure, doubled on each further one. Zero retries on
+	// the next pass.
+	Backoff time.Duration
+	// MaxBackoff caps the delay; it defaults to DefaultMaxBackoff.
+	MaxBackoff time.Duration
+}
+
+// Delay is the wait before the next attempt of a message that has failed attempts times.
+func (p Policy) Delay(attempts int) time.Duration {
+	if p.Backoff <= 0 || attempts <= 0 {
+		return 0
+	}
+	ceiling := p.MaxBackoff
+	if ceiling <= 0 {
+		ceiling = DefaultMaxBackoff
+	}
+	delay := p.Backoff
+	for n := 1; n < attempts; n++ {
+		delay *= 2
+		if delay >= ceiling {
+			return ceiling
+		}
+	}
+	return min(delay, ceiling)
+}
+
+// Exhausted reports whether a message that has failed attempts times and is age old should be
+// dead-lettered instead of tried again.
+func (p Policy) Exhausted(attempts int, age time.Duration) bool {
+	if p.MaxAttempts > 0 && attempts >= p.MaxAttempts {
+		return true
+	}
+	return p.MaxAge > 0 && age > p.MaxAge
+}
+
+// Queue records what became of a message. Implementations persist the change; an error leaves the
+// message where it was, to be handled again on a later pass.
+type Queue[M any] interface {
+	// Ack removes a message that was processed.
+	Ack(ctx context.Context, msg M) error
+	// Retry records a failed attempt and defers the message until next.
+	Retry(ctx context.Context, msg M, attempts int, next time.Time, cause error) error
+	// DeadLetter sets a poison message aside; cause is nil when it aged out before being tried.
+	DeadLetter(ctx context.Context, msg M, attempts int, cause error) error
+}
+
+// Outcome is what Handle did with a message.
+type Outcome int
+
+const (
+	Succeeded Outcome = iota
+	Retried
+	DeadLettered
+)
+
+// Result reports how one message was handled.
+type Result struct {
+	Outcome Outcome
+	// Attempts counts the message's failed attempts, including one made by this call.
+	Attempts int
+	// Cause is the processing error behind a retry or dead letter; nil on success or when the
+	// message aged out before being tried.
+	Cause error
+	// Err is the queue's failure to record the outcome.
+	Err error
+}
+
+// State reads a message's failed attempts so far and its age at now.
+type State[M any] func(msg M, now time.Time) (attempts int, age time.Duration)
+
+// Engine applies a Policy to messages from one Queue.
+type Engine[M any] struct {
+	policy Policy
+	queue  Queue[M]
+	state  State[M]
+}
+
+func NewEngine[M any](policy Policy, queue Queue[M], state State[M]) *Engine[M] {
+	return &Engine[M]{policy: policy, queue: queue, state: state}
+}
+
+// Handle runs process on msg unless it is already exhausted, then acks, retries or dead-letters it
+// in the queue. now is the pass's reference time, used for the age check and the next attempt.
+func (e *Engine[M]) Handle(ctx context.Context, msg M, now time.Time, process func(context.Context, M) error) Result {
+	attempts, age := e.state(msg, now)
+	if e.policy.Exhausted(attempts, age) {
+		return Result{Outcome: DeadLettered, Attempts: attempts, Err: e.queue.DeadLetter(ctx, msg, attempts, nil)}
+	}
+
+	cause := process(ctx, msg)
+	if cause == nil {
+		return Result{Outcome: Succeeded, Attempts: attempts, Err: e.queue.Ack(ctx, msg)}
+	}
+
+	attempts++
+	if e.policy.Exhausted(attempts, age) {
+		return Result{Outcome: DeadLettered, Attempts: attempts, Cause: cause, Err: e.queue.DeadLetter(ctx, msg, attempts, cause)}
+	}
+	next := now.Add(e.policy.Delay(attempts))
+	return Result{Outcome: Retried, Attempts: attempts, Cause: cause, Err: e.queue.Retry(ctx, msg, attempts, next, cause)}
+}
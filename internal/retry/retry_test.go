@@ -0,0 +1,156 @@
+package retry_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/fastygo/backend/internal/retry"
+)
+
+var start = time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)
+
+type message struct {
+	id       string
+	attempts int
+	created  time.Time
+}
+
+// recordingQueue records what it is told about each message and fails with err when set.
+type recordingQueue struct {
+	acked, deadLettered []string
+	retried             map[string]time.Time
+	attempts            map[string]int
+	causes              map[string]error
+	err                 error
+}
+
+func newRecordingQueue() *recordingQueue {
+	return &recordingQueue{retried: map[string]time.Time{}, attempts: map[string]int{}, causes: map[string]error{}}
+}
+
+func (q *recordingQueue) Ack(_ context.Context, msg message) error {
+	q.acked = append(q.acked, msg.id)
+	return q.err
+}
+
+func (q *recordingQueue) Retry(_ context.Context, msg message, attempts int, next time.Time, cause error) error {
+	q.retried[msg.id], q.attempts[msg.id], q.causes[msg.id] = next, attempts, cause
+	return q.err
+}
+
+func (q *recordingQueue) DeadLetter(_ context.Context, msg message, attempts int, cause error) error {
+	q.deadLettered = append(q.deadLettered, msg.id)
+	q.attempts[msg.id], q.causes[msg.id] = attempts, cause
+	return q.err
+}
+
+func state(msg message, now time.Time) (int, time.Duration) {
+	return msg.attempts, now.Sub(msg.created)
+}
+
+func TestPolicyDelayDoublesUpToCap(t *testing.T) {
+	policy := retry.Policy{Backoff: time.Second, MaxBackoff: 5 * time.Second}
+	for attempts, want := range map[int]time.Duration{0: 0, 1: time.Second, 2: 2 * time.Second, 3: 4 * time.Second, 4: 5 * time.Second, 60: 5 * time.Second} {
+		if got := policy.Delay(attempts); got != want {
+			t.Errorf("Delay(%d) = %v, want %v", attempts, got, want)
+		}
+	}
+	if got := (retry.Policy{Backoff: time.Minute}).Delay(100); got != retry.DefaultMaxBackoff {
+		t.Errorf("default cap = %v, want %v", got, retry.DefaultMaxBackoff)
+	}
+	if got := (retry.Policy{}).Delay(3); got != 0 {
+		t.Errorf("no backoff = %v, want 0", got)
+	}
+}
+
+func TestPolicyExhausted(t *testing.T) {
+	tests := []struct {
+		name     string
+		policy   retry.Policy
+		attempts int
+		age      time.Duration
+		want     bool
+	}{
+		{name: "no limits", policy: retry.Policy{}, attempts: 1000, age: 1000 * time.Hour},
+		{name: "under attempts", policy: retry.Policy{MaxAttempts: 3}, attempts: 2},
+		{name: "at attempts", policy: retry.Policy{MaxAttempts: 3}, attempts: 3, want: true},
+		{name: "young", policy: retry.Policy{MaxAge: time.Hour}, age: time.Hour},
+		{name: "old", policy: retry.Policy{MaxAge: time.Hour}, age: time.Hour + time.Second, want: true},
+	}
+	for _, tt := range tests {
+		if got := tt.policy.Exhausted(tt.attempts, tt.age); got != tt.want {
+			t.Errorf("%s: Exhausted = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestEngineHandlesEachOutcome(t *testing.T) {
+	boom := errors.New("boom")
+	fail := func(context.Context, message) error { return boom }
+	succeed := func(context.Context, message) error { return nil }
+	policy := retry.Policy{MaxAttempts: 3, MaxAge: time.Hour, Backoff: time.Minute}
+
+	tests := []struct {
+		name        string
+		msg         message
+		process     func(context.Context, message) error
+		want        retry.Outcome
+		wantAtts    int
+		wantCause   error
+		wantProcess bool
+	}{
+		{name: "success acks", msg: message{id: "ok", attempts: 1, created: start}, process: succeed, want: retry.Succeeded, wantAtts: 1, wantProcess: true},
+		{name: "failure retries", msg: message{id: "flaky", attempts: 1, created: start}, process: fail, want: retry.Retried, wantAtts: 2, wantCause: boom, wantProcess: true},
+		{name: "last failure dead-letters", msg: message{id: "poison", attempts: 2, created: start}, process: fail, want: retry.DeadLettered, wantAtts: 3, wantCause: boom, wantProcess: true},
+		{name: "aged out before trying", msg: message{id: "stale", created: start.Add(-2 * time.Hour)}, process: succeed, want: retry.DeadLettered},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			queue := newRecordingQueue()
+			engine := retry.NewEngine[message](policy, queue, state)
+			processed := false
+
+			got := engine.Handle(context.Background(), tt.msg, start, func(ctx context.Context, msg message) error {
+				processed = true
+				return tt.process(ctx, msg)
+			})
+
+			if got.Outcome != tt.want || got.Attempts != tt.wantAtts || !errors.Is(got.Cause, tt.wantCause) || got.Err != nil {
+				t.Fatalf("result = %+v, want outcome %v, %d attempts, cause %v", got, tt.want, tt.wantAtts, tt.wantCause)
+			}
+			if processed != tt.wantProcess {
+				t.Fatalf("processed = %v, want %v", processed, tt.wantProcess)
+			}
+			switch tt.want {
+			case retry.Succeeded:
+				if len(queue.acked) != 1 {
+					t.Fatalf("acked = %v, want the message", queue.acked)
+				}
+			case retry.Retried:
+				// Second failure: twice the base backoff.
+				if next := queue.retried[tt.msg.id]; !next.Equal(start.Add(2 * time.Minute)) {
+					t.Fatalf("next attempt = %v, want %v", next, start.Add(2*time.Minute))
+				}
+			case retry.DeadLettered:
+				if len(queue.deadLettered) != 1 || queue.attempts[tt.msg.id] != tt.wantAtts || !errors.Is(queue.causes[tt.msg.id], tt.wantCause) {
+					t.Fatalf("dead-lettered %v with %d attempts and cause %v", queue.deadLettered, queue.attempts[tt.msg.id], queue.causes[tt.msg.id])
+				}
+			}
+		})
+	}
+}
+
+func TestEngineReportsQueueFailures(t *testing.T) {
+	queue := newRecordingQueue()
+	queue.err = errors.New("disk full")
+	engine := retry.NewEngine[message](retry.Policy{MaxAttempts: 5}, queue, state)
+
+	got := engine.Handle(context.Background(), message{id: "m", created: start}, start, func(context.Context, message) error {
+		return errors.New("boom")
+	})
+	if got.Outcome != retry.Retried || !errors.Is(got.Err, queue.err) {
+		t.Fatalf("result = %+v, want a retry whose recording failed", got)
+	}
+}
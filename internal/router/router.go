@@ -3,34 +3,146 @@ package router
 import (
 	"github.com/fasthttp/router"
 	"github.com/valyala/fasthttp"
+	"github.com/valyala/fasthttp/expvarhandler"
+	"github.com/valyala/fasthttp/pprofhandler"
 
 	apiHandler "github.com/fastygo/backend/api/handler"
+	"github.com/fastygo/backend/internal/middleware"
 )
 
 type Handlers struct {
-	Auth    *apiHandler.AuthHandler
-	Profile *apiHandler.ProfileHandler
-	Task    *apiHandler.TaskHandler
-	Health  *apiHandler.HealthHandler
+	Auth      *apiHandler.AuthHandler
+	Profile   *apiHandler.ProfileHandler
+	Task      *apiHandler.TaskHandler
+	Health    *apiHandler.HealthHandler
+	Version   *apiHandler.VersionHandler
+	Admin     *apiHandler.AdminHandler
+	GraphQL   *apiHandler.GraphQLHandler
+	Aggregate *apiHandler.AggregateHandler
+	Fallback  *apiHandler.FallbackHandler
 }
 
-func New(handlers Handlers, authMiddleware func(fasthttp.RequestHandler) fasthttp.RequestHandler) *router.Router {
+type options struct {
+	requireTenant bool
+	adminGuard    func(fasthttp.RequestHandler) fasthttp.RequestHandler
+	metrics       bool
+	pprof         bool
+}
+
+// Option customizes route registration.
+type Option func(*options)
+
+// WithRequireTenant rejects task and GraphQL requests whose token carries no tenant_id claim.
+func WithRequireTenant(require bool) Option {
+	return func(o *options) {
+		o.requireTenant = require
+	}
+}
+
+// WithAdminGuard wraps state-changing (POST and DELETE) admin routes with guard after
+// authentication and the role check, e.g. to require a replay-protection nonce. Read-only admin and
+// debug routes are left unguarded.
+func WithAdminGuard(guard func(fasthttp.RequestHandler) fasthttp.RequestHandler) Option {
+	return func(o *options) {
+		o.adminGuard = guard
+	}
+}
+
+// WithMetrics serves expvar counters at /debug/vars to admins.
+func WithMetrics(enabled bool) Option {
+	return func(o *options) {
+		o.metrics = enabled
+	}
+}
+
+// WithPprof serves the runtime profiler under /debug/pprof/ to admins.
+func WithPprof(enabled bool) Option {
+	return func(o *options) {
+		o.pprof = enabled
+	}
+}
+
+func New(handlers Handlers, authMiddleware func(fasthttp.RequestHandler) fasthttp.RequestHandler, opts ...Option) *router.Router {
+	var o options
+	for _, opt := range opts {
+		opt(&o)
+	}
+
 	r := router.New()
+	// A known path hit with the wrong method answers 405 and OPTIONS answers 204, both with an
+	// Allow header listing the registered methods. OPTIONS is served before authentication.
+	r.HandleMethodNotAllowed = true
+	r.HandleOPTIONS = true
+	r.GlobalOPTIONS = func(ctx *fasthttp.RequestCtx) {
+		ctx.SetStatusCode(fasthttp.StatusNoContent)
+	}
+	if handlers.Fallback != nil {
+		r.NotFound = handlers.Fallback.NotFound
+	}
+
+	tenantScoped := authMiddleware
+	if o.requireTenant {
+		tenantScoped = func(next fasthttp.RequestHandler) fasthttp.RequestHandler {
+			return authMiddleware(middleware.RequireTenant(next))
+		}
+	}
 
 	r.GET("/health", handlers.Health.Check)
+	r.GET("/health/ready", handlers.Health.Ready)
+	r.GET("/health/live", handlers.Health.Live)
+	if handlers.Version != nil {
+		r.GET("/version", handlers.Version.Get)
+	}
 
 	// Auth routes
 	r.POST("/api/v1/auth/login", handlers.Auth.Login)
 	r.POST("/api/v1/auth/refresh", handlers.Auth.Refresh)
+	r.POST("/api/v1/auth/logout", authMiddleware(handlers.Auth.Logout))
 
 	// Protected routes
 	r.GET("/api/v1/profile", authMiddleware(handlers.Profile.GetProfile))
+	r.HEAD("/api/v1/profile", middleware.HeadOnly(authMiddleware(handlers.Profile.GetProfile)))
 	r.PUT("/api/v1/profile", authMiddleware(handlers.Profile.UpdateProfile))
 
-	r.GET("/api/v1/tasks", authMiddleware(handlers.Task.GetTasks))
-	r.POST("/api/v1/tasks", authMiddleware(handlers.Task.CreateTask))
-	r.PUT("/api/v1/tasks/{id}", authMiddleware(handlers.Task.UpdateTask))
-	r.DELETE("/api/v1/tasks/{id}", authMiddleware(handlers.Task.DeleteTask))
+	r.GET("/api/v1/tasks", tenantScoped(handlers.Task.GetTasks))
+	r.POST("/api/v1/tasks", tenantScoped(handlers.Task.CreateTask))
+	r.GET("/api/v1/tasks/{id}", tenantScoped(handlers.Task.GetTask))
+	r.HEAD("/api/v1/tasks/{id}", middleware.HeadOnly(tenantScoped(handlers.Task.GetTask)))
+	r.PUT("/api/v1/tasks/{id}", tenantScoped(handlers.Task.UpdateTask))
+	r.DELETE("/api/v1/tasks/{id}", tenantScoped(handlers.Task.DeleteTask))
+
+	if handlers.Aggregate != nil {
+		r.POST("/api/v1/aggregates/batch", tenantScoped(handlers.Aggregate.SaveBatch))
+		r.GET("/api/v1/aggregates/{id}/diff", tenantScoped(handlers.Aggregate.Diff))
+	}
+
+	if handlers.GraphQL != nil {
+		r.POST("/graphql", tenantScoped(handlers.GraphQL.Query))
+	}
+
+	// Admin routes
+	adminOnly := func(next fasthttp.RequestHandler) fasthttp.RequestHandler {
+		return authMiddleware(middleware.RequireRole(middleware.RoleAdmin)(next))
+	}
+	adminWrite := func(next fasthttp.RequestHandler) fasthttp.RequestHandler {
+		if o.adminGuard != nil {
+			next = o.adminGuard(next)
+		}
+		return adminOnly(next)
+	}
+	if handlers.Admin != nil {
+		r.POST("/admin/buffer/sync", adminWrite(handlers.Admin.SyncBuffer))
+		r.GET("/admin/buffer/export", adminOnly(handlers.Admin.ExportBuffer))
+		r.POST("/admin/buffer/import", adminWrite(handlers.Admin.ImportBuffer))
+	}
+	r.GET("/admin/sessions/{id}", adminOnly(handlers.Auth.InspectSession))
+	r.DELETE("/admin/sessions/{id}", adminWrite(handlers.Auth.EvictSession))
+	if o.metrics {
+		r.GET("/debug/vars", adminOnly(expvarhandler.ExpvarHandler))
+	}
+	if o.pprof {
+		r.GET("/debug/pprof/{profile:*}", adminOnly(pprofhandler.PprofHandler))
+	}
 
 	return r
 }
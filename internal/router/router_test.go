@@ -0,0 +1,218 @@
+package router_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/valyala/fasthttp"
+
+	apiHandler "github.com/fastygo/backend/api/handler"
+	"github.com/fastygo/backend/domain"
+	"github.com/fastygo/backend/internal/middleware"
+	"github.com/fastygo/backend/internal/router"
+	"github.com/fastygo/backend/pkg/httpcontext"
+	"github.com/fastygo/backend/repository/repositorytest"
+	taskUC "github.com/fastygo/backend/usecase/task"
+)
+
+const testSecret = "router-test-secret"
+
+func signToken(t *testing.T, claims jwt.MapClaims) string {
+	t.Helper()
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(testSecret))
+	if err != nil {
+		t.Fatalf("sign token: %v", err)
+	}
+	return token
+}
+
+func newTestRouter(opts ...router.Option) fasthttp.RequestHandler {
+	tasks := repositorytest.NewTasks(
+		domain.Task{ID: "t-acme", UserID: "u1", TenantID: "acme", UpdatedAt: time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)},
+		domain.Task{ID: "t-globex", UserID: "u1", TenantID: "globex"},
+	)
+	adapter := httpcontext.NewAdapter(time.Second)
+	handlers := router.Handlers{
+		Task:     apiHandler.NewTaskHandler(taskUC.New(tasks, nil, nil), adapter, nil),
+		Fallback: apiHandler.NewFallbackHandler(adapter, nil),
+	}
+	return router.New(handlers, middleware.JWTAuth(testSecret, nil), opts...).Handler
+}
+
+func listTasks(handler fasthttp.RequestHandler, token string) *fasthttp.RequestCtx {
+	ctx := &fasthttp.RequestCtx{}
+	ctx.Request.Header.SetMethod(http.MethodGet)
+	ctx.Request.SetRequestURI("/api/v1/tasks")
+	ctx.Request.Header.Set("Authorization", "Bearer "+token)
+	handler(ctx)
+	return ctx
+}
+
+func TestTenantScopedRoutesRejectTokensWithoutTenant(t *testing.T) {
+	handler := newTestRouter(router.WithRequireTenant(true))
+
+	ctx := listTasks(handler, signToken(t, jwt.MapClaims{"user_id": "u1"}))
+
+	if ctx.Response.StatusCode() != http.StatusForbidden {
+		t.Fatalf("status = %d, want 403", ctx.Response.StatusCode())
+	}
+}
+
+func TestTenantScopedRoutesListOnlyCallerTenant(t *testing.T) {
+	handler := newTestRouter(router.WithRequireTenant(true))
+
+	ctx := listTasks(handler, signToken(t, jwt.MapClaims{"user_id": "u1", "tenant_id": "acme"}))
+
+	if ctx.Response.StatusCode() != http.StatusOK {
+		t.Fatalf("status = %d, want 200; body %s", ctx.Response.StatusCode(), ctx.Response.Body())
+	}
+	var body struct {
+		Data []domain.Task `json:"data"`
+	}
+	if err := json.Unmarshal(ctx.Response.Body(), &body); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if len(body.Data) != 1 || body.Data[0].ID != "t-acme" {
+		t.Fatalf("tasks = %+v, want only the acme task", body.Data)
+	}
+}
+
+func TestTenantNotRequiredByDefault(t *testing.T) {
+	handler := newTestRouter()
+
+	ctx := listTasks(handler, signToken(t, jwt.MapClaims{"user_id": "u1"}))
+
+	if ctx.Response.StatusCode() != http.StatusOK {
+		t.Fatalf("status = %d, want 200", ctx.Response.StatusCode())
+	}
+}
+
+func TestHeadTaskReturnsHeadersWithoutBody(t *testing.T) {
+	handler := newTestRouter()
+	token := signToken(t, jwt.MapClaims{"user_id": "u1", "tenant_id": "acme"})
+	request := func(method, id string) *fasthttp.RequestCtx {
+		ctx := &fasthttp.RequestCtx{}
+		ctx.Request.Header.SetMethod(method)
+		ctx.Request.SetRequestURI("/api/v1/tasks/" + id)
+		ctx.Request.Header.Set("Authorization", "Bearer "+token)
+		handler(ctx)
+		return ctx
+	}
+
+	get := request(http.MethodGet, "t-acme")
+	head := request(http.MethodHead, "t-acme")
+	if head.Response.StatusCode() != http.StatusOK {
+		t.Fatalf("status = %d, want 200", head.Response.StatusCode())
+	}
+	if got := string(head.Response.Header.Peek(fasthttp.HeaderLastModified)); got != "Fri, 01 Mar 2024 12:00:00 GMT" {
+		t.Fatalf("Last-Modified = %q, want the task's update time", got)
+	}
+
+	var wire bytes.Buffer
+	if _, err := head.Response.WriteTo(&wire); err != nil {
+		t.Fatalf("write response: %v", err)
+	}
+	if !bytes.HasSuffix(wire.Bytes(), []byte("\r\n\r\n")) {
+		t.Fatalf("HEAD response carried a body:\n%s", wire.String())
+	}
+	if got, want := head.Response.Header.ContentLength(), len(get.Response.Body()); got != want {
+		t.Fatalf("Content-Length = %d, want %d as for GET", got, want)
+	}
+
+	if missing := request(http.MethodHead, "t-globex"); missing.Response.StatusCode() != http.StatusNotFound {
+		t.Fatalf("status = %d, want 404 for another tenant's task", missing.Response.StatusCode())
+	}
+}
+
+func TestWrongMethodAndOptionsListAllowedMethods(t *testing.T) {
+	handler := newTestRouter()
+	request := func(method string) *fasthttp.RequestCtx {
+		ctx := &fasthttp.RequestCtx{}
+		ctx.Request.Header.SetMethod(method)
+		ctx.Request.SetRequestURI("/api/v1/tasks/t-acme")
+		handler(ctx)
+		return ctx
+	}
+	const allow = "DELETE, GET, HEAD, OPTIONS, PUT"
+
+	patch := request(http.MethodPatch)
+	if patch.Response.StatusCode() != http.StatusMethodNotAllowed {
+		t.Fatalf("status = %d, want 405", patch.Response.StatusCode())
+	}
+	if got := string(patch.Response.Header.Peek(fasthttp.HeaderAllow)); got != allow {
+		t.Fatalf("Allow = %q, want %q", got, allow)
+	}
+
+	options := request(http.MethodOptions)
+	if options.Response.StatusCode() != http.StatusNoContent {
+		t.Fatalf("status = %d, want 204", options.Response.StatusCode())
+	}
+	if got := string(options.Response.Header.Peek(fasthttp.HeaderAllow)); got != allow {
+		t.Fatalf("Allow = %q, want %q", got, allow)
+	}
+}
+
+func TestUnknownPathAnswersJSONNotFound(t *testing.T) {
+	ctx := &fasthttp.RequestCtx{}
+	ctx.Request.Header.SetMethod(http.MethodGet)
+	ctx.Request.SetRequestURI("/api/v1/nowhere")
+	newTestRouter()(ctx)
+
+	if ctx.Response.StatusCode() != http.StatusNotFound {
+		t.Fatalf("status = %d, want 404", ctx.Response.StatusCode())
+	}
+	if len(ctx.Response.Header.Peek("X-Request-ID")) == 0 {
+		t.Fatal("X-Request-ID missing on the 404")
+	}
+	var env struct {
+		Status string `json:"status"`
+		Code   string `json:"code"`
+	}
+	if err := json.Unmarshal(ctx.Response.Body(), &env); err != nil || env.Status != "error" || env.Code != "NOT_FOUND" {
+		t.Fatalf("body = %s, want a NOT_FOUND envelope", ctx.Response.Body())
+	}
+}
+
+func TestAdminSessionRoutesRequireAdminRole(t *testing.T) {
+	handler := newTestRouter()
+	for _, method := range []string{http.MethodGet, http.MethodDelete} {
+		ctx := &fasthttp.RequestCtx{}
+		ctx.Request.Header.SetMethod(method)
+		ctx.Request.SetRequestURI("/admin/sessions/s1")
+		ctx.Request.Header.Set("Authorization", "Bearer "+signToken(t, jwt.MapClaims{"user_id": "u1", "role": "member"}))
+		handler(ctx)
+
+		if ctx.Response.StatusCode() != http.StatusForbidden {
+			t.Fatalf("%s as member: status = %d, want 403", method, ctx.Response.StatusCode())
+		}
+	}
+}
+
+func TestAdminGuardCoversOnlyStateChangingRoutes(t *testing.T) {
+	guard := func(next fasthttp.RequestHandler) fasthttp.RequestHandler {
+		return func(ctx *fasthttp.RequestCtx) {
+			ctx.SetStatusCode(http.StatusPreconditionRequired)
+		}
+	}
+	handler := newTestRouter(router.WithAdminGuard(guard), router.WithMetrics(true))
+	admin := signToken(t, jwt.MapClaims{"user_id": "u1", "role": "admin"})
+	call := func(method, uri string) int {
+		ctx := &fasthttp.RequestCtx{}
+		ctx.Request.Header.SetMethod(method)
+		ctx.Request.SetRequestURI(uri)
+		ctx.Request.Header.Set("Authorization", "Bearer "+admin)
+		handler(ctx)
+		return ctx.Response.StatusCode()
+	}
+
+	if status := call(http.MethodDelete, "/admin/sessions/s1"); status != http.StatusPreconditionRequired {
+		t.Fatalf("DELETE session: status = %d, want the guard to run", status)
+	}
+	if status := call(http.MethodGet, "/debug/vars"); status != http.StatusOK {
+		t.Fatalf("GET /debug/vars: status = %d, want it served without the guard", status)
+	}
+}
@@ -3,34 +3,94 @@ package services
 import (
 	"context"
 	"encoding/json"
+	"errors"
 
 	"github.com/fastygo/backend/domain"
 	"github.com/fastygo/backend/internal/infrastructure/buffer"
 	"github.com/fastygo/backend/usecase"
 )
 
+// ErrBufferingDisabled is returned when the entity's policy does not allow buffering.
+var ErrBufferingDisabled = errors.New("buffering disabled for entity")
+
+// WriteStrategy decides whether a live write is attempted while the database is known to be offline.
+type WriteStrategy string
+
+const (
+	// StrategyOptimistic always tries the live write first and buffers it on failure.
+	StrategyOptimistic WriteStrategy = "optimistic"
+	// StrategyDeferred buffers writes directly while the monitor reports the database offline.
+	StrategyDeferred WriteStrategy = "deferred"
+)
+
+// BufferPolicy controls whether failed writes of one entity are buffered, at which priority, and
+// whether they skip the live attempt while offline.
+type BufferPolicy struct {
+	Enabled  bool
+	Priority int
+	// Strategy defaults to StrategyOptimistic.
+	Strategy WriteStrategy
+}
+
+// DefaultBufferPolicies buffers profiles at priority 3 and tasks at priority 4, optimistically.
+func DefaultBufferPolicies() map[string]BufferPolicy {
+	return map[string]BufferPolicy{
+		buffer.EntityProfile: {Enabled: true, Priority: 3, Strategy: StrategyOptimistic},
+		buffer.EntityTask:    {Enabled: true, Priority: 4, Strategy: StrategyOptimistic},
+	}
+}
+
 type BufferBridge struct {
 	processor *BufferProcessor
+	policies  map[string]BufferPolicy
 }
 
-func NewBufferBridge(processor *BufferProcessor) *BufferBridge {
-	return &BufferBridge{processor: processor}
+// NewBufferBridge adapts the processor to usecase.OperationBuffer. Entities missing from policies
+// keep their DefaultBufferPolicies entry.
+func NewBufferBridge(processor *BufferProcessor, policies map[string]BufferPolicy) *BufferBridge {
+	merged := DefaultBufferPolicies()
+	for entity, policy := range policies {
+		merged[entity] = policy
+	}
+	return &BufferBridge{processor: processor, policies: merged}
+}
+
+// Buffers reports whether failed writes of entity should be buffered.
+func (b *BufferBridge) Buffers(entity string) bool {
+	return b.policies[entity].Enabled
+}
+
+// Defers reports whether writes of entity should go straight to the buffer: its policy is deferred
+// and the monitor currently reports the database offline.
+func (b *BufferBridge) Defers(entity string) bool {
+	policy := b.policies[entity]
+	return policy.Enabled && policy.Strategy == StrategyDeferred && !b.DatabaseOnline()
+}
+
+// DatabaseOnline reports the monitor's view of the database; without a monitor it is assumed online.
+func (b *BufferBridge) DatabaseOnline() bool {
+	return b.processor == nil || b.processor.monitor == nil || b.processor.monitor.IsOnline()
 }
 
 func (b *BufferBridge) BufferProfile(ctx context.Context, operation string, user *domain.User) error {
 	if b.processor == nil || user == nil {
 		return domain.ErrInvalidPayload
 	}
+	policy := b.policies[buffer.EntityProfile]
+	if !policy.Enabled {
+		return ErrBufferingDisabled
+	}
 	payload, err := json.Marshal(user)
 	if err != nil {
 		return err
 	}
 	item := buffer.Item{
-		UserID:    user.ID,
-		Entity:    buffer.EntityProfile,
-		Operation: operation,
-		Data:      payload,
-		Priority:  3,
+		UserID:      user.ID,
+		Entity:      buffer.EntityProfile,
+		Operation:   operation,
+		Data:        payload,
+		Priority:    policy.Priority,
+		CallbackURL: usecase.CallbackURL(ctx),
 	}
 	return b.processor.BufferOperation(ctx, item)
 }
@@ -39,17 +99,21 @@ func (b *BufferBridge) BufferTask(ctx context.Context, operation string, task *d
 	if b.processor == nil || task == nil {
 		return domain.ErrInvalidPayload
 	}
+	policy := b.policies[buffer.EntityTask]
+	if !policy.Enabled {
+		return ErrBufferingDisabled
+	}
 	payload, err := json.Marshal(task)
 	if err != nil {
 		return err
 	}
 	item := buffer.Item{
-		ID:        task.ID,
-		UserID:    task.UserID,
-		Entity:    buffer.EntityTask,
-		Operation: operation,
-		Data:      payload,
-		Priority:  4,
+		UserID:      task.UserID,
+		Entity:      buffer.EntityTask,
+		Operation:   operation,
+		Data:        payload,
+		Priority:    policy.Priority,
+		CallbackURL: usecase.CallbackURL(ctx),
 	}
 	return b.processor.BufferOperation(ctx, item)
 }
@@ -0,0 +1,130 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/fastygo/backend/domain"
+	"github.com/fastygo/backend/internal/infrastructure/buffer"
+	"github.com/fastygo/backend/internal/infrastructure/buffer/buffertest"
+	"github.com/fastygo/backend/pkg/clock"
+	"github.com/fastygo/backend/repository/repositorytest"
+	profileUC "github.com/fastygo/backend/usecase/profile"
+	taskUC "github.com/fastygo/backend/usecase/task"
+)
+
+var errDatabaseDown = errors.New("connection refused")
+
+func TestBufferPolicyDisabledEntityReturnsOriginalError(t *testing.T) {
+	store := buffertest.NewMemoryStore(clock.NewFake(testStart))
+	users := repositorytest.NewUsers()
+	users.Err = errDatabaseDown
+	tasks := repositorytest.NewTasks()
+	tasks.Err = errDatabaseDown
+	bp := newTestProcessor(t, store, nil, users, tasks, nil, ProcessorConfig{})
+	bridge := NewBufferBridge(bp, map[string]BufferPolicy{
+		buffer.EntityProfile: {Enabled: false},
+	})
+
+	_, err := profileUC.New(users, bridge, nil).UpdateProfile(context.Background(), &domain.User{ID: "u1"})
+	if !errors.Is(err, errDatabaseDown) {
+		t.Fatalf("profile update err = %v, want the repository error", err)
+	}
+	if size, _ := store.Size(); size != 0 {
+		t.Fatalf("buffer size = %d after a disabled-policy failure, want 0", size)
+	}
+
+	if _, err := taskUC.New(tasks, bridge, nil).CreateTask(context.Background(), &domain.Task{ID: "t1", UserID: "u1"}); err != nil {
+		t.Fatalf("task create err = %v, want it buffered", err)
+	}
+	items, _ := store.GetBatch(10)
+	if len(items) != 1 || items[0].Entity != buffer.EntityTask || items[0].Priority != 4 {
+		t.Fatalf("buffered = %+v, want one task at the default priority 4", items)
+	}
+}
+
+func TestBufferBridgeAppliesConfiguredPriority(t *testing.T) {
+	store := buffertest.NewMemoryStore(clock.NewFake(testStart))
+	users := repositorytest.NewUsers()
+	users.Err = errDatabaseDown
+	bp := newTestProcessor(t, store, nil, users, repositorytest.NewTasks(), nil, ProcessorConfig{})
+	bridge := NewBufferBridge(bp, map[string]BufferPolicy{
+		buffer.EntityProfile: {Enabled: true, Priority: 1},
+	})
+
+	if err := bridge.BufferProfile(context.Background(), buffer.OperationUpdate, &domain.User{ID: "u1"}); err != nil {
+		t.Fatalf("buffer profile: %v", err)
+	}
+	items, _ := store.GetBatch(10)
+	if len(items) != 1 || items[0].Priority != 1 {
+		t.Fatalf("buffered = %+v, want priority 1", items)
+	}
+	if !bridge.Buffers(buffer.EntityTask) {
+		t.Fatal("task policy lost its default when only the profile policy was configured")
+	}
+}
+
+type staticHealth bool
+
+func (h staticHealth) IsOnline() bool { return bool(h) }
+
+// countingTasks records how many live writes reach the repository.
+type countingTasks struct {
+	*repositorytest.Tasks
+	creates int
+}
+
+func (c *countingTasks) Create(ctx context.Context, task *domain.Task) (*domain.Task, error) {
+	c.creates++
+	return c.Tasks.Create(ctx, task)
+}
+
+func TestWriteStrategyWhileOffline(t *testing.T) {
+	for _, tc := range []struct {
+		strategy   WriteStrategy
+		wantWrites int
+	}{
+		{StrategyDeferred, 0},
+		{StrategyOptimistic, 1},
+	} {
+		t.Run(string(tc.strategy), func(t *testing.T) {
+			store := buffertest.NewMemoryStore(clock.NewFake(testStart))
+			tasks := &countingTasks{Tasks: repositorytest.NewTasks()}
+			tasks.Err = errDatabaseDown
+			bp := newTestProcessor(t, store, staticHealth(false), repositorytest.NewUsers(), tasks, nil, ProcessorConfig{})
+			bridge := NewBufferBridge(bp, map[string]BufferPolicy{
+				buffer.EntityTask: {Enabled: true, Priority: 4, Strategy: tc.strategy},
+			})
+
+			if _, err := taskUC.New(tasks, bridge, nil).CreateTask(context.Background(), &domain.Task{ID: "t1", UserID: "u1"}); err != nil {
+				t.Fatalf("create task: %v", err)
+			}
+			if tasks.creates != tc.wantWrites {
+				t.Fatalf("live writes = %d, want %d", tasks.creates, tc.wantWrites)
+			}
+			if size, _ := store.Size(); size != 1 {
+				t.Fatalf("buffer size = %d, want the task buffered", size)
+			}
+		})
+	}
+}
+
+func TestDeferredStrategyWritesLiveWhileOnline(t *testing.T) {
+	store := buffertest.NewMemoryStore(clock.NewFake(testStart))
+	tasks := &countingTasks{Tasks: repositorytest.NewTasks()}
+	bp := newTestProcessor(t, store, staticHealth(true), repositorytest.NewUsers(), tasks, nil, ProcessorConfig{})
+	bridge := NewBufferBridge(bp, map[string]BufferPolicy{
+		buffer.EntityTask: {Enabled: true, Priority: 4, Strategy: StrategyDeferred},
+	})
+
+	if _, err := taskUC.New(tasks, bridge, nil).CreateTask(context.Background(), &domain.Task{ID: "t1", UserID: "u1"}); err != nil {
+		t.Fatalf("create task: %v", err)
+	}
+	if tasks.creates != 1 {
+		t.Fatalf("live writes = %d, want 1", tasks.creates)
+	}
+	if size, _ := store.Size(); size != 0 {
+		t.Fatalf("buffer size = %d, want nothing buffered", size)
+	}
+}
@@ -0,0 +1,99 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/fastygo/backend/internal/infrastructure/buffer"
+)
+
+// Final outcomes reported to a buffered item's callback URL.
+const (
+	OutcomeApplied      = "applied"
+	OutcomeDeadLettered = "dead_lettered"
+)
+
+// callbackRetryDelay is the pause before the second callback attempt, doubled for each later one.
+var callbackRetryDelay = time.Second
+
+// CallbackPayload is the JSON body POSTed to an item's callback URL.
+type CallbackPayload struct {
+	ItemID    string    `json:"item_id"`
+	Entity    string    `json:"entity"`
+	Operation string    `json:"operation"`
+	Outcome   string    `json:"outcome"`
+	Retries   int       `json:"retries"`
+	Error     string    `json:"error,omitempty"`
+	Completed time.Time `json:"completed_at"`
+}
+
+// notify reports the item's final outcome in the background. Callbacks are best effort: a
+// receiver that keeps failing is logged and given up on after CallbackAttempts tries.
+func (bp *BufferProcessor) notify(item buffer.Item, outcome string, cause error) {
+	if item.CallbackURL == "" || bp.cfg.CallbackAttempts <= 0 {
+		return
+	}
+	payload := CallbackPayload{
+		ItemID:    item.ID,
+		Entity:    item.Entity,
+		Operation: item.Operation,
+		Outcome:   outcome,
+		Retries:   item.Retries,
+		Completed: bp.store.Now(),
+	}
+	if cause != nil {
+		payload.Error = cause.Error()
+	}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		bp.logger.Error("failed to encode buffer callback", zap.String("item_id", item.ID), zap.Error(err))
+		return
+	}
+
+	bp.callbacks.Add(1)
+	go func() {
+		defer bp.callbacks.Done()
+		delay := callbackRetryDelay
+		for attempt := 1; ; attempt++ {
+			err := bp.postCallback(item.CallbackURL, body)
+			if err == nil {
+				return
+			}
+			if attempt >= bp.cfg.CallbackAttempts {
+				bp.logger.Warn("buffer callback failed",
+					zap.String("item_id", item.ID),
+					zap.String("outcome", outcome),
+					zap.Int("attempts", attempt),
+					zap.Error(err))
+				return
+			}
+			time.Sleep(delay)
+			delay *= 2
+		}
+	}()
+}
+
+func (bp *BufferProcessor) postCallback(url string, body []byte) error {
+	ctx, cancel := context.WithTimeout(context.Background(), bp.cfg.CallbackTimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := bp.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("callback answered %d", resp.StatusCode)
+	}
+	return nil
+}
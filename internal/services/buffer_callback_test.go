@@ -0,0 +1,109 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/fastygo/backend/domain"
+	"github.com/fastygo/backend/internal/infrastructure/buffer/buffertest"
+	"github.com/fastygo/backend/pkg/clock"
+	"github.com/fastygo/backend/repository/repositorytest"
+)
+
+// callbackReceiver records callback payloads, failing the first failFirst requests with 503.
+func callbackReceiver(t *testing.T, failFirst int32) (*httptest.Server, <-chan CallbackPayload) {
+	t.Helper()
+	received := make(chan CallbackPayload, 4)
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) <= failFirst {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		var payload CallbackPayload
+		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+			t.Errorf("decode callback: %v", err)
+		}
+		received <- payload
+	}))
+	t.Cleanup(srv.Close)
+	return srv, received
+}
+
+func awaitCallback(t *testing.T, received <-chan CallbackPayload) CallbackPayload {
+	t.Helper()
+	select {
+	case payload := <-received:
+		return payload
+	case <-time.After(5 * time.Second):
+		t.Fatal("callback was not delivered")
+		return CallbackPayload{}
+	}
+}
+
+func TestDrainReportsOutcomeToCallbackURL(t *testing.T) {
+	callbackRetryDelay = time.Millisecond
+	srv, received := callbackReceiver(t, 1)
+
+	fake := clock.NewFake(testStart)
+	store := buffertest.NewMemoryStore(fake)
+	tasks := repositorytest.NewTasks()
+	bp := newTestProcessor(t, store, nil, repositorytest.NewUsers(), tasks, nil, ProcessorConfig{
+		MaxRetries:       1,
+		CallbackAttempts: 2,
+	})
+
+	applied := taskItem(t, "applied", domain.Task{ID: "t-applied", UserID: "u1"})
+	applied.CallbackURL = srv.URL
+	if err := store.Enqueue(applied); err != nil {
+		t.Fatalf("enqueue: %v", err)
+	}
+	if _, err := bp.Drain(context.Background()); err != nil {
+		t.Fatalf("drain: %v", err)
+	}
+	if got := awaitCallback(t, received); got.ItemID != "applied" || got.Outcome != OutcomeApplied || got.Error != "" {
+		t.Fatalf("callback = %+v, want the item reported applied after a retried delivery", got)
+	}
+
+	tasks.Err = errors.New("constraint violated")
+	doomed := taskItem(t, "doomed", domain.Task{ID: "t-doomed", UserID: "u1"})
+	doomed.CallbackURL = srv.URL
+	if err := store.Enqueue(doomed); err != nil {
+		t.Fatalf("enqueue: %v", err)
+	}
+	if _, err := bp.Drain(context.Background()); err != nil {
+		t.Fatalf("drain: %v", err)
+	}
+	got := awaitCallback(t, received)
+	if got.ItemID != "doomed" || got.Outcome != OutcomeDeadLettered || got.Error != "constraint violated" || got.Retries != 1 {
+		t.Fatalf("callback = %+v, want the item reported dead-lettered with its last error", got)
+	}
+}
+
+func TestCallbacksDisabledByDefault(t *testing.T) {
+	srv, received := callbackReceiver(t, 0)
+
+	store := buffertest.NewMemoryStore(clock.NewFake(testStart))
+	bp := newTestProcessor(t, store, nil, repositorytest.NewUsers(), repositorytest.NewTasks(), nil, ProcessorConfig{})
+
+	item := taskItem(t, "quiet", domain.Task{ID: "t-quiet", UserID: "u1"})
+	item.CallbackURL = srv.URL
+	if err := store.Enqueue(item); err != nil {
+		t.Fatalf("enqueue: %v", err)
+	}
+	if _, err := bp.Drain(context.Background()); err != nil {
+		t.Fatalf("drain: %v", err)
+	}
+	bp.callbacks.Wait()
+	select {
+	case payload := <-received:
+		t.Fatalf("unexpected callback %+v with callbacks disabled", payload)
+	default:
+	}
+}
@@ -3,7 +3,11 @@ package services
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"net/http"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/robfig/cron/v3"
@@ -11,40 +15,133 @@ import (
 
 	"github.com/fastygo/backend/domain"
 	"github.com/fastygo/backend/internal/infrastructure/buffer"
+	"github.com/fastygo/backend/internal/notify"
+	"github.com/fastygo/backend/internal/retry"
 	"github.com/fastygo/backend/repository"
 )
 
+// ErrDrainInProgress is returned by Drain when another pass is still running.
+var ErrDrainInProgress = domain.NewError(domain.ErrCodeConflict, "buffer drain already in progress")
+
 // ConnectionHealth abstracts the connection monitor functionality.
 type ConnectionHealth interface {
 	IsOnline() bool
 }
 
+// BufferStore is the persistence contract the processor relies on; *buffer.Store is the production implementation.
+type BufferStore interface {
+	Enqueue(item buffer.Item) error
+	GetBatch(limit int) ([]buffer.Item, error)
+	GetEntityBatch(entity string, limit int) ([]buffer.Item, error)
+	Remove(item buffer.Item) error
+	Requeue(item buffer.Item) error
+	Reschedule(item buffer.Item, nextAttempt time.Time) error
+	DeadLetter(item buffer.Item) error
+	Size() (int, error)
+	Cleanup(olderThan time.Time) (int, error)
+	Now() time.Time
+}
+
+var _ BufferStore = (*buffer.Store)(nil)
+
 // ProcessorConfig controls how frequently the buffer is drained.
 type ProcessorConfig struct {
-	Interval   time.Duration
-	BatchSize  int
-	MaxRetries int
+	Interval time.Duration
+	// Schedule is a cron expression with a leading seconds field (e.g. "0 */15 * * * *") or a
+	// descriptor such as "@daily". When set it replaces the Interval-based schedule; Interval still
+	// bounds each scheduled pass.
+	Schedule  string
+	BatchSize int
+	// EntityBatchSizes overrides BatchSize per entity (buffer.EntityProfile, buffer.EntityTask).
+	// When any size is positive, each pass fetches every entity separately, up to its own size.
+	EntityBatchSizes map[string]int
+	MaxRetries       int
+	// MaxAge dead-letters items buffered for longer than this, regardless of retries. Zero disables the check.
+	MaxAge time.Duration
+	// RetryBackoff is the base delay before retrying a failed item, doubled on each retry. Zero retries on the next pass.
+	RetryBackoff time.Duration
+	// Retention expires queued items whose last enqueue is older than this; dead letters are kept
+	// for operators. Zero disables expiry.
+	Retention time.Duration
+	// CleanupInterval is how often expired items are purged; it defaults to an hour.
+	CleanupInterval time.Duration
+	// CallbackAttempts bounds how often an item's callback URL is tried once the item is applied or
+	// dead-lettered. Zero disables callbacks.
+	CallbackAttempts int
+	// CallbackTimeout bounds each callback request; it defaults to five seconds.
+	CallbackTimeout time.Duration
+	// RecoveryThreshold is the backlog size that, on the first scheduled pass after an outage,
+	// switches to back-to-back passes until the backlog falls to RecoveryLowWater. Zero disables it.
+	RecoveryThreshold int
+	// RecoveryLowWater ends recovery; it defaults to BatchSize.
+	RecoveryLowWater int
+	// RecoveryBudget bounds the whole recovery; it defaults to ten times Interval.
+	RecoveryBudget time.Duration
+	// RecoveryPause separates recovery passes so live traffic still reaches the database.
+	RecoveryPause time.Duration
+	// Notifier receives dead-letter and buffer-full alerts; it defaults to notify.Nop.
+	Notifier notify.Notifier
+	// Heartbeat, when set, is called after every scheduled or recovery pass that ran, so a watchdog
+	// notices a scheduler that stopped or a pass that never returns.
+	Heartbeat func()
+}
+
+// DrainResult summarises a single drain pass.
+type DrainResult struct {
+	Attempted    int `json:"attempted"`
+	Succeeded    int `json:"succeeded"`
+	Requeued     int `json:"requeued"`
+	DeadLettered int `json:"dead_lettered"`
+	// RemainingEstimate is the buffer size observed after the pass; concurrent enqueues may change it.
+	RemainingEstimate int           `json:"remaining_estimate"`
+	Duration          time.Duration `json:"duration_ns"`
+}
+
+// Fields renders the result as structured log fields.
+func (r DrainResult) Fields() []zap.Field {
+	return []zap.Field{
+		zap.Int("attempted", r.Attempted),
+		zap.Int("succeeded", r.Succeeded),
+		zap.Int("requeued", r.Requeued),
+		zap.Int("dead_lettered", r.DeadLettered),
+		zap.Int("remaining_estimate", r.RemainingEstimate),
+		zap.Duration("duration", r.Duration),
+	}
 }
 
 // BufferProcessor synchronizes buffered operations with primary datastores.
 type BufferProcessor struct {
-	store    *buffer.Store
+	store    BufferStore
 	monitor  ConnectionHealth
 	userRepo repository.UserRepository
 	taskRepo repository.TaskRepository
 	logger   *zap.Logger
 	cron     *cron.Cron
 	cfg      ProcessorConfig
+	retries  *retry.Engine[buffer.Item]
+
+	httpClient *http.Client
+	callbacks  sync.WaitGroup
+
+	// draining serializes passes: the cron job and the admin endpoint must never
+	// fetch and apply the same batch concurrently.
+	draining sync.Mutex
+	// sawOffline is set when a scheduled pass found the database offline, so the next online pass
+	// knows it follows an outage.
+	sawOffline atomic.Bool
+	// full is set while enqueues are rejected with buffer.ErrBufferFull, so the alert is sent once
+	// per episode rather than once per rejected write.
+	full atomic.Bool
 }
 
 func NewBufferProcessor(
-	store *buffer.Store,
+	store BufferStore,
 	monitor ConnectionHealth,
 	userRepo repository.UserRepository,
 	taskRepo repository.TaskRepository,
 	logger *zap.Logger,
 	cfg ProcessorConfig,
-) *BufferProcessor {
+) (*BufferProcessor, error) {
 	if cfg.Interval <= 0 {
 		cfg.Interval = 30 * time.Second
 	}
@@ -54,6 +151,31 @@ func NewBufferProcessor(
 	if cfg.MaxRetries <= 0 {
 		cfg.MaxRetries = 3
 	}
+	sizes := make(map[string]int, len(cfg.EntityBatchSizes))
+	for entity, size := range cfg.EntityBatchSizes {
+		if size > 0 {
+			sizes[entity] = size
+		}
+	}
+	cfg.EntityBatchSizes = nil
+	if len(sizes) > 0 {
+		cfg.EntityBatchSizes = sizes
+	}
+	if cfg.CleanupInterval <= 0 {
+		cfg.CleanupInterval = time.Hour
+	}
+	if cfg.CallbackTimeout <= 0 {
+		cfg.CallbackTimeout = 5 * time.Second
+	}
+	if cfg.RecoveryLowWater <= 0 {
+		cfg.RecoveryLowWater = cfg.BatchSize
+	}
+	if cfg.RecoveryBudget <= 0 {
+		cfg.RecoveryBudget = 10 * cfg.Interval
+	}
+	if cfg.Notifier == nil {
+		cfg.Notifier = notify.Nop{}
+	}
 	if logger == nil {
 		logger = zap.NewNop()
 	}
@@ -66,18 +188,154 @@ func NewBufferProcessor(
 		logger:   logger,
 		cfg:      cfg,
 		cron:     cron.New(cron.WithSeconds()),
+
+		httpClient: &http.Client{},
+	}
+	bp.retries = retry.NewEngine[buffer.Item](retry.Policy{
+		MaxAttempts: cfg.MaxRetries,
+		MaxAge:      cfg.MaxAge,
+		Backoff:     cfg.RetryBackoff,
+	}, bufferQueue{store: store}, func(item buffer.Item, now time.Time) (int, time.Duration) {
+		return item.Retries, item.Age(now)
+	})
+
+	schedule := cfg.Schedule
+	if schedule == "" {
+		schedule = fmt.Sprintf("@every %ds", int(cfg.Interval.Seconds()))
+	}
+	if _, err := bp.cron.AddFunc(schedule, bp.guard("drain", bp.scheduledDrain)); err != nil {
+		return nil, fmt.Errorf("invalid buffer drain schedule %q: %w", schedule, err)
 	}
 
-	schedule := fmt.Sprintf("@every %ds", int(cfg.Interval.Seconds()))
-	_, _ = bp.cron.AddFunc(schedule, func() {
-		ctx, cancel := context.WithTimeout(context.Background(), cfg.Interval)
+	if cfg.Retention > 0 {
+		cleanupSchedule := fmt.Sprintf("@every %ds", int(cfg.CleanupInterval.Seconds()))
+		if _, err := bp.cron.AddFunc(cleanupSchedule, bp.guard("cleanup", func() {
+			if _, err := bp.PurgeExpired(); err != nil {
+				bp.logger.Error("buffer expiry cleanup failed", zap.Error(err))
+			}
+		})); err != nil {
+			return nil, fmt.Errorf("invalid buffer cleanup schedule %q: %w", cleanupSchedule, err)
+		}
+	}
+
+	return bp, nil
+}
+
+// guard recovers and logs a panic in a cron job, e.g. a nil pointer in a repository, so the
+// scheduler and the process survive it and the job runs again on its next tick.
+func (bp *BufferProcessor) guard(job string, run func()) func() {
+	return func() {
+		defer func() {
+			if r := recover(); r != nil {
+				bp.logger.Error("buffer job panicked", zap.String("job", job), zap.Any("panic", r), zap.Stack("stack"))
+			}
+		}()
+		run()
+	}
+}
+
+// scheduledDrain is the cron job: one pass bounded by Interval, followed by a recovery when that
+// pass was the first after an outage and left a large backlog.
+func (bp *BufferProcessor) scheduledDrain() {
+	afterOutage := false
+	if bp.monitor == nil || bp.monitor.IsOnline() {
+		afterOutage = bp.sawOffline.Swap(false)
+	} else {
+		bp.sawOffline.Store(true)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), bp.cfg.Interval)
+	result, err := bp.Drain(ctx)
+	cancel()
+	if errors.Is(err, ErrDrainInProgress) {
+		bp.logger.Debug("skipping scheduled buffer drain (manual drain running)")
+		return
+	}
+	bp.beat()
+	if err != nil {
+		bp.logger.Error("buffer drain failed", append(result.Fields(), zap.Error(err))...)
+		return
+	}
+	if result.Attempted > 0 {
+		bp.logger.Info("buffer drain completed", result.Fields()...)
+	}
+
+	if afterOutage && bp.cfg.RecoveryThreshold > 0 && result.RemainingEstimate >= bp.cfg.RecoveryThreshold {
+		bp.logger.Info("buffer backlog after outage, draining in recovery mode",
+			zap.Int("backlog", result.RemainingEstimate))
+		ctx, cancel := context.WithTimeout(context.Background(), bp.cfg.RecoveryBudget)
 		defer cancel()
-		if err := bp.Drain(ctx); err != nil {
-			bp.logger.Error("buffer drain failed", zap.Error(err))
+		recovered, err := bp.Recover(ctx)
+		if err != nil {
+			bp.logger.Error("buffer recovery failed", append(recovered.Fields(), zap.Error(err))...)
+			return
 		}
-	})
+		bp.logger.Info("buffer recovery completed", recovered.Fields()...)
+	}
+}
+
+// Recover runs drain passes back to back, RecoveryPause apart, until the backlog is at or below
+// RecoveryLowWater, a pass shrinks nothing, the database goes offline, or ctx expires. It returns
+// the sum of the passes.
+func (bp *BufferProcessor) Recover(ctx context.Context) (DrainResult, error) {
+	var total DrainResult
+	started := time.Now()
+	defer func() {
+		total.Duration = time.Since(started)
+	}()
+	for {
+		result, err := bp.Drain(ctx)
+		total.Attempted += result.Attempted
+		total.Succeeded += result.Succeeded
+		total.Requeued += result.Requeued
+		total.DeadLettered += result.DeadLettered
+		total.RemainingEstimate = result.RemainingEstimate
+		if !errors.Is(err, ErrDrainInProgress) {
+			bp.beat()
+		}
+		if err != nil {
+			return total, err
+		}
+		if result.RemainingEstimate <= bp.cfg.RecoveryLowWater || result.Succeeded+result.DeadLettered == 0 {
+			return total, nil
+		}
+		select {
+		case <-ctx.Done():
+			return total, nil
+		case <-time.After(bp.cfg.RecoveryPause):
+		}
+	}
+}
 
-	return bp
+func (bp *BufferProcessor) beat() {
+	if bp.cfg.Heartbeat != nil {
+		bp.cfg.Heartbeat()
+	}
+}
+
+// PurgeExpired drops queued items older than the configured retention without replaying them.
+// Dead-lettered items are never touched. It waits for a running drain so the two never handle the
+// same items at once.
+func (bp *BufferProcessor) PurgeExpired() (int, error) {
+	if bp == nil || bp.store == nil || bp.cfg.Retention <= 0 {
+		return 0, nil
+	}
+	bp.draining.Lock()
+	defer bp.draining.Unlock()
+
+	cutoff := bp.store.Now().Add(-bp.cfg.Retention)
+	purged, err := bp.store.Cleanup(cutoff)
+	if err != nil {
+		return purged, err
+	}
+	if purged > 0 {
+		// Logged apart from drain results: these operations were discarded, not applied.
+		bp.logger.Warn("expired buffer items purged without being applied",
+			zap.Int("expired", purged),
+			zap.Duration("retention", bp.cfg.Retention),
+			zap.Time("cutoff", cutoff))
+	}
+	return purged, nil
 }
 
 // Start launches the cron scheduler.
@@ -89,7 +347,7 @@ func (bp *BufferProcessor) Start() {
 	bp.logger.Info("buffer processor started")
 }
 
-// Stop gracefully stops the scheduler.
+// Stop gracefully stops the scheduler and waits for pending callbacks until ctx expires.
 func (bp *BufferProcessor) Stop(ctx context.Context) {
 	if bp == nil || bp.cron == nil {
 		return
@@ -99,52 +357,152 @@ func (bp *BufferProcessor) Stop(ctx context.Context) {
 	case <-stopCtx.Done():
 	case <-ctx.Done():
 	}
+	callbacksDone := make(chan struct{})
+	go func() {
+		bp.callbacks.Wait()
+		close(callbacksDone)
+	}()
+	select {
+	case <-callbacksDone:
+	case <-ctx.Done():
+	}
 	bp.logger.Info("buffer processor stopped")
 }
 
-// Drain processes buffered items synchronously.
-func (bp *BufferProcessor) Drain(ctx context.Context) error {
+// Drain processes buffered items synchronously. Only one pass runs at a time; a concurrent
+// call returns ErrDrainInProgress immediately instead of waiting.
+func (bp *BufferProcessor) Drain(ctx context.Context) (result DrainResult, err error) {
 	if bp == nil || bp.store == nil {
-		return nil
+		return result, nil
+	}
+	if !bp.draining.TryLock() {
+		return result, ErrDrainInProgress
 	}
+	defer bp.draining.Unlock()
+
+	started := bp.store.Now()
+	defer func() {
+		result.Duration = bp.store.Now().Sub(started)
+	}()
+
 	if bp.monitor != nil && !bp.monitor.IsOnline() {
 		bp.logger.Debug("skipping buffer drain (offline)")
-		return nil
+		result.RemainingEstimate = bp.Size()
+		return result, nil
 	}
 
-	items, err := bp.store.GetBatch(bp.cfg.BatchSize)
+	items, err := bp.nextBatch()
 	if err != nil {
-		return err
+		return result, err
 	}
 
+	now := bp.store.Now()
 	for _, item := range items {
-		if err := bp.processItem(ctx, item); err != nil {
+		result.Attempted++
+		handled := bp.retries.Handle(ctx, item, now, bp.processItem)
+		item.Retries = handled.Attempts
+		if handled.Cause != nil {
 			bp.logger.Error("failed to process buffer item",
 				zap.String("item_id", item.ID),
 				zap.String("entity", item.Entity),
-				zap.Error(err))
+				zap.Error(handled.Cause))
+		}
 
-			item.Retries++
-			if item.Retries >= bp.cfg.MaxRetries {
-				bp.logger.Warn("dropping buffer item (max retries reached)", zap.String("item_id", item.ID))
-				_ = bp.store.Remove(item)
-				continue
+		switch handled.Outcome {
+		case retry.Succeeded:
+			result.Succeeded++
+			if handled.Err != nil {
+				bp.logger.Warn("failed to purge processed buffer item", zap.Error(handled.Err))
 			}
-
-			if err := bp.store.Remove(item); err != nil {
-				bp.logger.Warn("failed to remove buffer item", zap.Error(err))
+			bp.notify(item, OutcomeApplied, nil)
+		case retry.Retried:
+			if handled.Err != nil {
+				bp.logger.Error("failed to requeue buffer item", zap.Error(handled.Err))
+				continue
 			}
-			if err := bp.store.Requeue(item); err != nil {
-				bp.logger.Error("failed to requeue buffer item", zap.Error(err))
+			result.Requeued++
+		case retry.DeadLettered:
+			if handled.Err != nil {
+				bp.logger.Error("failed to dead-letter buffer item", zap.String("item_id", item.ID), zap.Error(handled.Err))
+				continue
 			}
-			continue
+			result.DeadLettered++
+			bp.deadLettered(item, now, handled.Cause)
 		}
+	}
+	result.RemainingEstimate = bp.Size()
+	return result, nil
+}
 
-		if err := bp.store.Remove(item); err != nil {
-			bp.logger.Warn("failed to purge processed buffer item", zap.Error(err))
+// drainEntities is the order entities are fetched in when per-entity batch sizes are configured.
+var drainEntities = []string{buffer.EntityProfile, buffer.EntityTask}
+
+func (bp *BufferProcessor) nextBatch() ([]buffer.Item, error) {
+	if len(bp.cfg.EntityBatchSizes) == 0 {
+		return bp.store.GetBatch(bp.cfg.BatchSize)
+	}
+	var items []buffer.Item
+	for _, entity := range drainEntities {
+		size := bp.cfg.EntityBatchSizes[entity]
+		if size <= 0 {
+			size = bp.cfg.BatchSize
+		}
+		batch, err := bp.store.GetEntityBatch(entity, size)
+		if err != nil {
+			return nil, err
 		}
+		items = append(items, batch...)
+	}
+	return items, nil
+}
+
+// bufferQueue records retry outcomes in the buffer store.
+type bufferQueue struct {
+	store BufferStore
+}
+
+func (q bufferQueue) Ack(_ context.Context, item buffer.Item) error {
+	return q.store.Remove(item)
+}
+
+func (q bufferQueue) Retry(_ context.Context, item buffer.Item, attempts int, next time.Time, _ error) error {
+	item.Retries = attempts
+	return q.store.Reschedule(item, next)
+}
+
+func (q bufferQueue) DeadLetter(_ context.Context, item buffer.Item, attempts int, _ error) error {
+	item.Retries = attempts
+	return q.store.DeadLetter(item)
+}
+
+// deadLettered reports an item the retry engine set aside; cause is the last processing error, nil
+// when the item aged out.
+func (bp *BufferProcessor) deadLettered(item buffer.Item, now time.Time, cause error) {
+	bp.logger.Warn("dead-lettered buffer item",
+		zap.String("item_id", item.ID),
+		zap.String("entity", item.Entity),
+		zap.Int("retries", item.Retries),
+		zap.Duration("age", item.Age(now)))
+	bp.notify(item, OutcomeDeadLettered, cause)
+	fields := map[string]string{"item_id": item.ID, "entity": item.Entity, "operation": item.Operation}
+	if cause != nil {
+		fields["error"] = cause.Error()
 	}
-	return nil
+	bp.alert(notify.EventBufferDeadLettered, "buffer item dead-lettered", fields)
+}
+
+// alert sends a notification in the background, tracked like callbacks so Stop waits for it.
+func (bp *BufferProcessor) alert(event, message string, fields map[string]string) {
+	n := notify.Notification{Event: event, Message: message, Fields: fields, Time: bp.store.Now()}
+	bp.callbacks.Add(1)
+	go func() {
+		defer bp.callbacks.Done()
+		ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+		defer cancel()
+		if err := bp.cfg.Notifier.Notify(ctx, n); err != nil {
+			bp.logger.Warn("buffer alert failed", zap.String("event", event), zap.Error(err))
+		}
+	}()
 }
 
 // BufferOperation attempts to run the operation immediately and falls back to persisting it.
@@ -160,7 +518,16 @@ func (bp *BufferProcessor) BufferOperation(ctx context.Context, item buffer.Item
 			bp.logger.Warn("immediate processing failed, buffering", zap.Error(err))
 		}
 	}
-	return bp.store.Enqueue(item)
+	err := bp.store.Enqueue(item)
+	switch {
+	case errors.Is(err, buffer.ErrBufferFull):
+		if !bp.full.Swap(true) {
+			bp.alert(notify.EventBufferFull, "buffer is full, rejecting writes", map[string]string{"entity": item.Entity})
+		}
+	case err == nil:
+		bp.full.Store(false)
+	}
+	return err
 }
 
 // Size returns the number of buffered items.
@@ -200,7 +567,11 @@ func (bp *BufferProcessor) processItem(ctx context.Context, item buffer.Item) er
 		case buffer.OperationUpdate:
 			return bp.taskRepo.Update(ctx, &task)
 		case buffer.OperationDelete:
-			return bp.taskRepo.Delete(ctx, task.ID)
+			if task.UserID == "" {
+				// Deletes buffered before they carried their owner hold only the task ID.
+				return bp.taskRepo.Delete(ctx, task.ID)
+			}
+			return bp.taskRepo.DeleteOwned(ctx, task.ID, task.UserID, task.TenantID)
 		default:
 			return fmt.Errorf("unsupported operation %s", item.Operation)
 		}
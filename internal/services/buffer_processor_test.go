@@ -0,0 +1,639 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/fastygo/backend/domain"
+	"github.com/fastygo/backend/internal/infrastructure/buffer"
+	"github.com/fastygo/backend/internal/infrastructure/buffer/buffertest"
+	"github.com/fastygo/backend/internal/notify"
+	"github.com/fastygo/backend/pkg/clock"
+	"github.com/fastygo/backend/repository"
+	"github.com/fastygo/backend/repository/repositorytest"
+)
+
+var testStart = time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)
+
+func taskItem(t *testing.T, id string, task domain.Task) buffer.Item {
+	t.Helper()
+	data, err := json.Marshal(task)
+	if err != nil {
+		t.Fatalf("marshal task: %v", err)
+	}
+	return buffer.Item{ID: id, Entity: buffer.EntityTask, Operation: buffer.OperationCreate, Data: data}
+}
+
+func newTestProcessor(t *testing.T, store BufferStore, monitor ConnectionHealth, users repository.UserRepository, tasks repository.TaskRepository, logger *zap.Logger, cfg ProcessorConfig) *BufferProcessor {
+	t.Helper()
+	bp, err := NewBufferProcessor(store, monitor, users, tasks, logger, cfg)
+	if err != nil {
+		t.Fatalf("new processor: %v", err)
+	}
+	return bp
+}
+
+func TestDrainDeadLettersItemsOlderThanMaxAge(t *testing.T) {
+	fake := clock.NewFake(testStart)
+	store := buffertest.NewMemoryStore(fake)
+	tasks := repositorytest.NewTasks()
+	bp := newTestProcessor(t, store, nil, repositorytest.NewUsers(), tasks, nil, ProcessorConfig{
+		MaxRetries: 10,
+		MaxAge:     time.Hour,
+	})
+
+	if err := store.Enqueue(taskItem(t, "aged", domain.Task{ID: "t-aged", UserID: "u1"})); err != nil {
+		t.Fatalf("enqueue: %v", err)
+	}
+	fake.Advance(2 * time.Hour)
+	if err := store.Enqueue(taskItem(t, "fresh", domain.Task{ID: "t-fresh", UserID: "u1"})); err != nil {
+		t.Fatalf("enqueue: %v", err)
+	}
+
+	result, err := bp.Drain(context.Background())
+	if err != nil {
+		t.Fatalf("drain: %v", err)
+	}
+	if result.DeadLettered != 1 || result.Succeeded != 1 {
+		t.Fatalf("result = %+v, want 1 dead-lettered and 1 succeeded", result)
+	}
+
+	dead := store.DeadLettered()
+	if len(dead) != 1 || dead[0].ID != "aged" {
+		t.Fatalf("dead-lettered = %+v, want only the aged item", dead)
+	}
+	if _, err := tasks.GetByID(context.Background(), "t-aged"); err != domain.ErrTaskNotFound {
+		t.Fatalf("aged operation must not be applied, got %v", err)
+	}
+	if _, err := tasks.GetByID(context.Background(), "t-fresh"); err != nil {
+		t.Fatalf("fresh operation should be applied: %v", err)
+	}
+}
+
+// blockingTasks parks Create until release is closed so a drain can be held mid-pass.
+type blockingTasks struct {
+	*repositorytest.Tasks
+	entered chan struct{}
+	release chan struct{}
+}
+
+func (b *blockingTasks) Create(ctx context.Context, task *domain.Task) (*domain.Task, error) {
+	b.entered <- struct{}{}
+	<-b.release
+	return b.Tasks.Create(ctx, task)
+}
+
+func TestDrainRejectsConcurrentPass(t *testing.T) {
+	store := buffertest.NewMemoryStore(clock.NewFake(testStart))
+	tasks := &blockingTasks{Tasks: repositorytest.NewTasks(), entered: make(chan struct{}, 1), release: make(chan struct{})}
+	bp := newTestProcessor(t, store, nil, repositorytest.NewUsers(), tasks, nil, ProcessorConfig{})
+
+	if err := store.Enqueue(taskItem(t, "a", domain.Task{ID: "t-a", UserID: "u1"})); err != nil {
+		t.Fatalf("enqueue: %v", err)
+	}
+
+	done := make(chan DrainResult)
+	go func() {
+		result, _ := bp.Drain(context.Background())
+		done <- result
+	}()
+	<-tasks.entered
+
+	if _, err := bp.Drain(context.Background()); err != ErrDrainInProgress {
+		t.Fatalf("concurrent drain error = %v, want ErrDrainInProgress", err)
+	}
+
+	close(tasks.release)
+	if result := <-done; result.Succeeded != 1 {
+		t.Fatalf("first drain result = %+v, want the item applied once", result)
+	}
+	if _, err := bp.Drain(context.Background()); err != nil {
+		t.Fatalf("drain after the first finished: %v", err)
+	}
+}
+
+// slowTasks advances the fake clock on every write and fails tasks listed in reject.
+type slowTasks struct {
+	*repositorytest.Tasks
+	clock  *clock.Fake
+	step   time.Duration
+	reject map[string]bool
+}
+
+func (s *slowTasks) Create(ctx context.Context, task *domain.Task) (*domain.Task, error) {
+	s.clock.Advance(s.step)
+	if s.reject[task.ID] {
+		return nil, errors.New("database unavailable")
+	}
+	return s.Tasks.Create(ctx, task)
+}
+
+func TestDrainCountsMixedBatch(t *testing.T) {
+	fake := clock.NewFake(testStart)
+	store := buffertest.NewMemoryStore(fake)
+	tasks := &slowTasks{Tasks: repositorytest.NewTasks(), clock: fake, step: time.Second, reject: map[string]bool{"t-bad": true}}
+	bp := newTestProcessor(t, store, nil, repositorytest.NewUsers(), tasks, nil, ProcessorConfig{MaxRetries: 3})
+
+	exhausted := taskItem(t, "exhausted", domain.Task{ID: "t-exhausted", UserID: "u1"})
+	exhausted.Retries = 3
+	for _, item := range []buffer.Item{
+		taskItem(t, "ok-1", domain.Task{ID: "t-1", UserID: "u1"}),
+		taskItem(t, "ok-2", domain.Task{ID: "t-2", UserID: "u1"}),
+		taskItem(t, "bad", domain.Task{ID: "t-bad", UserID: "u1"}),
+		exhausted,
+	} {
+		if err := store.Enqueue(item); err != nil {
+			t.Fatalf("enqueue %s: %v", item.ID, err)
+		}
+	}
+
+	result, err := bp.Drain(context.Background())
+	if err != nil {
+		t.Fatalf("drain: %v", err)
+	}
+	want := DrainResult{Attempted: 4, Succeeded: 2, Requeued: 1, DeadLettered: 1, RemainingEstimate: 1, Duration: 3 * time.Second}
+	if result != want {
+		t.Fatalf("result = %+v, want %+v", result, want)
+	}
+}
+
+// failingReschedule counts Remove calls and rejects every Reschedule and DeadLetter, standing in for a
+// store that fails mid-write.
+type failingReschedule struct {
+	*buffertest.MemoryStore
+	removes int
+}
+
+func (f *failingReschedule) Remove(item buffer.Item) error {
+	f.removes++
+	return f.MemoryStore.Remove(item)
+}
+
+func (f *failingReschedule) Reschedule(buffer.Item, time.Time) error {
+	return errors.New("disk full")
+}
+
+func (f *failingReschedule) DeadLetter(buffer.Item) error {
+	return errors.New("disk full")
+}
+
+func TestDrainKeepsItemWhenRescheduleOrDeadLetterFails(t *testing.T) {
+	store := &failingReschedule{MemoryStore: buffertest.NewMemoryStore(clock.NewFake(testStart))}
+	tasks := repositorytest.NewTasks()
+	tasks.Err = errors.New("database unavailable")
+	bp := newTestProcessor(t, store, nil, repositorytest.NewUsers(), tasks, nil, ProcessorConfig{MaxRetries: 3})
+
+	exhausted := taskItem(t, "exhausted", domain.Task{ID: "t-exhausted", UserID: "u1"})
+	exhausted.Retries = 3
+	for _, item := range []buffer.Item{taskItem(t, "retry", domain.Task{ID: "t-retry", UserID: "u1"}), exhausted} {
+		if err := store.Enqueue(item); err != nil {
+			t.Fatalf("enqueue %s: %v", item.ID, err)
+		}
+	}
+
+	result, err := bp.Drain(context.Background())
+	if err != nil {
+		t.Fatalf("drain: %v", err)
+	}
+	if result.Requeued != 0 || result.DeadLettered != 0 {
+		t.Fatalf("result = %+v, failed writes must not be counted", result)
+	}
+	if store.removes != 0 {
+		t.Fatalf("Remove called %d times; failure branches must not delete before rewriting", store.removes)
+	}
+	if items := store.Items(); len(items) != 2 {
+		t.Fatalf("active items = %+v, want both kept after failed writes", items)
+	}
+}
+
+func TestDrainWithBoltStoreReschedulesAndDeadLetters(t *testing.T) {
+	fake := clock.NewFake(testStart)
+	store, err := buffer.Open(filepath.Join(t.TempDir(), "buffer.db"), "buffer", buffer.WithClock(fake))
+	if err != nil {
+		t.Fatalf("open store: %v", err)
+	}
+	t.Cleanup(func() { _ = store.Close() })
+
+	tasks := repositorytest.NewTasks()
+	tasks.Err = errors.New("database unavailable")
+	bp := newTestProcessor(t, store, nil, repositorytest.NewUsers(), tasks, nil, ProcessorConfig{
+		MaxRetries:   3,
+		RetryBackoff: time.Minute,
+	})
+
+	exhausted := taskItem(t, "exhausted", domain.Task{ID: "t-exhausted", UserID: "u1"})
+	exhausted.Retries = 3
+	for _, item := range []buffer.Item{taskItem(t, "retry", domain.Task{ID: "t-retry", UserID: "u1"}), exhausted} {
+		if err := store.Enqueue(item); err != nil {
+			t.Fatalf("enqueue %s: %v", item.ID, err)
+		}
+	}
+
+	result, err := bp.Drain(context.Background())
+	if err != nil {
+		t.Fatalf("drain: %v", err)
+	}
+	if result.Requeued != 1 || result.DeadLettered != 1 {
+		t.Fatalf("result = %+v, want 1 requeued and 1 dead-lettered", result)
+	}
+	if size, _ := store.Size(); size != 1 {
+		t.Fatalf("active size = %d, want the rescheduled item only", size)
+	}
+	if dead, _ := store.DeadLetterSize(); dead != 1 {
+		t.Fatalf("dead-letter size = %d, want 1", dead)
+	}
+
+	batch, err := store.GetBatch(10)
+	if err != nil {
+		t.Fatalf("get batch: %v", err)
+	}
+	if len(batch) != 0 {
+		t.Fatalf("batch = %+v, a rescheduled item must wait for its NextAttempt", batch)
+	}
+
+	fake.Advance(time.Minute)
+	batch, err = store.GetBatch(10)
+	if err != nil {
+		t.Fatalf("get batch: %v", err)
+	}
+	if len(batch) != 1 || batch[0].ID != "retry" || batch[0].Retries != 1 {
+		t.Fatalf("batch after backoff = %+v, want the retried item with one retry", batch)
+	}
+}
+
+func TestDrainRetriesWithBackoffUntilRecovered(t *testing.T) {
+	fake := clock.NewFake(testStart)
+	store := buffertest.NewMemoryStore(fake)
+	tasks := repositorytest.NewTasks()
+	tasks.Err = errors.New("database unavailable")
+	bp := newTestProcessor(t, store, nil, repositorytest.NewUsers(), tasks, nil, ProcessorConfig{
+		MaxRetries:   5,
+		RetryBackoff: time.Minute,
+	})
+	if err := store.Enqueue(taskItem(t, "retry", domain.Task{ID: "t-retry", UserID: "u1"})); err != nil {
+		t.Fatalf("enqueue: %v", err)
+	}
+
+	if result, _ := bp.Drain(context.Background()); result.Requeued != 1 {
+		t.Fatalf("first drain = %+v, want the item requeued", result)
+	}
+	items := store.Items()
+	if len(items) != 1 || items[0].Retries != 1 || !items[0].NextAttempt.Equal(testStart.Add(time.Minute)) {
+		t.Fatalf("items = %+v, want one retry deferred by the backoff", items)
+	}
+
+	if result, _ := bp.Drain(context.Background()); result.Attempted != 0 {
+		t.Fatalf("drain before backoff = %+v, want nothing attempted", result)
+	}
+
+	tasks.Err = nil
+	fake.Advance(time.Minute)
+	if result, _ := bp.Drain(context.Background()); result.Succeeded != 1 || result.RemainingEstimate != 0 {
+		t.Fatalf("drain after recovery = %+v, want the item applied and removed", result)
+	}
+	if _, err := tasks.GetByID(context.Background(), "t-retry"); err != nil {
+		t.Fatalf("recovered operation not applied: %v", err)
+	}
+}
+
+func TestDrainDeadLettersAfterMaxRetries(t *testing.T) {
+	fake := clock.NewFake(testStart)
+	store := buffertest.NewMemoryStore(fake)
+	tasks := repositorytest.NewTasks()
+	tasks.Err = errors.New("database unavailable")
+	bp := newTestProcessor(t, store, nil, repositorytest.NewUsers(), tasks, nil, ProcessorConfig{
+		MaxRetries:   2,
+		RetryBackoff: time.Minute,
+	})
+	if err := store.Enqueue(taskItem(t, "doomed", domain.Task{ID: "t-doomed", UserID: "u1"})); err != nil {
+		t.Fatalf("enqueue: %v", err)
+	}
+
+	if result, _ := bp.Drain(context.Background()); result.Requeued != 1 {
+		t.Fatalf("first drain = %+v, want a retry", result)
+	}
+	fake.Advance(time.Minute)
+	if result, _ := bp.Drain(context.Background()); result.DeadLettered != 1 {
+		t.Fatalf("second drain = %+v, want the item dead-lettered on its last retry", result)
+	}
+
+	if len(store.Items()) != 0 {
+		t.Fatalf("active items = %+v, want none", store.Items())
+	}
+	dead := store.DeadLettered()
+	if len(dead) != 1 || dead[0].ID != "doomed" || dead[0].Retries != 2 {
+		t.Fatalf("dead-lettered = %+v, want the item with 2 retries", dead)
+	}
+}
+
+func TestPurgeExpiredDropsAgedItemsAndKeepsDeadLetters(t *testing.T) {
+	fake := clock.NewFake(testStart)
+	store := buffertest.NewMemoryStore(fake)
+	bp := newTestProcessor(t, store, nil, repositorytest.NewUsers(), repositorytest.NewTasks(), nil, ProcessorConfig{
+		Retention: 24 * time.Hour,
+	})
+
+	for _, id := range []string{"aged", "dead"} {
+		if err := store.Enqueue(taskItem(t, id, domain.Task{ID: "t-" + id, UserID: "u1"})); err != nil {
+			t.Fatalf("enqueue %s: %v", id, err)
+		}
+	}
+	items, _ := store.GetBatch(10)
+	for _, item := range items {
+		if item.ID == "dead" {
+			if err := store.DeadLetter(item); err != nil {
+				t.Fatalf("dead-letter: %v", err)
+			}
+		}
+	}
+	fake.Advance(25 * time.Hour)
+	if err := store.Enqueue(taskItem(t, "fresh", domain.Task{ID: "t-fresh", UserID: "u1"})); err != nil {
+		t.Fatalf("enqueue fresh: %v", err)
+	}
+
+	purged, err := bp.PurgeExpired()
+	if err != nil || purged != 1 {
+		t.Fatalf("purge = %d, %v; want the aged item expired", purged, err)
+	}
+	if left := store.Items(); len(left) != 1 || left[0].ID != "fresh" {
+		t.Fatalf("active items = %+v, want only the fresh one", left)
+	}
+	if dead := store.DeadLettered(); len(dead) != 1 || dead[0].ID != "dead" {
+		t.Fatalf("dead letters = %+v, want them left for operators", dead)
+	}
+}
+
+func TestPurgeExpiredDisabledWithoutRetention(t *testing.T) {
+	fake := clock.NewFake(testStart)
+	store := buffertest.NewMemoryStore(fake)
+	bp := newTestProcessor(t, store, nil, repositorytest.NewUsers(), repositorytest.NewTasks(), nil, ProcessorConfig{})
+	if err := store.Enqueue(taskItem(t, "old", domain.Task{ID: "t-old", UserID: "u1"})); err != nil {
+		t.Fatalf("enqueue: %v", err)
+	}
+	fake.Advance(365 * 24 * time.Hour)
+
+	if purged, err := bp.PurgeExpired(); err != nil || purged != 0 {
+		t.Fatalf("purge = %d, %v; want nothing without a retention", purged, err)
+	}
+}
+
+func TestNewBufferProcessorSchedule(t *testing.T) {
+	store := buffertest.NewMemoryStore(clock.NewFake(testStart))
+	for schedule, valid := range map[string]bool{
+		"":               true,
+		"0 */15 * * * *": true,
+		"@daily":         true,
+		"*/15 * * * *":   false, // missing the seconds field
+		"every night":    false,
+	} {
+		_, err := NewBufferProcessor(store, nil, repositorytest.NewUsers(), repositorytest.NewTasks(), nil, ProcessorConfig{Schedule: schedule})
+		if valid && err != nil {
+			t.Errorf("schedule %q rejected: %v", schedule, err)
+		}
+		if !valid && err == nil {
+			t.Errorf("schedule %q accepted, want an error", schedule)
+		}
+	}
+}
+
+func TestNewBufferProcessorSurfacesRejectedSchedule(t *testing.T) {
+	store := buffertest.NewMemoryStore(clock.NewFake(testStart))
+
+	bp, err := NewBufferProcessor(store, nil, repositorytest.NewUsers(), repositorytest.NewTasks(), nil, ProcessorConfig{Schedule: "61 * * * * *"})
+
+	if err == nil || bp != nil {
+		t.Fatalf("processor = %v, err = %v; want no processor and an error", bp, err)
+	}
+	if !strings.Contains(err.Error(), "61 * * * * *") {
+		t.Fatalf("err = %v, want it to name the rejected schedule", err)
+	}
+}
+
+func TestDrainHonoursPerEntityBatchSizes(t *testing.T) {
+	store := buffertest.NewMemoryStore(clock.NewFake(testStart))
+	tasks := repositorytest.NewTasks()
+	users := repositorytest.NewUsers()
+	bp := newTestProcessor(t, store, nil, users, tasks, nil, ProcessorConfig{
+		BatchSize:        2,
+		EntityBatchSizes: map[string]int{buffer.EntityProfile: 5, buffer.EntityTask: 0},
+	})
+
+	for i := 0; i < 6; i++ {
+		if err := store.Enqueue(taskItem(t, fmt.Sprintf("task-%d", i), domain.Task{ID: fmt.Sprintf("t%d", i), UserID: "u1"})); err != nil {
+			t.Fatalf("enqueue task: %v", err)
+		}
+		data, _ := json.Marshal(domain.User{ID: fmt.Sprintf("u%d", i)})
+		if err := store.Enqueue(buffer.Item{ID: fmt.Sprintf("profile-%d", i), Entity: buffer.EntityProfile, Operation: buffer.OperationUpdate, Data: data}); err != nil {
+			t.Fatalf("enqueue profile: %v", err)
+		}
+	}
+
+	result, err := bp.Drain(context.Background())
+	if err != nil {
+		t.Fatalf("drain: %v", err)
+	}
+	if result.Succeeded != 7 {
+		t.Fatalf("result = %+v, want 5 profiles and 2 tasks applied", result)
+	}
+	left := map[string]int{}
+	for _, item := range store.Items() {
+		left[item.Entity]++
+	}
+	if left[buffer.EntityProfile] != 1 || left[buffer.EntityTask] != 4 {
+		t.Fatalf("remaining = %v, want 1 profile and 4 tasks", left)
+	}
+}
+
+// switchableHealth lets a test take the database offline and bring it back.
+type switchableHealth struct{ online atomic.Bool }
+
+func (h *switchableHealth) IsOnline() bool { return h.online.Load() }
+
+func TestScheduledDrainRecoversLargeBacklogAfterOutage(t *testing.T) {
+	store := buffertest.NewMemoryStore(clock.NewFake(testStart))
+	tasks := repositorytest.NewTasks()
+	health := &switchableHealth{}
+	bp := newTestProcessor(t, store, health, repositorytest.NewUsers(), tasks, nil, ProcessorConfig{
+		BatchSize:         50,
+		RecoveryThreshold: 100,
+		RecoveryLowWater:  10,
+		RecoveryBudget:    time.Minute,
+	})
+
+	// Offline: the scheduled pass skips and remembers the outage.
+	bp.scheduledDrain()
+	for i := 0; i < 1000; i++ {
+		id := fmt.Sprintf("t-%04d", i)
+		if err := store.Enqueue(taskItem(t, id, domain.Task{ID: id, UserID: "u1"})); err != nil {
+			t.Fatalf("enqueue: %v", err)
+		}
+	}
+
+	health.online.Store(true)
+	bp.scheduledDrain()
+
+	if size := bp.Size(); size != 0 {
+		t.Fatalf("backlog = %d after one recovery pass, want 0", size)
+	}
+	if got, _ := tasks.List(context.Background(), repository.TaskFilter{Limit: 2000}); len(got) != 1000 {
+		t.Fatalf("applied %d tasks, want 1000", len(got))
+	}
+}
+
+func TestScheduledDrainKeepsCadenceWithoutOutage(t *testing.T) {
+	store := buffertest.NewMemoryStore(clock.NewFake(testStart))
+	health := &switchableHealth{}
+	health.online.Store(true)
+	bp := newTestProcessor(t, store, health, repositorytest.NewUsers(), repositorytest.NewTasks(), nil, ProcessorConfig{
+		BatchSize:         50,
+		RecoveryThreshold: 100,
+	})
+	for i := 0; i < 300; i++ {
+		id := fmt.Sprintf("t-%04d", i)
+		if err := store.Enqueue(taskItem(t, id, domain.Task{ID: id, UserID: "u1"})); err != nil {
+			t.Fatalf("enqueue: %v", err)
+		}
+	}
+
+	bp.scheduledDrain()
+
+	if size := bp.Size(); size != 250 {
+		t.Fatalf("backlog = %d, want a single batch of 50 drained", size)
+	}
+}
+
+func TestScheduledDrainHeartbeatsUnlessAnotherPassIsRunning(t *testing.T) {
+	var beats int
+	store := buffertest.NewMemoryStore(clock.NewFake(testStart))
+	bp := newTestProcessor(t, store, nil, repositorytest.NewUsers(), repositorytest.NewTasks(), nil, ProcessorConfig{
+		Heartbeat: func() { beats++ },
+	})
+
+	bp.scheduledDrain()
+	if beats != 1 {
+		t.Fatalf("beats = %d, want one after a completed pass", beats)
+	}
+
+	bp.draining.Lock()
+	bp.scheduledDrain()
+	bp.draining.Unlock()
+	if beats != 1 {
+		t.Fatalf("beats = %d, want none while another pass holds the buffer", beats)
+	}
+}
+
+// panickingTasks panics on the first Create, like a repository hitting a nil pointer.
+type panickingTasks struct {
+	*repositorytest.Tasks
+	panicked atomic.Bool
+}
+
+func (r *panickingTasks) Create(ctx context.Context, task *domain.Task) (*domain.Task, error) {
+	if !r.panicked.Swap(true) {
+		panic("nil pointer in repository")
+	}
+	return r.Tasks.Create(ctx, task)
+}
+
+func TestScheduledDrainPanicIsContained(t *testing.T) {
+	store := buffertest.NewMemoryStore(clock.NewFake(testStart))
+	tasks := &panickingTasks{Tasks: repositorytest.NewTasks()}
+	bp := newTestProcessor(t, store, nil, repositorytest.NewUsers(), tasks, nil, ProcessorConfig{})
+	if err := store.Enqueue(taskItem(t, "i1", domain.Task{ID: "t1", UserID: "u1"})); err != nil {
+		t.Fatalf("enqueue: %v", err)
+	}
+
+	// Run the job exactly as the scheduler would, twice: the panicking tick and the next one.
+	job := bp.cron.Entries()[0].Job
+	job.Run()
+	job.Run()
+
+	if size := bp.Size(); size != 0 {
+		t.Fatalf("backlog = %d, want the item applied on the tick after the panic", size)
+	}
+	if _, err := tasks.GetByID(context.Background(), "t1"); err != nil {
+		t.Fatalf("task not applied after the panic: %v", err)
+	}
+}
+
+// recordingNotifier collects notifications sent from background goroutines.
+type recordingNotifier struct {
+	mu   sync.Mutex
+	sent []notify.Notification
+}
+
+func (n *recordingNotifier) Notify(_ context.Context, notification notify.Notification) error {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.sent = append(n.sent, notification)
+	return nil
+}
+
+func (n *recordingNotifier) events() []string {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	var events []string
+	for _, notification := range n.sent {
+		events = append(events, notification.Event)
+	}
+	return events
+}
+
+// fullStore rejects enqueues while full is set.
+type fullStore struct {
+	BufferStore
+	full atomic.Bool
+}
+
+func (s *fullStore) Enqueue(item buffer.Item) error {
+	if s.full.Load() {
+		return buffer.ErrBufferFull
+	}
+	return s.BufferStore.Enqueue(item)
+}
+
+func TestBufferAlertsOnDeadLetterAndOncePerFullEpisode(t *testing.T) {
+	notifier := &recordingNotifier{}
+	store := &fullStore{BufferStore: buffertest.NewMemoryStore(clock.NewFake(testStart))}
+	tasks := repositorytest.NewTasks()
+	tasks.Err = errors.New("constraint violated")
+	health := &switchableHealth{}
+	bp := newTestProcessor(t, store, health, repositorytest.NewUsers(), tasks, nil, ProcessorConfig{
+		MaxRetries: 1,
+		Notifier:   notifier,
+	})
+
+	store.full.Store(true)
+	for i := 0; i < 3; i++ {
+		if err := bp.BufferOperation(context.Background(), taskItem(t, fmt.Sprintf("f%d", i), domain.Task{ID: "t", UserID: "u1"})); !errors.Is(err, buffer.ErrBufferFull) {
+			t.Fatalf("enqueue: err = %v, want ErrBufferFull", err)
+		}
+	}
+	store.full.Store(false)
+	if err := bp.BufferOperation(context.Background(), taskItem(t, "doomed", domain.Task{ID: "t-doomed", UserID: "u1"})); err != nil {
+		t.Fatalf("enqueue: %v", err)
+	}
+	health.online.Store(true)
+	if _, err := bp.Drain(context.Background()); err != nil {
+		t.Fatalf("drain: %v", err)
+	}
+	bp.callbacks.Wait()
+
+	got := notifier.events()
+	sort.Strings(got)
+	if want := []string{notify.EventBufferDeadLettered, notify.EventBufferFull}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("events = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,58 @@
+package lifecycle
+
+import (
+	"context"
+	"net"
+	"sync/atomic"
+)
+
+// Readiness tracks whether the service has finished initialization and can accept traffic.
+type Readiness struct {
+	ready atomic.Bool
+}
+
+// NewReadiness returns a readiness flag in the not-ready state.
+func NewReadiness() *Readiness {
+	return &Readiness{}
+}
+
+// MarkReady flips the flag once all dependencies and the listener are up.
+func (r *Readiness) MarkReady() {
+	r.ready.Store(true)
+}
+
+// MarkNotReady flips the flag back, e.g. when shutdown begins.
+func (r *Readiness) MarkNotReady() {
+	r.ready.Store(false)
+}
+
+// IsReady reports the current readiness state.
+func (r *Readiness) IsReady() bool {
+	return r != nil && r.ready.Load()
+}
+
+// Check confirms that one dependency is up before readiness is advertised.
+type Check func(ctx context.Context) error
+
+// MarkReadyAfter runs checks in order and flips the flag only when every one of them passes.
+func (r *Readiness) MarkReadyAfter(ctx context.Context, checks ...Check) error {
+	for _, check := range checks {
+		if err := check(ctx); err != nil {
+			return err
+		}
+	}
+	r.MarkReady()
+	return nil
+}
+
+// ListenerCheck confirms that addr accepts TCP connections.
+func ListenerCheck(addr string) Check {
+	return func(ctx context.Context) error {
+		var dialer net.Dialer
+		conn, err := dialer.DialContext(ctx, "tcp", addr)
+		if err != nil {
+			return err
+		}
+		return conn.Close()
+	}
+}
@@ -0,0 +1,69 @@
+package lifecycle_test
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+
+	"github.com/fastygo/backend/internal/services/lifecycle"
+)
+
+func TestMarkReadyAfterWaitsForChecks(t *testing.T) {
+	readiness := lifecycle.NewReadiness()
+	started := make(chan struct{})
+	release := make(chan struct{})
+	done := make(chan error)
+
+	go func() {
+		done <- readiness.MarkReadyAfter(context.Background(), func(context.Context) error {
+			close(started)
+			<-release
+			return nil
+		})
+	}()
+
+	<-started
+	if readiness.IsReady() {
+		t.Fatal("ready before initialization completed")
+	}
+	close(release)
+	if err := <-done; err != nil {
+		t.Fatalf("mark ready: %v", err)
+	}
+	if !readiness.IsReady() {
+		t.Fatal("not ready after initialization completed")
+	}
+}
+
+func TestMarkReadyAfterStaysNotReadyOnFailedCheck(t *testing.T) {
+	readiness := lifecycle.NewReadiness()
+	failure := errors.New("database unreachable")
+
+	err := readiness.MarkReadyAfter(context.Background(),
+		func(context.Context) error { return nil },
+		func(context.Context) error { return failure },
+	)
+	if !errors.Is(err, failure) {
+		t.Fatalf("error = %v, want %v", err, failure)
+	}
+	if readiness.IsReady() {
+		t.Fatal("ready despite a failed check")
+	}
+}
+
+func TestListenerCheck(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	addr := listener.Addr().String()
+
+	if err := lifecycle.ListenerCheck(addr)(context.Background()); err != nil {
+		t.Fatalf("open listener check: %v", err)
+	}
+	listener.Close()
+	if err := lifecycle.ListenerCheck(addr)(context.Background()); err == nil {
+		t.Fatal("closed listener check succeeded")
+	}
+}
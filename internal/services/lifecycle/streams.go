@@ -0,0 +1,119 @@
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// ClosingEvent names the event or frame a streaming handler sends when Closing fires, telling the
+// client to reconnect, ideally to another instance.
+const ClosingEvent = "server_closing"
+
+// ErrShuttingDown is returned by Streams.Open once shutdown has started.
+var ErrShuttingDown = errors.New("server is shutting down")
+
+// Streams tracks long-lived streaming responses (SSE, WebSocket) so shutdown can ask their clients
+// to reconnect and give them a grace period before the HTTP server cuts the connections.
+type Streams struct {
+	grace time.Duration
+
+	mu      sync.Mutex
+	open    map[*Stream]struct{}
+	closing bool
+	drained chan struct{}
+}
+
+// Stream is one open streaming response. Its handler writes ClosingEvent when Closing fires and
+// must stop writing and return once Done fires, calling Close on the way out.
+type Stream struct {
+	owner     *Streams
+	closing   chan struct{}
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+// NewStreams returns an empty registry; shutdown waits at most grace for streams to end on their own.
+func NewStreams(grace time.Duration) *Streams {
+	return &Streams{grace: grace, open: make(map[*Stream]struct{})}
+}
+
+// Open registers a stream. Handlers should answer 503 on ErrShuttingDown.
+func (s *Streams) Open() (*Stream, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closing {
+		return nil, ErrShuttingDown
+	}
+	stream := &Stream{owner: s, closing: make(chan struct{}), done: make(chan struct{})}
+	s.open[stream] = struct{}{}
+	return stream, nil
+}
+
+// Len reports how many streams are open.
+func (s *Streams) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.open)
+}
+
+// Shutdown refuses new streams and fires every open stream's Closing. It then waits for the grace
+// period, or ctx, whichever ends first, or until every stream has closed. Streams still open after
+// that get Done and are expected to return promptly. Register it to run before the HTTP server stops.
+func (s *Streams) Shutdown(ctx context.Context) error {
+	s.mu.Lock()
+	if s.closing {
+		s.mu.Unlock()
+		return nil
+	}
+	s.closing = true
+	s.drained = make(chan struct{})
+	for stream := range s.open {
+		close(stream.closing)
+	}
+	if len(s.open) == 0 {
+		close(s.drained)
+	}
+	drained := s.drained
+	s.mu.Unlock()
+
+	timer := time.NewTimer(s.grace)
+	defer timer.Stop()
+	select {
+	case <-drained:
+		return nil
+	case <-timer.C:
+	case <-ctx.Done():
+	}
+
+	s.mu.Lock()
+	for stream := range s.open {
+		close(stream.done)
+	}
+	s.mu.Unlock()
+	return nil
+}
+
+// Closing fires when shutdown starts; the handler should send ClosingEvent to its client.
+func (st *Stream) Closing() <-chan struct{} {
+	return st.closing
+}
+
+// Done fires when the grace period is over; the handler must stop writing.
+func (st *Stream) Done() <-chan struct{} {
+	return st.done
+}
+
+// Close unregisters the stream. It is safe to call more than once.
+func (st *Stream) Close() {
+	st.closeOnce.Do(func() {
+		s := st.owner
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		delete(s.open, st)
+		if s.closing && len(s.open) == 0 {
+			close(s.drained)
+		}
+	})
+}
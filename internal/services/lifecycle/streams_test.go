@@ -0,0 +1,111 @@
+package lifecycle_test
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/fastygo/backend/internal/services/lifecycle"
+)
+
+// sseServer streams one "ready" event, then the closing event once shutdown starts.
+func sseServer(t *testing.T, streams *lifecycle.Streams) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		stream, err := streams.Open()
+		if err != nil {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		defer stream.Close()
+		w.Header().Set("Content-Type", "text/event-stream")
+		fmt.Fprint(w, "event: ready\ndata: {}\n\n")
+		w.(http.Flusher).Flush()
+		select {
+		case <-stream.Closing():
+			fmt.Fprintf(w, "event: %s\ndata: {}\n\n", lifecycle.ClosingEvent)
+			w.(http.Flusher).Flush()
+		case <-r.Context().Done():
+			return
+		}
+		select {
+		case <-stream.Done():
+		case <-r.Context().Done():
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func readEvent(t *testing.T, lines *bufio.Scanner) string {
+	t.Helper()
+	for lines.Scan() {
+		if event, ok := strings.CutPrefix(lines.Text(), "event: "); ok {
+			return event
+		}
+	}
+	t.Fatalf("stream ended without an event: %v", lines.Err())
+	return ""
+}
+
+func TestShutdownSendsClosingEventToOpenStreams(t *testing.T) {
+	streams := lifecycle.NewStreams(time.Minute)
+	srv := sseServer(t, streams)
+
+	resp, err := http.Get(srv.URL)
+	if err != nil {
+		t.Fatalf("open stream: %v", err)
+	}
+	lines := bufio.NewScanner(resp.Body)
+	if event := readEvent(t, lines); event != "ready" {
+		t.Fatalf("first event = %q, want ready", event)
+	}
+
+	shutdown := make(chan error, 1)
+	go func() { shutdown <- streams.Shutdown(context.Background()) }()
+
+	if event := readEvent(t, lines); event != lifecycle.ClosingEvent {
+		t.Fatalf("event during shutdown = %q, want %q", event, lifecycle.ClosingEvent)
+	}
+	// The client reconnecting elsewhere ends the stream, so shutdown need not wait out the grace period.
+	resp.Body.Close()
+	select {
+	case err := <-shutdown:
+		if err != nil {
+			t.Fatalf("shutdown: %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("shutdown kept waiting after the last stream closed")
+	}
+
+	if _, err := streams.Open(); !errors.Is(err, lifecycle.ErrShuttingDown) {
+		t.Fatalf("open after shutdown: err = %v, want ErrShuttingDown", err)
+	}
+}
+
+func TestShutdownForcesStreamsAfterGracePeriod(t *testing.T) {
+	streams := lifecycle.NewStreams(10 * time.Millisecond)
+	stream, err := streams.Open()
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+
+	if err := streams.Shutdown(context.Background()); err != nil {
+		t.Fatalf("shutdown: %v", err)
+	}
+	select {
+	case <-stream.Done():
+	default:
+		t.Fatal("a stream ignoring Closing was not told to stop after the grace period")
+	}
+	stream.Close()
+	if n := streams.Len(); n != 0 {
+		t.Fatalf("open streams = %d, want 0", n)
+	}
+}
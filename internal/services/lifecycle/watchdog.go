@@ -0,0 +1,63 @@
+package lifecycle
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/fastygo/backend/pkg/clock"
+)
+
+// Watchdog tracks heartbeats from background components, so a loop that panicked or deadlocked
+// shows up as a liveness failure instead of the process quietly doing nothing.
+type Watchdog struct {
+	clock clock.Clock
+	mu    sync.Mutex
+	beats map[string]heartbeat
+}
+
+type heartbeat struct {
+	last   time.Time
+	maxAge time.Duration
+}
+
+// NewWatchdog returns a watchdog with no components; clk defaults to the real clock.
+func NewWatchdog(clk clock.Clock) *Watchdog {
+	if clk == nil {
+		clk = clock.Real()
+	}
+	return &Watchdog{clock: clk, beats: make(map[string]heartbeat)}
+}
+
+// Register tracks a component that must beat at least every maxAge and returns the function it
+// calls to beat. The component counts as fresh from registration.
+func (w *Watchdog) Register(name string, maxAge time.Duration) func() {
+	w.mu.Lock()
+	w.beats[name] = heartbeat{last: w.clock.Now(), maxAge: maxAge}
+	w.mu.Unlock()
+	return func() {
+		w.mu.Lock()
+		defer w.mu.Unlock()
+		beat := w.beats[name]
+		beat.last = w.clock.Now()
+		w.beats[name] = beat
+	}
+}
+
+// Stale lists, sorted, the components whose last heartbeat is older than their maxAge.
+func (w *Watchdog) Stale() []string {
+	if w == nil {
+		return nil
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	now := w.clock.Now()
+	var stale []string
+	for name, beat := range w.beats {
+		if now.Sub(beat.last) > beat.maxAge {
+			stale = append(stale, name)
+		}
+	}
+	sort.Strings(stale)
+	return stale
+}
@@ -0,0 +1,34 @@
+package lifecycle_test
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/fastygo/backend/internal/services/lifecycle"
+	"github.com/fastygo/backend/pkg/clock"
+)
+
+func TestWatchdogFlagsStalledHeartbeats(t *testing.T) {
+	fake := clock.NewFake(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	watchdog := lifecycle.NewWatchdog(fake)
+	beatMonitor := watchdog.Register("monitor", 30*time.Second)
+	beatBuffer := watchdog.Register("buffer", time.Minute)
+
+	fake.Advance(25 * time.Second)
+	beatMonitor()
+	if stale := watchdog.Stale(); len(stale) != 0 {
+		t.Fatalf("stale = %v, want every component fresh", stale)
+	}
+
+	fake.Advance(40 * time.Second)
+	if stale := watchdog.Stale(); !reflect.DeepEqual(stale, []string{"buffer", "monitor"}) {
+		t.Fatalf("stale = %v, want both components stalled", stale)
+	}
+
+	beatBuffer()
+	beatMonitor()
+	if stale := watchdog.Stale(); len(stale) != 0 {
+		t.Fatalf("stale = %v, want a fresh beat to recover", stale)
+	}
+}
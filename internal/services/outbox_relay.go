@@ -0,0 +1,264 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/robfig/cron/v3"
+	"go.uber.org/zap"
+
+	"github.com/fastygo/backend/domain"
+	"github.com/fastygo/backend/internal/notify"
+	"github.com/fastygo/backend/internal/retry"
+	"github.com/fastygo/backend/pkg/clock"
+	"github.com/fastygo/backend/repository"
+)
+
+// ErrRelayInProgress is returned by OutboxRelay.RunOnce when another pass is still running.
+var ErrRelayInProgress = errors.New("outbox relay already in progress")
+
+// OutboxPublisher delivers one outbox message. Delivery is at least once: a message whose
+// publication succeeded but could not be marked is published again, so consumers dedupe on its ID.
+type OutboxPublisher interface {
+	Publish(ctx context.Context, msg domain.OutboxMessage) error
+}
+
+// NotifierPublisher publishes outbox messages through a notify.Notifier, with the topic as the
+// event and the message ID and payload as fields.
+type NotifierPublisher struct {
+	Notifier notify.Notifier
+}
+
+func (p NotifierPublisher) Publish(ctx context.Context, msg domain.OutboxMessage) error {
+	return p.Notifier.Notify(ctx, notify.Notification{
+		Event:   msg.Topic,
+		Message: "outbox event",
+		Fields:  map[string]string{"id": msg.ID, "payload": string(msg.Payload)},
+		Time:    msg.CreatedAt,
+	})
+}
+
+// OutboxConfig controls how often the outbox is relayed and how failures are retried.
+type OutboxConfig struct {
+	// Interval separates relay passes and bounds each one; it defaults to five seconds.
+	Interval time.Duration
+	// BatchSize bounds the messages published per pass; it defaults to 100.
+	BatchSize int
+	// MaxAttempts dead-letters a message after this many failed publications; it defaults to 10.
+	MaxAttempts int
+	// MaxAge dead-letters a message created longer ago than this. Zero disables the check.
+	MaxAge time.Duration
+	// RetryBackoff is the base delay before republishing a failed message, doubled on each retry.
+	RetryBackoff time.Duration
+	// Notifier receives dead-letter alerts; it defaults to notify.Nop.
+	Notifier notify.Notifier
+	// Clock defaults to the real clock.
+	Clock clock.Clock
+}
+
+// RelayResult summarises a single relay pass.
+type RelayResult struct {
+	Attempted    int `json:"attempted"`
+	Published    int `json:"published"`
+	Retried      int `json:"retried"`
+	DeadLettered int `json:"dead_lettered"`
+}
+
+// Fields renders the result as structured log fields.
+func (r RelayResult) Fields() []zap.Field {
+	return []zap.Field{
+		zap.Int("attempted", r.Attempted),
+		zap.Int("published", r.Published),
+		zap.Int("retried", r.Retried),
+		zap.Int("dead_lettered", r.DeadLettered),
+	}
+}
+
+// OutboxRelay publishes pending outbox messages, retrying failures with the same backoff and
+// dead-letter rules the buffer processor applies to buffered writes.
+type OutboxRelay struct {
+	repo      repository.OutboxRepository
+	publisher OutboxPublisher
+	logger    *zap.Logger
+	cfg       OutboxConfig
+	cron      *cron.Cron
+	retries   *retry.Engine[domain.OutboxMessage]
+
+	// running serializes passes, like BufferProcessor.draining.
+	running sync.Mutex
+	alerts  sync.WaitGroup
+}
+
+func NewOutboxRelay(repo repository.OutboxRepository, publisher OutboxPublisher, logger *zap.Logger, cfg OutboxConfig) (*OutboxRelay, error) {
+	if cfg.Interval <= 0 {
+		cfg.Interval = 5 * time.Second
+	}
+	if cfg.BatchSize <= 0 {
+		cfg.BatchSize = 100
+	}
+	if cfg.MaxAttempts <= 0 {
+		cfg.MaxAttempts = 10
+	}
+	if cfg.Notifier == nil {
+		cfg.Notifier = notify.Nop{}
+	}
+	if cfg.Clock == nil {
+		cfg.Clock = clock.Real()
+	}
+	if logger == nil {
+		logger = zap.NewNop()
+	}
+
+	r := &OutboxRelay{
+		repo:      repo,
+		publisher: publisher,
+		logger:    logger,
+		cfg:       cfg,
+		cron:      cron.New(cron.WithSeconds()),
+	}
+	r.retries = retry.NewEngine[domain.OutboxMessage](retry.Policy{
+		MaxAttempts: cfg.MaxAttempts,
+		MaxAge:      cfg.MaxAge,
+		Backoff:     cfg.RetryBackoff,
+	}, outboxQueue{repo: repo, clock: cfg.Clock}, func(msg domain.OutboxMessage, now time.Time) (int, time.Duration) {
+		return msg.Attempts, now.Sub(msg.CreatedAt)
+	})
+
+	schedule := fmt.Sprintf("@every %s", cfg.Interval)
+	if _, err := r.cron.AddFunc(schedule, r.scheduledRun); err != nil {
+		return nil, fmt.Errorf("invalid outbox relay schedule %q: %w", schedule, err)
+	}
+	return r, nil
+}
+
+func (r *OutboxRelay) scheduledRun() {
+	defer func() {
+		if p := recover(); p != nil {
+			r.logger.Error("outbox relay panicked", zap.Any("panic", p), zap.Stack("stack"))
+		}
+	}()
+	ctx, cancel := context.WithTimeout(context.Background(), r.cfg.Interval)
+	defer cancel()
+	result, err := r.RunOnce(ctx)
+	if errors.Is(err, ErrRelayInProgress) {
+		return
+	}
+	if err != nil {
+		r.logger.Error("outbox relay failed", append(result.Fields(), zap.Error(err))...)
+		return
+	}
+	if result.Attempted > 0 {
+		r.logger.Info("outbox relay completed", result.Fields()...)
+	}
+}
+
+// RunOnce publishes one batch of pending messages. Only one pass runs at a time; a concurrent call
+// returns ErrRelayInProgress immediately.
+func (r *OutboxRelay) RunOnce(ctx context.Context) (result RelayResult, err error) {
+	if !r.running.TryLock() {
+		return result, ErrRelayInProgress
+	}
+	defer r.running.Unlock()
+
+	now := r.cfg.Clock.Now()
+	messages, err := r.repo.Pending(ctx, now, r.cfg.BatchSize)
+	if err != nil {
+		return result, err
+	}
+	for _, msg := range messages {
+		result.Attempted++
+		handled := r.retries.Handle(ctx, msg, now, r.publisher.Publish)
+		if handled.Cause != nil {
+			r.logger.Warn("failed to publish outbox message",
+				zap.String("message_id", msg.ID),
+				zap.String("topic", msg.Topic),
+				zap.Int("attempts", handled.Attempts),
+				zap.Error(handled.Cause))
+		}
+		if handled.Err != nil {
+			// The message stays pending; a published one is published again on the next pass.
+			r.logger.Error("failed to record outbox outcome", zap.String("message_id", msg.ID), zap.Error(handled.Err))
+			continue
+		}
+		switch handled.Outcome {
+		case retry.Succeeded:
+			result.Published++
+		case retry.Retried:
+			result.Retried++
+		case retry.DeadLettered:
+			result.DeadLettered++
+			r.deadLettered(msg, handled)
+		}
+	}
+	return result, nil
+}
+
+func (r *OutboxRelay) deadLettered(msg domain.OutboxMessage, handled retry.Result) {
+	fields := map[string]string{"message_id": msg.ID, "topic": msg.Topic}
+	if handled.Cause != nil {
+		fields["error"] = handled.Cause.Error()
+	}
+	r.logger.Warn("dead-lettered outbox message",
+		zap.String("message_id", msg.ID),
+		zap.String("topic", msg.Topic),
+		zap.Int("attempts", handled.Attempts))
+
+	n := notify.Notification{Event: notify.EventOutboxDeadLettered, Message: "outbox message dead-lettered", Fields: fields, Time: r.cfg.Clock.Now()}
+	r.alerts.Add(1)
+	go func() {
+		defer r.alerts.Done()
+		ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+		defer cancel()
+		if err := r.cfg.Notifier.Notify(ctx, n); err != nil {
+			r.logger.Warn("outbox alert failed", zap.Error(err))
+		}
+	}()
+}
+
+// Start launches the scheduler.
+func (r *OutboxRelay) Start() {
+	r.cron.Start()
+	r.logger.Info("outbox relay started", zap.Duration("interval", r.cfg.Interval))
+}
+
+// Stop stops the scheduler and waits for a running pass and pending alerts until ctx expires.
+func (r *OutboxRelay) Stop(ctx context.Context) {
+	select {
+	case <-r.cron.Stop().Done():
+	case <-ctx.Done():
+	}
+	alertsDone := make(chan struct{})
+	go func() {
+		r.alerts.Wait()
+		close(alertsDone)
+	}()
+	select {
+	case <-alertsDone:
+	case <-ctx.Done():
+	}
+}
+
+// outboxQueue records retry outcomes in the outbox table.
+type outboxQueue struct {
+	repo  repository.OutboxRepository
+	clock clock.Clock
+}
+
+func (q outboxQueue) Ack(ctx context.Context, msg domain.OutboxMessage) error {
+	return q.repo.MarkPublished(ctx, msg.ID, q.clock.Now())
+}
+
+func (q outboxQueue) Retry(ctx context.Context, msg domain.OutboxMessage, attempts int, next time.Time, cause error) error {
+	return q.repo.Reschedule(ctx, msg.ID, attempts, next, cause.Error())
+}
+
+func (q outboxQueue) DeadLetter(ctx context.Context, msg domain.OutboxMessage, attempts int, cause error) error {
+	lastError := msg.LastError
+	if cause != nil {
+		lastError = cause.Error()
+	}
+	return q.repo.DeadLetter(ctx, msg.ID, attempts, lastError, q.clock.Now())
+}
@@ -0,0 +1,107 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/fastygo/backend/domain"
+	"github.com/fastygo/backend/internal/notify"
+	"github.com/fastygo/backend/pkg/clock"
+	"github.com/fastygo/backend/repository/repositorytest"
+)
+
+// failingPublisher fails the message IDs in fail and records the rest.
+type failingPublisher struct {
+	published []string
+	fail      map[string]bool
+}
+
+func (p *failingPublisher) Publish(_ context.Context, msg domain.OutboxMessage) error {
+	if p.fail[msg.ID] {
+		return errors.New("broker unavailable")
+	}
+	p.published = append(p.published, msg.ID)
+	return nil
+}
+
+func TestOutboxRelayRetriesThenDeadLetters(t *testing.T) {
+	fake := clock.NewFake(testStart)
+	outbox := repositorytest.NewOutbox(
+		domain.OutboxMessage{ID: "ok", Topic: "task.created", CreatedAt: testStart},
+		domain.OutboxMessage{ID: "poison", Topic: "task.created", CreatedAt: testStart.Add(time.Second)},
+	)
+	publisher := &failingPublisher{fail: map[string]bool{"poison": true}}
+	alerts := &recordingNotifier{}
+	relay, err := NewOutboxRelay(outbox, publisher, nil, OutboxConfig{
+		MaxAttempts:  2,
+		RetryBackoff: time.Minute,
+		Notifier:     alerts,
+		Clock:        fake,
+	})
+	if err != nil {
+		t.Fatalf("new relay: %v", err)
+	}
+
+	first, err := relay.RunOnce(context.Background())
+	if err != nil {
+		t.Fatalf("first pass: %v", err)
+	}
+	if want := (RelayResult{Attempted: 2, Published: 1, Retried: 1}); first != want {
+		t.Fatalf("first pass = %+v, want %+v", first, want)
+	}
+	if !outbox.Published("ok") || !reflect.DeepEqual(publisher.published, []string{"ok"}) {
+		t.Fatalf("published = %v, want [ok] marked in the outbox", publisher.published)
+	}
+	if msg, _ := outbox.Get("poison"); msg.Attempts != 1 || !msg.NextAttemptAt.Equal(testStart.Add(time.Minute)) || msg.LastError == "" {
+		t.Fatalf("poison after one failure = %+v, want attempt 1 deferred a minute with the error", msg)
+	}
+
+	// Still backing off: nothing is due.
+	if idle, _ := relay.RunOnce(context.Background()); idle.Attempted != 0 {
+		t.Fatalf("pass during backoff = %+v, want nothing attempted", idle)
+	}
+
+	fake.Advance(time.Minute)
+	second, err := relay.RunOnce(context.Background())
+	if err != nil {
+		t.Fatalf("second pass: %v", err)
+	}
+	if want := (RelayResult{Attempted: 1, DeadLettered: 1}); second != want {
+		t.Fatalf("second pass = %+v, want %+v", second, want)
+	}
+	if !outbox.DeadLettered("poison") {
+		t.Fatal("poison message was not dead-lettered")
+	}
+
+	relay.Stop(context.Background())
+	if got := alerts.events(); !reflect.DeepEqual(got, []string{notify.EventOutboxDeadLettered}) {
+		t.Fatalf("alerts = %v, want one dead-letter alert", got)
+	}
+}
+
+func TestOutboxRelayLeavesMessagePendingWhenOutcomeIsNotRecorded(t *testing.T) {
+	outbox := repositorytest.NewOutbox(domain.OutboxMessage{ID: "m", Topic: "t", CreatedAt: testStart})
+	relay, err := NewOutboxRelay(outboxFailingMarks{outbox}, &failingPublisher{}, nil, OutboxConfig{Clock: clock.NewFake(testStart)})
+	if err != nil {
+		t.Fatalf("new relay: %v", err)
+	}
+	result, err := relay.RunOnce(context.Background())
+	if err != nil {
+		t.Fatalf("run: %v", err)
+	}
+	if result.Published != 0 || outbox.Published("m") {
+		t.Fatalf("result = %+v, want the unmarked message not counted as published", result)
+	}
+}
+
+// outboxFailingMarks cannot record publications.
+type outboxFailingMarks struct {
+	*repositorytest.Outbox
+}
+
+func (outboxFailingMarks) MarkPublished(context.Context, string, time.Time) error {
+	return errors.New("connection reset")
+}
@@ -0,0 +1,145 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/robfig/cron/v3"
+	"go.uber.org/zap"
+
+	"github.com/fastygo/backend/domain"
+	"github.com/fastygo/backend/internal/notify"
+	"github.com/fastygo/backend/pkg/clock"
+	"github.com/fastygo/backend/repository"
+)
+
+// ReminderConfig controls how often reminders are looked for and how far ahead.
+type ReminderConfig struct {
+	// Schedule is a cron expression with a leading seconds field or a descriptor; it defaults to
+	// every minute.
+	Schedule string
+	// Window is how long before its due date a task is reminded; it defaults to an hour.
+	Window time.Duration
+	// BatchSize bounds the reminders sent per run; it defaults to 100.
+	BatchSize int
+	// Clock defaults to the real clock.
+	Clock clock.Clock
+}
+
+// ReminderScheduler periodically reminds owners of tasks falling due within the window. Each
+// task is claimed before it is sent, so overlapping runs or replicas remind it only once.
+type ReminderScheduler struct {
+	repo     repository.TaskReminders
+	notifier notify.Notifier
+	logger   *zap.Logger
+	cfg      ReminderConfig
+	cron     *cron.Cron
+}
+
+func NewReminderScheduler(repo repository.TaskReminders, notifier notify.Notifier, logger *zap.Logger, cfg ReminderConfig) (*ReminderScheduler, error) {
+	if cfg.Schedule == "" {
+		cfg.Schedule = "@every 1m"
+	}
+	if cfg.Window <= 0 {
+		cfg.Window = time.Hour
+	}
+	if cfg.BatchSize <= 0 {
+		cfg.BatchSize = 100
+	}
+	if cfg.Clock == nil {
+		cfg.Clock = clock.Real()
+	}
+	if notifier == nil {
+		notifier = notify.Nop{}
+	}
+	if logger == nil {
+		logger = zap.NewNop()
+	}
+
+	s := &ReminderScheduler{
+		repo:     repo,
+		notifier: notifier,
+		logger:   logger,
+		cfg:      cfg,
+		cron:     cron.New(cron.WithSeconds()),
+	}
+	if _, err := s.cron.AddFunc(cfg.Schedule, s.scheduledRun); err != nil {
+		return nil, fmt.Errorf("invalid reminder schedule %q: %w", cfg.Schedule, err)
+	}
+	return s, nil
+}
+
+func (s *ReminderScheduler) scheduledRun() {
+	defer func() {
+		if r := recover(); r != nil {
+			s.logger.Error("reminder job panicked", zap.Any("panic", r), zap.Stack("stack"))
+		}
+	}()
+	sent, err := s.RunOnce(context.Background())
+	if err != nil {
+		s.logger.Error("task reminders failed", zap.Int("sent", sent), zap.Error(err))
+		return
+	}
+	if sent > 0 {
+		s.logger.Info("task reminders sent", zap.Int("sent", sent))
+	}
+}
+
+// RunOnce reminds every unreminded, incomplete task due within the window and returns how many
+// reminders were sent. A reminder that fails to send is released so the next run retries it.
+func (s *ReminderScheduler) RunOnce(ctx context.Context) (int, error) {
+	now := s.cfg.Clock.Now()
+	tasks, err := s.repo.DueForReminder(ctx, now, now.Add(s.cfg.Window), s.cfg.BatchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	sent := 0
+	for _, task := range tasks {
+		claimed, err := s.repo.ClaimReminder(ctx, task.ID, now)
+		if err != nil {
+			return sent, err
+		}
+		if !claimed {
+			continue
+		}
+		if err := s.notifier.Notify(ctx, dueSoon(task, now)); err != nil {
+			s.logger.Warn("task reminder failed", zap.String("task_id", task.ID), zap.Error(err))
+			if err := s.repo.ReleaseReminder(ctx, task.ID); err != nil {
+				s.logger.Error("failed to release task reminder", zap.String("task_id", task.ID), zap.Error(err))
+			}
+			continue
+		}
+		sent++
+	}
+	return sent, nil
+}
+
+func dueSoon(task domain.Task, now time.Time) notify.Notification {
+	return notify.Notification{
+		Event:   notify.EventTaskDueSoon,
+		Message: "task due soon",
+		Fields: map[string]string{
+			"task_id":  task.ID,
+			"user_id":  task.UserID,
+			"title":    task.Title,
+			"due_date": task.DueDate.UTC().Format(time.RFC3339),
+		},
+		Time: now,
+	}
+}
+
+// Start launches the scheduler.
+func (s *ReminderScheduler) Start() {
+	s.cron.Start()
+	s.logger.Info("task reminders started", zap.Duration("window", s.cfg.Window))
+}
+
+// Stop stops the scheduler and waits for a running pass until ctx expires.
+func (s *ReminderScheduler) Stop(ctx context.Context) {
+	select {
+	case <-s.cron.Stop().Done():
+	case <-ctx.Done():
+	}
+}
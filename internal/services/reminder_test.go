@@ -0,0 +1,93 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/fastygo/backend/domain"
+	"github.com/fastygo/backend/internal/notify"
+	"github.com/fastygo/backend/pkg/clock"
+	"github.com/fastygo/backend/repository/repositorytest"
+)
+
+// recordingReminders records reminded task IDs and fails those listed in fail.
+type recordingReminders struct {
+	sent []string
+	fail map[string]bool
+}
+
+func (r *recordingReminders) Notify(_ context.Context, n notify.Notification) error {
+	if r.fail[n.Fields["task_id"]] {
+		return errors.New("channel down")
+	}
+	r.sent = append(r.sent, n.Fields["task_id"])
+	return nil
+}
+
+func TestReminderRunOnceSendsOnlyInWindowUnremindedTasks(t *testing.T) {
+	fake := clock.NewFake(testStart)
+	due := func(d time.Duration) *time.Time {
+		at := testStart.Add(d)
+		return &at
+	}
+	tasks := repositorytest.NewTasks(
+		domain.Task{ID: "soon", UserID: "u1", Status: "pending", DueDate: due(30 * time.Minute)},
+		domain.Task{ID: "now", UserID: "u1", Status: "pending", DueDate: due(0)},
+		domain.Task{ID: "later", UserID: "u1", Status: "pending", DueDate: due(2 * time.Hour)},
+		domain.Task{ID: "past", UserID: "u1", Status: "pending", DueDate: due(-time.Minute)},
+		domain.Task{ID: "completed", UserID: "u1", Status: "completed", DueDate: due(10 * time.Minute)},
+		domain.Task{ID: "undated", UserID: "u1", Status: "pending"},
+		domain.Task{ID: "already", UserID: "u1", Status: "pending", DueDate: due(20 * time.Minute)},
+	)
+	if _, err := tasks.ClaimReminder(context.Background(), "already", testStart.Add(-time.Hour)); err != nil {
+		t.Fatalf("claim: %v", err)
+	}
+	notifier := &recordingReminders{}
+	s, err := NewReminderScheduler(tasks, notifier, nil, ReminderConfig{Window: time.Hour, Clock: fake})
+	if err != nil {
+		t.Fatalf("new scheduler: %v", err)
+	}
+
+	sent, err := s.RunOnce(context.Background())
+	if err != nil {
+		t.Fatalf("run: %v", err)
+	}
+	sort.Strings(notifier.sent)
+	if want := []string{"now", "soon"}; sent != 2 || !reflect.DeepEqual(notifier.sent, want) {
+		t.Fatalf("sent %d %v, want %v", sent, notifier.sent, want)
+	}
+	if at, ok := tasks.RemindedAt("soon"); !ok || !at.Equal(testStart) {
+		t.Fatalf("reminded_at = %v, %v; want the run time recorded", at, ok)
+	}
+
+	fake.Advance(time.Minute)
+	if sent, err := s.RunOnce(context.Background()); err != nil || sent != 0 {
+		t.Fatalf("second run sent %d (%v), want no repeat reminders", sent, err)
+	}
+}
+
+func TestReminderRetriedAfterFailedSend(t *testing.T) {
+	due := testStart.Add(10 * time.Minute)
+	tasks := repositorytest.NewTasks(domain.Task{ID: "t1", UserID: "u1", Status: "pending", DueDate: &due})
+	notifier := &recordingReminders{fail: map[string]bool{"t1": true}}
+	s, err := NewReminderScheduler(tasks, notifier, nil, ReminderConfig{Clock: clock.NewFake(testStart)})
+	if err != nil {
+		t.Fatalf("new scheduler: %v", err)
+	}
+
+	if sent, _ := s.RunOnce(context.Background()); sent != 0 {
+		t.Fatalf("sent = %d while the channel is down", sent)
+	}
+	if _, ok := tasks.RemindedAt("t1"); ok {
+		t.Fatal("a failed reminder must not be recorded as sent")
+	}
+
+	notifier.fail = nil
+	if sent, _ := s.RunOnce(context.Background()); sent != 1 {
+		t.Fatalf("sent = %d, want the reminder retried", sent)
+	}
+}
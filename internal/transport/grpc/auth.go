@@ -0,0 +1,56 @@
+package grpc
+
+import (
+	"context"
+	"strings"
+
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/fastygo/backend/internal/middleware"
+	"github.com/fastygo/backend/pkg/httpcontext"
+)
+
+const authorizationKey = "authorization"
+
+type userIDKey struct{}
+
+// authInterceptor is the gRPC counterpart of middleware.JWTAuth: it validates the bearer token
+// and exposes only its user_id and tenant_id claims to the service, so callers cannot pick an
+// identity. The tenant travels under httpcontext.KeyTenantID, as on HTTP requests.
+func authInterceptor(secret string, rules middleware.TokenRules, logger *zap.Logger) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		tokenString := bearerToken(ctx)
+		if tokenString == "" {
+			return nil, status.Error(codes.Unauthenticated, "missing token")
+		}
+
+		claims, err := middleware.ParseToken(secret, tokenString, rules)
+		if err != nil {
+			logger.Warn("invalid jwt token", zap.String("method", info.FullMethod), zap.Error(err))
+			return nil, status.Error(codes.Unauthenticated, "invalid token")
+		}
+
+		userID, _ := claims["user_id"].(string)
+		if userID == "" {
+			return nil, status.Error(codes.Unauthenticated, "missing user id")
+		}
+		ctx = context.WithValue(ctx, userIDKey{}, userID)
+		if tenantID, ok := claims["tenant_id"].(string); ok && tenantID != "" {
+			ctx = context.WithValue(ctx, httpcontext.KeyTenantID, tenantID)
+		}
+		return handler(ctx, req)
+	}
+}
+
+func bearerToken(ctx context.Context) string {
+	md, _ := metadata.FromIncomingContext(ctx)
+	values := md.Get(authorizationKey)
+	if len(values) == 0 {
+		return ""
+	}
+	return strings.TrimPrefix(values[0], "Bearer ")
+}
@@ -0,0 +1,67 @@
+package grpc
+
+import (
+	"context"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// TaskServiceClient calls a remote TaskService using the package codec.
+type TaskServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewTaskServiceClient(cc grpc.ClientConnInterface) *TaskServiceClient {
+	return &TaskServiceClient{cc: cc}
+}
+
+// WithToken returns a context that authenticates outgoing calls with the given JWT.
+func WithToken(ctx context.Context, token string) context.Context {
+	return metadata.AppendToOutgoingContext(ctx, authorizationKey, "Bearer "+token)
+}
+
+func (c *TaskServiceClient) ListTasks(ctx context.Context, req *ListTasksRequest, opts ...grpc.CallOption) (*ListTasksResponse, error) {
+	out := new(ListTasksResponse)
+	if err := c.invoke(ctx, "ListTasks", req, out, opts); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *TaskServiceClient) GetTask(ctx context.Context, req *GetTaskRequest, opts ...grpc.CallOption) (*Task, error) {
+	out := new(Task)
+	if err := c.invoke(ctx, "GetTask", req, out, opts); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *TaskServiceClient) CreateTask(ctx context.Context, req *CreateTaskRequest, opts ...grpc.CallOption) (*Task, error) {
+	out := new(Task)
+	if err := c.invoke(ctx, "CreateTask", req, out, opts); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *TaskServiceClient) UpdateTask(ctx context.Context, req *UpdateTaskRequest, opts ...grpc.CallOption) (*Task, error) {
+	out := new(Task)
+	if err := c.invoke(ctx, "UpdateTask", req, out, opts); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *TaskServiceClient) DeleteTask(ctx context.Context, req *DeleteTaskRequest, opts ...grpc.CallOption) (*DeleteTaskResponse, error) {
+	out := new(DeleteTaskResponse)
+	if err := c.invoke(ctx, "DeleteTask", req, out, opts); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *TaskServiceClient) invoke(ctx context.Context, method string, req, out message, opts []grpc.CallOption) error {
+	opts = append([]grpc.CallOption{grpc.ForceCodec(codec{})}, opts...)
+	return c.cc.Invoke(ctx, fullMethod(method), req, out, opts...)
+}
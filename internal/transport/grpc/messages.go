@@ -0,0 +1,283 @@
+package grpc
+
+import (
+	"time"
+
+	"github.com/fastygo/backend/domain"
+)
+
+// Task mirrors the Task message in task.proto.
+type Task struct {
+	ID          string
+	UserID      string
+	Title       string
+	Description string
+	Status      string
+	Priority    int32
+	DueDate     *time.Time
+	Metadata    map[string]string
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+}
+
+func taskFromDomain(t *domain.Task) *Task {
+	if t == nil {
+		return nil
+	}
+	return &Task{
+		ID:          t.ID,
+		UserID:      t.UserID,
+		Title:       t.Title,
+		Description: t.Description,
+		Status:      t.Status,
+		Priority:    int32(t.Priority),
+		DueDate:     t.DueDate,
+		Metadata:    t.Metadata,
+		CreatedAt:   t.CreatedAt,
+		UpdatedAt:   t.UpdatedAt,
+	}
+}
+
+func (t *Task) toDomain() *domain.Task {
+	return &domain.Task{
+		ID:          t.ID,
+		UserID:      t.UserID,
+		Title:       t.Title,
+		Description: t.Description,
+		Status:      t.Status,
+		Priority:    int(t.Priority),
+		DueDate:     t.DueDate,
+		Metadata:    t.Metadata,
+		CreatedAt:   t.CreatedAt,
+		UpdatedAt:   t.UpdatedAt,
+	}
+}
+
+func (t *Task) marshalProto() []byte {
+	var b []byte
+	b = appendString(b, 1, t.ID)
+	b = appendString(b, 2, t.UserID)
+	b = appendString(b, 3, t.Title)
+	b = appendString(b, 4, t.Description)
+	b = appendString(b, 5, t.Status)
+	b = appendInt(b, 6, int64(t.Priority))
+	if t.DueDate != nil {
+		b = appendTimestamp(b, 7, *t.DueDate)
+	}
+	for key, value := range t.Metadata {
+		var entry []byte
+		entry = appendString(entry, 1, key)
+		entry = appendString(entry, 2, value)
+		b = appendMessage(b, 8, entry)
+	}
+	b = appendTimestamp(b, 9, t.CreatedAt)
+	b = appendTimestamp(b, 10, t.UpdatedAt)
+	return b
+}
+
+func (t *Task) unmarshalProto(data []byte) error {
+	*t = Task{}
+	return parseFields(data, func(f field) error {
+		switch f.num {
+		case 1:
+			t.ID = string(f.bytes)
+		case 2:
+			t.UserID = string(f.bytes)
+		case 3:
+			t.Title = string(f.bytes)
+		case 4:
+			t.Description = string(f.bytes)
+		case 5:
+			t.Status = string(f.bytes)
+		case 6:
+			t.Priority = int32(f.varint)
+		case 7:
+			due, err := parseTimestamp(f.bytes)
+			if err != nil {
+				return err
+			}
+			t.DueDate = &due
+		case 8:
+			var key, value string
+			err := parseFields(f.bytes, func(entry field) error {
+				switch entry.num {
+				case 1:
+					key = string(entry.bytes)
+				case 2:
+					value = string(entry.bytes)
+				}
+				return nil
+			})
+			if err != nil {
+				return err
+			}
+			if t.Metadata == nil {
+				t.Metadata = make(map[string]string)
+			}
+			t.Metadata[key] = value
+		case 9:
+			created, err := parseTimestamp(f.bytes)
+			if err != nil {
+				return err
+			}
+			t.CreatedAt = created
+		case 10:
+			updated, err := parseTimestamp(f.bytes)
+			if err != nil {
+				return err
+			}
+			t.UpdatedAt = updated
+		}
+		return nil
+	})
+}
+
+type ListTasksRequest struct {
+	Status   string
+	Priority int32
+	Limit    int32
+	Offset   int32
+}
+
+func (r *ListTasksRequest) marshalProto() []byte {
+	var b []byte
+	b = appendString(b, 1, r.Status)
+	b = appendInt(b, 2, int64(r.Priority))
+	b = appendInt(b, 3, int64(r.Limit))
+	b = appendInt(b, 4, int64(r.Offset))
+	return b
+}
+
+func (r *ListTasksRequest) unmarshalProto(data []byte) error {
+	*r = ListTasksRequest{}
+	return parseFields(data, func(f field) error {
+		switch f.num {
+		case 1:
+			r.Status = string(f.bytes)
+		case 2:
+			r.Priority = int32(f.varint)
+		case 3:
+			r.Limit = int32(f.varint)
+		case 4:
+			r.Offset = int32(f.varint)
+		}
+		return nil
+	})
+}
+
+type ListTasksResponse struct {
+	Tasks []*Task
+}
+
+func (r *ListTasksResponse) marshalProto() []byte {
+	var b []byte
+	for _, task := range r.Tasks {
+		b = appendMessage(b, 1, task.marshalProto())
+	}
+	return b
+}
+
+func (r *ListTasksResponse) unmarshalProto(data []byte) error {
+	*r = ListTasksResponse{}
+	return parseFields(data, func(f field) error {
+		if f.num != 1 {
+			return nil
+		}
+		task := new(Task)
+		if err := task.unmarshalProto(f.bytes); err != nil {
+			return err
+		}
+		r.Tasks = append(r.Tasks, task)
+		return nil
+	})
+}
+
+type GetTaskRequest struct {
+	ID string
+}
+
+func (r *GetTaskRequest) marshalProto() []byte {
+	return appendString(nil, 1, r.ID)
+}
+
+func (r *GetTaskRequest) unmarshalProto(data []byte) error {
+	*r = GetTaskRequest{}
+	return parseFields(data, func(f field) error {
+		if f.num == 1 {
+			r.ID = string(f.bytes)
+		}
+		return nil
+	})
+}
+
+type CreateTaskRequest struct {
+	Task *Task
+}
+
+func (r *CreateTaskRequest) marshalProto() []byte {
+	return marshalTaskField(r.Task)
+}
+
+func (r *CreateTaskRequest) unmarshalProto(data []byte) error {
+	task, err := unmarshalTaskField(data)
+	r.Task = task
+	return err
+}
+
+type UpdateTaskRequest struct {
+	Task *Task
+}
+
+func (r *UpdateTaskRequest) marshalProto() []byte {
+	return marshalTaskField(r.Task)
+}
+
+func (r *UpdateTaskRequest) unmarshalProto(data []byte) error {
+	task, err := unmarshalTaskField(data)
+	r.Task = task
+	return err
+}
+
+type DeleteTaskRequest struct {
+	ID string
+}
+
+func (r *DeleteTaskRequest) marshalProto() []byte {
+	return appendString(nil, 1, r.ID)
+}
+
+func (r *DeleteTaskRequest) unmarshalProto(data []byte) error {
+	*r = DeleteTaskRequest{}
+	return parseFields(data, func(f field) error {
+		if f.num == 1 {
+			r.ID = string(f.bytes)
+		}
+		return nil
+	})
+}
+
+type DeleteTaskResponse struct{}
+
+func (r *DeleteTaskResponse) marshalProto() []byte { return nil }
+
+func (r *DeleteTaskResponse) unmarshalProto([]byte) error { return nil }
+
+// marshalTaskField encodes a message whose only field is `Task task = 1`.
+func marshalTaskField(task *Task) []byte {
+	if task == nil {
+		return nil
+	}
+	return appendMessage(nil, 1, task.marshalProto())
+}
+
+func unmarshalTaskField(data []byte) (*Task, error) {
+	var task *Task
+	err := parseFields(data, func(f field) error {
+		if f.num != 1 {
+			return nil
+		}
+		task = new(Task)
+		return task.unmarshalProto(f.bytes)
+	})
+	return task, err
+}
@@ -0,0 +1,56 @@
+package grpc
+
+import (
+	"context"
+	"net"
+
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+
+	"github.com/fastygo/backend/internal/middleware"
+	taskUC "github.com/fastygo/backend/usecase/task"
+)
+
+// Server runs the gRPC TaskService alongside the fasthttp API.
+type Server struct {
+	server *grpc.Server
+}
+
+// NewServer registers the TaskService backed by the task use case. Every call must carry a JWT
+// signed with secret that satisfies rules. Extra server options (interceptors, credentials) are appended after the
+// package codec and the auth interceptor.
+func NewServer(uc *taskUC.UseCase, secret string, rules middleware.TokenRules, logger *zap.Logger, opts ...grpc.ServerOption) *Server {
+	if logger == nil {
+		logger = zap.NewNop()
+	}
+	base := []grpc.ServerOption{
+		grpc.ForceServerCodec(codec{}),
+		grpc.ChainUnaryInterceptor(authInterceptor(secret, rules, logger)),
+	}
+	server := grpc.NewServer(append(base, opts...)...)
+	server.RegisterService(&taskServiceDesc, &taskService{uc: uc, logger: logger})
+	return &Server{server: server}
+}
+
+// Serve accepts connections on l until the server is stopped.
+func (s *Server) Serve(l net.Listener) error {
+	return s.server.Serve(l)
+}
+
+// Shutdown waits for in-flight RPCs to finish and forces the remaining ones closed once ctx expires.
+func (s *Server) Shutdown(ctx context.Context) error {
+	done := make(chan struct{})
+	go func() {
+		s.server.GracefulStop()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		s.server.Stop()
+		<-done
+		return ctx.Err()
+	}
+}
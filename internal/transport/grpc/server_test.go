@@ -0,0 +1,195 @@
+package grpc_test
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+
+	"github.com/fastygo/backend/internal/middleware"
+	grpcTransport "github.com/fastygo/backend/internal/transport/grpc"
+	"github.com/fastygo/backend/repository/repositorytest"
+	taskUC "github.com/fastygo/backend/usecase/task"
+)
+
+const testSecret = "test-secret"
+
+func newTestClient(t *testing.T) *grpcTransport.TaskServiceClient {
+	t.Helper()
+
+	uc := taskUC.New(repositorytest.NewTasks(), nil, nil)
+	server := grpcTransport.NewServer(uc, testSecret, middleware.TokenRules{Leeway: middleware.DefaultLeeway}, nil)
+	listener := bufconn.Listen(1 << 20)
+	go func() { _ = server.Serve(listener) }()
+	t.Cleanup(func() {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		_ = server.Shutdown(ctx)
+	})
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	t.Cleanup(func() { _ = conn.Close() })
+	return grpcTransport.NewTaskServiceClient(conn)
+}
+
+func tokenFor(t *testing.T, userID string) string {
+	t.Helper()
+	return tokenWithClaims(t, jwt.MapClaims{"user_id": userID})
+}
+
+func tokenWithClaims(t *testing.T, claims jwt.MapClaims) string {
+	t.Helper()
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(testSecret))
+	if err != nil {
+		t.Fatalf("sign token: %v", err)
+	}
+	return token
+}
+
+func TestTaskServiceRoundTrip(t *testing.T) {
+	client := newTestClient(t)
+	ctx := grpcTransport.WithToken(context.Background(), tokenFor(t, "user-1"))
+	due := time.Date(2030, 1, 2, 3, 4, 5, 600, time.UTC)
+
+	created, err := client.CreateTask(ctx, &grpcTransport.CreateTaskRequest{Task: &grpcTransport.Task{
+		UserID:   "someone-else",
+		Title:    "write proto",
+		Priority: 2,
+		DueDate:  &due,
+		Metadata: map[string]string{"source": "grpc"},
+	}})
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	if created.ID == "" || created.UserID != "user-1" || created.Status != "pending" {
+		t.Fatalf("unexpected created task: %+v", created)
+	}
+	if created.DueDate == nil || !created.DueDate.Equal(due) || created.Metadata["source"] != "grpc" {
+		t.Fatalf("fields lost in round trip: %+v", created)
+	}
+
+	created.Title = "write more proto"
+	created.Status = "completed"
+	if _, err := client.UpdateTask(ctx, &grpcTransport.UpdateTaskRequest{Task: created}); err != nil {
+		t.Fatalf("update: %v", err)
+	}
+
+	got, err := client.GetTask(ctx, &grpcTransport.GetTaskRequest{ID: created.ID})
+	if err != nil {
+		t.Fatalf("get: %v", err)
+	}
+	if got.Title != "write more proto" || got.Status != "completed" || got.CreatedAt.IsZero() {
+		t.Fatalf("unexpected task after update: %+v", got)
+	}
+
+	list, err := client.ListTasks(ctx, &grpcTransport.ListTasksRequest{Status: "completed"})
+	if err != nil {
+		t.Fatalf("list: %v", err)
+	}
+	if len(list.Tasks) != 1 || list.Tasks[0].ID != created.ID {
+		t.Fatalf("unexpected list: %+v", list.Tasks)
+	}
+
+	if _, err := client.DeleteTask(ctx, &grpcTransport.DeleteTaskRequest{ID: created.ID}); err != nil {
+		t.Fatalf("delete: %v", err)
+	}
+	_, err = client.GetTask(ctx, &grpcTransport.GetTaskRequest{ID: created.ID})
+	if status.Code(err) != codes.NotFound {
+		t.Fatalf("expected NotFound after delete, got %v", err)
+	}
+}
+
+func TestTaskServiceScopesToTokenUser(t *testing.T) {
+	client := newTestClient(t)
+	owner := grpcTransport.WithToken(context.Background(), tokenFor(t, "owner"))
+	other := grpcTransport.WithToken(context.Background(), tokenFor(t, "other"))
+
+	created, err := client.CreateTask(owner, &grpcTransport.CreateTaskRequest{Task: &grpcTransport.Task{Title: "private"}})
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+
+	if _, err := client.GetTask(other, &grpcTransport.GetTaskRequest{ID: created.ID}); status.Code(err) != codes.NotFound {
+		t.Fatalf("expected NotFound for another user's task, got %v", err)
+	}
+	list, err := client.ListTasks(other, &grpcTransport.ListTasksRequest{})
+	if err != nil {
+		t.Fatalf("list: %v", err)
+	}
+	if len(list.Tasks) != 0 {
+		t.Fatalf("expected no tasks for other user, got %d", len(list.Tasks))
+	}
+}
+
+func TestTaskServiceRejectsUnauthenticatedCalls(t *testing.T) {
+	client := newTestClient(t)
+
+	tests := []struct {
+		name string
+		ctx  context.Context
+	}{
+		{name: "missing token", ctx: context.Background()},
+		{name: "bad signature", ctx: grpcTransport.WithToken(context.Background(), "not-a-jwt")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := client.ListTasks(tt.ctx, &grpcTransport.ListTasksRequest{})
+			if status.Code(err) != codes.Unauthenticated {
+				t.Fatalf("expected Unauthenticated, got %v", err)
+			}
+		})
+	}
+}
+
+func TestTaskServiceWritesAreScopedToOwnerAndTenant(t *testing.T) {
+	client := newTestClient(t)
+	owner := grpcTransport.WithToken(context.Background(), tokenWithClaims(t, jwt.MapClaims{"user_id": "owner", "tenant_id": "acme"}))
+
+	created, err := client.CreateTask(owner, &grpcTransport.CreateTaskRequest{Task: &grpcTransport.Task{Title: "private"}})
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+
+	callers := map[string]jwt.MapClaims{
+		"other user in the tenant":  {"user_id": "other", "tenant_id": "acme"},
+		"owner from another tenant": {"user_id": "owner", "tenant_id": "globex"},
+	}
+	for name, claims := range callers {
+		ctx := grpcTransport.WithToken(context.Background(), tokenWithClaims(t, claims))
+		hijack := *created
+		hijack.Title = "hijacked"
+		if _, err := client.UpdateTask(ctx, &grpcTransport.UpdateTaskRequest{Task: &hijack}); status.Code(err) != codes.NotFound {
+			t.Errorf("%s: update error = %v, want NotFound", name, err)
+		}
+		if _, err := client.DeleteTask(ctx, &grpcTransport.DeleteTaskRequest{ID: created.ID}); status.Code(err) != codes.NotFound {
+			t.Errorf("%s: delete error = %v, want NotFound", name, err)
+		}
+		if _, err := client.GetTask(ctx, &grpcTransport.GetTaskRequest{ID: created.ID}); status.Code(err) != codes.NotFound {
+			t.Errorf("%s: get error = %v, want NotFound", name, err)
+		}
+		list, err := client.ListTasks(ctx, &grpcTransport.ListTasksRequest{})
+		if err != nil || len(list.Tasks) != 0 {
+			t.Errorf("%s: list = %v, %v; want no tasks", name, list, err)
+		}
+	}
+
+	got, err := client.GetTask(owner, &grpcTransport.GetTaskRequest{ID: created.ID})
+	if err != nil || got.Title != "private" {
+		t.Fatalf("owner get = %+v, %v; want the untouched task", got, err)
+	}
+}
@@ -0,0 +1,228 @@
+package grpc
+
+import (
+	"context"
+
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/fastygo/backend/domain"
+	"github.com/fastygo/backend/pkg/httpcontext"
+	"github.com/fastygo/backend/repository"
+	taskUC "github.com/fastygo/backend/usecase/task"
+)
+
+const serviceName = "fastygo.task.v1.TaskService"
+
+// TaskServiceServer is the server API for the TaskService defined in task.proto.
+type TaskServiceServer interface {
+	ListTasks(ctx context.Context, req *ListTasksRequest) (*ListTasksResponse, error)
+	GetTask(ctx context.Context, req *GetTaskRequest) (*Task, error)
+	CreateTask(ctx context.Context, req *CreateTaskRequest) (*Task, error)
+	UpdateTask(ctx context.Context, req *UpdateTaskRequest) (*Task, error)
+	DeleteTask(ctx context.Context, req *DeleteTaskRequest) (*DeleteTaskResponse, error)
+}
+
+var taskServiceDesc = grpc.ServiceDesc{
+	ServiceName: serviceName,
+	HandlerType: (*TaskServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{MethodName: "ListTasks", Handler: unaryHandler("ListTasks", TaskServiceServer.ListTasks)},
+		{MethodName: "GetTask", Handler: unaryHandler("GetTask", TaskServiceServer.GetTask)},
+		{MethodName: "CreateTask", Handler: unaryHandler("CreateTask", TaskServiceServer.CreateTask)},
+		{MethodName: "UpdateTask", Handler: unaryHandler("UpdateTask", TaskServiceServer.UpdateTask)},
+		{MethodName: "DeleteTask", Handler: unaryHandler("DeleteTask", TaskServiceServer.DeleteTask)},
+	},
+	Metadata: "task.proto",
+}
+
+func fullMethod(method string) string {
+	return "/" + serviceName + "/" + method
+}
+
+// methodHandler matches grpc.MethodDesc.Handler, whose named type grpc-go does not export.
+type methodHandler = func(srv any, ctx context.Context, dec func(any) error, interceptor grpc.UnaryServerInterceptor) (any, error)
+
+// unaryHandler adapts a typed service method to a method handler, the way generated code does.
+func unaryHandler[Req any, Resp any](method string, call func(TaskServiceServer, context.Context, *Req) (Resp, error)) methodHandler {
+	info := &grpc.UnaryServerInfo{FullMethod: fullMethod(method)}
+	return func(srv any, ctx context.Context, dec func(any) error, interceptor grpc.UnaryServerInterceptor) (any, error) {
+		req := new(Req)
+		if err := dec(req); err != nil {
+			return nil, err
+		}
+		handler := func(ctx context.Context, req any) (any, error) {
+			return call(srv.(TaskServiceServer), ctx, req.(*Req))
+		}
+		if interceptor == nil {
+			return handler(ctx, req)
+		}
+		info := *info
+		info.Server = srv
+		return interceptor(ctx, req, &info, handler)
+	}
+}
+
+type taskService struct {
+	uc     *taskUC.UseCase
+	logger *zap.Logger
+}
+
+func (s *taskService) ListTasks(ctx context.Context, req *ListTasksRequest) (*ListTasksResponse, error) {
+	userID, err := callerID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	limit := int(req.Limit)
+	if limit <= 0 {
+		limit = 50
+	}
+	if limit > 100 {
+		limit = 100
+	}
+	offset := int(req.Offset)
+	if offset < 0 {
+		offset = 0
+	}
+
+	tasks, err := s.uc.ListTasks(ctx, repository.TaskFilter{
+		UserID:   userID,
+		TenantID: httpcontext.TenantID(ctx),
+		Status:   req.Status,
+		Priority: int(req.Priority),
+		Limit:    limit,
+		Offset:   offset,
+	})
+	if err != nil {
+		return nil, s.statusError(err)
+	}
+
+	resp := &ListTasksResponse{Tasks: make([]*Task, 0, len(tasks))}
+	for i := range tasks {
+		resp.Tasks = append(resp.Tasks, taskFromDomain(&tasks[i]))
+	}
+	return resp, nil
+}
+
+func (s *taskService) GetTask(ctx context.Context, req *GetTaskRequest) (*Task, error) {
+	userID, err := callerID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if req.ID == "" {
+		return nil, status.Error(codes.InvalidArgument, "missing task id")
+	}
+
+	task, err := s.uc.GetTask(ctx, req.ID)
+	if err != nil {
+		return nil, s.statusError(err)
+	}
+	// Report another user's or tenant's task as missing rather than leaking its existence.
+	if tenantID := httpcontext.TenantID(ctx); task.UserID != userID || (tenantID != "" && task.TenantID != tenantID) {
+		return nil, s.statusError(domain.ErrTaskNotFound)
+	}
+	return taskFromDomain(task), nil
+}
+
+func (s *taskService) CreateTask(ctx context.Context, req *CreateTaskRequest) (*Task, error) {
+	task, err := s.taskFromRequest(ctx, req.Task)
+	if err != nil {
+		return nil, err
+	}
+
+	created, err := s.uc.CreateTask(ctx, task)
+	if err != nil {
+		return nil, s.statusError(err)
+	}
+	return taskFromDomain(created), nil
+}
+
+func (s *taskService) UpdateTask(ctx context.Context, req *UpdateTaskRequest) (*Task, error) {
+	task, err := s.taskFromRequest(ctx, req.Task)
+	if err != nil {
+		return nil, err
+	}
+	if task.ID == "" {
+		return nil, status.Error(codes.InvalidArgument, "missing task id")
+	}
+
+	updated, err := s.uc.UpdateTask(ctx, task)
+	if err != nil {
+		return nil, s.statusError(err)
+	}
+	return taskFromDomain(updated), nil
+}
+
+func (s *taskService) DeleteTask(ctx context.Context, req *DeleteTaskRequest) (*DeleteTaskResponse, error) {
+	userID, err := callerID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if req.ID == "" {
+		return nil, status.Error(codes.InvalidArgument, "missing task id")
+	}
+
+	if err := s.uc.DeleteTask(ctx, req.ID, userID, httpcontext.TenantID(ctx)); err != nil {
+		return nil, s.statusError(err)
+	}
+	return &DeleteTaskResponse{}, nil
+}
+
+// taskFromRequest applies the same defaults as the REST handler and pins the task to the caller
+// and their tenant, which also confines updates to the caller's own tasks.
+func (s *taskService) taskFromRequest(ctx context.Context, msg *Task) (*domain.Task, error) {
+	userID, err := callerID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if msg == nil {
+		return nil, status.Error(codes.InvalidArgument, "missing task")
+	}
+
+	task := msg.toDomain()
+	task.UserID = userID
+	task.TenantID = httpcontext.TenantID(ctx)
+	if task.Status == "" {
+		task.Status = "pending"
+	}
+	return task, nil
+}
+
+func (s *taskService) statusError(err error) error {
+	st := statusFromError(err)
+	if st.Code() == codes.Internal {
+		s.logger.Error("grpc task call failed", zap.Error(err))
+	}
+	return st.Err()
+}
+
+// statusFromError translates domain errors into gRPC statuses, mirroring the HTTP mapError.
+func statusFromError(err error) *status.Status {
+	switch {
+	case domain.IsDomainError(err, domain.ErrCodeUnauthorized):
+		return status.New(codes.Unauthenticated, err.Error())
+	case domain.IsDomainError(err, domain.ErrCodeForbidden):
+		return status.New(codes.PermissionDenied, err.Error())
+	case domain.IsDomainError(err, domain.ErrCodeInvalid):
+		return status.New(codes.InvalidArgument, err.Error())
+	case domain.IsDomainError(err, domain.ErrCodeNotFound):
+		return status.New(codes.NotFound, err.Error())
+	case domain.IsDomainError(err, domain.ErrCodeConflict):
+		return status.New(codes.AlreadyExists, err.Error())
+	case domain.IsDomainError(err, domain.ErrCodeLimitExceeded):
+		return status.New(codes.ResourceExhausted, err.Error())
+	default:
+		return status.New(codes.Internal, err.Error())
+	}
+}
+
+func callerID(ctx context.Context) (string, error) {
+	userID, _ := ctx.Value(userIDKey{}).(string)
+	if userID == "" {
+		return "", status.Error(codes.Unauthenticated, "missing user id")
+	}
+	return userID, nil
+}
@@ -0,0 +1,11 @@
+// Package buildinfo carries the build's version, stamped at link time:
+//
+//	go build -ldflags "-X github.com/fastygo/backend/pkg/buildinfo.Version=v1.4.0 -X github.com/fastygo/backend/pkg/buildinfo.Commit=$(git rev-parse --short HEAD)"
+package buildinfo
+
+var (
+	// Version is the release the binary was built from; "dev" for unstamped builds.
+	Version = "dev"
+	// Commit is the source revision, empty for unstamped builds.
+	Commit = ""
+)
@@ -0,0 +1,27 @@
+// Package clientinfo carries the calling client's network identity through a context,
+// independent of the transport that accepted the request.
+package clientinfo
+
+import "context"
+
+// Info describes the client that issued a request.
+type Info struct {
+	IP        string
+	UserAgent string
+}
+
+type contextKey struct{}
+
+// NewContext returns a copy of ctx carrying info.
+func NewContext(ctx context.Context, info Info) context.Context {
+	return context.WithValue(ctx, contextKey{}, info)
+}
+
+// FromContext returns the client info stored in ctx, or the zero Info.
+func FromContext(ctx context.Context) Info {
+	if ctx == nil {
+		return Info{}
+	}
+	info, _ := ctx.Value(contextKey{}).(Info)
+	return info
+}
@@ -0,0 +1,21 @@
+package clock
+
+import (
+	"testing"
+	"time"
+)
+
+func TestFakeAdvanceAndSet(t *testing.T) {
+	start := time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)
+	fake := NewFake(start)
+
+	fake.Advance(90 * time.Second)
+	if got, want := fake.Now(), start.Add(90*time.Second); !got.Equal(want) {
+		t.Fatalf("after Advance: %v, want %v", got, want)
+	}
+
+	fake.Set(start)
+	if got := fake.Now(); !got.Equal(start) {
+		t.Fatalf("after Set: %v, want %v", got, start)
+	}
+}
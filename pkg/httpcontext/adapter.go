@@ -2,13 +2,16 @@ package httpcontext
 
 import (
 	"context"
+	"net"
 	"strings"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/valyala/fasthttp"
 
+	"github.com/fastygo/backend/pkg/clientinfo"
 	appLogger "github.com/fastygo/backend/pkg/logger"
+	"github.com/fastygo/backend/pkg/timing"
 )
 
 // Key represents a context value key exported for reuse.
@@ -17,21 +20,47 @@ type Key string
 const (
 	KeyRemoteAddr Key = "remote_addr"
 	KeyUserAgent  Key = "user_agent"
+	KeyTenantID   Key = "tenant_id"
+	KeyScopes     Key = "scopes"
+	KeySessionID  Key = "session_id"
+	KeyTiming     Key = "timing"
 )
 
+// DefaultTimeout bounds requests when NewAdapter is given no timeout.
+const DefaultTimeout = 5 * time.Second
+
 // Adapter converts fasthttp.RequestCtx into a stdlib context with deadlines and metadata.
 type Adapter struct {
 	timeout time.Duration
+	newID   func() string
+}
+
+// AdapterOption customizes an Adapter.
+type AdapterOption func(*Adapter)
+
+// WithRequestIDGenerator replaces uuid.NewString as the source of request IDs the client did not
+// supply, e.g. with NewULID for IDs that sort by time.
+func WithRequestIDGenerator(generate func() string) AdapterOption {
+	return func(a *Adapter) {
+		if generate != nil {
+			a.newID = generate
+		}
+	}
 }
 
 // NewAdapter constructs a new Adapter using the provided timeout.
-func NewAdapter(timeout time.Duration) *Adapter {
+func NewAdapter(timeout time.Duration, opts ...AdapterOption) *Adapter {
 	if timeout <= 0 {
-		timeout = 5 * time.Second
+		timeout = DefaultTimeout
 	}
-	return &Adapter{
+	a := &Adapter{
 		timeout: timeout,
+		newID:   uuid.NewString,
+	}
+	for _, opt := range opts {
+		opt(a)
 	}
+	return a
 }
 
 // Attach creates a context with timeout derived from the adapter and enriches it with request metadata.
@@ -40,26 +69,105 @@ func (a *Adapter) Attach(ctx *fasthttp.RequestCtx) (context.Context, context.Can
 
 	stdCtx, cancel := context.WithTimeout(base, a.timeout)
 
-	reqID := getRequestID(ctx)
+	reqID := a.requestID(ctx)
 	stdCtx = appLogger.ContextWithRequestID(stdCtx, reqID)
 	ctx.Response.Header.Set("X-Request-ID", reqID)
 
+	var client clientinfo.Info
 	if remoteAddr := ctx.RemoteAddr(); remoteAddr != nil {
 		stdCtx = context.WithValue(stdCtx, KeyRemoteAddr, remoteAddr.String())
+		client.IP = remoteAddr.String()
+		if host, _, err := net.SplitHostPort(client.IP); err == nil {
+			client.IP = host
+		}
 	}
 	if ua := string(ctx.Request.Header.UserAgent()); ua != "" {
 		stdCtx = context.WithValue(stdCtx, KeyUserAgent, ua)
+		client.UserAgent = ua
+	}
+	stdCtx = clientinfo.NewContext(stdCtx, client)
+	// Identity attributes resolved by the auth middleware travel as request user values.
+	if tenantID, ok := ctx.UserValue(KeyTenantID).(string); ok && tenantID != "" {
+		stdCtx = context.WithValue(stdCtx, KeyTenantID, tenantID)
+	}
+	if scopes, ok := ctx.UserValue(KeyScopes).([]string); ok && len(scopes) > 0 {
+		stdCtx = context.WithValue(stdCtx, KeyScopes, scopes)
+	}
+	if sessionID, ok := ctx.UserValue(KeySessionID).(string); ok && sessionID != "" {
+		stdCtx = context.WithValue(stdCtx, KeySessionID, sessionID)
+	}
+	if rec := Timing(ctx); rec != nil {
+		stdCtx = timing.NewContext(stdCtx, rec)
 	}
 
 	return stdCtx, cancel
 }
 
-func getRequestID(ctx *fasthttp.RequestCtx) string {
+// Timing returns the recorder the access log attached to the request, or nil when the timing
+// breakdown is disabled.
+func Timing(ctx *fasthttp.RequestCtx) *timing.Recorder {
+	rec, _ := ctx.UserValue(KeyTiming).(*timing.Recorder)
+	return rec
+}
+
+// TenantID returns the tenant resolved from the caller's token, if any.
+func TenantID(ctx context.Context) string {
+	return stringValue(ctx, KeyTenantID)
+}
+
+// SessionID returns the session referenced by the caller's token, if any.
+func SessionID(ctx context.Context) string {
+	return stringValue(ctx, KeySessionID)
+}
+
+// Scopes returns the scopes granted to the caller's token.
+func Scopes(ctx context.Context) []string {
+	if ctx == nil {
+		return nil
+	}
+	scopes, _ := ctx.Value(KeyScopes).([]string)
+	return scopes
+}
+
+func stringValue(ctx context.Context, key Key) string {
+	if ctx == nil {
+		return ""
+	}
+	value, _ := ctx.Value(key).(string)
+	return value
+}
+
+// maxRequestIDLength bounds client-supplied request IDs; longer ones are replaced.
+const maxRequestIDLength = 128
+
+// requestID returns the first well-formed X-Request-ID the client sent, looking through repeated
+// headers and comma-joined values, and a freshly generated ID when none is usable. Client IDs end
+// up in logs, so anything outside a conservative charset is discarded rather than escaped.
+func (a *Adapter) requestID(ctx *fasthttp.RequestCtx) string {
 	if ctx == nil {
-		return uuid.NewString()
+		return a.newID()
+	}
+	for _, header := range ctx.Request.Header.PeekAll("X-Request-ID") {
+		for _, candidate := range strings.Split(string(header), ",") {
+			if id := strings.TrimSpace(candidate); validRequestID(id) {
+				return id
+			}
+		}
+	}
+	return a.newID()
+}
+
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
 	}
-	if header := string(ctx.Request.Header.Peek("X-Request-ID")); strings.TrimSpace(header) != "" {
-		return header
+	for i := 0; i < len(id); i++ {
+		switch c := id[i]; {
+		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c >= '0' && c <= '9':
+		case c == '-', c == '_', c == '.', c == ':':
+		default:
+			return false
+		}
 	}
-	return uuid.NewString()
+	return true
 }
@@ -0,0 +1,106 @@
+package httpcontext_test
+
+import (
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/valyala/fasthttp"
+
+	"github.com/fastygo/backend/pkg/clientinfo"
+	"github.com/fastygo/backend/pkg/httpcontext"
+)
+
+func TestAttachRecordsClientInfo(t *testing.T) {
+	var req fasthttp.Request
+	req.Header.SetUserAgent("test-agent/1.0")
+
+	var ctx fasthttp.RequestCtx
+	ctx.Init(&req, &net.TCPAddr{IP: net.ParseIP("198.51.100.4"), Port: 51234}, nil)
+
+	stdCtx, cancel := httpcontext.NewAdapter(time.Second).Attach(&ctx)
+	defer cancel()
+
+	client := clientinfo.FromContext(stdCtx)
+	if client.IP != "198.51.100.4" {
+		t.Errorf("ip = %q, want port stripped %q", client.IP, "198.51.100.4")
+	}
+	if client.UserAgent != "test-agent/1.0" {
+		t.Errorf("user agent = %q, want %q", client.UserAgent, "test-agent/1.0")
+	}
+}
+
+func TestAttachCarriesIdentityResolvedByMiddleware(t *testing.T) {
+	var ctx fasthttp.RequestCtx
+	ctx.SetUserValue(httpcontext.KeyTenantID, "acme")
+	ctx.SetUserValue(httpcontext.KeyScopes, []string{"tasks:read", "tasks:write"})
+
+	stdCtx, cancel := httpcontext.NewAdapter(time.Second).Attach(&ctx)
+	defer cancel()
+
+	if got := httpcontext.TenantID(stdCtx); got != "acme" {
+		t.Errorf("tenant = %q, want %q", got, "acme")
+	}
+	if got := httpcontext.Scopes(stdCtx); len(got) != 2 || got[1] != "tasks:write" {
+		t.Errorf("scopes = %v, want both granted scopes", got)
+	}
+}
+
+func TestAttachValidatesSuppliedRequestID(t *testing.T) {
+	tests := []struct {
+		name    string
+		headers []string
+		want    string
+	}{
+		{name: "valid id kept", headers: []string{"req-42.a_b:c"}, want: "req-42.a_b:c"},
+		{name: "first of repeated headers", headers: []string{"first", "second"}, want: "first"},
+		{name: "first of comma-joined values", headers: []string{" proxy-id , client-id"}, want: "proxy-id"},
+		{name: "malformed first skipped", headers: []string{"bad id\n", "good-id"}, want: "good-id"},
+		{name: "log injection replaced", headers: []string{"x\nlevel=error msg=forged"}},
+		{name: "oversized replaced", headers: []string{strings.Repeat("a", 129)}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var ctx fasthttp.RequestCtx
+			for _, value := range tt.headers {
+				ctx.Request.Header.Add("X-Request-ID", value)
+			}
+			_, cancel := httpcontext.NewAdapter(time.Second).Attach(&ctx)
+			defer cancel()
+
+			got := string(ctx.Response.Header.Peek("X-Request-ID"))
+			if tt.want != "" {
+				if got != tt.want {
+					t.Fatalf("request id = %q, want %q", got, tt.want)
+				}
+				return
+			}
+			if _, err := uuid.Parse(got); err != nil {
+				t.Fatalf("request id = %q, want a freshly generated UUID", got)
+			}
+		})
+	}
+}
+
+func TestAttachUsesInjectedRequestIDGenerator(t *testing.T) {
+	adapter := httpcontext.NewAdapter(time.Second, httpcontext.WithRequestIDGenerator(func() string {
+		return "req-fixed"
+	}))
+
+	var ctx fasthttp.RequestCtx
+	_, cancel := adapter.Attach(&ctx)
+	defer cancel()
+	if got := string(ctx.Response.Header.Peek("X-Request-ID")); got != "req-fixed" {
+		t.Fatalf("request id = %q, want the injected generator's", got)
+	}
+
+	var supplied fasthttp.RequestCtx
+	supplied.Request.Header.Set("X-Request-ID", "client-id")
+	_, cancel = adapter.Attach(&supplied)
+	defer cancel()
+	if got := string(supplied.Response.Header.Peek("X-Request-ID")); got != "client-id" {
+		t.Fatalf("request id = %q, want the client's valid ID kept", got)
+	}
+}
@@ -0,0 +1,26 @@
+package httpcontext
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestULIDEncoding(t *testing.T) {
+	// Timestamp prefix from the ULID specification's example.
+	if got := ulidAt(time.UnixMilli(1469918176385))[:10]; got != "01ARYZ6S41" {
+		t.Fatalf("timestamp prefix = %q, want 01ARYZ6S41", got)
+	}
+	var max [16]byte
+	for i := range max {
+		max[i] = 0xff
+	}
+	if got := encodeULID(max); got != "7"+strings.Repeat("Z", 25) {
+		t.Fatalf("max ULID = %q", got)
+	}
+
+	earlier, later := ulidAt(time.UnixMilli(1000)), ulidAt(time.UnixMilli(2000))
+	if len(earlier) != 26 || earlier >= later {
+		t.Fatalf("ULIDs %q and %q must be 26 characters and sort by time", earlier, later)
+	}
+}
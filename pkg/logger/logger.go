@@ -2,6 +2,7 @@ package logger
 
 import (
 	"context"
+	"io"
 	"os"
 
 	"go.uber.org/zap"
@@ -16,10 +17,19 @@ const requestIDKey ctxKey = "request_id"
 type Config struct {
 	Level    string
 	Encoding string
+	// Environment, Version and Commit are attached to every line so logs from different
+	// deployments can be told apart. Empty values are left out.
+	Environment string
+	Version     string
+	Commit      string
 }
 
 // New builds a zap.Logger using the provided configuration.
 func New(cfg Config) (*zap.Logger, error) {
+	return newLogger(cfg, os.Stdout)
+}
+
+func newLogger(cfg Config, out io.Writer) (*zap.Logger, error) {
 	encoderCfg := zap.NewProductionEncoderConfig()
 	encoderCfg.TimeKey = "timestamp"
 	encoderCfg.EncodeTime = zapcore.ISO8601TimeEncoder
@@ -40,11 +50,21 @@ func New(cfg Config) (*zap.Logger, error) {
 
 	core := zapcore.NewCore(
 		encoder,
-		zapcore.AddSync(zapcore.Lock(os.Stdout)),
+		zapcore.Lock(zapcore.AddSync(out)),
 		level,
 	)
 
-	return zap.New(core, zap.AddCaller()), nil
+	var fields []zap.Field
+	for _, field := range []struct{ key, value string }{
+		{"env", cfg.Environment},
+		{"version", cfg.Version},
+		{"commit", cfg.Commit},
+	} {
+		if field.value != "" {
+			fields = append(fields, zap.String(field.key, field.value))
+		}
+	}
+	return zap.New(core, zap.AddCaller(), zap.Fields(fields...)), nil
 }
 
 // ContextWithRequestID attaches a request ID to the provided context.
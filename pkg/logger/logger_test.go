@@ -0,0 +1,31 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+)
+
+func TestNewAttachesDeploymentFields(t *testing.T) {
+	var out bytes.Buffer
+	log, err := newLogger(Config{Level: "info", Environment: "staging", Version: "v1.4.0", Commit: "abc123"}, &out)
+	if err != nil {
+		t.Fatalf("new logger: %v", err)
+	}
+	log.Info("hello")
+	log.Named("child").Warn("again")
+
+	lines := bytes.Split(bytes.TrimSpace(out.Bytes()), []byte("\n"))
+	if len(lines) != 2 {
+		t.Fatalf("got %d log lines, want 2: %s", len(lines), out.String())
+	}
+	for _, line := range lines {
+		var entry map[string]interface{}
+		if err := json.Unmarshal(line, &entry); err != nil {
+			t.Fatalf("decode %s: %v", line, err)
+		}
+		if entry["env"] != "staging" || entry["version"] != "v1.4.0" || entry["commit"] != "abc123" {
+			t.Fatalf("entry = %v, want env, version and commit on every line", entry)
+		}
+	}
+}
@@ -0,0 +1,15 @@
+// Package security holds primitives shared by every check that handles secrets.
+package security
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+)
+
+// Equal reports whether two secrets (API keys, tokens, signatures) are the same. It runs in time
+// independent of where they differ, and because both sides are hashed to a fixed size first, of
+// their lengths too. Use it instead of == for anything an attacker may be guessing.
+func Equal(a, b string) bool {
+	x, y := sha256.Sum256([]byte(a)), sha256.Sum256([]byte(b))
+	return subtle.ConstantTimeCompare(x[:], y[:]) == 1
+}
@@ -0,0 +1,25 @@
+package security_test
+
+import (
+	"testing"
+
+	"github.com/fastygo/backend/pkg/security"
+)
+
+func TestEqual(t *testing.T) {
+	cases := []struct {
+		a, b string
+		want bool
+	}{
+		{"secret", "secret", true},
+		{"", "", true},
+		{"secret", "secreT", false},
+		{"secret", "secret-longer", false},
+		{"secret", "", false},
+	}
+	for _, tc := range cases {
+		if got := security.Equal(tc.a, tc.b); got != tc.want {
+			t.Errorf("Equal(%q, %q) = %v, want %v", tc.a, tc.b, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,127 @@
+// Package timing records where a request spends its time. A Recorder travels with the request and
+// accumulates named spans; code that touches the database, Redis or the serializer marks a span
+// around its work. Without a recorder every call is a no-op, so instrumented code costs next to
+// nothing when the breakdown is disabled.
+package timing
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// Span names recorded by the built-in instrumentation.
+const (
+	SpanDB        = "db"
+	SpanRedis     = "redis"
+	SpanSerialize = "serialize"
+)
+
+// Span is the time accumulated under one name.
+type Span struct {
+	Name  string
+	Count int
+	Total time.Duration
+}
+
+// Recorder accumulates spans for one request. It is safe for concurrent use; a nil Recorder records
+// nothing.
+type Recorder struct {
+	mu    sync.Mutex
+	spans []Span
+}
+
+func NewRecorder() *Recorder {
+	return &Recorder{}
+}
+
+var noop = func() {}
+
+// Start marks the beginning of a span and returns the function that ends it.
+func (r *Recorder) Start(name string) (stop func()) {
+	if r == nil {
+		return noop
+	}
+	start := time.Now()
+	return func() { r.Add(name, time.Since(start)) }
+}
+
+// Add records d under name.
+func (r *Recorder) Add(name string, d time.Duration) {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for i := range r.spans {
+		if r.spans[i].Name == name {
+			r.spans[i].Count++
+			r.spans[i].Total += d
+			return
+		}
+	}
+	r.spans = append(r.spans, Span{Name: name, Count: 1, Total: d})
+}
+
+// Spans returns the recorded spans in the order their names were first seen.
+func (r *Recorder) Spans() []Span {
+	if r == nil {
+		return nil
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]Span(nil), r.spans...)
+}
+
+// MarshalLogObject writes each span's total and count, e.g. db=12ms db_count=3.
+func (r *Recorder) MarshalLogObject(enc zapcore.ObjectEncoder) error {
+	for _, span := range r.Spans() {
+		enc.AddDuration(span.Name, span.Total)
+		enc.AddInt(span.Name+"_count", span.Count)
+	}
+	return nil
+}
+
+// ServerTiming formats the spans, followed by total, as a Server-Timing header value with
+// durations in milliseconds.
+func (r *Recorder) ServerTiming(total time.Duration) string {
+	var b strings.Builder
+	for _, span := range r.Spans() {
+		writeMetric(&b, span.Name, span.Total)
+		b.WriteString(", ")
+	}
+	writeMetric(&b, "total", total)
+	return b.String()
+}
+
+func writeMetric(b *strings.Builder, name string, d time.Duration) {
+	b.WriteString(name)
+	b.WriteString(";dur=")
+	b.WriteString(strconv.FormatFloat(float64(d)/float64(time.Millisecond), 'f', 3, 64))
+}
+
+type contextKey struct{}
+
+// NewContext returns a copy of ctx carrying r.
+func NewContext(ctx context.Context, r *Recorder) context.Context {
+	return context.WithValue(ctx, contextKey{}, r)
+}
+
+// FromContext returns the recorder stored in ctx, or nil.
+func FromContext(ctx context.Context) *Recorder {
+	if ctx == nil {
+		return nil
+	}
+	r, _ := ctx.Value(contextKey{}).(*Recorder)
+	return r
+}
+
+// Start marks the beginning of a span on the recorder in ctx, if any, and returns the function that
+// ends it: defer timing.Start(ctx, timing.SpanRedis)().
+func Start(ctx context.Context, name string) (stop func()) {
+	return FromContext(ctx).Start(name)
+}
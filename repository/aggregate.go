@@ -2,6 +2,7 @@ package repository
 
 import (
 	"context"
+	"fmt"
 
 	"github.com/fastygo/backend/domain"
 )
@@ -14,9 +15,52 @@ type AggregateFilter struct {
 	Offset   int
 }
 
+// SaveBatchOptions controls AggregateRepository.SaveBatch.
+type SaveBatchOptions struct {
+	// BestEffort saves every item it can and reports failures per item instead of rolling back.
+	BestEffort bool
+	// CheckVersion enables optimistic locking: an existing aggregate is only replaced by an item whose
+	// Version is exactly one above the stored version.
+	CheckVersion bool
+	// Limits bounds each aggregate's labels.
+	Limits domain.MetadataLimits
+}
+
+// SaveResult is the outcome of one item of a batch; Err is nil when the aggregate was saved.
+type SaveResult struct {
+	ID  string
+	Err error
+}
+
+// BatchItemError reports the item that aborted an all-or-nothing batch. It unwraps to the item's error.
+type BatchItemError struct {
+	Index int
+	ID    string
+	Err   error
+}
+
+func (e *BatchItemError) Error() string {
+	return fmt.Sprintf("aggregate %d (%s): %v", e.Index, e.ID, e.Err)
+}
+
+func (e *BatchItemError) Unwrap() error {
+	return e.Err
+}
+
 type AggregateRepository interface {
 	Get(ctx context.Context, id string) (*domain.Aggregate, error)
 	List(ctx context.Context, filter AggregateFilter) ([]domain.Aggregate, error)
 	Save(ctx context.Context, aggregate *domain.Aggregate) error
+	// SaveBatch upserts aggregates in a single transaction. Without BestEffort the first failing item
+	// rolls everything back and is returned as a *BatchItemError; with it, failed items are skipped and
+	// reported in the results. Existing aggregates are never moved to another tenant. Callers bound
+	// the batch size (see aggregate.MaxBatchSizeLimit), since the transaction locks every row it writes.
+	SaveBatch(ctx context.Context, aggregates []*domain.Aggregate, opts SaveBatchOptions) ([]SaveResult, error)
+	// BulkImport inserts new aggregates in one streamed, all-or-nothing write. It is insert-only: an ID
+	// that is already stored fails the whole import with domain.ErrConflict. Callers validate and
+	// de-duplicate the input first. Zero timestamps are set to the import time.
+	BulkImport(ctx context.Context, aggregates []*domain.Aggregate) error
 	AppendEvent(ctx context.Context, event domain.Event) error
+	// ListEvents returns the aggregate's events ordered by version.
+	ListEvents(ctx context.Context, aggregateID string) ([]domain.Event, error)
 }
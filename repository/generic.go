@@ -0,0 +1,11 @@
+package repository
+
+import "context"
+
+// Repository is the read and delete shape shared by entity repositories: T is the entity and F the
+// filter List accepts. Writes stay entity-specific because their SQL and conflict rules differ.
+type Repository[T any, F any] interface {
+	GetByID(ctx context.Context, id string) (*T, error)
+	List(ctx context.Context, filter F) ([]T, error)
+	Delete(ctx context.Context, id string) error
+}
@@ -0,0 +1,61 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+
+	"github.com/fastygo/backend/domain"
+)
+
+// KindKeyValue is the aggregate kind under which key-value entries are stored.
+const KindKeyValue = "kv"
+
+// Labels identifying a key-value entry on its aggregate.
+const (
+	LabelNamespace = "namespace"
+	LabelKey       = "key"
+)
+
+// KeyValue is one entry of a namespace; Value is any JSON document.
+type KeyValue struct {
+	Key   string          `json:"key"`
+	Value json.RawMessage `json:"value"`
+}
+
+// KeyValueRepository keeps small pieces of persisted state (feature flags, counters) grouped by
+// namespace. Entries live in the aggregate table as KindKeyValue aggregates.
+type KeyValueRepository interface {
+	Put(ctx context.Context, namespace, key string, value json.RawMessage) error
+	// Get returns domain.ErrKeyNotFound when the key is not set.
+	Get(ctx context.Context, namespace, key string) (json.RawMessage, error)
+	// Delete is a no-op for keys that are not set.
+	Delete(ctx context.Context, namespace, key string) error
+	// List returns every entry of the namespace ordered by key.
+	List(ctx context.Context, namespace string) ([]KeyValue, error)
+}
+
+// KeyValueID is the aggregate ID of an entry. The namespace is length-prefixed so that no
+// namespace/key pair can collide with another.
+func KeyValueID(namespace, key string) string {
+	return KindKeyValue + ":" + strconv.Itoa(len(namespace)) + ":" + namespace + ":" + key
+}
+
+// KeyValueAggregate builds the aggregate an entry is stored as, rejecting an empty namespace or key
+// and a value that is not JSON.
+func KeyValueAggregate(namespace, key string, value json.RawMessage) (*domain.Aggregate, error) {
+	if namespace == "" || key == "" || !json.Valid(value) {
+		return nil, domain.ErrInvalidPayload
+	}
+	return &domain.Aggregate{
+		ID:      KeyValueID(namespace, key),
+		Kind:    KindKeyValue,
+		Payload: value,
+		Labels:  map[string]string{LabelNamespace: namespace, LabelKey: key},
+	}, nil
+}
+
+// KeyValueFromAggregate reads an entry back from its aggregate.
+func KeyValueFromAggregate(aggregate domain.Aggregate) KeyValue {
+	return KeyValue{Key: aggregate.Labels[LabelKey], Value: aggregate.Payload}
+}
@@ -0,0 +1,99 @@
+package repository_test
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/fastygo/backend/domain"
+	"github.com/fastygo/backend/repository"
+	"github.com/fastygo/backend/repository/repositorytest"
+)
+
+func TestKeyValueRoundTrip(t *testing.T) {
+	ctx := context.Background()
+	aggregates := repositorytest.NewAggregates()
+	kv := repositorytest.NewKeyValues(aggregates)
+
+	if err := kv.Put(ctx, "flags", "beta", json.RawMessage(`true`)); err != nil {
+		t.Fatalf("put: %v", err)
+	}
+	if err := kv.Put(ctx, "flags", "beta", json.RawMessage(`{"rollout":50}`)); err != nil {
+		t.Fatalf("overwrite: %v", err)
+	}
+	value, err := kv.Get(ctx, "flags", "beta")
+	if err != nil || string(value) != `{"rollout":50}` {
+		t.Fatalf("get = %s, %v; want the overwritten value", value, err)
+	}
+
+	stored, err := aggregates.Get(ctx, repository.KeyValueID("flags", "beta"))
+	if err != nil {
+		t.Fatalf("entry not stored as an aggregate: %v", err)
+	}
+	if stored.Kind != repository.KindKeyValue || stored.Labels[repository.LabelNamespace] != "flags" || stored.Labels[repository.LabelKey] != "beta" {
+		t.Fatalf("aggregate = %+v, want kind kv labelled with namespace and key", stored)
+	}
+
+	if err := kv.Delete(ctx, "flags", "beta"); err != nil {
+		t.Fatalf("delete: %v", err)
+	}
+	if _, err := kv.Get(ctx, "flags", "beta"); err != domain.ErrKeyNotFound {
+		t.Fatalf("get after delete err = %v, want ErrKeyNotFound", err)
+	}
+	if err := kv.Delete(ctx, "flags", "beta"); err != nil {
+		t.Fatalf("deleting a missing key: %v", err)
+	}
+
+	for _, bad := range []struct{ namespace, key, value string }{
+		{"", "k", `1`},
+		{"ns", "", `1`},
+		{"ns", "k", `not json`},
+	} {
+		if err := kv.Put(ctx, bad.namespace, bad.key, json.RawMessage(bad.value)); err != domain.ErrInvalidPayload {
+			t.Errorf("put(%q, %q, %q) err = %v, want ErrInvalidPayload", bad.namespace, bad.key, bad.value, err)
+		}
+	}
+}
+
+func TestKeyValueListByNamespace(t *testing.T) {
+	ctx := context.Background()
+	kv := repositorytest.NewKeyValues(nil)
+
+	puts := []struct{ namespace, key, value string }{
+		{"counters", "visits", `12`},
+		{"counters", "signups", `3`},
+		{"flags", "visits", `false`},
+		// Would share an ID with counters/"x:y" if namespaces were joined without a length prefix.
+		{"counters:x", "y", `1`},
+	}
+	for _, p := range puts {
+		if err := kv.Put(ctx, p.namespace, p.key, json.RawMessage(p.value)); err != nil {
+			t.Fatalf("put %s/%s: %v", p.namespace, p.key, err)
+		}
+	}
+	if err := kv.Put(ctx, "counters", "x:y", json.RawMessage(`2`)); err != nil {
+		t.Fatalf("put: %v", err)
+	}
+
+	entries, err := kv.List(ctx, "counters")
+	if err != nil {
+		t.Fatalf("list: %v", err)
+	}
+	want := []repository.KeyValue{
+		{Key: "signups", Value: json.RawMessage(`3`)},
+		{Key: "visits", Value: json.RawMessage(`12`)},
+		{Key: "x:y", Value: json.RawMessage(`2`)},
+	}
+	if len(entries) != len(want) {
+		t.Fatalf("list = %+v, want %+v", entries, want)
+	}
+	for i := range want {
+		if entries[i].Key != want[i].Key || string(entries[i].Value) != string(want[i].Value) {
+			t.Errorf("entry %d = %s=%s, want %s=%s", i, entries[i].Key, entries[i].Value, want[i].Key, want[i].Value)
+		}
+	}
+
+	if entries, _ := kv.List(ctx, "missing"); len(entries) != 0 {
+		t.Fatalf("list of an unknown namespace = %+v, want none", entries)
+	}
+}
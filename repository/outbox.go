@@ -0,0 +1,23 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/fastygo/backend/domain"
+)
+
+// OutboxRepository stores outbox messages until the relay has published or given up on them.
+type OutboxRepository interface {
+	// Add stores a message, generating its ID and CreatedAt when unset.
+	Add(ctx context.Context, msg *domain.OutboxMessage) error
+	// Pending lists up to limit messages that are neither published nor dead-lettered and are due
+	// at now, oldest first.
+	Pending(ctx context.Context, now time.Time, limit int) ([]domain.OutboxMessage, error)
+	// MarkPublished records that the message was delivered, so it is never sent again.
+	MarkPublished(ctx context.Context, id string, at time.Time) error
+	// Reschedule records a failed attempt and defers the message until next.
+	Reschedule(ctx context.Context, id string, attempts int, next time.Time, lastError string) error
+	// DeadLetter sets the message aside for operators; it is no longer pending.
+	DeadLetter(ctx context.Context, id string, attempts int, lastError string, at time.Time) error
+}
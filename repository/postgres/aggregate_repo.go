@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"time"
 
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
@@ -58,11 +59,122 @@ func (r *aggregateRepository) List(ctx context.Context, filter repository.Aggreg
 	return aggregates, rows.Err()
 }
 
+// rowQuerier is satisfied by both the pool and a transaction.
+type rowQuerier interface {
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+}
+
 func (r *aggregateRepository) Save(ctx context.Context, aggregate *domain.Aggregate) error {
 	if aggregate == nil {
 		return domain.ErrInvalidPayload
 	}
+	return upsertAggregate(ctx, r.pool, aggregate)
+}
+
+func (r *aggregateRepository) SaveBatch(ctx context.Context, aggregates []*domain.Aggregate, opts repository.SaveBatchOptions) ([]repository.SaveResult, error) {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	results := make([]repository.SaveResult, len(aggregates))
+	for i, aggregate := range aggregates {
+		if aggregate != nil {
+			results[i].ID = aggregate.ID
+		}
+		if !opts.BestEffort {
+			if err := saveBatchItem(ctx, tx, aggregate, opts); err != nil {
+				return nil, &repository.BatchItemError{Index: i, ID: results[i].ID, Err: err}
+			}
+			continue
+		}
+
+		// A savepoint per item lets a failed statement be undone without aborting the transaction.
+		savepoint, err := tx.Begin(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if err := saveBatchItem(ctx, savepoint, aggregate, opts); err != nil {
+			if rbErr := savepoint.Rollback(ctx); rbErr != nil {
+				return nil, rbErr
+			}
+			results[i].Err = err
+			continue
+		}
+		if err := savepoint.Commit(ctx); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+// aggregateCopyColumns is the column order of the rows built by aggregateCopyRows.
+var aggregateCopyColumns = []string{"id", "kind", "tenant_id", "owner_id", "version", "payload", "labels", "created_at", "updated_at"}
+
+// BulkImport streams the aggregates with COPY, skipping the per-row round trips of SaveBatch. COPY
+// has no ON CONFLICT clause, so a stored ID aborts the whole copy with a unique violation.
+func (r *aggregateRepository) BulkImport(ctx context.Context, aggregates []*domain.Aggregate) error {
+	_, err := r.pool.CopyFrom(ctx, pgx.Identifier{"aggregates"}, aggregateCopyColumns,
+		pgx.CopyFromRows(aggregateCopyRows(aggregates, time.Now())))
+	return translateError(err)
+}
 
+// aggregateCopyRows stamps zero timestamps with now and lays the aggregates out in aggregateCopyColumns order.
+func aggregateCopyRows(aggregates []*domain.Aggregate, now time.Time) [][]any {
+	rows := make([][]any, len(aggregates))
+	for i, aggregate := range aggregates {
+		if aggregate.CreatedAt.IsZero() {
+			aggregate.CreatedAt = now
+		}
+		if aggregate.UpdatedAt.IsZero() {
+			aggregate.UpdatedAt = now
+		}
+		rows[i] = []any{
+			aggregate.ID,
+			aggregate.Kind,
+			aggregate.TenantID,
+			aggregate.OwnerID,
+			aggregate.Version,
+			[]byte(aggregate.Payload),
+			marshalMap(aggregate.Labels),
+			aggregate.CreatedAt,
+			aggregate.UpdatedAt,
+		}
+	}
+	return rows
+}
+
+// saveBatchItem locks the stored row, if any, to check tenant ownership and the optimistic version
+// before upserting.
+func saveBatchItem(ctx context.Context, tx pgx.Tx, aggregate *domain.Aggregate, opts repository.SaveBatchOptions) error {
+	if err := aggregate.Validate(opts.Limits); err != nil {
+		return err
+	}
+
+	var (
+		version  int
+		tenantID string
+	)
+	err := tx.QueryRow(ctx, `SELECT version, tenant_id FROM aggregates WHERE id = $1 FOR UPDATE`, aggregate.ID).
+		Scan(&version, &tenantID)
+	switch {
+	case errors.Is(err, pgx.ErrNoRows):
+	case err != nil:
+		return err
+	case tenantID != aggregate.TenantID:
+		return domain.ErrAggregateForeignTenant
+	case opts.CheckVersion && aggregate.Version != version+1:
+		return domain.ErrAggregateVersionConflict
+	}
+	return upsertAggregate(ctx, tx, aggregate)
+}
+
+func upsertAggregate(ctx context.Context, q rowQuerier, aggregate *domain.Aggregate) error {
 	const query = `
 	INSERT INTO aggregates (id, kind, tenant_id, owner_id, version, payload, labels, created_at, updated_at)
 	VALUES ($1, $2, $3, $4, $5, $6, $7, COALESCE($8, NOW()), NOW())
@@ -83,7 +195,7 @@ func (r *aggregateRepository) Save(ctx context.Context, aggregate *domain.Aggreg
 
 	labels := marshalMap(aggregate.Labels)
 
-	if err := r.pool.QueryRow(ctx, query,
+	if err := q.QueryRow(ctx, query,
 		aggregate.ID,
 		aggregate.Kind,
 		aggregate.TenantID,
@@ -93,7 +205,7 @@ func (r *aggregateRepository) Save(ctx context.Context, aggregate *domain.Aggreg
 		labels,
 		nullTime(aggregate.CreatedAt),
 	).Scan(&aggregate.CreatedAt, &aggregate.UpdatedAt); err != nil {
-		return err
+		return translateError(err)
 	}
 
 	return nil
@@ -117,7 +229,39 @@ func (r *aggregateRepository) AppendEvent(ctx context.Context, event domain.Even
 		nullTime(event.CreatedAt),
 	)
 
-	return err
+	return translateError(err)
+}
+
+func (r *aggregateRepository) ListEvents(ctx context.Context, aggregateID string) ([]domain.Event, error) {
+	const query = `
+	SELECT id, aggregate_id, name, version, payload, metadata, created_at
+	FROM aggregate_events
+	WHERE aggregate_id = $1
+	ORDER BY version
+	`
+	rows, err := r.pool.Query(ctx, query, aggregateID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var events []domain.Event
+	for rows.Next() {
+		var (
+			event    domain.Event
+			payload  []byte
+			metadata []byte
+		)
+		if err := rows.Scan(&event.ID, &event.AggregateID, &event.Name, &event.Version, &payload, &metadata, &event.CreatedAt); err != nil {
+			return nil, err
+		}
+		event.Payload = append(json.RawMessage(nil), payload...)
+		if len(metadata) > 0 {
+			_ = json.Unmarshal(metadata, &event.Metadata)
+		}
+		events = append(events, event)
+	}
+	return events, rows.Err()
 }
 
 func scanAggregate(row interface {
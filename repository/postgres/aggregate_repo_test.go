@@ -0,0 +1,89 @@
+package postgres
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/fastygo/backend/domain"
+	"github.com/fastygo/backend/repository"
+)
+
+func TestAggregateCopyRowsMatchColumns(t *testing.T) {
+	now := time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)
+	created := now.Add(-time.Hour)
+	aggregates := []*domain.Aggregate{
+		{ID: "a1", Kind: "deal", TenantID: "acme", OwnerID: "u1", Version: 2, Payload: json.RawMessage(`{"v":1}`), Labels: map[string]string{"stage": "won"}, CreatedAt: created},
+		{ID: "a2", Kind: "deal"},
+	}
+
+	rows := aggregateCopyRows(aggregates, now)
+
+	want := [][]any{
+		{"a1", "deal", "acme", "u1", 2, []byte(`{"v":1}`), []byte(`{"stage":"won"}`), created, now},
+		{"a2", "deal", "", "", 0, []byte(nil), []byte(nil), now, now},
+	}
+	if !reflect.DeepEqual(rows, want) {
+		t.Fatalf("rows = %v, want %v", rows, want)
+	}
+	for _, row := range rows {
+		if len(row) != len(aggregateCopyColumns) {
+			t.Fatalf("row has %d values for %d columns", len(row), len(aggregateCopyColumns))
+		}
+	}
+	if !aggregates[1].CreatedAt.Equal(now) || !aggregates[1].UpdatedAt.Equal(now) {
+		t.Fatalf("timestamps not reported back to the caller: %+v", aggregates[1])
+	}
+}
+
+// benchmarkAggregates returns n aggregates with IDs unique to this run, and removes them afterwards.
+func benchmarkAggregates(b *testing.B, pool *pgxpool.Pool, prefix string, n int) []*domain.Aggregate {
+	b.Helper()
+	aggregates := make([]*domain.Aggregate, n)
+	for i := range aggregates {
+		aggregates[i] = &domain.Aggregate{ID: fmt.Sprintf("%s-%d", prefix, i), Kind: "bench", Version: 1, Payload: json.RawMessage(`{"n":1}`)}
+	}
+	b.Cleanup(func() {
+		_, _ = pool.Exec(context.Background(), `DELETE FROM aggregates WHERE kind = 'bench' AND id LIKE $1`, prefix+"-%")
+	})
+	return aggregates
+}
+
+// BenchmarkAggregateImport compares COPY with the per-row SaveBatch path. It needs a database with
+// the aggregates table: TEST_DATABASE_URL=postgres://... go test -run=^$ -bench=AggregateImport ./repository/postgres
+func BenchmarkAggregateImport(b *testing.B) {
+	url := os.Getenv("TEST_DATABASE_URL")
+	if url == "" {
+		b.Skip("TEST_DATABASE_URL is not set")
+	}
+	pool, err := pgxpool.New(context.Background(), url)
+	if err != nil {
+		b.Fatalf("connect: %v", err)
+	}
+	b.Cleanup(pool.Close)
+	repo := NewAggregateRepository(pool)
+	const size = 5000
+
+	b.Run("SaveBatch", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			aggregates := benchmarkAggregates(b, pool, fmt.Sprintf("bench-insert-%d", i), size)
+			if _, err := repo.SaveBatch(context.Background(), aggregates, repository.SaveBatchOptions{}); err != nil {
+				b.Fatalf("save batch: %v", err)
+			}
+		}
+	})
+	b.Run("BulkImport", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			aggregates := benchmarkAggregates(b, pool, fmt.Sprintf("bench-copy-%d", i), size)
+			if err := repo.BulkImport(context.Background(), aggregates); err != nil {
+				b.Fatalf("bulk import: %v", err)
+			}
+		}
+	})
+}
@@ -0,0 +1,113 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// querier is the part of *pgxpool.Pool the generic repository uses.
+type querier interface {
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+	Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error)
+}
+
+// rowScanner reads one row selected with a table's columns. It maps pgx.ErrNoRows to the entity's
+// not-found error.
+type rowScanner[T any] func(row pgx.Row) (*T, error)
+
+// sqlFilter collects the equality conditions, ordering and paging a filter maps to.
+type sqlFilter struct {
+	conditions []string
+	args       []any
+	limit      int
+	offset     int
+}
+
+// eq adds "column = $n" with value as its argument.
+func (f *sqlFilter) eq(column string, value any) {
+	f.args = append(f.args, value)
+	f.conditions = append(f.conditions, fmt.Sprintf("%s = $%d", column, len(f.args)))
+}
+
+// where adds a condition that takes no arguments.
+func (f *sqlFilter) where(condition string) {
+	f.conditions = append(f.conditions, condition)
+}
+
+// table describes how an entity is stored: its columns in scan order, how to scan them, and how
+// its filter becomes SQL.
+type table[T any, F any] struct {
+	name     string
+	columns  string
+	orderBy  string
+	scan     rowScanner[T]
+	filter   func(F) sqlFilter
+	notFound error
+}
+
+// genericRepository implements repository.Repository for any entity described by a table.
+type genericRepository[T any, F any] struct {
+	db    querier
+	table table[T, F]
+}
+
+func newGenericRepository[T any, F any](db querier, t table[T, F]) *genericRepository[T, F] {
+	return &genericRepository[T, F]{db: db, table: t}
+}
+
+func (r *genericRepository[T, F]) GetByID(ctx context.Context, id string) (*T, error) {
+	query := fmt.Sprintf("SELECT %s FROM %s WHERE id = $1", r.table.columns, r.table.name)
+	return r.table.scan(r.db.QueryRow(ctx, query, id))
+}
+
+func (r *genericRepository[T, F]) List(ctx context.Context, filter F) ([]T, error) {
+	query, args := r.listQuery(filter)
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var items []T
+	for rows.Next() {
+		item, err := r.table.scan(rows)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, *item)
+	}
+	return items, rows.Err()
+}
+
+func (r *genericRepository[T, F]) listQuery(filter F) (string, []any) {
+	f := r.table.filter(filter)
+	var b strings.Builder
+	fmt.Fprintf(&b, "SELECT %s FROM %s", r.table.columns, r.table.name)
+	if len(f.conditions) > 0 {
+		b.WriteString(" WHERE ")
+		b.WriteString(strings.Join(f.conditions, " AND "))
+	}
+	if r.table.orderBy != "" {
+		b.WriteString(" ORDER BY ")
+		b.WriteString(r.table.orderBy)
+	}
+	args := append(f.args, clampLimit(f.limit), f.offset)
+	fmt.Fprintf(&b, " LIMIT $%d OFFSET $%d", len(args)-1, len(args))
+	return b.String(), args
+}
+
+func (r *genericRepository[T, F]) Delete(ctx context.Context, id string) error {
+	tag, err := r.db.Exec(ctx, fmt.Sprintf("DELETE FROM %s WHERE id = $1", r.table.name), id)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return r.table.notFound
+	}
+	return nil
+}
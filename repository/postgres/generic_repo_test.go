@@ -0,0 +1,136 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+
+	"github.com/fastygo/backend/domain"
+	"github.com/fastygo/backend/repository"
+)
+
+// errRow fails every Scan with err.
+type errRow struct{ err error }
+
+func (r errRow) Scan(...interface{}) error { return r.err }
+
+// valuesRows iterates over fixed rows.
+type valuesRows struct {
+	rows []valuesRow
+	next int
+}
+
+func (r *valuesRows) Close()                                       {}
+func (r *valuesRows) Err() error                                   { return nil }
+func (r *valuesRows) CommandTag() pgconn.CommandTag                { return pgconn.CommandTag{} }
+func (r *valuesRows) FieldDescriptions() []pgconn.FieldDescription { return nil }
+func (r *valuesRows) Values() ([]any, error)                       { return nil, nil }
+func (r *valuesRows) RawValues() [][]byte                          { return nil }
+func (r *valuesRows) Conn() *pgx.Conn                              { return nil }
+func (r *valuesRows) Next() bool {
+	r.next++
+	return r.next <= len(r.rows)
+}
+func (r *valuesRows) Scan(dest ...any) error { return r.rows[r.next-1].Scan(dest...) }
+
+// recordingDB records the last statement and answers with canned results.
+type recordingDB struct {
+	sql  string
+	args []any
+	row  pgx.Row
+	rows []valuesRow
+	tag  pgconn.CommandTag
+}
+
+func (db *recordingDB) QueryRow(_ context.Context, sql string, args ...any) pgx.Row {
+	db.sql, db.args = sql, args
+	return db.row
+}
+
+func (db *recordingDB) Query(_ context.Context, sql string, args ...any) (pgx.Rows, error) {
+	db.sql, db.args = sql, args
+	return &valuesRows{rows: db.rows}, nil
+}
+
+func (db *recordingDB) Exec(_ context.Context, sql string, args ...any) (pgconn.CommandTag, error) {
+	db.sql, db.args = sql, args
+	return db.tag, nil
+}
+
+func taskRow(id string) valuesRow {
+	return valuesRow{id, "u1", "", "title", "", "pending", 3, (*time.Time)(nil), []byte(nil), time.Time{}, time.Time{}}
+}
+
+func TestTaskListQueryKeepsFilterSemantics(t *testing.T) {
+	const selectTasks = "SELECT id, user_id, COALESCE(tenant_id, ''), title, description, status, priority, due_date, metadata, created_at, updated_at FROM tasks"
+	tests := []struct {
+		name   string
+		filter repository.TaskFilter
+		sql    string
+		args   []any
+	}{
+		{
+			name:   "empty fields do not constrain and limit is clamped",
+			filter: repository.TaskFilter{Limit: 500},
+			sql:    selectTasks + " ORDER BY created_at DESC LIMIT $1 OFFSET $2",
+			args:   []any{100, 0},
+		},
+		{
+			name:   "every field",
+			filter: repository.TaskFilter{UserID: "u1", TenantID: "acme", Status: "pending", Priority: 2, Limit: 10, Offset: 20},
+			sql:    selectTasks + " WHERE user_id = $1 AND lower(status) = $2 AND priority = $3 AND tenant_id = $4 ORDER BY created_at DESC LIMIT $5 OFFSET $6",
+			args:   []any{"u1", "pending", 2, "acme", 10, 20},
+		},
+		{
+			name:   "only overdue",
+			filter: repository.TaskFilter{UserID: "u1", OnlyOverdue: true},
+			sql:    selectTasks + " WHERE user_id = $1 AND due_date < NOW() AND lower(status) != 'completed' ORDER BY created_at DESC LIMIT $2 OFFSET $3",
+			args:   []any{"u1", 100, 0},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := &recordingDB{}
+			if _, err := newGenericRepository(db, taskTable).List(context.Background(), tt.filter); err != nil {
+				t.Fatalf("list: %v", err)
+			}
+			if db.sql != tt.sql || !reflect.DeepEqual(db.args, tt.args) {
+				t.Fatalf("query = %q %v\nwant    %q %v", db.sql, db.args, tt.sql, tt.args)
+			}
+		})
+	}
+}
+
+func TestGenericTaskRepositoryMatchesTaskRepository(t *testing.T) {
+	ctx := context.Background()
+
+	db := &recordingDB{rows: []valuesRow{taskRow("t1"), taskRow("t2")}}
+	repo := newGenericRepository(db, taskTable)
+	tasks, err := repo.List(ctx, repository.TaskFilter{})
+	if err != nil || len(tasks) != 2 || tasks[0].ID != "t1" || tasks[1].ID != "t2" {
+		t.Fatalf("list = %+v, %v; want t1 and t2 in row order", tasks, err)
+	}
+
+	db.row = taskRow("t1")
+	if task, err := repo.GetByID(ctx, "t1"); err != nil || task.ID != "t1" || db.args[0] != "t1" {
+		t.Fatalf("get = %+v, %v; want t1", task, err)
+	}
+	db.row = errRow{pgx.ErrNoRows}
+	if _, err := repo.GetByID(ctx, "missing"); !errors.Is(err, domain.ErrTaskNotFound) {
+		t.Fatalf("get missing: err = %v, want ErrTaskNotFound", err)
+	}
+
+	db.tag = pgconn.NewCommandTag("DELETE 1")
+	if err := repo.Delete(ctx, "t1"); err != nil || db.sql != "DELETE FROM tasks WHERE id = $1" {
+		t.Fatalf("delete: %v (%q)", err, db.sql)
+	}
+	db.tag = pgconn.NewCommandTag("DELETE 0")
+	if err := repo.Delete(ctx, "missing"); !errors.Is(err, domain.ErrTaskNotFound) {
+		t.Fatalf("delete missing: err = %v, want ErrTaskNotFound", err)
+	}
+}
@@ -2,9 +2,17 @@ package postgres
 
 import (
 	"encoding/json"
+	"errors"
 	"time"
+
+	"github.com/jackc/pgx/v5/pgconn"
+
+	"github.com/fastygo/backend/domain"
 )
 
+// uniqueViolation is the SQLSTATE reported when a unique constraint is breached.
+const uniqueViolation = "23505"
+
 func marshalMap(data map[string]string) []byte {
 	if len(data) == 0 {
 		return nil
@@ -22,3 +30,22 @@ func nullTime(t time.Time) interface{} {
 	}
 	return t
 }
+
+// utcTime returns a UTC copy of t, so due dates are stored and read back in one zone whatever
+// offset the client sent.
+func utcTime(t *time.Time) *time.Time {
+	if t == nil {
+		return nil
+	}
+	utc := t.UTC()
+	return &utc
+}
+
+// translateError maps Postgres-specific failures onto domain errors.
+func translateError(err error) error {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == uniqueViolation {
+		return domain.ErrConflict
+	}
+	return err
+}
@@ -0,0 +1,64 @@
+package postgres
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgconn"
+
+	"github.com/fastygo/backend/domain"
+)
+
+func TestTranslateErrorMapsUniqueViolationToConflict(t *testing.T) {
+	err := fmt.Errorf("insert task: %w", &pgconn.PgError{Code: uniqueViolation})
+	if got := translateError(err); got != domain.ErrConflict {
+		t.Fatalf("translateError = %v, want ErrConflict", got)
+	}
+
+	other := &pgconn.PgError{Code: "23503"}
+	if got := translateError(other); !errors.Is(got, other) {
+		t.Fatalf("non-unique violations must pass through, got %v", got)
+	}
+}
+
+// valuesRow scans fixed column values into Scan destinations.
+type valuesRow []interface{}
+
+func (r valuesRow) Scan(dest ...interface{}) error {
+	for i, d := range dest {
+		if r[i] != nil {
+			reflect.ValueOf(d).Elem().Set(reflect.ValueOf(r[i]))
+		}
+	}
+	return nil
+}
+
+func TestDueDateRoundTripsInUTC(t *testing.T) {
+	berlin := time.FixedZone("CET", 3600)
+	due := time.Date(2024, 6, 1, 0, 30, 0, 0, berlin)
+
+	stored := utcTime(&due)
+	if stored.Location() != time.UTC || !stored.Equal(due) {
+		t.Fatalf("stored = %v, want the same instant in UTC", stored)
+	}
+
+	// Reading back through a session whose zone is not UTC must still yield UTC.
+	read := due.In(time.FixedZone("EST", -5*3600))
+	task, err := scanTask(valuesRow{"t1", "u1", "", "title", "", "pending", 3, &read, []byte(nil), time.Time{}, time.Time{}})
+	if err != nil {
+		t.Fatalf("scanTask: %v", err)
+	}
+	if task.DueDate.Location() != time.UTC || !task.DueDate.Equal(due) {
+		t.Fatalf("due date = %v, want %v in UTC", task.DueDate, due.UTC())
+	}
+	if got := task.DueDate.Format(time.RFC3339); got != "2024-05-31T23:30:00Z" {
+		t.Fatalf("due date = %s, want 2024-05-31T23:30:00Z", got)
+	}
+
+	if utcTime(nil) != nil {
+		t.Fatal("utcTime(nil) must stay nil")
+	}
+}
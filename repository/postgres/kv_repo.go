@@ -0,0 +1,75 @@
+package postgres
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/fastygo/backend/domain"
+	"github.com/fastygo/backend/repository"
+)
+
+type keyValueRepository struct {
+	pool *pgxpool.Pool
+}
+
+// NewKeyValueRepository creates a KeyValueRepository storing entries in the aggregates table.
+func NewKeyValueRepository(pool *pgxpool.Pool) repository.KeyValueRepository {
+	return &keyValueRepository{pool: pool}
+}
+
+func (r *keyValueRepository) Put(ctx context.Context, namespace, key string, value json.RawMessage) error {
+	aggregate, err := repository.KeyValueAggregate(namespace, key, value)
+	if err != nil {
+		return err
+	}
+	return upsertAggregate(ctx, r.pool, aggregate)
+}
+
+func (r *keyValueRepository) Get(ctx context.Context, namespace, key string) (json.RawMessage, error) {
+	const query = `
+	SELECT id, kind, tenant_id, owner_id, version, payload, labels, created_at, updated_at
+	FROM aggregates
+	WHERE id = $1 AND kind = $2
+	`
+	aggregate, err := scanAggregate(r.pool.QueryRow(ctx, query, repository.KeyValueID(namespace, key), repository.KindKeyValue))
+	if errors.Is(err, domain.ErrAggregateNotFound) {
+		return nil, domain.ErrKeyNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return aggregate.Payload, nil
+}
+
+func (r *keyValueRepository) Delete(ctx context.Context, namespace, key string) error {
+	const query = `DELETE FROM aggregates WHERE id = $1 AND kind = $2`
+	_, err := r.pool.Exec(ctx, query, repository.KeyValueID(namespace, key), repository.KindKeyValue)
+	return err
+}
+
+func (r *keyValueRepository) List(ctx context.Context, namespace string) ([]repository.KeyValue, error) {
+	const query = `
+	SELECT id, kind, tenant_id, owner_id, version, payload, labels, created_at, updated_at
+	FROM aggregates
+	WHERE kind = $1 AND labels ->> 'namespace' = $2
+	ORDER BY labels ->> 'key'
+	`
+	rows, err := r.pool.Query(ctx, query, repository.KindKeyValue, namespace)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var entries []repository.KeyValue
+	for rows.Next() {
+		aggregate, err := scanAggregate(rows)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, repository.KeyValueFromAggregate(*aggregate))
+	}
+	return entries, rows.Err()
+}
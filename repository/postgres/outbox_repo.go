@@ -0,0 +1,97 @@
+package postgres
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/fastygo/backend/domain"
+	"github.com/fastygo/backend/repository"
+)
+
+// outboxRepository stores messages in the outbox table:
+//
+//	CREATE TABLE outbox (
+//		id               uuid PRIMARY KEY,
+//		topic            text NOT NULL,
+//		payload          jsonb NOT NULL,
+//		attempts         int NOT NULL DEFAULT 0,
+//		created_at       timestamptz NOT NULL DEFAULT NOW(),
+//		next_attempt_at  timestamptz NOT NULL DEFAULT NOW(),
+//		published_at     timestamptz,
+//		dead_lettered_at timestamptz,
+//		last_error       text NOT NULL DEFAULT ''
+//	);
+//	CREATE INDEX outbox_pending ON outbox (next_attempt_at) WHERE published_at IS NULL AND dead_lettered_at IS NULL;
+type outboxRepository struct {
+	pool *pgxpool.Pool
+}
+
+// NewOutboxRepository returns a Postgres-backed implementation of OutboxRepository.
+func NewOutboxRepository(pool *pgxpool.Pool) repository.OutboxRepository {
+	return &outboxRepository{pool: pool}
+}
+
+func (r *outboxRepository) Add(ctx context.Context, msg *domain.OutboxMessage) error {
+	if msg == nil {
+		return domain.ErrInvalidPayload
+	}
+	if msg.ID == "" {
+		msg.ID = uuid.NewString()
+	}
+	const query = `
+	INSERT INTO outbox (id, topic, payload)
+	VALUES ($1, $2, $3)
+	RETURNING created_at, next_attempt_at`
+	return r.pool.QueryRow(ctx, query, msg.ID, msg.Topic, []byte(msg.Payload)).Scan(&msg.CreatedAt, &msg.NextAttemptAt)
+}
+
+func (r *outboxRepository) Pending(ctx context.Context, now time.Time, limit int) ([]domain.OutboxMessage, error) {
+	const query = `
+	SELECT id, topic, payload, attempts, created_at, next_attempt_at, last_error
+	FROM outbox
+	WHERE published_at IS NULL AND dead_lettered_at IS NULL AND next_attempt_at <= $1
+	ORDER BY created_at, id
+	LIMIT $2`
+	rows, err := r.pool.Query(ctx, query, now.UTC(), clampLimit(limit))
+	if err != nil {
+		return nil, err
+	}
+	return pgx.CollectRows(rows, func(row pgx.CollectableRow) (domain.OutboxMessage, error) {
+		var (
+			msg     domain.OutboxMessage
+			payload []byte
+		)
+		err := row.Scan(&msg.ID, &msg.Topic, &payload, &msg.Attempts, &msg.CreatedAt, &msg.NextAttemptAt, &msg.LastError)
+		msg.Payload = payload
+		return msg, err
+	})
+}
+
+func (r *outboxRepository) MarkPublished(ctx context.Context, id string, at time.Time) error {
+	return r.exec(ctx, `UPDATE outbox SET published_at = $2 WHERE id = $1`, id, at.UTC())
+}
+
+func (r *outboxRepository) Reschedule(ctx context.Context, id string, attempts int, next time.Time, lastError string) error {
+	return r.exec(ctx, `UPDATE outbox SET attempts = $2, next_attempt_at = $3, last_error = $4 WHERE id = $1`,
+		id, attempts, next.UTC(), lastError)
+}
+
+func (r *outboxRepository) DeadLetter(ctx context.Context, id string, attempts int, lastError string, at time.Time) error {
+	return r.exec(ctx, `UPDATE outbox SET attempts = $2, last_error = $3, dead_lettered_at = $4 WHERE id = $1`,
+		id, attempts, lastError, at.UTC())
+}
+
+func (r *outboxRepository) exec(ctx context.Context, query string, args ...any) error {
+	tag, err := r.pool.Exec(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.ErrOutboxNotFound
+	}
+	return nil
+}
@@ -14,49 +14,77 @@ import (
 	"github.com/fastygo/backend/repository"
 )
 
-type taskRepository struct {
-	pool *pgxpool.Pool
+// taskTable reads and deletes tasks through the generic repository; empty filter fields do not
+// constrain the list.
+var taskTable = table[domain.Task, repository.TaskFilter]{
+	name:    "tasks",
+	columns: "id, user_id, COALESCE(tenant_id, ''), title, description, status, priority, due_date, metadata, created_at, updated_at",
+	orderBy: "created_at DESC",
+	scan: func(row pgx.Row) (*domain.Task, error) {
+		return scanTask(row)
+	},
+	filter: func(filter repository.TaskFilter) sqlFilter {
+		f := sqlFilter{limit: filter.Limit, offset: filter.Offset}
+		if filter.UserID != "" {
+			f.eq("user_id", filter.UserID)
+		}
+		if filter.Status != "" {
+			// Callers pass a normalized status; lower() also matches rows stored before normalization.
+			f.eq("lower(status)", filter.Status)
+		}
+		if filter.Priority != 0 {
+			f.eq("priority", filter.Priority)
+		}
+		if filter.TenantID != "" {
+			f.eq("tenant_id", filter.TenantID)
+		}
+		if filter.OnlyOverdue {
+			// Matches domain.Task.IsOverdue: tasks without a due date fail the comparison.
+			f.where("due_date < NOW() AND lower(status) != 'completed'")
+		}
+		return f
+	},
+	notFound: domain.ErrTaskNotFound,
 }
 
-// NewTaskRepository returns a Postgres-backed implementation of TaskRepository.
-func NewTaskRepository(pool *pgxpool.Pool) repository.TaskRepository {
-	return &taskRepository{pool: pool}
+type taskRepository struct {
+	*genericRepository[domain.Task, repository.TaskFilter]
+	pool      *pgxpool.Pool
+	reminders bool
 }
 
-func (r *taskRepository) GetByID(ctx context.Context, id string) (*domain.Task, error) {
-	const query = `
-	SELECT id, user_id, title, description, status, priority, due_date, metadata, created_at, updated_at
-	FROM tasks
-	WHERE id = $1
-	`
-	row := r.pool.QueryRow(ctx, query, id)
-	return scanTask(row)
+// TaskOption customizes the task repository.
+type TaskOption func(*taskRepository)
+
+// WithReminders clears a task's reminded_at when an update moves its due date, so it is reminded
+// again. Enable it only with reminders on: it needs the reminded_at column.
+func WithReminders(enabled bool) TaskOption {
+	return func(r *taskRepository) {
+		r.reminders = enabled
+	}
 }
 
-func (r *taskRepository) List(ctx context.Context, filter repository.TaskFilter) ([]domain.Task, error) {
-	const query = `
-	SELECT id, user_id, title, description, status, priority, due_date, metadata, created_at, updated_at
-	FROM tasks
-	WHERE ($1 = '' OR user_id = $1)
-	  AND ($2 = '' OR status = $2)
-	ORDER BY created_at DESC
-	LIMIT $3 OFFSET $4
-	`
-	rows, err := r.pool.Query(ctx, query, filter.UserID, filter.Status, clampLimit(filter.Limit), filter.Offset)
-	if err != nil {
-		return nil, err
+// NewTaskRepository returns a Postgres-backed implementation of TaskRepository.
+func NewTaskRepository(pool *pgxpool.Pool, opts ...TaskOption) repository.TaskRepository {
+	r := newTaskRepository(pool)
+	for _, opt := range opts {
+		opt(r)
 	}
-	defer rows.Close()
+	return r
+}
 
-	var tasks []domain.Task
-	for rows.Next() {
-		task, err := scanTask(rows)
-		if err != nil {
-			return nil, err
-		}
-		tasks = append(tasks, *task)
+// NewTaskReminders tracks task reminders in the reminded_at column of tasks.
+func NewTaskReminders(pool *pgxpool.Pool) repository.TaskReminders {
+	r := newTaskRepository(pool)
+	r.reminders = true
+	return r
+}
+
+func newTaskRepository(pool *pgxpool.Pool) *taskRepository {
+	return &taskRepository{
+		genericRepository: newGenericRepository(pool, taskTable),
+		pool:              pool,
 	}
-	return tasks, rows.Err()
 }
 
 func (r *taskRepository) Create(ctx context.Context, task *domain.Task) (*domain.Task, error) {
@@ -68,11 +96,12 @@ func (r *taskRepository) Create(ctx context.Context, task *domain.Task) (*domain
 	}
 
 	const query = `
-	INSERT INTO tasks (id, user_id, title, description, status, priority, due_date, metadata)
-	VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+	INSERT INTO tasks (id, user_id, title, description, status, priority, due_date, metadata, tenant_id)
+	VALUES ($1, $2, $3, $4, $5, $6, $7, $8, NULLIF($9, ''))
 	RETURNING created_at, updated_at
 	`
 
+	task.DueDate = utcTime(task.DueDate)
 	var due interface{}
 	if task.DueDate != nil {
 		due = *task.DueDate
@@ -89,8 +118,9 @@ func (r *taskRepository) Create(ctx context.Context, task *domain.Task) (*domain
 		task.Priority,
 		due,
 		metadata,
+		task.TenantID,
 	).Scan(&task.CreatedAt, &task.UpdatedAt); err != nil {
-		return nil, err
+		return nil, translateError(err)
 	}
 
 	return task, nil
@@ -101,19 +131,26 @@ func (r *taskRepository) Update(ctx context.Context, task *domain.Task) error {
 		return domain.ErrInvalidPayload
 	}
 
-	const query = `
+	query := `
 	UPDATE tasks
 	SET title = $2,
 		description = $3,
 		status = $4,
-		priority = $5,
+		priority = $5,`
+	if r.reminders {
+		query += `
+		-- A moved due date earns a fresh reminder.
+		reminded_at = CASE WHEN due_date IS DISTINCT FROM $6 THEN NULL ELSE reminded_at END,`
+	}
+	query += `
 		due_date = $6,
 		metadata = $7,
 		updated_at = NOW()
-	WHERE id = $1
+	WHERE id = $1 AND user_id = $8 AND tenant_id IS NOT DISTINCT FROM NULLIF($9, '')
 	RETURNING updated_at
 	`
 
+	task.DueDate = utcTime(task.DueDate)
 	var due interface{}
 	if task.DueDate != nil {
 		due = *task.DueDate
@@ -129,21 +166,24 @@ func (r *taskRepository) Update(ctx context.Context, task *domain.Task) error {
 		task.Priority,
 		due,
 		metadata,
+		task.UserID,
+		task.TenantID,
 	).Scan(&task.UpdatedAt); err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			return domain.ErrTaskNotFound
 		}
-		return err
+		return translateError(err)
 	}
 
 	return nil
 }
 
-func (r *taskRepository) Delete(ctx context.Context, id string) error {
-	const query = `DELETE FROM tasks WHERE id = $1`
-	tag, err := r.pool.Exec(ctx, query, id)
+// DeleteOwned implements repository.TaskRepository.
+func (r *taskRepository) DeleteOwned(ctx context.Context, id, userID, tenantID string) error {
+	tag, err := r.pool.Exec(ctx, `DELETE FROM tasks WHERE id = $1 AND user_id = $2 AND tenant_id IS NOT DISTINCT FROM NULLIF($3, '')`,
+		id, userID, tenantID)
 	if err != nil {
-		return err
+		return translateError(err)
 	}
 	if tag.RowsAffected() == 0 {
 		return domain.ErrTaskNotFound
@@ -151,6 +191,44 @@ func (r *taskRepository) Delete(ctx context.Context, id string) error {
 	return nil
 }
 
+// DueForReminder implements repository.TaskReminders.
+func (r *taskRepository) DueForReminder(ctx context.Context, from, until time.Time, limit int) ([]domain.Task, error) {
+	query := `SELECT ` + taskTable.columns + ` FROM tasks
+	WHERE due_date >= $1 AND due_date < $2 AND lower(status) != 'completed' AND reminded_at IS NULL
+	ORDER BY due_date
+	LIMIT $3`
+	rows, err := r.pool.Query(ctx, query, from.UTC(), until.UTC(), clampLimit(limit))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var tasks []domain.Task
+	for rows.Next() {
+		task, err := scanTask(rows)
+		if err != nil {
+			return nil, err
+		}
+		tasks = append(tasks, *task)
+	}
+	return tasks, rows.Err()
+}
+
+// ClaimReminder implements repository.TaskReminders.
+func (r *taskRepository) ClaimReminder(ctx context.Context, id string, at time.Time) (bool, error) {
+	tag, err := r.pool.Exec(ctx, `UPDATE tasks SET reminded_at = $2 WHERE id = $1 AND reminded_at IS NULL`, id, at.UTC())
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() == 1, nil
+}
+
+// ReleaseReminder implements repository.TaskReminders.
+func (r *taskRepository) ReleaseReminder(ctx context.Context, id string) error {
+	_, err := r.pool.Exec(ctx, `UPDATE tasks SET reminded_at = NULL WHERE id = $1`, id)
+	return err
+}
+
 func scanTask(row interface {
 	Scan(dest ...interface{}) error
 }) (*domain.Task, error) {
@@ -163,6 +241,7 @@ func scanTask(row interface {
 	if err := row.Scan(
 		&task.ID,
 		&task.UserID,
+		&task.TenantID,
 		&task.Title,
 		&task.Description,
 		&task.Status,
@@ -178,7 +257,7 @@ func scanTask(row interface {
 		return nil, err
 	}
 
-	task.DueDate = due
+	task.DueDate = utcTime(due)
 	if len(metadata) > 0 {
 		_ = json.Unmarshal(metadata, &task.Metadata)
 	}
@@ -75,7 +75,7 @@ func (r *userRepository) Upsert(ctx context.Context, user *domain.User) error {
 		metadata,
 		nullTime(user.CreatedAt),
 	).Scan(&createdAt, &updatedAt); err != nil {
-		return err
+		return translateError(err)
 	}
 
 	user.CreatedAt = createdAt
@@ -0,0 +1,175 @@
+package redis
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	redislib "github.com/redis/go-redis/v9"
+)
+
+// fakeClient is an in-memory SessionClient. Errors queued in failures are returned, one per
+// command, before the command touches the map.
+type fakeClient struct {
+	mu       sync.Mutex
+	values   map[string]string
+	ttls     map[string]time.Duration
+	zsets    map[string]map[string]float64
+	failures []error
+	calls    int
+}
+
+func newFakeClient() *fakeClient {
+	return &fakeClient{
+		values: make(map[string]string),
+		ttls:   make(map[string]time.Duration),
+		zsets:  make(map[string]map[string]float64),
+	}
+}
+
+func (c *fakeClient) nextFailure() error {
+	c.calls++
+	if len(c.failures) == 0 {
+		return nil
+	}
+	err := c.failures[0]
+	c.failures = c.failures[1:]
+	return err
+}
+
+func (c *fakeClient) Get(ctx context.Context, key string) *redislib.StringCmd {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.nextFailure(); err != nil {
+		return redislib.NewStringResult("", err)
+	}
+	value, ok := c.values[key]
+	if !ok {
+		return redislib.NewStringResult("", redislib.Nil)
+	}
+	return redislib.NewStringResult(value, nil)
+}
+
+func (c *fakeClient) Set(ctx context.Context, key string, value interface{}, expiration time.Duration) *redislib.StatusCmd {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.nextFailure(); err != nil {
+		return redislib.NewStatusResult("", err)
+	}
+	switch v := value.(type) {
+	case []byte:
+		c.values[key] = string(v)
+	default:
+		c.values[key] = fmt.Sprint(v)
+	}
+	c.ttls[key] = expiration
+	return redislib.NewStatusResult("OK", nil)
+}
+
+func (c *fakeClient) Del(ctx context.Context, keys ...string) *redislib.IntCmd {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.nextFailure(); err != nil {
+		return redislib.NewIntResult(0, err)
+	}
+	var deleted int64
+	for _, key := range keys {
+		if _, ok := c.values[key]; ok {
+			delete(c.values, key)
+			delete(c.ttls, key)
+			deleted++
+		}
+	}
+	return redislib.NewIntResult(deleted, nil)
+}
+
+func (c *fakeClient) Expire(ctx context.Context, key string, expiration time.Duration) *redislib.BoolCmd {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.nextFailure(); err != nil {
+		return redislib.NewBoolResult(false, err)
+	}
+	_, isValue := c.values[key]
+	_, isZSet := c.zsets[key]
+	if !isValue && !isZSet {
+		return redislib.NewBoolResult(false, nil)
+	}
+	c.ttls[key] = expiration
+	return redislib.NewBoolResult(true, nil)
+}
+
+func (c *fakeClient) ZAdd(ctx context.Context, key string, members ...redislib.Z) *redislib.IntCmd {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.nextFailure(); err != nil {
+		return redislib.NewIntResult(0, err)
+	}
+	set, ok := c.zsets[key]
+	if !ok {
+		set = make(map[string]float64)
+		c.zsets[key] = set
+	}
+	var added int64
+	for _, member := range members {
+		name := fmt.Sprint(member.Member)
+		if _, exists := set[name]; !exists {
+			added++
+		}
+		set[name] = member.Score
+	}
+	return redislib.NewIntResult(added, nil)
+}
+
+func (c *fakeClient) ZRevRange(ctx context.Context, key string, start, stop int64) *redislib.StringSliceCmd {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.nextFailure(); err != nil {
+		return redislib.NewStringSliceResult(nil, err)
+	}
+	set := c.zsets[key]
+	members := make([]string, 0, len(set))
+	for member := range set {
+		members = append(members, member)
+	}
+	sort.Slice(members, func(i, j int) bool { return set[members[i]] > set[members[j]] })
+	if stop < 0 || stop >= int64(len(members)) {
+		stop = int64(len(members)) - 1
+	}
+	if start > stop {
+		return redislib.NewStringSliceResult(nil, nil)
+	}
+	return redislib.NewStringSliceResult(members[start:stop+1], nil)
+}
+
+func (c *fakeClient) ZRem(ctx context.Context, key string, members ...interface{}) *redislib.IntCmd {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.nextFailure(); err != nil {
+		return redislib.NewIntResult(0, err)
+	}
+	var removed int64
+	for _, member := range members {
+		name := fmt.Sprint(member)
+		if _, ok := c.zsets[key][name]; ok {
+			delete(c.zsets[key], name)
+			removed++
+		}
+	}
+	return redislib.NewIntResult(removed, nil)
+}
+
+func (c *fakeClient) SetNX(ctx context.Context, key string, value interface{}, expiration time.Duration) *redislib.BoolCmd {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.nextFailure(); err != nil {
+		return redislib.NewBoolResult(false, err)
+	}
+	if _, ok := c.values[key]; ok {
+		return redislib.NewBoolResult(false, nil)
+	}
+	c.values[key] = fmt.Sprint(value)
+	c.ttls[key] = expiration
+	return redislib.NewBoolResult(true, nil)
+}
@@ -0,0 +1,21 @@
+package redis
+
+import "strings"
+
+// Keyspace builds Redis keys under an optional namespace so several environments can share a cluster.
+type Keyspace struct {
+	namespace string
+}
+
+// NewKeyspace returns a key builder scoped to namespace. An empty namespace keeps keys unprefixed.
+func NewKeyspace(namespace string) Keyspace {
+	return Keyspace{namespace: strings.Trim(namespace, ":")}
+}
+
+// Key joins the namespace and parts with ':' separators.
+func (k Keyspace) Key(parts ...string) string {
+	if k.namespace == "" {
+		return strings.Join(parts, ":")
+	}
+	return k.namespace + ":" + strings.Join(parts, ":")
+}
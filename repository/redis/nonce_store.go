@@ -0,0 +1,41 @@
+package redis
+
+import (
+	"context"
+	"time"
+
+	redislib "github.com/redis/go-redis/v9"
+)
+
+// NonceClient is the subset of the go-redis API used by NonceStore.
+type NonceClient interface {
+	SetNX(ctx context.Context, key string, value interface{}, expiration time.Duration) *redislib.BoolCmd
+}
+
+// NonceStore records single-use request nonces as expiring keys.
+type NonceStore struct {
+	client NonceClient
+	keys   Keyspace
+	ttls   TTLPolicy
+	retry  RetryPolicy
+}
+
+// NewNonceStore creates a nonce store whose keys live under namespace and expire after ttls.Nonce.
+func NewNonceStore(client NonceClient, namespace string, ttls TTLPolicy, retry RetryPolicy) *NonceStore {
+	return &NonceStore{client: client, keys: NewKeyspace(namespace), ttls: ttls.withDefaults(), retry: retry}
+}
+
+// Claim atomically records nonce and reports false when it was already recorded. The key expires
+// after the policy's Nonce TTL, or after ttl when the caller accepts nonces for longer.
+func (s *NonceStore) Claim(ctx context.Context, nonce string, ttl time.Duration) (bool, error) {
+	if ttl < s.ttls.Nonce {
+		ttl = s.ttls.Nonce
+	}
+	var fresh bool
+	err := withRetry(ctx, s.retry, func() error {
+		var err error
+		fresh, err = s.client.SetNX(ctx, s.keys.Key("nonce", nonce), 1, ttl).Result()
+		return err
+	})
+	return fresh, err
+}
@@ -0,0 +1,37 @@
+package redis
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestNonceStoreClaimsOnce(t *testing.T) {
+	client := newFakeClient()
+	store := NewNonceStore(client, "prod", TTLPolicy{Nonce: time.Minute}, RetryPolicy{})
+
+	fresh, err := store.Claim(context.Background(), "n1", time.Minute)
+	if err != nil || !fresh {
+		t.Fatalf("first claim = %v, %v; want fresh", fresh, err)
+	}
+	if got := client.ttls["prod:nonce:n1"]; got != time.Minute {
+		t.Fatalf("ttl = %v, want 1m under the namespaced key", got)
+	}
+
+	fresh, err = store.Claim(context.Background(), "n1", time.Minute)
+	if err != nil || fresh {
+		t.Fatalf("replayed claim = %v, %v; want already seen", fresh, err)
+	}
+}
+
+func TestNonceStoreNeverForgetsBeforeThePolicyTTL(t *testing.T) {
+	client := newFakeClient()
+	store := NewNonceStore(client, "", TTLPolicy{}, RetryPolicy{})
+
+	if _, err := store.Claim(context.Background(), "n1", time.Second); err != nil {
+		t.Fatalf("claim: %v", err)
+	}
+	if got := client.ttls["nonce:n1"]; got != DefaultTTLPolicy.Nonce {
+		t.Fatalf("ttl = %v, want the default policy's %v", got, DefaultTTLPolicy.Nonce)
+	}
+}
@@ -0,0 +1,77 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"strings"
+	"time"
+
+	redislib "github.com/redis/go-redis/v9"
+
+	"github.com/fastygo/backend/pkg/timing"
+)
+
+// RetryPolicy bounds how transient Redis failures are retried.
+type RetryPolicy struct {
+	// MaxRetries is the number of additional attempts after the first failure. Zero disables retries.
+	MaxRetries int
+	// Backoff is the delay before the first retry; it doubles for each subsequent one.
+	Backoff time.Duration
+}
+
+func (p RetryPolicy) delay(attempt int) time.Duration {
+	if p.Backoff <= 0 {
+		return 0
+	}
+	return p.Backoff << min(attempt, 10)
+}
+
+// transientReplyPrefixes are server replies signalling a temporary condition (failover, loading,
+// resharding). MOVED and ASK are slot-migration redirections: a cluster client follows them itself
+// and only surfaces one when its redirect budget ran out mid-migration, by which time its slot map
+// is refreshed and a retry reaches the new owner.
+var transientReplyPrefixes = []string{"LOADING ", "READONLY ", "MASTERDOWN ", "TRYAGAIN ", "CLUSTERDOWN ", "MOVED ", "ASK "}
+
+// isRetryable distinguishes transient network/server failures from logical outcomes such as redis.Nil.
+func isRetryable(err error) bool {
+	if err == nil || errors.Is(err, redislib.Nil) {
+		return false
+	}
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) || errors.Is(err, net.ErrClosed) {
+		return true
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		return true
+	}
+	msg := err.Error()
+	for _, prefix := range transientReplyPrefixes {
+		if strings.HasPrefix(msg, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// withRetry runs op, retrying retryable failures according to policy until ctx is done.
+func withRetry(ctx context.Context, policy RetryPolicy, op func() error) error {
+	defer timing.Start(ctx, timing.SpanRedis)()
+	for attempt := 0; ; attempt++ {
+		err := op()
+		if err == nil || attempt >= policy.MaxRetries || !isRetryable(err) {
+			return err
+		}
+		timer := time.NewTimer(policy.delay(attempt))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return err
+		case <-timer.C:
+		}
+	}
+}
@@ -0,0 +1,116 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	redislib "github.com/redis/go-redis/v9"
+
+	"github.com/fastygo/backend/domain"
+)
+
+func TestSessionGetRetriesTransientFailureOnce(t *testing.T) {
+	client := newFakeClient()
+	repo := NewSessionRepository(client, TTLPolicy{Session: time.Hour}, WithRetry(RetryPolicy{MaxRetries: 2}))
+	if err := repo.Save(context.Background(), &domain.Session{ID: "s1", UserID: "u1"}); err != nil {
+		t.Fatalf("save: %v", err)
+	}
+
+	client.calls = 0
+	client.failures = []error{io.EOF}
+	session, err := repo.Get(context.Background(), "s1")
+	if err != nil {
+		t.Fatalf("get after one flaky call: %v", err)
+	}
+	if session.UserID != "u1" {
+		t.Fatalf("session = %+v, want u1", session)
+	}
+	if client.calls != 2 {
+		t.Fatalf("calls = %d, want the failure plus one retry", client.calls)
+	}
+}
+
+func TestSessionRetriesClusterRedirection(t *testing.T) {
+	client := newFakeClient()
+	repo := NewSessionRepository(client, TTLPolicy{Session: time.Hour}, WithRetry(RetryPolicy{MaxRetries: 2}))
+	if err := repo.Save(context.Background(), &domain.Session{ID: "s1", UserID: "u1"}); err != nil {
+		t.Fatalf("save: %v", err)
+	}
+
+	// The slot moved while the command was in flight: the retry reaches the new owner.
+	client.calls = 0
+	client.failures = []error{errors.New("MOVED 3999 127.0.0.1:6381")}
+	if _, err := repo.Get(context.Background(), "s1"); err != nil {
+		t.Fatalf("get across a MOVED redirection: %v", err)
+	}
+	if client.calls != 2 {
+		t.Fatalf("calls = %d, want the redirection plus one retry", client.calls)
+	}
+
+	// A redirection that outlasts the retries is an infrastructure error, not a missing session.
+	moved := errors.New("MOVED 3999 127.0.0.1:6381")
+	client.failures = []error{moved, moved, moved}
+	err := repo.Extend(context.Background(), "s1", 60)
+	if !errors.Is(err, moved) || errors.Is(err, domain.ErrSessionNotFound) {
+		t.Fatalf("extend error = %v, want the MOVED reply", err)
+	}
+}
+
+func TestSessionRetryGivesUpAfterMaxRetries(t *testing.T) {
+	client := newFakeClient()
+	repo := NewSessionRepository(client, TTLPolicy{Session: time.Hour}, WithRetry(RetryPolicy{MaxRetries: 1}))
+
+	client.failures = []error{io.EOF, io.EOF, io.EOF}
+	if err := repo.Delete(context.Background(), "s1"); !errors.Is(err, io.EOF) {
+		t.Fatalf("delete error = %v, want io.EOF after exhausting retries", err)
+	}
+	if client.calls != 2 {
+		t.Fatalf("calls = %d, want 2", client.calls)
+	}
+}
+
+func TestSessionRetrySkipsLogicalErrors(t *testing.T) {
+	client := newFakeClient()
+	repo := NewSessionRepository(client, TTLPolicy{Session: time.Hour}, WithRetry(RetryPolicy{MaxRetries: 3}))
+
+	if _, err := repo.Get(context.Background(), "missing"); err != domain.ErrSessionNotFound {
+		t.Fatalf("get missing error = %v, want ErrSessionNotFound", err)
+	}
+	if client.calls != 1 {
+		t.Fatalf("calls = %d, a cache miss must not be retried", client.calls)
+	}
+
+	client.calls = 0
+	client.failures = []error{errors.New("WRONGTYPE Operation against a key holding the wrong kind of value")}
+	if err := repo.Delete(context.Background(), "s1"); err == nil {
+		t.Fatal("delete succeeded despite a non-transient error")
+	}
+	if client.calls != 1 {
+		t.Fatalf("calls = %d, a non-transient error must not be retried", client.calls)
+	}
+}
+
+func TestIsRetryable(t *testing.T) {
+	tests := []struct {
+		err  error
+		want bool
+	}{
+		{err: nil, want: false},
+		{err: redislib.Nil, want: false},
+		{err: context.Canceled, want: false},
+		{err: io.EOF, want: true},
+		{err: errors.New("LOADING Redis is loading the dataset in memory"), want: true},
+		{err: errors.New("MOVED 3999 127.0.0.1:6381"), want: true},
+		{err: errors.New("ASK 3999 127.0.0.1:6381"), want: true},
+		{err: errors.New("ERR unknown command"), want: false},
+		{err: errors.New("ERR ASKING is not allowed"), want: false},
+	}
+	for _, tt := range tests {
+		if got := isRetryable(tt.err); got != tt.want {
+			t.Errorf("isRetryable(%v) = %v, want %v", tt.err, got, tt.want)
+		}
+	}
+}
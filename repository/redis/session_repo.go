@@ -3,35 +3,111 @@ package redis
 import (
 	"context"
 	"encoding/json"
-	"fmt"
+	"sync"
 	"time"
 
 	redislib "github.com/redis/go-redis/v9"
 
 	"github.com/fastygo/backend/domain"
+	"github.com/fastygo/backend/pkg/clock"
 	"github.com/fastygo/backend/repository"
 )
 
+// SessionClient is the subset of the go-redis API used by the session repository.
+type SessionClient interface {
+	Get(ctx context.Context, key string) *redislib.StringCmd
+	Set(ctx context.Context, key string, value interface{}, expiration time.Duration) *redislib.StatusCmd
+	Del(ctx context.Context, keys ...string) *redislib.IntCmd
+	Expire(ctx context.Context, key string, expiration time.Duration) *redislib.BoolCmd
+	ZAdd(ctx context.Context, key string, members ...redislib.Z) *redislib.IntCmd
+	ZRevRange(ctx context.Context, key string, start, stop int64) *redislib.StringSliceCmd
+	ZRem(ctx context.Context, key string, members ...interface{}) *redislib.IntCmd
+}
+
+// SessionReader is the subset of the go-redis API used to read sessions from a replica.
+type SessionReader interface {
+	Get(ctx context.Context, key string) *redislib.StringCmd
+	ZRevRange(ctx context.Context, key string, start, stop int64) *redislib.StringSliceCmd
+}
+
 type sessionRepository struct {
-	client *redislib.Client
-	prefix string
-	ttl    time.Duration
+	client SessionClient
+	keys   Keyspace
+	ttls   TTLPolicy
+	clock  clock.Clock
+	retry  RetryPolicy
+
+	replica SessionReader
+	window  time.Duration
+
+	mu sync.Mutex
+	// written holds, per key, when the read-your-writes window of its latest write ends.
+	written map[string]time.Time
 }
 
-// NewSessionRepository creates a Redis-backed session repository.
-func NewSessionRepository(client *redislib.Client, ttl time.Duration) repository.SessionRepository {
-	if ttl <= 0 {
-		ttl = time.Hour
+// maxTrackedWrites bounds written before expired windows are swept.
+const maxTrackedWrites = 4096
+
+// Option customizes the session repository.
+type Option func(*sessionRepository)
+
+// WithClock overrides the time source used to stamp sessions and derive key TTLs.
+func WithClock(c clock.Clock) Option {
+	return func(r *sessionRepository) {
+		if c != nil {
+			r.clock = c
+		}
+	}
+}
+
+// WithNamespace prefixes session keys with namespace (e.g. "staging" yields "staging:session:<id>").
+func WithNamespace(namespace string) Option {
+	return func(r *sessionRepository) {
+		r.keys = NewKeyspace(namespace)
+	}
+}
+
+// WithRetry retries transient Redis failures (network errors, failover replies) per policy.
+func WithRetry(policy RetryPolicy) Option {
+	return func(r *sessionRepository) {
+		r.retry = policy
 	}
-	return &sessionRepository{
+}
+
+// WithReadReplica serves reads from replica, except reads of a key written by this repository
+// within the last window, which go to the primary so a session is visible right after it is saved,
+// extended or deleted despite asynchronous replication. A longer window covers more replication lag
+// but sends more reads to the primary and so costs it load and the reads their replica locality.
+// The window is tracked per process: another instance reading the session it did not write sees
+// the replica, so route a client's requests to one instance when that matters.
+func WithReadReplica(replica SessionReader, window time.Duration) Option {
+	return func(r *sessionRepository) {
+		r.replica = replica
+		r.window = window
+	}
+}
+
+// NewSessionRepository creates a Redis-backed session repository whose keys expire per ttls.
+func NewSessionRepository(client SessionClient, ttls TTLPolicy, opts ...Option) repository.SessionRepository {
+	r := &sessionRepository{
 		client: client,
-		prefix: "session:",
-		ttl:    ttl,
+		keys:   NewKeyspace(""),
+		ttls:   ttls.withDefaults(),
+		clock:  clock.Real(),
 	}
+	for _, opt := range opts {
+		opt(r)
+	}
+	return r
 }
 
 func (r *sessionRepository) Get(ctx context.Context, id string) (*domain.Session, error) {
-	result, err := r.client.Get(ctx, r.key(id)).Result()
+	var result string
+	err := withRetry(ctx, r.retry, func() error {
+		var err error
+		result, err = r.reader(r.key(id)).Get(ctx, r.key(id)).Result()
+		return err
+	})
 	if err != nil {
 		if err == redislib.Nil {
 			return nil, domain.ErrSessionNotFound
@@ -52,10 +128,10 @@ func (r *sessionRepository) Save(ctx context.Context, session *domain.Session) e
 	}
 
 	if session.CreatedAt.IsZero() {
-		session.CreatedAt = time.Now()
+		session.CreatedAt = r.clock.Now()
 	}
 	if session.ExpiresAt.Before(session.CreatedAt) {
-		session.ExpiresAt = session.CreatedAt.Add(r.ttl)
+		session.ExpiresAt = session.CreatedAt.Add(r.ttls.Session)
 	}
 
 	payload, err := json.Marshal(session)
@@ -63,26 +139,138 @@ func (r *sessionRepository) Save(ctx context.Context, session *domain.Session) e
 		return err
 	}
 
-	ttl := time.Until(session.ExpiresAt)
+	ttl := session.ExpiresAt.Sub(r.clock.Now())
 	if ttl <= 0 {
-		ttl = r.ttl
+		ttl = r.ttls.Session
+	}
+
+	r.wrote(r.key(session.ID))
+	if err := withRetry(ctx, r.retry, func() error {
+		return r.client.Set(ctx, r.key(session.ID), payload, ttl).Err()
+	}); err != nil {
+		return err
+	}
+	if session.UserID == "" {
+		return nil
+	}
+	// The per-user index is scored by creation time. Members outlive their sessions and are pruned
+	// by ListByUser; the index itself expires UserSessions after the user's latest session is saved,
+	// or with that session if it lives longer.
+	index := r.userKey(session.UserID)
+	indexTTL := max(ttl, r.ttls.UserSessions)
+	r.wrote(index)
+	return withRetry(ctx, r.retry, func() error {
+		member := redislib.Z{Score: float64(session.CreatedAt.UnixNano()), Member: session.ID}
+		if err := r.client.ZAdd(ctx, index, member).Err(); err != nil {
+			return err
+		}
+		return r.client.Expire(ctx, index, indexTTL).Err()
+	})
+}
+
+func (r *sessionRepository) ListByUser(ctx context.Context, userID string, limit int) ([]domain.Session, error) {
+	if limit <= 0 {
+		return nil, nil
+	}
+	index := r.userKey(userID)
+	var ids []string
+	err := withRetry(ctx, r.retry, func() error {
+		var err error
+		ids, err = r.reader(index).ZRevRange(ctx, index, 0, int64(limit)-1).Result()
+		return err
+	})
+	if err != nil {
+		return nil, err
 	}
 
-	return r.client.Set(ctx, r.key(session.ID), payload, ttl).Err()
+	sessions := make([]domain.Session, 0, len(ids))
+	var gone []interface{}
+	for _, id := range ids {
+		session, err := r.Get(ctx, id)
+		if err == domain.ErrSessionNotFound {
+			gone = append(gone, id)
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		sessions = append(sessions, *session)
+	}
+	if len(gone) > 0 {
+		// Best effort: a failed prune only means the next listing skips the same IDs again.
+		_ = r.client.ZRem(ctx, index, gone...).Err()
+	}
+	return sessions, nil
 }
 
 func (r *sessionRepository) Delete(ctx context.Context, id string) error {
-	return r.client.Del(ctx, r.key(id)).Err()
+	r.wrote(r.key(id))
+	return withRetry(ctx, r.retry, func() error {
+		return r.client.Del(ctx, r.key(id)).Err()
+	})
 }
 
 func (r *sessionRepository) Extend(ctx context.Context, id string, ttlSeconds int) error {
 	duration := time.Duration(ttlSeconds) * time.Second
 	if duration <= 0 {
-		duration = r.ttl
+		duration = r.ttls.Session
+	}
+	r.wrote(r.key(id))
+	// Expire reports false when the key does not exist, e.g. the session already expired.
+	var extended bool
+	err := withRetry(ctx, r.retry, func() error {
+		var err error
+		extended, err = r.client.Expire(ctx, r.key(id), duration).Result()
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	if !extended {
+		return domain.ErrSessionNotFound
+	}
+	return nil
+}
+
+// reader returns the client to read key from: the replica, unless key was written within the window.
+func (r *sessionRepository) reader(key string) SessionReader {
+	if r.replica == nil {
+		return r.client
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if until, ok := r.written[key]; ok && r.clock.Now().Before(until) {
+		return r.client
+	}
+	return r.replica
+}
+
+// wrote opens key's read-your-writes window. It is called before the write is sent, so a read
+// racing the write also goes to the primary.
+func (r *sessionRepository) wrote(key string) {
+	if r.replica == nil || r.window <= 0 {
+		return
 	}
-	return r.client.Expire(ctx, r.key(id), duration).Err()
+	now := r.clock.Now()
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.written == nil {
+		r.written = make(map[string]time.Time)
+	}
+	if len(r.written) >= maxTrackedWrites {
+		for k, until := range r.written {
+			if !now.Before(until) {
+				delete(r.written, k)
+			}
+		}
+	}
+	r.written[key] = now.Add(r.window)
 }
 
 func (r *sessionRepository) key(id string) string {
-	return fmt.Sprintf("%s%s", r.prefix, id)
+	return r.keys.Key("session", id)
+}
+
+func (r *sessionRepository) userKey(userID string) string {
+	return r.keys.Key("user_sessions", userID)
 }
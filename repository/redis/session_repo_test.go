@@ -0,0 +1,168 @@
+package redis
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/fastygo/backend/domain"
+	"github.com/fastygo/backend/pkg/clock"
+)
+
+func TestSessionSaveDerivesTTLFromClock(t *testing.T) {
+	fake := clock.NewFake(time.Date(2030, 1, 1, 12, 0, 0, 0, time.UTC))
+	client := newFakeClient()
+	repo := NewSessionRepository(client, TTLPolicy{Session: time.Hour}, WithClock(fake))
+
+	session := &domain.Session{ID: "s1", UserID: "u1", ExpiresAt: fake.Now().Add(90 * time.Minute)}
+	fake.Advance(30 * time.Minute)
+	if err := repo.Save(context.Background(), session); err != nil {
+		t.Fatalf("save: %v", err)
+	}
+
+	if got := client.ttls["session:s1"]; got != time.Hour {
+		t.Fatalf("ttl = %v, want 1h remaining at the fake time", got)
+	}
+	if !session.CreatedAt.Equal(fake.Now()) {
+		t.Fatalf("created_at = %v, want fake now %v", session.CreatedAt, fake.Now())
+	}
+}
+
+func TestSessionKeyspacesUseTheirConfiguredTTL(t *testing.T) {
+	fake := clock.NewFake(time.Date(2030, 1, 1, 12, 0, 0, 0, time.UTC))
+	client := newFakeClient()
+	repo := NewSessionRepository(client, TTLPolicy{Session: 2 * time.Hour, UserSessions: 72 * time.Hour}, WithClock(fake))
+
+	if err := repo.Save(context.Background(), &domain.Session{ID: "s1", UserID: "u1"}); err != nil {
+		t.Fatalf("save: %v", err)
+	}
+	if got := client.ttls["session:s1"]; got != 2*time.Hour {
+		t.Fatalf("session ttl = %v, want 2h", got)
+	}
+	if got := client.ttls["user_sessions:u1"]; got != 72*time.Hour {
+		t.Fatalf("user sessions ttl = %v, want 72h", got)
+	}
+
+	// A session outliving the index TTL keeps its index alive as long as itself.
+	long := &domain.Session{ID: "s2", UserID: "u1", ExpiresAt: fake.Now().Add(100 * time.Hour)}
+	if err := repo.Save(context.Background(), long); err != nil {
+		t.Fatalf("save: %v", err)
+	}
+	if got := client.ttls["user_sessions:u1"]; got != 100*time.Hour {
+		t.Fatalf("user sessions ttl = %v, want the 100h of its longest session", got)
+	}
+
+	if err := repo.Extend(context.Background(), "s1", 0); err != nil {
+		t.Fatalf("extend: %v", err)
+	}
+	if got := client.ttls["session:s1"]; got != 2*time.Hour {
+		t.Fatalf("extended ttl = %v, want the 2h session TTL", got)
+	}
+}
+
+func TestSessionExtendMissingReturnsNotFound(t *testing.T) {
+	client := newFakeClient()
+	repo := NewSessionRepository(client, TTLPolicy{Session: time.Hour})
+
+	if err := repo.Extend(context.Background(), "expired", 600); err != domain.ErrSessionNotFound {
+		t.Fatalf("extend missing session error = %v, want ErrSessionNotFound", err)
+	}
+	if _, ok := client.ttls["session:expired"]; ok {
+		t.Fatal("extend must not create a ttl for a missing key")
+	}
+}
+
+func TestSessionKeysUseConfiguredNamespace(t *testing.T) {
+	client := newFakeClient()
+	repo := NewSessionRepository(client, TTLPolicy{Session: time.Hour}, WithNamespace("staging:"))
+
+	if err := repo.Save(context.Background(), &domain.Session{ID: "s1", UserID: "u1"}); err != nil {
+		t.Fatalf("save: %v", err)
+	}
+	if _, ok := client.values["staging:session:s1"]; !ok {
+		t.Fatalf("stored keys = %v, want staging:session:s1", client.values)
+	}
+	if _, err := repo.Get(context.Background(), "s1"); err != nil {
+		t.Fatalf("get through namespace: %v", err)
+	}
+}
+
+func TestKeyspace(t *testing.T) {
+	tests := []struct {
+		namespace string
+		want      string
+	}{
+		{namespace: "", want: "session:abc"},
+		{namespace: "prod", want: "prod:session:abc"},
+		{namespace: ":prod:", want: "prod:session:abc"},
+	}
+	for _, tt := range tests {
+		if got := NewKeyspace(tt.namespace).Key("session", "abc"); got != tt.want {
+			t.Errorf("NewKeyspace(%q).Key = %q, want %q", tt.namespace, got, tt.want)
+		}
+	}
+}
+
+func TestSessionListByUserNewestFirstAndPrunesDeleted(t *testing.T) {
+	fake := clock.NewFake(time.Date(2030, 1, 1, 12, 0, 0, 0, time.UTC))
+	client := newFakeClient()
+	repo := NewSessionRepository(client, TTLPolicy{Session: time.Hour, UserSessions: time.Hour}, WithClock(fake))
+	ctx := context.Background()
+
+	for _, id := range []string{"s1", "s2", "s3"} {
+		if err := repo.Save(ctx, &domain.Session{ID: id, UserID: "u1"}); err != nil {
+			t.Fatalf("save %s: %v", id, err)
+		}
+		fake.Advance(time.Minute)
+	}
+	if err := repo.Save(ctx, &domain.Session{ID: "other", UserID: "u2"}); err != nil {
+		t.Fatalf("save: %v", err)
+	}
+	if err := repo.Delete(ctx, "s2"); err != nil {
+		t.Fatalf("delete: %v", err)
+	}
+
+	sessions, err := repo.ListByUser(ctx, "u1", 10)
+	if err != nil {
+		t.Fatalf("list: %v", err)
+	}
+	if len(sessions) != 2 || sessions[0].ID != "s3" || sessions[1].ID != "s1" {
+		t.Fatalf("sessions = %+v, want s3 then s1", sessions)
+	}
+	if _, indexed := client.zsets["user_sessions:u1"]["s2"]; indexed {
+		t.Fatal("the deleted session was not pruned from the user index")
+	}
+	if got := client.ttls["user_sessions:u1"]; got != time.Hour {
+		t.Fatalf("index ttl = %v, want the newest session's 1h", got)
+	}
+}
+
+func TestSessionReadsHitPrimaryWithinReadYourWritesWindow(t *testing.T) {
+	fake := clock.NewFake(time.Date(2030, 1, 1, 12, 0, 0, 0, time.UTC))
+	primary, replica := newFakeClient(), newFakeClient()
+	repo := NewSessionRepository(primary, TTLPolicy{Session: time.Hour}, WithClock(fake), WithReadReplica(replica, 2*time.Second))
+
+	if err := repo.Save(context.Background(), &domain.Session{ID: "s1", UserID: "u1"}); err != nil {
+		t.Fatalf("save: %v", err)
+	}
+	// The replica has not caught up; a read there would miss the session.
+	primary.calls = 0
+	if _, err := repo.Get(context.Background(), "s1"); err != nil {
+		t.Fatalf("get right after save: %v", err)
+	}
+	if sessions, err := repo.ListByUser(context.Background(), "u1", 10); err != nil || len(sessions) != 1 {
+		t.Fatalf("list right after save = %v, %v; want the new session", sessions, err)
+	}
+	if primary.calls != 3 || replica.calls != 0 {
+		t.Fatalf("within the window: primary calls = %d, replica calls = %d; want every read on the primary", primary.calls, replica.calls)
+	}
+
+	fake.Advance(2 * time.Second)
+	primary.calls = 0
+	if _, err := repo.Get(context.Background(), "s1"); err == nil {
+		t.Fatal("get after the window was served by the primary, want the (stale) replica")
+	}
+	if primary.calls != 0 || replica.calls != 1 {
+		t.Fatalf("after the window: primary calls = %d, replica calls = %d; want the replica", primary.calls, replica.calls)
+	}
+}
@@ -0,0 +1,35 @@
+package redis
+
+import "time"
+
+// TTLPolicy holds the expiry of each keyspace the Redis repositories write, so operators can tune
+// them independently; config validates that each is positive. New keyspaces get their TTL here
+// rather than hardcoding one where they are used.
+type TTLPolicy struct {
+	// Session is the lifetime of a session saved without its own expiry, and the extension granted
+	// when Extend is not given one.
+	Session time.Duration
+	// UserSessions is how long a user's session index lives after their latest login. It is never
+	// shorter than the session just saved, so the index cannot expire before a session it lists.
+	UserSessions time.Duration
+	// Nonce is how long a claimed request nonce is remembered. A claim never remembers a nonce for
+	// less than the window its caller accepts it in, so a replay cannot outlive the key.
+	Nonce time.Duration
+}
+
+// DefaultTTLPolicy is used for any TTL left at zero; it matches the config defaults.
+var DefaultTTLPolicy = TTLPolicy{Session: 24 * time.Hour, UserSessions: 24 * time.Hour, Nonce: 5 * time.Minute}
+
+// withDefaults fills TTLs left at zero or below from DefaultTTLPolicy.
+func (p TTLPolicy) withDefaults() TTLPolicy {
+	if p.Session <= 0 {
+		p.Session = DefaultTTLPolicy.Session
+	}
+	if p.UserSessions <= 0 {
+		p.UserSessions = DefaultTTLPolicy.UserSessions
+	}
+	if p.Nonce <= 0 {
+		p.Nonce = DefaultTTLPolicy.Nonce
+	}
+	return p
+}
@@ -0,0 +1,718 @@
+// Package repositorytest provides in-memory repository implementations for tests.
+package repositorytest
+
+import (
+	"context"
+	"encoding/json"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/fastygo/backend/domain"
+	"github.com/fastygo/backend/repository"
+)
+
+// Tasks is an in-memory TaskRepository. Setting Err makes every call fail with it,
+// which lets tests exercise the buffering fallback.
+type Tasks struct {
+	mu       sync.Mutex
+	tasks    map[string]domain.Task
+	reminded map[string]time.Time
+	Err      error
+}
+
+var (
+	_ repository.TaskRepository = (*Tasks)(nil)
+	_ repository.TaskReminders  = (*Tasks)(nil)
+)
+
+func NewTasks(tasks ...domain.Task) *Tasks {
+	r := &Tasks{tasks: make(map[string]domain.Task, len(tasks)), reminded: make(map[string]time.Time)}
+	for _, task := range tasks {
+		r.tasks[task.ID] = task
+	}
+	return r
+}
+
+func (r *Tasks) GetByID(ctx context.Context, id string) (*domain.Task, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.Err != nil {
+		return nil, r.Err
+	}
+	task, ok := r.tasks[id]
+	if !ok {
+		return nil, domain.ErrTaskNotFound
+	}
+	return &task, nil
+}
+
+// List filters like the Postgres query and orders by creation time, newest first.
+func (r *Tasks) List(ctx context.Context, filter repository.TaskFilter) ([]domain.Task, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.Err != nil {
+		return nil, r.Err
+	}
+	var tasks []domain.Task
+	for _, task := range r.tasks {
+		if filter.UserID != "" && task.UserID != filter.UserID {
+			continue
+		}
+		if filter.TenantID != "" && task.TenantID != filter.TenantID {
+			continue
+		}
+		if filter.Status != "" && !strings.EqualFold(task.Status, filter.Status) {
+			continue
+		}
+		if filter.Priority != 0 && task.Priority != filter.Priority {
+			continue
+		}
+		if filter.OnlyOverdue && !task.IsOverdue(time.Now()) {
+			continue
+		}
+		tasks = append(tasks, task)
+	}
+	sort.Slice(tasks, func(i, j int) bool {
+		if tasks[i].CreatedAt.Equal(tasks[j].CreatedAt) {
+			return tasks[i].ID < tasks[j].ID
+		}
+		return tasks[i].CreatedAt.After(tasks[j].CreatedAt)
+	})
+	if filter.Offset >= len(tasks) {
+		return nil, nil
+	}
+	tasks = tasks[filter.Offset:]
+	if filter.Limit > 0 && filter.Limit < len(tasks) {
+		tasks = tasks[:filter.Limit]
+	}
+	return tasks, nil
+}
+
+func (r *Tasks) Create(ctx context.Context, task *domain.Task) (*domain.Task, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.Err != nil {
+		return nil, r.Err
+	}
+	if task == nil {
+		return nil, domain.ErrInvalidPayload
+	}
+	if task.ID == "" {
+		task.ID = uuid.NewString()
+	}
+	if _, exists := r.tasks[task.ID]; exists {
+		return nil, domain.ErrConflict
+	}
+	now := time.Now().UTC()
+	task.CreatedAt, task.UpdatedAt = now, now
+	r.tasks[task.ID] = *task
+	return task, nil
+}
+
+func (r *Tasks) Update(ctx context.Context, task *domain.Task) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.Err != nil {
+		return r.Err
+	}
+	existing, ok := r.tasks[task.ID]
+	if !ok || existing.UserID != task.UserID || existing.TenantID != task.TenantID {
+		return domain.ErrTaskNotFound
+	}
+	task.CreatedAt = existing.CreatedAt
+	task.UpdatedAt = time.Now().UTC()
+	if !sameTime(existing.DueDate, task.DueDate) {
+		delete(r.reminded, task.ID)
+	}
+	r.tasks[task.ID] = *task
+	return nil
+}
+
+func sameTime(a, b *time.Time) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return a.Equal(*b)
+}
+
+// DueForReminder filters like the Postgres query.
+func (r *Tasks) DueForReminder(ctx context.Context, from, until time.Time, limit int) ([]domain.Task, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.Err != nil {
+		return nil, r.Err
+	}
+	var tasks []domain.Task
+	for _, task := range r.tasks {
+		if _, done := r.reminded[task.ID]; done || task.IsCompleted() || task.DueDate == nil {
+			continue
+		}
+		if task.DueDate.Before(from) || !task.DueDate.Before(until) {
+			continue
+		}
+		tasks = append(tasks, task)
+	}
+	sort.Slice(tasks, func(i, j int) bool { return tasks[i].DueDate.Before(*tasks[j].DueDate) })
+	if limit > 0 && limit < len(tasks) {
+		tasks = tasks[:limit]
+	}
+	return tasks, nil
+}
+
+func (r *Tasks) ClaimReminder(ctx context.Context, id string, at time.Time) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.Err != nil {
+		return false, r.Err
+	}
+	if _, done := r.reminded[id]; done {
+		return false, nil
+	}
+	if _, ok := r.tasks[id]; !ok {
+		return false, nil
+	}
+	r.reminded[id] = at
+	return true, nil
+}
+
+func (r *Tasks) ReleaseReminder(ctx context.Context, id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.Err != nil {
+		return r.Err
+	}
+	delete(r.reminded, id)
+	return nil
+}
+
+// RemindedAt reports when the task was claimed for a reminder.
+func (r *Tasks) RemindedAt(id string) (time.Time, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	at, ok := r.reminded[id]
+	return at, ok
+}
+
+func (r *Tasks) Delete(ctx context.Context, id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.Err != nil {
+		return r.Err
+	}
+	if _, ok := r.tasks[id]; !ok {
+		return domain.ErrTaskNotFound
+	}
+	delete(r.tasks, id)
+	return nil
+}
+
+func (r *Tasks) DeleteOwned(ctx context.Context, id, userID, tenantID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.Err != nil {
+		return r.Err
+	}
+	existing, ok := r.tasks[id]
+	if !ok || existing.UserID != userID || existing.TenantID != tenantID {
+		return domain.ErrTaskNotFound
+	}
+	delete(r.tasks, id)
+	return nil
+}
+
+// Users is an in-memory UserRepository.
+type Users struct {
+	mu    sync.Mutex
+	users map[string]domain.User
+	Err   error
+}
+
+var _ repository.UserRepository = (*Users)(nil)
+
+func NewUsers(users ...domain.User) *Users {
+	r := &Users{users: make(map[string]domain.User, len(users))}
+	for _, user := range users {
+		r.users[user.ID] = user
+	}
+	return r
+}
+
+func (r *Users) GetByID(ctx context.Context, id string) (*domain.User, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.Err != nil {
+		return nil, r.Err
+	}
+	user, ok := r.users[id]
+	if !ok {
+		return nil, domain.ErrUserNotFound
+	}
+	return &user, nil
+}
+
+func (r *Users) Upsert(ctx context.Context, user *domain.User) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.Err != nil {
+		return r.Err
+	}
+	r.users[user.ID] = *user
+	return nil
+}
+
+// Sessions is an in-memory SessionRepository. Expiry is not enforced; Extend only
+// reports whether the session exists.
+type Sessions struct {
+	mu       sync.Mutex
+	sessions map[string]domain.Session
+	Err      error
+}
+
+var _ repository.SessionRepository = (*Sessions)(nil)
+
+func NewSessions() *Sessions {
+	return &Sessions{sessions: make(map[string]domain.Session)}
+}
+
+func (r *Sessions) Get(ctx context.Context, id string) (*domain.Session, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.Err != nil {
+		return nil, r.Err
+	}
+	session, ok := r.sessions[id]
+	if !ok {
+		return nil, domain.ErrSessionNotFound
+	}
+	return &session, nil
+}
+
+func (r *Sessions) Save(ctx context.Context, session *domain.Session) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.Err != nil {
+		return r.Err
+	}
+	r.sessions[session.ID] = *session
+	return nil
+}
+
+func (r *Sessions) Delete(ctx context.Context, id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.Err != nil {
+		return r.Err
+	}
+	delete(r.sessions, id)
+	return nil
+}
+
+func (r *Sessions) ListByUser(ctx context.Context, userID string, limit int) ([]domain.Session, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.Err != nil {
+		return nil, r.Err
+	}
+	var sessions []domain.Session
+	for _, session := range r.sessions {
+		if session.UserID == userID {
+			sessions = append(sessions, session)
+		}
+	}
+	sort.Slice(sessions, func(i, j int) bool { return sessions[i].CreatedAt.After(sessions[j].CreatedAt) })
+	if len(sessions) > limit {
+		sessions = sessions[:limit]
+	}
+	return sessions, nil
+}
+
+func (r *Sessions) Extend(ctx context.Context, id string, ttlSeconds int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.Err != nil {
+		return r.Err
+	}
+	session, ok := r.sessions[id]
+	if !ok {
+		return domain.ErrSessionNotFound
+	}
+	session.ExpiresAt = time.Now().Add(time.Duration(ttlSeconds) * time.Second)
+	r.sessions[session.ID] = session
+	return nil
+}
+
+// Aggregates is an in-memory AggregateRepository. SaveBatch applies the batch to a copy and swaps it
+// in on success, so a rolled-back batch leaves no partial writes.
+type Aggregates struct {
+	mu         sync.Mutex
+	aggregates map[string]domain.Aggregate
+	events     []domain.Event
+	Err        error
+}
+
+var _ repository.AggregateRepository = (*Aggregates)(nil)
+
+func NewAggregates(aggregates ...domain.Aggregate) *Aggregates {
+	r := &Aggregates{aggregates: make(map[string]domain.Aggregate, len(aggregates))}
+	for _, aggregate := range aggregates {
+		r.aggregates[aggregate.ID] = aggregate
+	}
+	return r
+}
+
+func (r *Aggregates) Get(ctx context.Context, id string) (*domain.Aggregate, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.Err != nil {
+		return nil, r.Err
+	}
+	aggregate, ok := r.aggregates[id]
+	if !ok {
+		return nil, domain.ErrAggregateNotFound
+	}
+	return &aggregate, nil
+}
+
+// List filters like the Postgres query and orders by update time, newest first.
+func (r *Aggregates) List(ctx context.Context, filter repository.AggregateFilter) ([]domain.Aggregate, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.Err != nil {
+		return nil, r.Err
+	}
+	var aggregates []domain.Aggregate
+	for _, aggregate := range r.aggregates {
+		if filter.Kind != "" && aggregate.Kind != filter.Kind {
+			continue
+		}
+		if filter.TenantID != "" && aggregate.TenantID != filter.TenantID {
+			continue
+		}
+		if filter.OwnerID != "" && aggregate.OwnerID != filter.OwnerID {
+			continue
+		}
+		aggregates = append(aggregates, aggregate)
+	}
+	sort.Slice(aggregates, func(i, j int) bool {
+		if aggregates[i].UpdatedAt.Equal(aggregates[j].UpdatedAt) {
+			return aggregates[i].ID < aggregates[j].ID
+		}
+		return aggregates[i].UpdatedAt.After(aggregates[j].UpdatedAt)
+	})
+	if filter.Offset >= len(aggregates) {
+		return nil, nil
+	}
+	aggregates = aggregates[filter.Offset:]
+	if filter.Limit > 0 && filter.Limit < len(aggregates) {
+		aggregates = aggregates[:filter.Limit]
+	}
+	return aggregates, nil
+}
+
+func (r *Aggregates) Save(ctx context.Context, aggregate *domain.Aggregate) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.Err != nil {
+		return r.Err
+	}
+	if aggregate == nil || aggregate.ID == "" {
+		return domain.ErrInvalidPayload
+	}
+	putAggregate(r.aggregates, aggregate)
+	return nil
+}
+
+func (r *Aggregates) SaveBatch(ctx context.Context, aggregates []*domain.Aggregate, opts repository.SaveBatchOptions) ([]repository.SaveResult, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.Err != nil {
+		return nil, r.Err
+	}
+
+	staged := make(map[string]domain.Aggregate, len(r.aggregates))
+	for id, aggregate := range r.aggregates {
+		staged[id] = aggregate
+	}
+	results := make([]repository.SaveResult, len(aggregates))
+	for i, aggregate := range aggregates {
+		if aggregate != nil {
+			results[i].ID = aggregate.ID
+		}
+		err := aggregate.Validate(opts.Limits)
+		if existing, ok := staged[results[i].ID]; err == nil && ok {
+			switch {
+			case existing.TenantID != aggregate.TenantID:
+				err = domain.ErrAggregateForeignTenant
+			case opts.CheckVersion && aggregate.Version != existing.Version+1:
+				err = domain.ErrAggregateVersionConflict
+			}
+		}
+		if err != nil {
+			if !opts.BestEffort {
+				return nil, &repository.BatchItemError{Index: i, ID: results[i].ID, Err: err}
+			}
+			results[i].Err = err
+			continue
+		}
+		putAggregate(staged, aggregate)
+	}
+	r.aggregates = staged
+	return results, nil
+}
+
+// BulkImport is all-or-nothing like COPY: any stored ID fails the import with domain.ErrConflict.
+func (r *Aggregates) BulkImport(ctx context.Context, aggregates []*domain.Aggregate) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.Err != nil {
+		return r.Err
+	}
+	for _, aggregate := range aggregates {
+		if _, exists := r.aggregates[aggregate.ID]; exists {
+			return domain.ErrConflict
+		}
+	}
+	now := time.Now().UTC()
+	for _, aggregate := range aggregates {
+		if aggregate.CreatedAt.IsZero() {
+			aggregate.CreatedAt = now
+		}
+		if aggregate.UpdatedAt.IsZero() {
+			aggregate.UpdatedAt = now
+		}
+		r.aggregates[aggregate.ID] = *aggregate
+	}
+	return nil
+}
+
+func (r *Aggregates) AppendEvent(ctx context.Context, event domain.Event) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.Err != nil {
+		return r.Err
+	}
+	r.events = append(r.events, event)
+	return nil
+}
+
+func (r *Aggregates) ListEvents(ctx context.Context, aggregateID string) ([]domain.Event, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.Err != nil {
+		return nil, r.Err
+	}
+	var events []domain.Event
+	for _, event := range r.events {
+		if event.AggregateID == aggregateID {
+			events = append(events, event)
+		}
+	}
+	sort.SliceStable(events, func(i, j int) bool { return events[i].Version < events[j].Version })
+	return events, nil
+}
+
+func putAggregate(aggregates map[string]domain.Aggregate, aggregate *domain.Aggregate) {
+	now := time.Now().UTC()
+	if existing, ok := aggregates[aggregate.ID]; ok {
+		aggregate.CreatedAt = existing.CreatedAt
+	} else if aggregate.CreatedAt.IsZero() {
+		aggregate.CreatedAt = now
+	}
+	aggregate.UpdatedAt = now
+	aggregates[aggregate.ID] = *aggregate
+}
+
+// KeyValues is an in-memory KeyValueRepository that stores entries as aggregates in Aggregates,
+// exactly as the Postgres implementation lays them out.
+type KeyValues struct {
+	Aggregates *Aggregates
+}
+
+var _ repository.KeyValueRepository = (*KeyValues)(nil)
+
+// NewKeyValues stores entries in aggregates, or in a fresh Aggregates when nil.
+func NewKeyValues(aggregates *Aggregates) *KeyValues {
+	if aggregates == nil {
+		aggregates = NewAggregates()
+	}
+	return &KeyValues{Aggregates: aggregates}
+}
+
+func (r *KeyValues) Put(ctx context.Context, namespace, key string, value json.RawMessage) error {
+	aggregate, err := repository.KeyValueAggregate(namespace, key, value)
+	if err != nil {
+		return err
+	}
+	return r.Aggregates.Save(ctx, aggregate)
+}
+
+func (r *KeyValues) Get(ctx context.Context, namespace, key string) (json.RawMessage, error) {
+	aggregate, err := r.Aggregates.Get(ctx, repository.KeyValueID(namespace, key))
+	if err == domain.ErrAggregateNotFound || (err == nil && aggregate.Kind != repository.KindKeyValue) {
+		return nil, domain.ErrKeyNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return aggregate.Payload, nil
+}
+
+func (r *KeyValues) Delete(ctx context.Context, namespace, key string) error {
+	r.Aggregates.mu.Lock()
+	defer r.Aggregates.mu.Unlock()
+	if r.Aggregates.Err != nil {
+		return r.Aggregates.Err
+	}
+	id := repository.KeyValueID(namespace, key)
+	if r.Aggregates.aggregates[id].Kind == repository.KindKeyValue {
+		delete(r.Aggregates.aggregates, id)
+	}
+	return nil
+}
+
+func (r *KeyValues) List(ctx context.Context, namespace string) ([]repository.KeyValue, error) {
+	r.Aggregates.mu.Lock()
+	defer r.Aggregates.mu.Unlock()
+	if r.Aggregates.Err != nil {
+		return nil, r.Aggregates.Err
+	}
+	var entries []repository.KeyValue
+	for _, aggregate := range r.Aggregates.aggregates {
+		if aggregate.Kind == repository.KindKeyValue && aggregate.Labels[repository.LabelNamespace] == namespace {
+			entries = append(entries, repository.KeyValueFromAggregate(aggregate))
+		}
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Key < entries[j].Key })
+	return entries, nil
+}
+
+// Outbox is an in-memory OutboxRepository that keeps published and dead-lettered messages so tests
+// can inspect them.
+type Outbox struct {
+	mu       sync.Mutex
+	messages map[string]*outboxEntry
+	Err      error
+}
+
+type outboxEntry struct {
+	msg          domain.OutboxMessage
+	published    bool
+	deadLettered bool
+}
+
+var _ repository.OutboxRepository = (*Outbox)(nil)
+
+func NewOutbox(messages ...domain.OutboxMessage) *Outbox {
+	r := &Outbox{messages: make(map[string]*outboxEntry)}
+	for _, msg := range messages {
+		r.messages[msg.ID] = &outboxEntry{msg: msg}
+	}
+	return r
+}
+
+func (r *Outbox) Add(ctx context.Context, msg *domain.OutboxMessage) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.Err != nil {
+		return r.Err
+	}
+	if msg.ID == "" {
+		msg.ID = uuid.NewString()
+	}
+	if msg.CreatedAt.IsZero() {
+		msg.CreatedAt = time.Now().UTC()
+	}
+	r.messages[msg.ID] = &outboxEntry{msg: *msg}
+	return nil
+}
+
+func (r *Outbox) Pending(ctx context.Context, now time.Time, limit int) ([]domain.OutboxMessage, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.Err != nil {
+		return nil, r.Err
+	}
+	var pending []domain.OutboxMessage
+	for _, entry := range r.messages {
+		if !entry.published && !entry.deadLettered && !entry.msg.NextAttemptAt.After(now) {
+			pending = append(pending, entry.msg)
+		}
+	}
+	sort.Slice(pending, func(i, j int) bool {
+		if pending[i].CreatedAt.Equal(pending[j].CreatedAt) {
+			return pending[i].ID < pending[j].ID
+		}
+		return pending[i].CreatedAt.Before(pending[j].CreatedAt)
+	})
+	if limit > 0 && len(pending) > limit {
+		pending = pending[:limit]
+	}
+	return pending, nil
+}
+
+func (r *Outbox) MarkPublished(ctx context.Context, id string, at time.Time) error {
+	return r.update(id, func(entry *outboxEntry) {
+		entry.published = true
+	})
+}
+
+func (r *Outbox) Reschedule(ctx context.Context, id string, attempts int, next time.Time, lastError string) error {
+	return r.update(id, func(entry *outboxEntry) {
+		entry.msg.Attempts = attempts
+		entry.msg.NextAttemptAt = next
+		entry.msg.LastError = lastError
+	})
+}
+
+func (r *Outbox) DeadLetter(ctx context.Context, id string, attempts int, lastError string, at time.Time) error {
+	return r.update(id, func(entry *outboxEntry) {
+		entry.msg.Attempts = attempts
+		entry.msg.LastError = lastError
+		entry.deadLettered = true
+	})
+}
+
+func (r *Outbox) update(id string, apply func(*outboxEntry)) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.Err != nil {
+		return r.Err
+	}
+	entry, ok := r.messages[id]
+	if !ok {
+		return domain.ErrOutboxNotFound
+	}
+	apply(entry)
+	return nil
+}
+
+// Get returns the stored message, published or not.
+func (r *Outbox) Get(id string) (domain.OutboxMessage, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	entry, ok := r.messages[id]
+	if !ok {
+		return domain.OutboxMessage{}, false
+	}
+	return entry.msg, true
+}
+
+// Published reports whether the message was marked published.
+func (r *Outbox) Published(id string) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	entry, ok := r.messages[id]
+	return ok && entry.published
+}
+
+// DeadLettered reports whether the message was dead-lettered.
+func (r *Outbox) DeadLettered(id string) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	entry, ok := r.messages[id]
+	return ok && entry.deadLettered
+}
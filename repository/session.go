@@ -11,4 +11,6 @@ type SessionRepository interface {
 	Save(ctx context.Context, session *domain.Session) error
 	Delete(ctx context.Context, id string) error
 	Extend(ctx context.Context, id string, ttlSeconds int) error
+	// ListByUser returns up to limit of the user's live sessions, most recently created first.
+	ListByUser(ctx context.Context, userID string, limit int) ([]domain.Session, error)
 }
@@ -2,21 +2,43 @@ package repository
 
 import (
 	"context"
+	"time"
 
 	"github.com/fastygo/backend/domain"
 )
 
 type TaskFilter struct {
 	UserID string
-	Status string
-	Limit  int
-	Offset int
+	// TenantID restricts results to one tenant; empty means unscoped.
+	TenantID string
+	Status   string
+	Priority int
+	// OnlyOverdue keeps tasks that are not completed and whose due date has passed.
+	OnlyOverdue bool
+	Limit       int
+	Offset      int
 }
 
 type TaskRepository interface {
-	GetByID(ctx context.Context, id string) (*domain.Task, error)
-	List(ctx context.Context, filter TaskFilter) ([]domain.Task, error)
+	Repository[domain.Task, TaskFilter]
 	Create(ctx context.Context, task *domain.Task) (*domain.Task, error)
+	// Update overwrites the task with task.ID only if it belongs to task.UserID and task.TenantID;
+	// otherwise it returns domain.ErrTaskNotFound.
 	Update(ctx context.Context, task *domain.Task) error
-	Delete(ctx context.Context, id string) error
+	// DeleteOwned deletes the task only if it belongs to userID and tenantID; otherwise it returns
+	// domain.ErrTaskNotFound, so another owner's task is not revealed.
+	DeleteOwned(ctx context.Context, id, userID, tenantID string) error
+}
+
+// TaskReminders finds tasks whose due date is approaching and records which were reminded, so
+// each task is reminded at most once per due date.
+type TaskReminders interface {
+	// DueForReminder lists up to limit tasks that are not completed, have not been reminded and
+	// fall due in [from, until), soonest first.
+	DueForReminder(ctx context.Context, from, until time.Time, limit int) ([]domain.Task, error)
+	// ClaimReminder sets the task's reminded_at to at and reports false when another run already
+	// claimed it.
+	ClaimReminder(ctx context.Context, id string, at time.Time) (bool, error)
+	// ReleaseReminder clears reminded_at so a reminder that failed to send is tried again.
+	ReleaseReminder(ctx context.Context, id string) error
 }
@@ -0,0 +1,198 @@
+package aggregate
+
+import (
+	"context"
+	"fmt"
+
+	"go.uber.org/zap"
+
+	"github.com/fastygo/backend/domain"
+	"github.com/fastygo/backend/repository"
+)
+
+// DefaultMaxBatchSize bounds SaveBatch when WithMaxBatchSize is not used.
+const DefaultMaxBatchSize = 500
+
+// MaxBatchSizeLimit is the largest cap WithMaxBatchSize accepts. The repository writes each item
+// with its own statements inside one transaction, so Postgres' 65535 bind-parameter limit never
+// applies; what grows with the batch is the transaction's duration and the row locks it holds.
+const MaxBatchSizeLimit = 10_000
+
+// DefaultMaxImportSize bounds Import when WithMaxImportSize is not used.
+const DefaultMaxImportSize = 100_000
+
+// ErrBatchTooLarge is returned for batches above the configured maximum.
+var ErrBatchTooLarge = domain.NewError(domain.ErrCodeInvalid, "too many aggregates in batch")
+
+// ErrDuplicateImportID is returned when an import lists the same aggregate ID twice.
+var ErrDuplicateImportID = domain.NewError(domain.ErrCodeInvalid, "aggregate id appears more than once in the import")
+
+type UseCase struct {
+	aggregates        repository.AggregateRepository
+	logger            *zap.Logger
+	optimisticLocking bool
+	maxBatchSize      int
+	maxImportSize     int
+	limits            domain.MetadataLimits
+}
+
+// Option customizes the aggregate use case.
+type Option func(*UseCase)
+
+// WithOptimisticLocking requires every update to carry the stored version plus one.
+func WithOptimisticLocking(enabled bool) Option {
+	return func(uc *UseCase) {
+		uc.optimisticLocking = enabled
+	}
+}
+
+// WithMaxBatchSize caps how many aggregates a single SaveBatch call may carry, up to MaxBatchSizeLimit.
+func WithMaxBatchSize(n int) Option {
+	return func(uc *UseCase) {
+		if n > 0 {
+			uc.maxBatchSize = min(n, MaxBatchSizeLimit)
+		}
+	}
+}
+
+// WithMaxImportSize caps how many aggregates a single Import call may carry.
+func WithMaxImportSize(n int) Option {
+	return func(uc *UseCase) {
+		if n > 0 {
+			uc.maxImportSize = n
+		}
+	}
+}
+
+// WithMetadataLimits bounds aggregate labels; domain.DefaultMetadataLimits apply otherwise.
+func WithMetadataLimits(limits domain.MetadataLimits) Option {
+	return func(uc *UseCase) {
+		uc.limits = limits
+	}
+}
+
+func New(aggregates repository.AggregateRepository, logger *zap.Logger, opts ...Option) *UseCase {
+	if logger == nil {
+		logger = zap.NewNop()
+	}
+	uc := &UseCase{
+		aggregates:    aggregates,
+		logger:        logger,
+		maxBatchSize:  DefaultMaxBatchSize,
+		maxImportSize: DefaultMaxImportSize,
+		limits:        domain.DefaultMetadataLimits,
+	}
+	for _, opt := range opts {
+		opt(uc)
+	}
+	return uc
+}
+
+// SaveBatch upserts aggregates in one transaction. Unless bestEffort is set, any failing item rolls
+// the whole batch back and is returned as a *repository.BatchItemError.
+func (uc *UseCase) SaveBatch(ctx context.Context, aggregates []*domain.Aggregate, bestEffort bool) ([]repository.SaveResult, error) {
+	if len(aggregates) == 0 {
+		return nil, domain.ErrInvalidPayload
+	}
+	if len(aggregates) > uc.maxBatchSize {
+		return nil, batchTooLarge(len(aggregates), uc.maxBatchSize)
+	}
+
+	results, err := uc.aggregates.SaveBatch(ctx, aggregates, repository.SaveBatchOptions{
+		BestEffort:   bestEffort,
+		CheckVersion: uc.optimisticLocking,
+		Limits:       uc.limits,
+	})
+	if err != nil {
+		uc.logger.Warn("aggregate batch rejected", zap.Int("size", len(aggregates)), zap.Error(err))
+		return nil, err
+	}
+
+	failed := 0
+	for _, result := range results {
+		if result.Err != nil {
+			failed++
+		}
+	}
+	if failed > 0 {
+		uc.logger.Warn("aggregate batch partially saved", zap.Int("size", len(aggregates)), zap.Int("failed", failed))
+	}
+	return results, nil
+}
+
+// Import bulk-inserts new aggregates for data migrations, much faster than SaveBatch for large
+// volumes. It is insert-only and all-or-nothing: every item is validated and IDs must be unique
+// within the import, reported as a *repository.BatchItemError, and an ID that is already stored
+// fails the import with domain.ErrConflict. Versions are stored as given and not checked.
+func (uc *UseCase) Import(ctx context.Context, aggregates []*domain.Aggregate) error {
+	if len(aggregates) == 0 {
+		return domain.ErrInvalidPayload
+	}
+	if len(aggregates) > uc.maxImportSize {
+		return batchTooLarge(len(aggregates), uc.maxImportSize)
+	}
+	seen := make(map[string]struct{}, len(aggregates))
+	for i, aggregate := range aggregates {
+		if err := aggregate.Validate(uc.limits); err != nil {
+			return &repository.BatchItemError{Index: i, ID: aggregate.ID, Err: err}
+		}
+		if _, dup := seen[aggregate.ID]; dup {
+			return &repository.BatchItemError{Index: i, ID: aggregate.ID, Err: ErrDuplicateImportID}
+		}
+		seen[aggregate.ID] = struct{}{}
+	}
+
+	if err := uc.aggregates.BulkImport(ctx, aggregates); err != nil {
+		uc.logger.Warn("aggregate import rejected", zap.Int("size", len(aggregates)), zap.Error(err))
+		return err
+	}
+	uc.logger.Info("aggregates imported", zap.Int("size", len(aggregates)))
+	return nil
+}
+
+func batchTooLarge(size, max int) error {
+	return domain.WrapError(domain.ErrCodeInvalid,
+		fmt.Sprintf("batch of %d aggregates exceeds the maximum of %d; split it into smaller batches", size, max),
+		ErrBatchTooLarge)
+}
+
+// Diff compares the aggregate's payload at two versions, rebuilt from its events. Aggregates of
+// another tenant are reported as missing, as are versions above the latest event.
+func (uc *UseCase) Diff(ctx context.Context, id, tenantID string, from, to int) (*domain.AggregateDiff, error) {
+	if from < 0 || to < 0 {
+		return nil, domain.ErrInvalidPayload
+	}
+	aggregate, err := uc.aggregates.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if aggregate.TenantID != tenantID {
+		return nil, domain.ErrAggregateNotFound
+	}
+
+	events, err := uc.aggregates.ListEvents(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	latest := 0
+	for _, event := range events {
+		latest = max(latest, event.Version)
+	}
+	if from > latest || to > latest {
+		return nil, domain.ErrAggregateVersionNotFound
+	}
+
+	before, err := domain.Rehydrate(events, from)
+	if err != nil {
+		return nil, err
+	}
+	after, err := domain.Rehydrate(events, to)
+	if err != nil {
+		return nil, err
+	}
+	changes, err := domain.DiffPayloads(before, after)
+	if err != nil {
+		return nil, err
+	}
+	return &domain.AggregateDiff{AggregateID: id, From: from, To: to, Changes: changes}, nil
+}
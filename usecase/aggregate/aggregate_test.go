@@ -0,0 +1,137 @@
+package aggregate_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/fastygo/backend/domain"
+	"github.com/fastygo/backend/repository"
+	"github.com/fastygo/backend/repository/repositorytest"
+	aggregateUC "github.com/fastygo/backend/usecase/aggregate"
+)
+
+func seededAggregates() *repositorytest.Aggregates {
+	return repositorytest.NewAggregates(
+		domain.Aggregate{ID: "deal-1", Kind: "deal", TenantID: "acme", Version: 3},
+		domain.Aggregate{ID: "deal-foreign", Kind: "deal", TenantID: "globex", Version: 1},
+	)
+}
+
+func batch() []*domain.Aggregate {
+	return []*domain.Aggregate{
+		{ID: "deal-new", Kind: "deal", TenantID: "acme", Version: 1},
+		{ID: "deal-1", Kind: "deal", TenantID: "acme", Version: 3}, // stale: stored version is 3
+		{ID: "deal-2", Kind: "deal", TenantID: "acme", Version: 1},
+	}
+}
+
+func TestSaveBatchAtomicRollsBackOnVersionConflict(t *testing.T) {
+	repo := seededAggregates()
+	uc := aggregateUC.New(repo, nil, aggregateUC.WithOptimisticLocking(true))
+
+	_, err := uc.SaveBatch(context.Background(), batch(), false)
+
+	var itemErr *repository.BatchItemError
+	if !errors.As(err, &itemErr) || itemErr.Index != 1 || !errors.Is(err, domain.ErrAggregateVersionConflict) {
+		t.Fatalf("err = %v, want version conflict on item 1", err)
+	}
+	for _, id := range []string{"deal-new", "deal-2"} {
+		if _, err := repo.Get(context.Background(), id); !errors.Is(err, domain.ErrAggregateNotFound) {
+			t.Errorf("%s persisted after rollback (err %v)", id, err)
+		}
+	}
+}
+
+func TestSaveBatchBestEffortReportsPerItemResults(t *testing.T) {
+	repo := seededAggregates()
+	uc := aggregateUC.New(repo, nil, aggregateUC.WithOptimisticLocking(true))
+	items := append(batch(),
+		&domain.Aggregate{ID: "deal-foreign", Kind: "deal", TenantID: "acme", Version: 2},
+		&domain.Aggregate{ID: "deal-1-next", Kind: ""},
+	)
+
+	results, err := uc.SaveBatch(context.Background(), items, true)
+	if err != nil {
+		t.Fatalf("save batch: %v", err)
+	}
+
+	want := []error{nil, domain.ErrAggregateVersionConflict, nil, domain.ErrAggregateForeignTenant, domain.ErrInvalidPayload}
+	for i, result := range results {
+		if !errors.Is(result.Err, want[i]) || (want[i] == nil && result.Err != nil) {
+			t.Errorf("result %d (%s) = %v, want %v", i, result.ID, result.Err, want[i])
+		}
+	}
+	if _, err := repo.Get(context.Background(), "deal-2"); err != nil {
+		t.Errorf("deal-2 not saved: %v", err)
+	}
+	if stored, _ := repo.Get(context.Background(), "deal-foreign"); stored.TenantID != "globex" {
+		t.Errorf("foreign aggregate moved to tenant %q", stored.TenantID)
+	}
+}
+
+func TestSaveBatchSkipsVersionCheckWithoutOptimisticLocking(t *testing.T) {
+	repo := seededAggregates()
+	uc := aggregateUC.New(repo, nil)
+
+	if _, err := uc.SaveBatch(context.Background(), batch(), false); err != nil {
+		t.Fatalf("save batch: %v", err)
+	}
+	if _, err := repo.Get(context.Background(), "deal-2"); err != nil {
+		t.Fatalf("deal-2 not saved: %v", err)
+	}
+}
+
+func TestSaveBatchRejectsOversizedAndEmptyBatches(t *testing.T) {
+	uc := aggregateUC.New(seededAggregates(), nil, aggregateUC.WithMaxBatchSize(2))
+
+	if _, err := uc.SaveBatch(context.Background(), batch(), false); !errors.Is(err, aggregateUC.ErrBatchTooLarge) {
+		t.Fatalf("oversized err = %v, want ErrBatchTooLarge", err)
+	}
+	if _, err := uc.SaveBatch(context.Background(), nil, false); !errors.Is(err, domain.ErrInvalidPayload) {
+		t.Fatalf("empty err = %v, want ErrInvalidPayload", err)
+	}
+}
+
+func TestImportIsInsertOnlyAndAllOrNothing(t *testing.T) {
+	repo := seededAggregates()
+	uc := aggregateUC.New(repo, nil, aggregateUC.WithMaxBatchSize(2))
+
+	// Imports are not bound by the SaveBatch maximum.
+	fresh := []*domain.Aggregate{
+		{ID: "deal-a", Kind: "deal", TenantID: "acme", Version: 1},
+		{ID: "deal-b", Kind: "deal", TenantID: "acme", Version: 1},
+		{ID: "deal-c", Kind: "deal", TenantID: "acme", Version: 1},
+	}
+	if err := uc.Import(context.Background(), fresh); err != nil {
+		t.Fatalf("import: %v", err)
+	}
+	for _, aggregate := range fresh {
+		if _, err := repo.Get(context.Background(), aggregate.ID); err != nil {
+			t.Fatalf("%s not imported: %v", aggregate.ID, err)
+		}
+	}
+
+	duplicated := []*domain.Aggregate{
+		{ID: "deal-d", Kind: "deal", Version: 1},
+		{ID: "deal-d", Kind: "deal", Version: 2},
+	}
+	var itemErr *repository.BatchItemError
+	if err := uc.Import(context.Background(), duplicated); !errors.As(err, &itemErr) || itemErr.Index != 1 || !errors.Is(err, aggregateUC.ErrDuplicateImportID) {
+		t.Fatalf("duplicate ids: err = %v, want ErrDuplicateImportID on item 1", err)
+	}
+
+	clashing := []*domain.Aggregate{
+		{ID: "deal-e", Kind: "deal", TenantID: "acme", Version: 1},
+		{ID: "deal-1", Kind: "deal", TenantID: "acme", Version: 4},
+	}
+	if err := uc.Import(context.Background(), clashing); !errors.Is(err, domain.ErrConflict) {
+		t.Fatalf("stored id: err = %v, want ErrConflict", err)
+	}
+	if _, err := repo.Get(context.Background(), "deal-e"); !errors.Is(err, domain.ErrAggregateNotFound) {
+		t.Fatalf("deal-e saved by a failed import: %v", err)
+	}
+	if stored, _ := repo.Get(context.Background(), "deal-1"); stored.Version != 3 {
+		t.Fatalf("deal-1 version = %d, an import must never overwrite", stored.Version)
+	}
+}
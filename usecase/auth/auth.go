@@ -8,51 +8,146 @@ import (
 	"go.uber.org/zap"
 
 	"github.com/fastygo/backend/domain"
+	"github.com/fastygo/backend/pkg/clientinfo"
+	"github.com/fastygo/backend/pkg/clock"
 	"github.com/fastygo/backend/repository"
+	"github.com/fastygo/backend/usecase"
+)
+
+// recentSessionLimit bounds how many of a user's sessions a login's IP is compared against.
+const recentSessionLimit = 20
+
+// Session metadata keys populated from the request context.
+const (
+	MetadataIP        = "ip"
+	MetadataUserAgent = "user_agent"
 )
 
 type UseCase struct {
 	users    repository.UserRepository
 	sessions repository.SessionRepository
 	logger   *zap.Logger
+	clock    clock.Clock
+	events   usecase.EventPublisher
+	authn    Authenticator
+}
+
+// Option customizes the auth use case.
+type Option func(*UseCase)
+
+// WithClock overrides the time source used for session timestamps and expiry checks.
+func WithClock(c clock.Clock) Option {
+	return func(uc *UseCase) {
+		if c != nil {
+			uc.clock = c
+		}
+	}
+}
+
+// WithAuthenticator sets how Login verifies credentials; without it every login is denied.
+func WithAuthenticator(authn Authenticator) Option {
+	return func(uc *UseCase) {
+		if authn != nil {
+			uc.authn = authn
+		}
+	}
+}
+
+// WithEvents publishes a domain.SessionEvent for every session created, refreshed, revoked or found
+// expired. Publishing is best effort: a failure is logged and never fails the operation.
+func WithEvents(events usecase.EventPublisher) Option {
+	return func(uc *UseCase) {
+		uc.events = events
+	}
 }
 
-func New(users repository.UserRepository, sessions repository.SessionRepository, logger *zap.Logger) *UseCase {
+func New(users repository.UserRepository, sessions repository.SessionRepository, logger *zap.Logger, opts ...Option) *UseCase {
 	if logger == nil {
 		logger = zap.NewNop()
 	}
-	return &UseCase{
+	uc := &UseCase{
 		users:    users,
 		sessions: sessions,
 		logger:   logger,
+		clock:    clock.Real(),
+		authn:    DenyAll{},
 	}
+	for _, opt := range opts {
+		opt(uc)
+	}
+	return uc
+}
+
+// Login verifies credential for userID and issues a session. Credentials are checked before the
+// user is looked up, so a rejected login reveals nothing about which user IDs exist.
+func (uc *UseCase) Login(ctx context.Context, userID, credential string, ttl time.Duration) (*domain.Session, error) {
+	if err := uc.authn.Authenticate(ctx, userID, credential); err != nil {
+		uc.logger.Info("login rejected", zap.String("user_id", userID), zap.Error(err))
+		if domain.IsDomainError(err, domain.ErrCodeUnauthorized) {
+			return nil, err
+		}
+		return nil, domain.WrapError(domain.ErrCodeUnauthorized, ErrInvalidCredentials.Message, err)
+	}
+	return uc.CreateSession(ctx, userID, ttl)
 }
 
+// CreateSession issues a session without checking credentials; callers must have authenticated
+// the user already. Login is the entry point for untrusted requests.
 func (uc *UseCase) CreateSession(ctx context.Context, userID string, ttl time.Duration) (*domain.Session, error) {
 	if _, err := uc.users.GetByID(ctx, userID); err != nil {
 		return nil, err
 	}
 
+	now := uc.clock.Now()
 	session := &domain.Session{
 		ID:        uuid.NewString(),
 		UserID:    userID,
-		CreatedAt: time.Now(),
-		ExpiresAt: time.Now().Add(ttl),
+		CreatedAt: now,
+		ExpiresAt: now.Add(ttl),
+		Metadata:  clientMetadata(ctx),
 	}
+	session.Suspicious = uc.fromNewIP(ctx, session)
 
 	if err := uc.sessions.Save(ctx, session); err != nil {
 		return nil, err
 	}
+	uc.publish(ctx, domain.SessionCreated, session)
 	return session, nil
 }
 
+// fromNewIP reports whether the session's IP differs from every recent session of its user. A first
+// login has nothing to compare against and is not flagged; neither is one whose history cannot be
+// read, since the check must never block a login.
+func (uc *UseCase) fromNewIP(ctx context.Context, session *domain.Session) bool {
+	ip := session.Metadata[MetadataIP]
+	if ip == "" {
+		return false
+	}
+	recent, err := uc.sessions.ListByUser(ctx, session.UserID, recentSessionLimit)
+	if err != nil {
+		uc.logger.Warn("failed to load recent sessions for the new-IP check",
+			zap.String("user_id", session.UserID), zap.Error(err))
+		return false
+	}
+	if len(recent) == 0 {
+		return false
+	}
+	for _, previous := range recent {
+		if previous.Metadata[MetadataIP] == ip {
+			return false
+		}
+	}
+	return true
+}
+
 func (uc *UseCase) GetSession(ctx context.Context, sessionID string) (*domain.Session, error) {
 	session, err := uc.sessions.Get(ctx, sessionID)
 	if err != nil {
 		return nil, err
 	}
-	if session.IsExpired(time.Now()) {
+	if session.IsExpired(uc.clock.Now()) {
 		_ = uc.sessions.Delete(ctx, sessionID)
+		uc.publish(ctx, domain.SessionExpired, session)
 		return nil, domain.ErrSessionNotFound
 	}
 	return session, nil
@@ -66,10 +161,95 @@ func (uc *UseCase) RefreshSession(ctx context.Context, sessionID string, ttl tim
 	if err := uc.sessions.Extend(ctx, sessionID, int(ttl.Seconds())); err != nil {
 		return nil, err
 	}
-	session.ExpiresAt = time.Now().Add(ttl)
+	session.ExpiresAt = uc.clock.Now().Add(ttl)
+	uc.publish(ctx, domain.SessionRefreshed, session)
 	return session, nil
 }
 
 func (uc *UseCase) RevokeSession(ctx context.Context, sessionID string) error {
-	return uc.sessions.Delete(ctx, sessionID)
+	session := &domain.Session{ID: sessionID}
+	if uc.events != nil {
+		// Looked up only so the event can name the owner; revocation does not depend on it.
+		if stored, err := uc.sessions.Get(ctx, sessionID); err == nil {
+			session = stored
+		}
+	}
+	return uc.revoke(ctx, session)
+}
+
+// RevokeUserSession revokes a session owned by userID. Revoking a session that no longer exists succeeds.
+func (uc *UseCase) RevokeUserSession(ctx context.Context, userID, sessionID string) error {
+	session, err := uc.sessions.Get(ctx, sessionID)
+	if err != nil {
+		if domain.IsDomainError(err, domain.ErrCodeNotFound) {
+			return nil
+		}
+		return err
+	}
+	if session.UserID != userID {
+		return domain.NewError(domain.ErrCodeForbidden, "session belongs to another user")
+	}
+	return uc.revoke(ctx, session)
+}
+
+// EvictSession revokes any user's session on an administrator's behalf. Unlike RevokeSession it
+// reports domain.ErrSessionNotFound for a session that does not exist.
+func (uc *UseCase) EvictSession(ctx context.Context, sessionID string) error {
+	session, err := uc.sessions.Get(ctx, sessionID)
+	if err != nil {
+		return err
+	}
+	return uc.revoke(ctx, session)
+}
+
+func (uc *UseCase) revoke(ctx context.Context, session *domain.Session) error {
+	if err := uc.sessions.Delete(ctx, session.ID); err != nil {
+		return err
+	}
+	uc.publish(ctx, domain.SessionRevoked, session)
+	return nil
+}
+
+// publish reports a session transition, attributing it to the calling client when the request
+// carries one and to the session's recorded client otherwise.
+func (uc *UseCase) publish(ctx context.Context, eventType domain.SessionEventType, session *domain.Session) {
+	if uc.events == nil {
+		return
+	}
+	event := domain.SessionEvent{
+		Type:      eventType,
+		UserID:    session.UserID,
+		SessionID: session.ID,
+		IP:        session.Metadata[MetadataIP],
+		UserAgent: session.Metadata[MetadataUserAgent],
+		At:        uc.clock.Now(),
+	}
+	if eventType == domain.SessionCreated {
+		event.Suspicious = session.Suspicious
+	}
+	if client := clientinfo.FromContext(ctx); client.IP != "" || client.UserAgent != "" {
+		event.IP, event.UserAgent = client.IP, client.UserAgent
+	}
+	if err := uc.events.Publish(ctx, event); err != nil {
+		uc.logger.Warn("failed to publish session event",
+			zap.String("event", string(eventType)),
+			zap.String("session_id", session.ID),
+			zap.Error(err))
+	}
+}
+
+// clientMetadata captures the caller's IP and user agent recorded by the transport layer.
+func clientMetadata(ctx context.Context) map[string]string {
+	client := clientinfo.FromContext(ctx)
+	metadata := make(map[string]string, 2)
+	if client.IP != "" {
+		metadata[MetadataIP] = client.IP
+	}
+	if client.UserAgent != "" {
+		metadata[MetadataUserAgent] = client.UserAgent
+	}
+	if len(metadata) == 0 {
+		return nil
+	}
+	return metadata
 }
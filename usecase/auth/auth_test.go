@@ -0,0 +1,248 @@
+package auth_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/fastygo/backend/domain"
+	"github.com/fastygo/backend/pkg/clientinfo"
+	"github.com/fastygo/backend/pkg/clock"
+	"github.com/fastygo/backend/repository/repositorytest"
+	authUC "github.com/fastygo/backend/usecase/auth"
+)
+
+func TestCreateSessionCapturesClientMetadata(t *testing.T) {
+	sessions := repositorytest.NewSessions()
+	uc := authUC.New(repositorytest.NewUsers(domain.User{ID: "user-1"}), sessions, nil)
+
+	ctx := clientinfo.NewContext(context.Background(), clientinfo.Info{IP: "203.0.113.7", UserAgent: "curl/8.0"})
+	session, err := uc.CreateSession(ctx, "user-1", time.Hour)
+	if err != nil {
+		t.Fatalf("create session: %v", err)
+	}
+
+	stored, err := sessions.Get(context.Background(), session.ID)
+	if err != nil {
+		t.Fatalf("get stored session: %v", err)
+	}
+	if got := stored.Metadata[authUC.MetadataIP]; got != "203.0.113.7" {
+		t.Errorf("ip = %q, want %q", got, "203.0.113.7")
+	}
+	if got := stored.Metadata[authUC.MetadataUserAgent]; got != "curl/8.0" {
+		t.Errorf("user agent = %q, want %q", got, "curl/8.0")
+	}
+}
+
+func TestCreateSessionWithoutClientInfoLeavesMetadataEmpty(t *testing.T) {
+	uc := authUC.New(repositorytest.NewUsers(domain.User{ID: "user-1"}), repositorytest.NewSessions(), nil)
+
+	session, err := uc.CreateSession(context.Background(), "user-1", time.Hour)
+	if err != nil {
+		t.Fatalf("create session: %v", err)
+	}
+	if session.Metadata != nil {
+		t.Fatalf("expected no metadata, got %v", session.Metadata)
+	}
+}
+
+func TestGetSessionExpiresWithFakeClock(t *testing.T) {
+	fake := clock.NewFake(time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC))
+	sessions := repositorytest.NewSessions()
+	uc := authUC.New(repositorytest.NewUsers(domain.User{ID: "user-1"}), sessions, nil, authUC.WithClock(fake))
+
+	session, err := uc.CreateSession(context.Background(), "user-1", time.Hour)
+	if err != nil {
+		t.Fatalf("create session: %v", err)
+	}
+
+	fake.Advance(59 * time.Minute)
+	if _, err := uc.GetSession(context.Background(), session.ID); err != nil {
+		t.Fatalf("session should still be valid: %v", err)
+	}
+
+	fake.Advance(time.Minute)
+	if _, err := uc.GetSession(context.Background(), session.ID); err != domain.ErrSessionNotFound {
+		t.Fatalf("expected expired session to be reported missing, got %v", err)
+	}
+	if _, err := sessions.Get(context.Background(), session.ID); err != domain.ErrSessionNotFound {
+		t.Fatalf("expected expired session to be deleted, got %v", err)
+	}
+}
+
+type recordingPublisher struct {
+	events []domain.SessionEvent
+	err    error
+}
+
+func (p *recordingPublisher) Publish(_ context.Context, event interface{}) error {
+	if e, ok := event.(domain.SessionEvent); ok {
+		p.events = append(p.events, e)
+	}
+	return p.err
+}
+
+func (p *recordingPublisher) last(t *testing.T) domain.SessionEvent {
+	t.Helper()
+	if len(p.events) == 0 {
+		t.Fatal("no session event published")
+	}
+	return p.events[len(p.events)-1]
+}
+
+func TestSessionOperationsPublishEvents(t *testing.T) {
+	fake := clock.NewFake(time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC))
+	events := &recordingPublisher{}
+	uc := authUC.New(repositorytest.NewUsers(domain.User{ID: "user-1"}), repositorytest.NewSessions(), nil,
+		authUC.WithClock(fake), authUC.WithEvents(events))
+	ctx := clientinfo.NewContext(context.Background(), clientinfo.Info{IP: "203.0.113.7", UserAgent: "curl/8.0"})
+
+	session, err := uc.CreateSession(ctx, "user-1", time.Hour)
+	if err != nil {
+		t.Fatalf("create session: %v", err)
+	}
+	want := domain.SessionEvent{Type: domain.SessionCreated, UserID: "user-1", SessionID: session.ID, IP: "203.0.113.7", UserAgent: "curl/8.0", At: fake.Now()}
+	if got := events.last(t); got != want {
+		t.Fatalf("event = %+v, want %+v", got, want)
+	}
+
+	if _, err := uc.RefreshSession(ctx, session.ID, time.Hour); err != nil {
+		t.Fatalf("refresh: %v", err)
+	}
+	if got := events.last(t); got.Type != domain.SessionRefreshed || got.SessionID != session.ID || got.UserID != "user-1" {
+		t.Fatalf("event = %+v, want a refresh of %s", got, session.ID)
+	}
+
+	// Without client info on the request, the session's recorded client is reported.
+	if err := uc.RevokeSession(context.Background(), session.ID); err != nil {
+		t.Fatalf("revoke: %v", err)
+	}
+	if got := events.last(t); got.Type != domain.SessionRevoked || got.UserID != "user-1" || got.IP != "203.0.113.7" {
+		t.Fatalf("event = %+v, want a revocation attributed to the session's client", got)
+	}
+
+	expiring, err := uc.CreateSession(ctx, "user-1", time.Minute)
+	if err != nil {
+		t.Fatalf("create session: %v", err)
+	}
+	fake.Advance(time.Minute)
+	if _, err := uc.GetSession(ctx, expiring.ID); err != domain.ErrSessionNotFound {
+		t.Fatalf("get expired session: err = %v", err)
+	}
+	if got := events.last(t); got.Type != domain.SessionExpired || got.SessionID != expiring.ID {
+		t.Fatalf("event = %+v, want expiry of %s", got, expiring.ID)
+	}
+	if len(events.events) != 5 {
+		t.Fatalf("published %d events, want 5", len(events.events))
+	}
+}
+
+func TestRevokeUserSessionPublishesEvent(t *testing.T) {
+	events := &recordingPublisher{}
+	uc := authUC.New(repositorytest.NewUsers(domain.User{ID: "user-1"}), repositorytest.NewSessions(), nil, authUC.WithEvents(events))
+	session, err := uc.CreateSession(context.Background(), "user-1", time.Hour)
+	if err != nil {
+		t.Fatalf("create session: %v", err)
+	}
+
+	if err := uc.RevokeUserSession(context.Background(), "user-2", session.ID); !domain.IsDomainError(err, domain.ErrCodeForbidden) {
+		t.Fatalf("revoke another user's session: err = %v, want forbidden", err)
+	}
+	if got := events.last(t); got.Type != domain.SessionCreated {
+		t.Fatalf("a refused revocation published %+v", got)
+	}
+	if err := uc.RevokeUserSession(context.Background(), "user-1", session.ID); err != nil {
+		t.Fatalf("revoke: %v", err)
+	}
+	if got := events.last(t); got.Type != domain.SessionRevoked || got.SessionID != session.ID {
+		t.Fatalf("event = %+v, want a revocation of %s", got, session.ID)
+	}
+}
+
+func TestPublishFailureDoesNotFailLogin(t *testing.T) {
+	events := &recordingPublisher{err: errors.New("bus unavailable")}
+	uc := authUC.New(repositorytest.NewUsers(domain.User{ID: "user-1"}), repositorytest.NewSessions(), nil, authUC.WithEvents(events))
+
+	if _, err := uc.CreateSession(context.Background(), "user-1", time.Hour); err != nil {
+		t.Fatalf("create session with a failing bus: %v", err)
+	}
+	if len(events.events) != 1 {
+		t.Fatalf("published %d events, want 1", len(events.events))
+	}
+}
+
+func TestCreateSessionFlagsLoginFromNewIP(t *testing.T) {
+	fake := clock.NewFake(time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC))
+	events := &recordingPublisher{}
+	uc := authUC.New(repositorytest.NewUsers(domain.User{ID: "user-1"}), repositorytest.NewSessions(), nil,
+		authUC.WithClock(fake), authUC.WithEvents(events))
+	from := func(ip string) context.Context {
+		return clientinfo.NewContext(context.Background(), clientinfo.Info{IP: ip, UserAgent: "curl/8.0"})
+	}
+
+	first, err := uc.CreateSession(from("203.0.113.7"), "user-1", time.Hour)
+	if err != nil {
+		t.Fatalf("first login: %v", err)
+	}
+	if first.Suspicious || events.last(t).Suspicious {
+		t.Fatal("a first login with no history was flagged")
+	}
+
+	fake.Advance(time.Minute)
+	again, err := uc.CreateSession(from("203.0.113.7"), "user-1", time.Hour)
+	if err != nil {
+		t.Fatalf("repeat login: %v", err)
+	}
+	if again.Suspicious {
+		t.Fatal("a login from a known IP was flagged")
+	}
+
+	fake.Advance(time.Minute)
+	elsewhere, err := uc.CreateSession(from("198.51.100.20"), "user-1", time.Hour)
+	if err != nil {
+		t.Fatalf("login from a new IP: %v", err)
+	}
+	if !elsewhere.Suspicious {
+		t.Fatal("a login from a new IP was not flagged")
+	}
+	if got := events.last(t); got.Type != domain.SessionCreated || !got.Suspicious || got.IP != "198.51.100.20" {
+		t.Fatalf("event = %+v, want a suspicious SessionCreated from the new IP", got)
+	}
+}
+
+func TestLoginRequiresAuthenticator(t *testing.T) {
+	users := repositorytest.NewUsers(domain.User{ID: "user-1"})
+	password := authUC.AuthenticatorFunc(func(_ context.Context, userID, credential string) error {
+		if userID == "user-1" && credential == "hunter2" {
+			return nil
+		}
+		return authUC.ErrInvalidCredentials
+	})
+
+	denied := authUC.New(users, repositorytest.NewSessions(), nil)
+	if _, err := denied.Login(context.Background(), "user-1", "hunter2", time.Hour); !errors.Is(err, authUC.ErrInvalidCredentials) {
+		t.Fatalf("login without an authenticator: err = %v, want ErrInvalidCredentials", err)
+	}
+
+	sessions := repositorytest.NewSessions()
+	uc := authUC.New(users, sessions, nil, authUC.WithAuthenticator(password))
+	session, err := uc.Login(context.Background(), "user-1", "hunter2", time.Hour)
+	if err != nil {
+		t.Fatalf("login with the right credential: %v", err)
+	}
+	if _, err := sessions.Get(context.Background(), session.ID); err != nil {
+		t.Fatalf("accepted login stored no session: %v", err)
+	}
+	for _, tc := range []struct{ user, credential string }{{"user-1", "wrong"}, {"ghost", "hunter2"}} {
+		if _, err := uc.Login(context.Background(), tc.user, tc.credential, time.Hour); !domain.IsDomainError(err, domain.ErrCodeUnauthorized) {
+			t.Fatalf("login %s/%s: err = %v, want unauthorized", tc.user, tc.credential, err)
+		}
+	}
+
+	failing := authUC.New(users, repositorytest.NewSessions(), nil, authUC.WithAuthenticator(authUC.AuthenticatorFunc(
+		func(context.Context, string, string) error { return errors.New("ldap unreachable") })))
+	if _, err := failing.Login(context.Background(), "user-1", "hunter2", time.Hour); !domain.IsDomainError(err, domain.ErrCodeUnauthorized) {
+		t.Fatalf("login with a failing authenticator: err = %v, want unauthorized", err)
+	}
+}
@@ -0,0 +1,39 @@
+package auth
+
+import (
+	"context"
+
+	"github.com/fastygo/backend/domain"
+)
+
+// ErrInvalidCredentials is returned when a login's credential is rejected. It deliberately does not
+// say whether the user exists.
+var ErrInvalidCredentials = domain.NewError(domain.ErrCodeUnauthorized, "invalid credentials")
+
+// Authenticator verifies the credential presented with a login before a session is issued.
+type Authenticator interface {
+	Authenticate(ctx context.Context, userID, credential string) error
+}
+
+// AuthenticatorFunc adapts a function to Authenticator.
+type AuthenticatorFunc func(ctx context.Context, userID, credential string) error
+
+func (f AuthenticatorFunc) Authenticate(ctx context.Context, userID, credential string) error {
+	return f(ctx, userID, credential)
+}
+
+// DenyAll rejects every login. It is the default, so a deployment that configures no
+// authenticator cannot hand out sessions for bare user IDs.
+type DenyAll struct{}
+
+func (DenyAll) Authenticate(context.Context, string, string) error {
+	return ErrInvalidCredentials
+}
+
+// AllowAll accepts any credential for any user. It exists for local development only; config
+// validation refuses it in production.
+type AllowAll struct{}
+
+func (AllowAll) Authenticate(context.Context, string, string) error {
+	return nil
+}
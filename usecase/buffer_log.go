@@ -0,0 +1,43 @@
+package usecase
+
+import "go.uber.org/zap"
+
+// Reasons recorded by LogBufferDecision.
+const (
+	BufferReasonOffline     = "database_offline"
+	BufferReasonWriteFailed = "write_failed"
+)
+
+// BufferDecision describes a failed write that was handed to the operation buffer.
+type BufferDecision struct {
+	Entity    string
+	Operation string
+	EntityID  string
+	UserID    string
+	// Cause is the repository error that triggered the fallback; nil when the live write was skipped.
+	Cause error
+	// BufferErr is set when the buffer refused the operation as well.
+	BufferErr error
+}
+
+// LogBufferDecision logs a buffering decision with the same fields for every entity, so deferred
+// writes can be traced to an ID and a reason. buf supplies the monitor's view of the database.
+func LogBufferDecision(logger *zap.Logger, buf OperationBuffer, d BufferDecision) {
+	reason := BufferReasonWriteFailed
+	if buf != nil && !buf.DatabaseOnline() {
+		reason = BufferReasonOffline
+	}
+	fields := []zap.Field{
+		zap.String("entity", d.Entity),
+		zap.String("operation", d.Operation),
+		zap.String("entity_id", d.EntityID),
+		zap.String("user_id", d.UserID),
+		zap.String("reason", reason),
+		zap.Error(d.Cause),
+	}
+	if d.BufferErr != nil {
+		logger.Error("operation could not be buffered", append(fields, zap.NamedError("buffer_error", d.BufferErr))...)
+		return
+	}
+	logger.Warn("operation buffered", fields...)
+}
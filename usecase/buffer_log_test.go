@@ -0,0 +1,71 @@
+package usecase_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+
+	"github.com/fastygo/backend/domain"
+	"github.com/fastygo/backend/repository/repositorytest"
+	"github.com/fastygo/backend/usecase"
+	profileUC "github.com/fastygo/backend/usecase/profile"
+	taskUC "github.com/fastygo/backend/usecase/task"
+)
+
+// stubBuffer accepts every operation unless err is set.
+type stubBuffer struct {
+	online bool
+	err    error
+}
+
+func (b stubBuffer) Buffers(string) bool                                       { return true }
+func (b stubBuffer) Defers(string) bool                                        { return false }
+func (b stubBuffer) DatabaseOnline() bool                                      { return b.online }
+func (b stubBuffer) BufferProfile(context.Context, string, *domain.User) error { return b.err }
+func (b stubBuffer) BufferTask(context.Context, string, *domain.Task) error    { return b.err }
+
+var errWrite = errors.New("connection reset")
+
+func TestBufferDecisionLogsStructuredFields(t *testing.T) {
+	core, logs := observer.New(zapcore.DebugLevel)
+	logger := zap.New(core)
+
+	tasks := repositorytest.NewTasks()
+	tasks.Err = errWrite
+	if _, err := taskUC.New(tasks, stubBuffer{online: false}, logger).
+		CreateTask(context.Background(), &domain.Task{ID: "t1", UserID: "u1"}); err != nil {
+		t.Fatalf("create task: %v", err)
+	}
+
+	users := repositorytest.NewUsers()
+	users.Err = errWrite
+	if _, err := profileUC.New(users, stubBuffer{online: true, err: errors.New("disk full")}, logger).
+		UpdateProfile(context.Background(), &domain.User{ID: "u2"}); !errors.Is(err, errWrite) {
+		t.Fatalf("update profile err = %v, want the write error", err)
+	}
+
+	entries := logs.All()
+	if len(entries) != 2 {
+		t.Fatalf("logged %d entries, want 2: %+v", len(entries), entries)
+	}
+	want := []map[string]interface{}{
+		{"entity": usecase.EntityTask, "operation": usecase.OperationCreate, "entity_id": "t1", "user_id": "u1", "reason": usecase.BufferReasonOffline, "error": errWrite.Error()},
+		{"entity": usecase.EntityProfile, "operation": usecase.OperationUpdate, "entity_id": "u2", "user_id": "u2", "reason": usecase.BufferReasonWriteFailed, "error": errWrite.Error(), "buffer_error": "disk full"},
+	}
+	levels := []zapcore.Level{zapcore.WarnLevel, zapcore.ErrorLevel}
+	for i, entry := range entries {
+		if entry.Level != levels[i] {
+			t.Errorf("entry %d level = %v, want %v", i, entry.Level, levels[i])
+		}
+		fields := entry.ContextMap()
+		for key, value := range want[i] {
+			if fields[key] != value {
+				t.Errorf("entry %d %s = %v, want %v", i, key, fields[key], value)
+			}
+		}
+	}
+}
@@ -8,6 +8,31 @@ import (
 
 // OperationBuffer abstracts the buffer processor so use cases stay storage-agnostic.
 type OperationBuffer interface {
+	// Buffers reports whether failed writes of entity (EntityProfile, EntityTask) should be buffered.
+	Buffers(entity string) bool
+	// Defers reports whether writes of entity should be buffered without attempting the live write.
+	Defers(entity string) bool
+	// DatabaseOnline reports whether the connection monitor currently considers the database reachable.
+	DatabaseOnline() bool
 	BufferProfile(ctx context.Context, operation string, user *domain.User) error
 	BufferTask(ctx context.Context, operation string, task *domain.Task) error
 }
+
+type callbackURLKey struct{}
+
+// WithCallbackURL asks for url to be told the final outcome of any write buffered under ctx.
+func WithCallbackURL(ctx context.Context, url string) context.Context {
+	if url == "" {
+		return ctx
+	}
+	return context.WithValue(ctx, callbackURLKey{}, url)
+}
+
+// CallbackURL returns the outcome callback requested for writes under ctx, if any.
+func CallbackURL(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+	url, _ := ctx.Value(callbackURLKey{}).(string)
+	return url
+}
@@ -4,4 +4,7 @@ const (
 	OperationCreate = "create"
 	OperationUpdate = "update"
 	OperationDelete = "delete"
+
+	EntityProfile = "profile"
+	EntityTask    = "task"
 )
@@ -0,0 +1,61 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// EventPublisher hands domain events to whoever subscribed to them.
+type EventPublisher interface {
+	Publish(ctx context.Context, event interface{}) error
+}
+
+type EventHandler func(ctx context.Context, event interface{}) error
+
+// EventBus is an in-process EventPublisher delivering each event to every subscriber synchronously,
+// in subscription order. Subscribers switch on the event's type to pick the ones they handle.
+type EventBus struct {
+	mu       sync.RWMutex
+	handlers []EventHandler
+}
+
+var _ EventPublisher = (*EventBus)(nil)
+
+func NewEventBus() *EventBus {
+	return &EventBus{}
+}
+
+func (b *EventBus) Subscribe(handler EventHandler) {
+	if handler == nil {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.handlers = append(b.handlers, handler)
+}
+
+// Publish delivers event to every subscriber, even after one fails or panics, and joins their errors.
+func (b *EventBus) Publish(ctx context.Context, event interface{}) error {
+	b.mu.RLock()
+	handlers := b.handlers
+	b.mu.RUnlock()
+
+	var errs []error
+	for _, handler := range handlers {
+		if err := deliver(ctx, handler, event); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func deliver(ctx context.Context, handler EventHandler, event interface{}) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("event subscriber panicked: %v", r)
+		}
+	}()
+	return handler(ctx, event)
+}
@@ -0,0 +1,28 @@
+package usecase_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/fastygo/backend/usecase"
+)
+
+func TestEventBusDeliversPastFailingSubscribers(t *testing.T) {
+	bus := usecase.NewEventBus()
+	bus.Subscribe(func(context.Context, interface{}) error { panic("subscriber bug") })
+	bus.Subscribe(func(context.Context, interface{}) error { return errors.New("siem unreachable") })
+	var delivered []interface{}
+	bus.Subscribe(func(_ context.Context, event interface{}) error {
+		delivered = append(delivered, event)
+		return nil
+	})
+
+	err := bus.Publish(context.Background(), "login")
+	if err == nil {
+		t.Fatal("publish hid the subscriber failures")
+	}
+	if len(delivered) != 1 || delivered[0] != "login" {
+		t.Fatalf("delivered = %v, want the event to reach the last subscriber", delivered)
+	}
+}
@@ -0,0 +1,104 @@
+package profile
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/fastygo/backend/domain"
+	"github.com/fastygo/backend/pkg/clock"
+)
+
+// Cache keeps recently read profiles so reads can degrade gracefully during a database outage.
+type Cache interface {
+	// Get returns the cached profile and when it was stored.
+	Get(ctx context.Context, userID string) (*domain.User, time.Time, bool)
+	Set(ctx context.Context, user *domain.User, storedAt time.Time)
+	Delete(ctx context.Context, userID string)
+}
+
+type cacheEntry struct {
+	user     domain.User
+	storedAt time.Time
+}
+
+// MemoryCache is an in-process Cache holding at most maxEntries profiles for retention.
+type MemoryCache struct {
+	mu         sync.Mutex
+	entries    map[string]cacheEntry
+	retention  time.Duration
+	maxEntries int
+	clock      clock.Clock
+}
+
+var _ Cache = (*MemoryCache)(nil)
+
+// NewMemoryCache creates an empty cache. A nil clock uses the real one.
+func NewMemoryCache(retention time.Duration, maxEntries int, c clock.Clock) *MemoryCache {
+	if c == nil {
+		c = clock.Real()
+	}
+	if maxEntries <= 0 {
+		maxEntries = 10000
+	}
+	return &MemoryCache{
+		entries:    make(map[string]cacheEntry),
+		retention:  retention,
+		maxEntries: maxEntries,
+		clock:      c,
+	}
+}
+
+func (c *MemoryCache) Get(_ context.Context, userID string) (*domain.User, time.Time, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	entry, ok := c.entries[userID]
+	if !ok {
+		return nil, time.Time{}, false
+	}
+	if c.expired(entry, c.clock.Now()) {
+		delete(c.entries, userID)
+		return nil, time.Time{}, false
+	}
+	user := entry.user
+	return &user, entry.storedAt, true
+}
+
+func (c *MemoryCache) Set(_ context.Context, user *domain.User, storedAt time.Time) {
+	if user == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, exists := c.entries[user.ID]; !exists && len(c.entries) >= c.maxEntries {
+		c.evict()
+	}
+	c.entries[user.ID] = cacheEntry{user: *user, storedAt: storedAt}
+}
+
+func (c *MemoryCache) Delete(_ context.Context, userID string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.entries, userID)
+}
+
+// evict drops expired entries, or an arbitrary one when none has expired.
+func (c *MemoryCache) evict() {
+	now := c.clock.Now()
+	for id, entry := range c.entries {
+		if c.expired(entry, now) {
+			delete(c.entries, id)
+		}
+	}
+	if len(c.entries) < c.maxEntries {
+		return
+	}
+	for id := range c.entries {
+		delete(c.entries, id)
+		return
+	}
+}
+
+func (c *MemoryCache) expired(entry cacheEntry, now time.Time) bool {
+	return c.retention > 0 && now.Sub(entry.storedAt) > c.retention
+}
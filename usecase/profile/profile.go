@@ -2,10 +2,12 @@ package profile
 
 import (
 	"context"
+	"time"
 
 	"go.uber.org/zap"
 
 	"github.com/fastygo/backend/domain"
+	"github.com/fastygo/backend/pkg/clock"
 	"github.com/fastygo/backend/repository"
 	"github.com/fastygo/backend/usecase"
 )
@@ -14,31 +16,132 @@ type UseCase struct {
 	users  repository.UserRepository
 	buffer usecase.OperationBuffer
 	logger *zap.Logger
+	clock  clock.Clock
+	limits domain.MetadataLimits
+
+	cache    Cache
+	cacheTTL time.Duration
+	dbOnline func() bool
+}
+
+// Option customizes the profile use case.
+type Option func(*UseCase)
+
+// WithCache enables the read-through profile cache. Entries younger than ttl are served without a
+// database read; older ones are served stale only while dbOnline reports the database as down.
+func WithCache(cache Cache, ttl time.Duration, dbOnline func() bool) Option {
+	return func(uc *UseCase) {
+		uc.cache = cache
+		uc.cacheTTL = ttl
+		uc.dbOnline = dbOnline
+	}
+}
+
+// WithClock overrides the time source used to age cache entries.
+func WithClock(c clock.Clock) Option {
+	return func(uc *UseCase) {
+		if c != nil {
+			uc.clock = c
+		}
+	}
 }
 
-func New(users repository.UserRepository, buffer usecase.OperationBuffer, logger *zap.Logger) *UseCase {
+// WithMetadataLimits bounds user metadata on update; domain.DefaultMetadataLimits apply otherwise.
+func WithMetadataLimits(limits domain.MetadataLimits) Option {
+	return func(uc *UseCase) {
+		uc.limits = limits
+	}
+}
+
+func New(users repository.UserRepository, buffer usecase.OperationBuffer, logger *zap.Logger, opts ...Option) *UseCase {
 	if logger == nil {
 		logger = zap.NewNop()
 	}
-	return &UseCase{
+	uc := &UseCase{
 		users:  users,
 		buffer: buffer,
 		logger: logger,
+		clock:  clock.Real(),
+		limits: domain.DefaultMetadataLimits,
+	}
+	for _, opt := range opts {
+		opt(uc)
 	}
+	return uc
 }
 
 func (uc *UseCase) GetProfile(ctx context.Context, userID string) (*domain.User, error) {
-	return uc.users.GetByID(ctx, userID)
+	user, _, err := uc.LookupProfile(ctx, userID)
+	return user, err
+}
+
+// LookupProfile reads a profile through the cache, if enabled. stale reports that the database is
+// offline and the profile was served from an expired cache entry.
+func (uc *UseCase) LookupProfile(ctx context.Context, userID string) (user *domain.User, stale bool, err error) {
+	if uc.cache == nil {
+		user, err = uc.users.GetByID(ctx, userID)
+		return user, false, err
+	}
+
+	now := uc.clock.Now()
+	cached, storedAt, hit := uc.cache.Get(ctx, userID)
+	if hit && now.Sub(storedAt) < uc.cacheTTL {
+		return cached, false, nil
+	}
+	if hit && uc.dbOnline != nil && !uc.dbOnline() {
+		uc.logger.Warn("serving stale profile while database is offline",
+			zap.String("user_id", userID),
+			zap.Duration("age", now.Sub(storedAt)))
+		return cached, true, nil
+	}
+
+	user, err = uc.users.GetByID(ctx, userID)
+	if err != nil {
+		return nil, false, err
+	}
+	uc.cache.Set(ctx, user, now)
+	return user, false, nil
 }
 
 func (uc *UseCase) UpdateProfile(ctx context.Context, user *domain.User) (*domain.User, error) {
+	if err := user.Validate(uc.limits); err != nil {
+		return nil, err
+	}
+	if uc.cache != nil {
+		// Drop the cached copy whether the write lands now or is buffered, so it is never served after an update.
+		uc.cache.Delete(ctx, user.ID)
+	}
+	if uc.buffer != nil && uc.buffer.Defers(usecase.EntityProfile) {
+		// The database is known to be offline: skip the doomed live write.
+		bufErr := uc.buffer.BufferProfile(ctx, usecase.OperationUpdate, user)
+		usecase.LogBufferDecision(uc.logger, uc.buffer, usecase.BufferDecision{
+			Entity:    usecase.EntityProfile,
+			Operation: usecase.OperationUpdate,
+			EntityID:  user.ID,
+			UserID:    user.ID,
+			BufferErr: bufErr,
+		})
+		if bufErr == nil {
+			return user, nil
+		}
+	}
 	if err := uc.users.Upsert(ctx, user); err != nil {
-		if uc.buffer != nil {
-			if bufErr := uc.buffer.BufferProfile(ctx, usecase.OperationUpdate, user); bufErr != nil {
-				uc.logger.Error("failed to buffer profile update", zap.Error(bufErr))
+		if domain.IsDomainError(err, domain.ErrCodeConflict) {
+			return nil, err
+		}
+		if uc.buffer != nil && uc.buffer.Buffers(usecase.EntityProfile) {
+			bufErr := uc.buffer.BufferProfile(ctx, usecase.OperationUpdate, user)
+			usecase.LogBufferDecision(uc.logger, uc.buffer, usecase.BufferDecision{
+				Entity:    usecase.EntityProfile,
+				Operation: usecase.OperationUpdate,
+				EntityID:  user.ID,
+				UserID:    user.ID,
+				Cause:     err,
+				BufferErr: bufErr,
+			})
+			if bufErr != nil {
 				return nil, err
 			}
-			uc.logger.Warn("profile update buffered due to repository error", zap.Error(err))
 			return user, nil
 		}
 		return nil, err
@@ -0,0 +1,98 @@
+package profile_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/fastygo/backend/domain"
+	"github.com/fastygo/backend/pkg/clock"
+	"github.com/fastygo/backend/repository/repositorytest"
+	profileUC "github.com/fastygo/backend/usecase/profile"
+)
+
+var errDatabaseDown = errors.New("connection refused")
+
+type cachedProfile struct {
+	uc     *profileUC.UseCase
+	users  *repositorytest.Users
+	clock  *clock.Fake
+	online bool
+}
+
+func newCachedProfile() *cachedProfile {
+	fake := clock.NewFake(time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC))
+	c := &cachedProfile{
+		users:  repositorytest.NewUsers(domain.User{ID: "user-1", Email: "one@example.com"}),
+		clock:  fake,
+		online: true,
+	}
+	cache := profileUC.NewMemoryCache(time.Hour, 0, fake)
+	c.uc = profileUC.New(c.users, nil, nil,
+		profileUC.WithCache(cache, time.Minute, func() bool { return c.online }),
+		profileUC.WithClock(fake),
+	)
+	return c
+}
+
+func TestLookupProfileCacheMissReadsDatabase(t *testing.T) {
+	c := newCachedProfile()
+
+	user, stale, err := c.uc.LookupProfile(context.Background(), "user-1")
+	if err != nil || stale || user.Email != "one@example.com" {
+		t.Fatalf("lookup = %+v, stale %v, err %v; want a fresh database read", user, stale, err)
+	}
+	if _, _, err := c.uc.LookupProfile(context.Background(), "missing"); err != domain.ErrUserNotFound {
+		t.Fatalf("missing user error = %v, want ErrUserNotFound", err)
+	}
+}
+
+func TestLookupProfileCacheHitSkipsDatabase(t *testing.T) {
+	c := newCachedProfile()
+	if _, _, err := c.uc.LookupProfile(context.Background(), "user-1"); err != nil {
+		t.Fatalf("warm cache: %v", err)
+	}
+
+	c.users.Err = errDatabaseDown
+	c.clock.Advance(30 * time.Second)
+	user, stale, err := c.uc.LookupProfile(context.Background(), "user-1")
+	if err != nil || stale || user.ID != "user-1" {
+		t.Fatalf("lookup = %+v, stale %v, err %v; want a fresh cache hit", user, stale, err)
+	}
+}
+
+func TestLookupProfileServesStaleWhileDatabaseOffline(t *testing.T) {
+	c := newCachedProfile()
+	if _, _, err := c.uc.LookupProfile(context.Background(), "user-1"); err != nil {
+		t.Fatalf("warm cache: %v", err)
+	}
+
+	c.users.Err = errDatabaseDown
+	c.online = false
+	c.clock.Advance(10 * time.Minute)
+	user, stale, err := c.uc.LookupProfile(context.Background(), "user-1")
+	if err != nil || !stale || user.ID != "user-1" {
+		t.Fatalf("lookup = %+v, stale %v, err %v; want the cached profile flagged stale", user, stale, err)
+	}
+
+	c.online = true
+	if _, _, err := c.uc.LookupProfile(context.Background(), "user-1"); !errors.Is(err, errDatabaseDown) {
+		t.Fatalf("expired entry with database online error = %v, want the database error", err)
+	}
+}
+
+func TestUpdateProfileInvalidatesCache(t *testing.T) {
+	c := newCachedProfile()
+	if _, _, err := c.uc.LookupProfile(context.Background(), "user-1"); err != nil {
+		t.Fatalf("warm cache: %v", err)
+	}
+
+	if _, err := c.uc.UpdateProfile(context.Background(), &domain.User{ID: "user-1", Email: "new@example.com"}); err != nil {
+		t.Fatalf("update: %v", err)
+	}
+	user, _, err := c.uc.LookupProfile(context.Background(), "user-1")
+	if err != nil || user.Email != "new@example.com" {
+		t.Fatalf("lookup after update = %+v, %v; want the updated email", user, err)
+	}
+}
@@ -0,0 +1,146 @@
+package task
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"slices"
+	"sync"
+	"time"
+
+	"golang.org/x/sync/singleflight"
+
+	"github.com/fastygo/backend/domain"
+	"github.com/fastygo/backend/pkg/clock"
+	"github.com/fastygo/backend/repository"
+)
+
+// generationShards bounds the per-user invalidation state: users are hashed onto a fixed set of
+// counters, so a write also busts the cached lists of the few users sharing its shard.
+const generationShards = 256
+
+// listCoalescer lets concurrent identical task listings share one repository call and, when ttl is
+// positive, serves repeats from a short-lived cache. Writes bump a generation that is part of every
+// key, so reads after a write never join a flight or hit an entry that started before it.
+type listCoalescer struct {
+	group      singleflight.Group
+	ttl        time.Duration
+	maxEntries int
+	clock      clock.Clock
+
+	mu          sync.Mutex
+	global      uint64
+	generations [generationShards]uint64
+	entries     map[string]listEntry
+}
+
+type listEntry struct {
+	tasks    []domain.Task
+	storedAt time.Time
+}
+
+func newListCoalescer(ttl time.Duration, maxEntries int, c clock.Clock) *listCoalescer {
+	if c == nil {
+		c = clock.Real()
+	}
+	if maxEntries <= 0 {
+		maxEntries = 1000
+	}
+	return &listCoalescer{
+		ttl:        ttl,
+		maxEntries: maxEntries,
+		clock:      c,
+		entries:    make(map[string]listEntry),
+	}
+}
+
+// list returns load's result for filter, shared with identical concurrent calls. The flight runs
+// with the first caller's context, so its cancellation fails every caller waiting on it.
+func (c *listCoalescer) list(ctx context.Context, filter repository.TaskFilter, load func(context.Context) ([]domain.Task, error)) ([]domain.Task, error) {
+	key := c.key(filter)
+	if tasks, ok := c.cached(key); ok {
+		return tasks, nil
+	}
+	result, err, _ := c.group.Do(key, func() (any, error) {
+		tasks, err := load(ctx)
+		if err == nil {
+			c.store(key, tasks)
+		}
+		return tasks, err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return slices.Clone(result.([]domain.Task)), nil
+}
+
+// bust invalidates userID's cached lists, or every list when userID is empty.
+func (c *listCoalescer) bust(userID string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if userID == "" {
+		c.global++
+		return
+	}
+	c.generations[shard(userID)]++
+}
+
+func (c *listCoalescer) key(filter repository.TaskFilter) string {
+	c.mu.Lock()
+	global, generation := c.global, c.generations[shard(filter.UserID)]
+	c.mu.Unlock()
+	return fmt.Sprintf("%d.%d|%q|%q|%q|%d|%t|%d|%d", global, generation,
+		filter.UserID, filter.TenantID, filter.Status, filter.Priority, filter.OnlyOverdue, filter.Limit, filter.Offset)
+}
+
+func (c *listCoalescer) cached(key string) ([]domain.Task, bool) {
+	if c.ttl <= 0 {
+		return nil, false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	entry, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+	if c.clock.Now().Sub(entry.storedAt) >= c.ttl {
+		delete(c.entries, key)
+		return nil, false
+	}
+	return slices.Clone(entry.tasks), true
+}
+
+func (c *listCoalescer) store(key string, tasks []domain.Task) {
+	if c.ttl <= 0 {
+		return
+	}
+	now := c.clock.Now()
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, exists := c.entries[key]; !exists && len(c.entries) >= c.maxEntries {
+		c.evict(now)
+	}
+	c.entries[key] = listEntry{tasks: tasks, storedAt: now}
+}
+
+// evict drops expired entries, including those orphaned by a bust, or an arbitrary one when none has expired.
+func (c *listCoalescer) evict(now time.Time) {
+	for key, entry := range c.entries {
+		if now.Sub(entry.storedAt) >= c.ttl {
+			delete(c.entries, key)
+		}
+	}
+	if len(c.entries) < c.maxEntries {
+		return
+	}
+	for key := range c.entries {
+		delete(c.entries, key)
+		return
+	}
+}
+
+func shard(userID string) int {
+	h := fnv.New32a()
+	h.Write([]byte(userID))
+	return int(h.Sum32() % generationShards)
+}
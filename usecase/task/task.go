@@ -2,10 +2,12 @@ package task
 
 import (
 	"context"
+	"time"
 
 	"go.uber.org/zap"
 
 	"github.com/fastygo/backend/domain"
+	"github.com/fastygo/backend/pkg/clock"
 	"github.com/fastygo/backend/repository"
 	"github.com/fastygo/backend/usecase"
 )
@@ -14,21 +16,56 @@ type UseCase struct {
 	tasks  repository.TaskRepository
 	buffer usecase.OperationBuffer
 	logger *zap.Logger
+	limits domain.MetadataLimits
+
+	// lists is nil unless WithListCoalescing is set.
+	lists *listCoalescer
+}
+
+// Option customizes the task use case.
+type Option func(*UseCase)
+
+// WithMetadataLimits bounds task metadata on create and update; domain.DefaultMetadataLimits apply otherwise.
+func WithMetadataLimits(limits domain.MetadataLimits) Option {
+	return func(uc *UseCase) {
+		uc.limits = limits
+	}
+}
+
+// WithListCoalescing makes concurrent identical ListTasks calls share one repository call. A
+// positive cacheTTL additionally serves repeats from an in-process cache of up to maxEntries lists
+// for that long. Task writes through the use case invalidate the writer's cached lists; nil c uses
+// the real clock.
+func WithListCoalescing(cacheTTL time.Duration, maxEntries int, c clock.Clock) Option {
+	return func(uc *UseCase) {
+		uc.lists = newListCoalescer(cacheTTL, maxEntries, c)
+	}
 }
 
-func New(tasks repository.TaskRepository, buffer usecase.OperationBuffer, logger *zap.Logger) *UseCase {
+func New(tasks repository.TaskRepository, buffer usecase.OperationBuffer, logger *zap.Logger, opts ...Option) *UseCase {
 	if logger == nil {
 		logger = zap.NewNop()
 	}
-	return &UseCase{
+	uc := &UseCase{
 		tasks:  tasks,
 		buffer: buffer,
 		logger: logger,
+		limits: domain.DefaultMetadataLimits,
 	}
+	for _, opt := range opts {
+		opt(uc)
+	}
+	return uc
 }
 
 func (uc *UseCase) ListTasks(ctx context.Context, filter repository.TaskFilter) ([]domain.Task, error) {
-	return uc.tasks.List(ctx, filter)
+	filter.Status = domain.NormalizeTaskStatus(filter.Status)
+	if uc.lists == nil {
+		return uc.tasks.List(ctx, filter)
+	}
+	return uc.lists.list(ctx, filter, func(ctx context.Context) ([]domain.Task, error) {
+		return uc.tasks.List(ctx, filter)
+	})
 }
 
 func (uc *UseCase) GetTask(ctx context.Context, id string) (*domain.Task, error) {
@@ -36,9 +73,19 @@ func (uc *UseCase) GetTask(ctx context.Context, id string) (*domain.Task, error)
 }
 
 func (uc *UseCase) CreateTask(ctx context.Context, task *domain.Task) (*domain.Task, error) {
+	if err := task.Validate(uc.limits); err != nil {
+		return nil, err
+	}
+	defer uc.bustLists(task.UserID)
+	if uc.deferWrite(ctx, usecase.OperationCreate, task) {
+		return task, nil
+	}
 	created, err := uc.tasks.Create(ctx, task)
 	if err != nil {
-		if uc.shouldBuffer(ctx, usecase.OperationCreate, task) {
+		if domain.IsDomainError(err, domain.ErrCodeConflict) {
+			return nil, err
+		}
+		if uc.shouldBuffer(ctx, usecase.OperationCreate, task, err) {
 			return task, nil
 		}
 		return nil, err
@@ -46,9 +93,37 @@ func (uc *UseCase) CreateTask(ctx context.Context, task *domain.Task) (*domain.T
 	return created, nil
 }
 
+// CreateOrGetTask makes creation idempotent for client-generated IDs: when a task with the same ID
+// already exists and belongs to the same user and tenant, it is returned with created=false instead
+// of a conflict. Someone else's task still yields the conflict, so its existence is not revealed.
+func (uc *UseCase) CreateOrGetTask(ctx context.Context, task *domain.Task) (*domain.Task, bool, error) {
+	created, err := uc.CreateTask(ctx, task)
+	if err == nil {
+		return created, true, nil
+	}
+	if !domain.IsDomainError(err, domain.ErrCodeConflict) || task.ID == "" {
+		return nil, false, err
+	}
+	existing, getErr := uc.tasks.GetByID(ctx, task.ID)
+	if getErr != nil || existing.UserID != task.UserID || existing.TenantID != task.TenantID {
+		return nil, false, err
+	}
+	return existing, false, nil
+}
+
 func (uc *UseCase) UpdateTask(ctx context.Context, task *domain.Task) (*domain.Task, error) {
+	if err := task.Validate(uc.limits); err != nil {
+		return nil, err
+	}
+	defer uc.bustLists(task.UserID)
+	if uc.deferWrite(ctx, usecase.OperationUpdate, task) {
+		return task, nil
+	}
 	if err := uc.tasks.Update(ctx, task); err != nil {
-		if uc.shouldBuffer(ctx, usecase.OperationUpdate, task) {
+		if domain.IsDomainError(err, domain.ErrCodeConflict) {
+			return nil, err
+		}
+		if uc.shouldBuffer(ctx, usecase.OperationUpdate, task, err) {
 			return task, nil
 		}
 		return nil, err
@@ -56,13 +131,19 @@ func (uc *UseCase) UpdateTask(ctx context.Context, task *domain.Task) (*domain.T
 	return task, nil
 }
 
-func (uc *UseCase) DeleteTask(ctx context.Context, id string) error {
-	if err := uc.tasks.Delete(ctx, id); err != nil {
+// DeleteTask deletes the task if it belongs to userID and tenantID; anyone else's task is reported
+// as not found.
+func (uc *UseCase) DeleteTask(ctx context.Context, id, userID, tenantID string) error {
+	defer uc.bustLists(userID)
+	task := &domain.Task{ID: id, UserID: userID, TenantID: tenantID}
+	if uc.deferWrite(ctx, usecase.OperationDelete, task) {
+		return nil
+	}
+	if err := uc.tasks.DeleteOwned(ctx, id, userID, tenantID); err != nil {
 		if err == domain.ErrTaskNotFound {
 			return err
 		}
-		task := &domain.Task{ID: id}
-		if uc.shouldBuffer(ctx, usecase.OperationDelete, task) {
+		if uc.shouldBuffer(ctx, usecase.OperationDelete, task, err) {
 			return nil
 		}
 		return err
@@ -70,14 +151,34 @@ func (uc *UseCase) DeleteTask(ctx context.Context, id string) error {
 	return nil
 }
 
-func (uc *UseCase) shouldBuffer(ctx context.Context, operation string, task *domain.Task) bool {
-	if uc.buffer == nil {
+// bustLists runs after a write, successful or not, so the next listing reflects it.
+func (uc *UseCase) bustLists(userID string) {
+	if uc.lists != nil {
+		uc.lists.bust(userID)
+	}
+}
+
+// deferWrite buffers the operation without a live attempt when the buffer policy says the database
+// is known to be offline. A failure to buffer falls back to the live write.
+func (uc *UseCase) deferWrite(ctx context.Context, operation string, task *domain.Task) bool {
+	if uc.buffer == nil || !uc.buffer.Defers(usecase.EntityTask) {
 		return false
 	}
-	if err := uc.buffer.BufferTask(ctx, operation, task); err != nil {
-		uc.logger.Error("failed to buffer task operation", zap.String("operation", operation), zap.Error(err))
+	return uc.shouldBuffer(ctx, operation, task, nil)
+}
+
+func (uc *UseCase) shouldBuffer(ctx context.Context, operation string, task *domain.Task, cause error) bool {
+	if uc.buffer == nil || !uc.buffer.Buffers(usecase.EntityTask) {
 		return false
 	}
-	uc.logger.Warn("task operation buffered", zap.String("operation", operation))
-	return true
+	bufErr := uc.buffer.BufferTask(ctx, operation, task)
+	usecase.LogBufferDecision(uc.logger, uc.buffer, usecase.BufferDecision{
+		Entity:    usecase.EntityTask,
+		Operation: operation,
+		EntityID:  task.ID,
+		UserID:    task.UserID,
+		Cause:     cause,
+		BufferErr: bufErr,
+	})
+	return bufErr == nil
 }
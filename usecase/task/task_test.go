@@ -0,0 +1,170 @@
+package task_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/fastygo/backend/domain"
+	"github.com/fastygo/backend/pkg/clock"
+	"github.com/fastygo/backend/repository"
+	"github.com/fastygo/backend/repository/repositorytest"
+	"github.com/fastygo/backend/usecase"
+	taskUC "github.com/fastygo/backend/usecase/task"
+)
+
+// deferringBuffer buffers every task write without a live attempt and keeps what it was given.
+type deferringBuffer struct {
+	tasks []domain.Task
+}
+
+func (b *deferringBuffer) Buffers(string) bool  { return true }
+func (b *deferringBuffer) Defers(string) bool   { return true }
+func (b *deferringBuffer) DatabaseOnline() bool { return false }
+func (b *deferringBuffer) BufferProfile(context.Context, string, *domain.User) error {
+	return nil
+}
+func (b *deferringBuffer) BufferTask(_ context.Context, _ string, task *domain.Task) error {
+	b.tasks = append(b.tasks, *task)
+	return nil
+}
+
+var _ usecase.OperationBuffer = (*deferringBuffer)(nil)
+
+func TestCreateTaskDefaultsPriorityOnLiveAndBufferedPaths(t *testing.T) {
+	tasks := repositorytest.NewTasks()
+	live := taskUC.New(tasks, nil, nil)
+	if _, err := live.CreateTask(context.Background(), &domain.Task{ID: "t-live", UserID: "u1"}); err != nil {
+		t.Fatalf("live create: %v", err)
+	}
+	stored, err := tasks.GetByID(context.Background(), "t-live")
+	if err != nil {
+		t.Fatalf("get: %v", err)
+	}
+	if stored.Priority != domain.DefaultTaskPriority {
+		t.Fatalf("live priority = %d, want %d", stored.Priority, domain.DefaultTaskPriority)
+	}
+
+	buf := &deferringBuffer{}
+	buffered := taskUC.New(repositorytest.NewTasks(), buf, nil)
+	if _, err := buffered.CreateTask(context.Background(), &domain.Task{ID: "t-buffered", UserID: "u1"}); err != nil {
+		t.Fatalf("buffered create: %v", err)
+	}
+	if len(buf.tasks) != 1 || buf.tasks[0].Priority != domain.DefaultTaskPriority {
+		t.Fatalf("buffered tasks = %+v, want one with priority %d", buf.tasks, domain.DefaultTaskPriority)
+	}
+}
+
+func TestCreateTaskRejectsOutOfRangePriority(t *testing.T) {
+	uc := taskUC.New(repositorytest.NewTasks(), nil, nil)
+	for _, priority := range []int{-1, domain.MaxTaskPriority + 1} {
+		_, err := uc.CreateTask(context.Background(), &domain.Task{UserID: "u1", Priority: priority})
+		if !errors.Is(err, domain.ErrInvalidPayload) {
+			t.Fatalf("priority %d: err = %v, want ErrInvalidPayload", priority, err)
+		}
+	}
+}
+
+func TestTaskStatusMatchingIgnoresCase(t *testing.T) {
+	// "Legacy" predates normalization, so only the filter comparison can make it match.
+	tasks := repositorytest.NewTasks(domain.Task{ID: "legacy", UserID: "u1", Status: "Completed"})
+	uc := taskUC.New(tasks, nil, nil)
+	for i, status := range []string{"COMPLETED", " completed ", "Pending"} {
+		created, err := uc.CreateTask(context.Background(), &domain.Task{ID: fmt.Sprintf("t%d", i), UserID: "u1", Status: status})
+		if err != nil {
+			t.Fatalf("create %q: %v", status, err)
+		}
+		if want := strings.ToLower(strings.TrimSpace(status)); created.Status != want {
+			t.Fatalf("stored status = %q, want %q", created.Status, want)
+		}
+	}
+
+	got, err := uc.ListTasks(context.Background(), repository.TaskFilter{UserID: "u1", Status: "cOmPlEtEd"})
+	if err != nil {
+		t.Fatalf("list: %v", err)
+	}
+	ids := make([]string, 0, len(got))
+	for _, task := range got {
+		ids = append(ids, task.ID)
+	}
+	slices.Sort(ids)
+	if !slices.Equal(ids, []string{"legacy", "t0", "t1"}) {
+		t.Fatalf("completed tasks = %v, want [legacy t0 t1]", ids)
+	}
+}
+
+// countingTasks counts List calls and holds each one until release is closed.
+type countingTasks struct {
+	*repositorytest.Tasks
+	lists   atomic.Int32
+	release chan struct{}
+}
+
+func (r *countingTasks) List(ctx context.Context, filter repository.TaskFilter) ([]domain.Task, error) {
+	r.lists.Add(1)
+	<-r.release
+	return r.Tasks.List(ctx, filter)
+}
+
+func TestListCoalescingSharesConcurrentReads(t *testing.T) {
+	tasks := &countingTasks{
+		Tasks:   repositorytest.NewTasks(domain.Task{ID: "t1", UserID: "u1"}),
+		release: make(chan struct{}),
+	}
+	fake := clock.NewFake(time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC))
+	uc := taskUC.New(tasks, nil, nil, taskUC.WithListCoalescing(time.Second, 0, fake))
+	filter := repository.TaskFilter{UserID: "u1", Limit: 50}
+
+	const readers = 20
+	var wg sync.WaitGroup
+	errs := make(chan error, readers)
+	for i := 0; i < readers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			listed, err := uc.ListTasks(context.Background(), filter)
+			if err == nil && len(listed) != 1 {
+				err = errors.New("reader got the wrong tasks")
+			}
+			errs <- err
+		}()
+	}
+	// Readers arriving after the flight lands are served from the cache, so the count is exact.
+	time.Sleep(20 * time.Millisecond)
+	close(tasks.release)
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		if err != nil {
+			t.Fatalf("list: %v", err)
+		}
+	}
+	if calls := tasks.lists.Load(); calls != 1 {
+		t.Fatalf("repository List calls = %d, want 1 for %d identical reads", calls, readers)
+	}
+
+	if _, err := uc.CreateTask(context.Background(), &domain.Task{ID: "t2", UserID: "u1"}); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	listed, err := uc.ListTasks(context.Background(), filter)
+	if err != nil {
+		t.Fatalf("list after write: %v", err)
+	}
+	if calls := tasks.lists.Load(); calls != 2 || len(listed) != 2 {
+		t.Fatalf("after a write: calls = %d, tasks = %d; want the cache busted and both tasks listed", calls, len(listed))
+	}
+
+	fake.Advance(time.Second)
+	if _, err := uc.ListTasks(context.Background(), filter); err != nil {
+		t.Fatalf("list after expiry: %v", err)
+	}
+	if calls := tasks.lists.Load(); calls != 3 {
+		t.Fatalf("after expiry: calls = %d, want 3", calls)
+	}
+}